@@ -29,6 +29,9 @@ type ServerInterface interface {
 	// Upload files
 	// (POST /files)
 	UploadFiles(c *gin.Context)
+	// Create presigned upload
+	// (POST /files/presignedupload)
+	CreatePresignedUpload(c *gin.Context)
 	// Delete file
 	// (DELETE /files/{id})
 	DeleteFile(c *gin.Context, id string)
@@ -41,6 +44,12 @@ type ServerInterface interface {
 	// Replace file
 	// (PUT /files/{id})
 	ReplaceFile(c *gin.Context, id string)
+	// Retrieve information about an image
+	// (GET /files/{id}/image-info)
+	GetFileImageInfo(c *gin.Context, id string)
+	// Complete presigned upload
+	// (POST /files/{id}/presignedupload/complete)
+	CompletePresignedUpload(c *gin.Context, id string, params CompletePresignedUploadParams)
 	// Retrieve presigned URL to retrieve the file
 	// (GET /files/{id}/presignedurl)
 	GetFilePresignedURL(c *gin.Context, id string)
@@ -52,10 +61,10 @@ type ServerInterface interface {
 	GetOpenAPISpec(c *gin.Context)
 	// Delete broken metadata
 	// (POST /ops/delete-broken-metadata)
-	DeleteBrokenMetadata(c *gin.Context)
+	DeleteBrokenMetadata(c *gin.Context, params DeleteBrokenMetadataParams)
 	// Deletes orphaned files
 	// (POST /ops/delete-orphans)
-	DeleteOrphanedFiles(c *gin.Context)
+	DeleteOrphanedFiles(c *gin.Context, params DeleteOrphanedFilesParams)
 	// Lists broken metadata
 	// (POST /ops/list-broken-metadata)
 	ListBrokenMetadata(c *gin.Context)
@@ -65,6 +74,9 @@ type ServerInterface interface {
 	// Lists orphaned files
 	// (POST /ops/list-orphans)
 	ListOrphanedFiles(c *gin.Context)
+	// Rescan files for viruses
+	// (POST /ops/rescan-files)
+	RescanFiles(c *gin.Context, params RescanFilesParams)
 	// Get service version information
 	// (GET /version)
 	GetVersion(c *gin.Context)
@@ -94,6 +106,21 @@ func (siw *ServerInterfaceWrapper) UploadFiles(c *gin.Context) {
 	siw.Handler.UploadFiles(c)
 }
 
+// CreatePresignedUpload operation middleware
+func (siw *ServerInterfaceWrapper) CreatePresignedUpload(c *gin.Context) {
+
+	c.Set(AuthorizationScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreatePresignedUpload(c)
+}
+
 // DeleteFile operation middleware
 func (siw *ServerInterfaceWrapper) DeleteFile(c *gin.Context) {
 
@@ -179,6 +206,94 @@ func (siw *ServerInterfaceWrapper) GetFile(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "crop" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "crop", c.Request.URL.Query(), &params.Crop)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter crop: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "fit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "fit", c.Request.URL.Query(), &params.Fit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter fit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "gravity" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "gravity", c.Request.URL.Query(), &params.Gravity)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gravity: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "rotate" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "rotate", c.Request.URL.Query(), &params.Rotate)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter rotate: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "flip" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "flip", c.Request.URL.Query(), &params.Flip)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter flip: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "sharpen" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sharpen", c.Request.URL.Query(), &params.Sharpen)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sharpen: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "grayscale" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "grayscale", c.Request.URL.Query(), &params.Grayscale)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter grayscale: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "strip" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "strip", c.Request.URL.Query(), &params.Strip)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter strip: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "background" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "background", c.Request.URL.Query(), &params.Background)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter background: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "dpr" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "dpr", c.Request.URL.Query(), &params.Dpr)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter dpr: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "preset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "preset", c.Request.URL.Query(), &params.Preset)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter preset: %w", err), http.StatusBadRequest)
+		return
+	}
+
 	headers := c.Request.Header
 
 	// ------------- Optional header parameter "if-match" -------------
@@ -345,6 +460,94 @@ func (siw *ServerInterfaceWrapper) GetFileMetadataHeaders(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "crop" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "crop", c.Request.URL.Query(), &params.Crop)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter crop: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "fit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "fit", c.Request.URL.Query(), &params.Fit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter fit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "gravity" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "gravity", c.Request.URL.Query(), &params.Gravity)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gravity: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "rotate" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "rotate", c.Request.URL.Query(), &params.Rotate)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter rotate: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "flip" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "flip", c.Request.URL.Query(), &params.Flip)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter flip: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "sharpen" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sharpen", c.Request.URL.Query(), &params.Sharpen)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sharpen: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "grayscale" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "grayscale", c.Request.URL.Query(), &params.Grayscale)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter grayscale: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "strip" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "strip", c.Request.URL.Query(), &params.Strip)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter strip: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "background" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "background", c.Request.URL.Query(), &params.Background)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter background: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "dpr" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "dpr", c.Request.URL.Query(), &params.Dpr)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter dpr: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "preset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "preset", c.Request.URL.Query(), &params.Preset)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter preset: %w", err), http.StatusBadRequest)
+		return
+	}
+
 	headers := c.Request.Header
 
 	// ------------- Optional header parameter "if-match" -------------
@@ -459,6 +662,69 @@ func (siw *ServerInterfaceWrapper) ReplaceFile(c *gin.Context) {
 	siw.Handler.ReplaceFile(c, id)
 }
 
+// GetFileImageInfo operation middleware
+func (siw *ServerInterfaceWrapper) GetFileImageInfo(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(AuthorizationScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetFileImageInfo(c, id)
+}
+
+// CompletePresignedUpload operation middleware
+func (siw *ServerInterfaceWrapper) CompletePresignedUpload(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(AuthorizationScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CompletePresignedUploadParams
+
+	// ------------- Optional query parameter "etag" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "etag", c.Request.URL.Query(), &params.Etag)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter etag: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CompletePresignedUpload(c, id, params)
+}
+
 // GetFilePresignedURL operation middleware
 func (siw *ServerInterfaceWrapper) GetFilePresignedURL(c *gin.Context) {
 
@@ -672,6 +938,94 @@ func (siw *ServerInterfaceWrapper) GetFileWithPresignedURL(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "crop" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "crop", c.Request.URL.Query(), &params.Crop)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter crop: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "fit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "fit", c.Request.URL.Query(), &params.Fit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter fit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "gravity" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "gravity", c.Request.URL.Query(), &params.Gravity)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gravity: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "rotate" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "rotate", c.Request.URL.Query(), &params.Rotate)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter rotate: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "flip" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "flip", c.Request.URL.Query(), &params.Flip)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter flip: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "sharpen" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sharpen", c.Request.URL.Query(), &params.Sharpen)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sharpen: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "grayscale" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "grayscale", c.Request.URL.Query(), &params.Grayscale)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter grayscale: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "strip" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "strip", c.Request.URL.Query(), &params.Strip)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter strip: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "background" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "background", c.Request.URL.Query(), &params.Background)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter background: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "dpr" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "dpr", c.Request.URL.Query(), &params.Dpr)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter dpr: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "preset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "preset", c.Request.URL.Query(), &params.Preset)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter preset: %w", err), http.StatusBadRequest)
+		return
+	}
+
 	headers := c.Request.Header
 
 	// ------------- Optional header parameter "if-match" -------------
@@ -795,8 +1149,45 @@ func (siw *ServerInterfaceWrapper) GetOpenAPISpec(c *gin.Context) {
 // DeleteBrokenMetadata operation middleware
 func (siw *ServerInterfaceWrapper) DeleteBrokenMetadata(c *gin.Context) {
 
+	var err error
+
 	c.Set(X_Hasura_Admin_SecretScopes, []string{})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteBrokenMetadataParams
+
+	// ------------- Optional query parameter "dryRun" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "dryRun", c.Request.URL.Query(), &params.DryRun)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter dryRun: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "maxDeletions" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "maxDeletions", c.Request.URL.Query(), &params.MaxDeletions)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter maxDeletions: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "minAge" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "minAge", c.Request.URL.Query(), &params.MinAge)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter minAge: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "bucketId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "bucketId", c.Request.URL.Query(), &params.BucketId)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter bucketId: %w", err), http.StatusBadRequest)
+		return
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -804,14 +1195,43 @@ func (siw *ServerInterfaceWrapper) DeleteBrokenMetadata(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.DeleteBrokenMetadata(c)
+	siw.Handler.DeleteBrokenMetadata(c, params)
 }
 
 // DeleteOrphanedFiles operation middleware
 func (siw *ServerInterfaceWrapper) DeleteOrphanedFiles(c *gin.Context) {
 
+	var err error
+
 	c.Set(X_Hasura_Admin_SecretScopes, []string{})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteOrphanedFilesParams
+
+	// ------------- Optional query parameter "dryRun" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "dryRun", c.Request.URL.Query(), &params.DryRun)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter dryRun: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "maxDeletions" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "maxDeletions", c.Request.URL.Query(), &params.MaxDeletions)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter maxDeletions: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "minAge" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "minAge", c.Request.URL.Query(), &params.MinAge)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter minAge: %w", err), http.StatusBadRequest)
+		return
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -819,7 +1239,7 @@ func (siw *ServerInterfaceWrapper) DeleteOrphanedFiles(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.DeleteOrphanedFiles(c)
+	siw.Handler.DeleteOrphanedFiles(c, params)
 }
 
 // ListBrokenMetadata operation middleware
@@ -867,6 +1287,82 @@ func (siw *ServerInterfaceWrapper) ListOrphanedFiles(c *gin.Context) {
 	siw.Handler.ListOrphanedFiles(c)
 }
 
+// RescanFiles operation middleware
+func (siw *ServerInterfaceWrapper) RescanFiles(c *gin.Context) {
+
+	var err error
+
+	c.Set(X_Hasura_Admin_SecretScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params RescanFilesParams
+
+	// ------------- Optional query parameter "bucketId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "bucketId", c.Request.URL.Query(), &params.BucketId)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter bucketId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "uploadedAfter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "uploadedAfter", c.Request.URL.Query(), &params.UploadedAfter)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter uploadedAfter: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "uploadedBefore" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "uploadedBefore", c.Request.URL.Query(), &params.UploadedBefore)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter uploadedBefore: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "after" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "after", c.Request.URL.Query(), &params.After)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter after: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "concurrency" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "concurrency", c.Request.URL.Query(), &params.Concurrency)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter concurrency: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "reloadSignatures" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "reloadSignatures", c.Request.URL.Query(), &params.ReloadSignatures)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter reloadSignatures: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "dryRun" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "dryRun", c.Request.URL.Query(), &params.DryRun)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter dryRun: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RescanFiles(c, params)
+}
+
 // GetVersion operation middleware
 func (siw *ServerInterfaceWrapper) GetVersion(c *gin.Context) {
 
@@ -908,10 +1404,13 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 	}
 
 	router.POST(options.BaseURL+"/files", wrapper.UploadFiles)
+	router.POST(options.BaseURL+"/files/presignedupload", wrapper.CreatePresignedUpload)
 	router.DELETE(options.BaseURL+"/files/:id", wrapper.DeleteFile)
 	router.GET(options.BaseURL+"/files/:id", wrapper.GetFile)
 	router.HEAD(options.BaseURL+"/files/:id", wrapper.GetFileMetadataHeaders)
 	router.PUT(options.BaseURL+"/files/:id", wrapper.ReplaceFile)
+	router.GET(options.BaseURL+"/files/:id/image-info", wrapper.GetFileImageInfo)
+	router.POST(options.BaseURL+"/files/:id/presignedupload/complete", wrapper.CompletePresignedUpload)
 	router.GET(options.BaseURL+"/files/:id/presignedurl", wrapper.GetFilePresignedURL)
 	router.GET(options.BaseURL+"/files/:id/presignedurl/contents", wrapper.GetFileWithPresignedURL)
 	router.GET(options.BaseURL+"/openapi.yaml", wrapper.GetOpenAPISpec)
@@ -920,6 +1419,7 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 	router.POST(options.BaseURL+"/ops/list-broken-metadata", wrapper.ListBrokenMetadata)
 	router.POST(options.BaseURL+"/ops/list-not-uploaded", wrapper.ListFilesNotUploaded)
 	router.POST(options.BaseURL+"/ops/list-orphans", wrapper.ListOrphanedFiles)
+	router.POST(options.BaseURL+"/ops/rescan-files", wrapper.RescanFiles)
 	router.GET(options.BaseURL+"/version", wrapper.GetVersion)
 }
 
@@ -936,19 +1436,48 @@ type UploadFiles201JSONResponse struct {
 	ProcessedFiles []FileMetadata `json:"processedFiles"`
 }
 
-func (response UploadFiles201JSONResponse) VisitUploadFilesResponse(w http.ResponseWriter) error {
+func (response UploadFiles201JSONResponse) VisitUploadFilesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(201)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UploadFilesdefaultJSONResponse struct {
+	Body       ErrorResponseWithProcessedFiles
+	StatusCode int
+}
+
+func (response UploadFilesdefaultJSONResponse) VisitUploadFilesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type CreatePresignedUploadRequestObject struct {
+	Body *CreatePresignedUploadJSONRequestBody
+}
+
+type CreatePresignedUploadResponseObject interface {
+	VisitCreatePresignedUploadResponse(w http.ResponseWriter) error
+}
+
+type CreatePresignedUpload201JSONResponse PresignedUploadResponse
+
+func (response CreatePresignedUpload201JSONResponse) VisitCreatePresignedUploadResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(201)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type UploadFilesdefaultJSONResponse struct {
-	Body       ErrorResponseWithProcessedFiles
+type CreatePresignedUploaddefaultJSONResponse struct {
+	Body       ErrorResponse
 	StatusCode int
 }
 
-func (response UploadFilesdefaultJSONResponse) VisitUploadFilesResponse(w http.ResponseWriter) error {
+func (response CreatePresignedUploaddefaultJSONResponse) VisitCreatePresignedUploadResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(response.StatusCode)
 
@@ -1001,6 +1530,7 @@ type GetFile200ResponseHeaders struct {
 	LastModified       time.Time
 	SurrogateControl   string
 	SurrogateKey       string
+	Vary               string
 }
 
 type GetFile200ApplicationoctetStreamResponse struct {
@@ -1022,6 +1552,7 @@ func (response GetFile200ApplicationoctetStreamResponse) VisitGetFileResponse(w
 	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
 	w.Header().Set("Surrogate-Control", fmt.Sprint(response.Headers.SurrogateControl))
 	w.Header().Set("Surrogate-Key", fmt.Sprint(response.Headers.SurrogateKey))
+	w.Header().Set("Vary", fmt.Sprint(response.Headers.Vary))
 	w.WriteHeader(200)
 
 	if closer, ok := response.Body.(io.ReadCloser); ok {
@@ -1040,6 +1571,7 @@ type GetFile206ResponseHeaders struct {
 	LastModified       time.Time
 	SurrogateControl   string
 	SurrogateKey       string
+	Vary               string
 }
 
 type GetFile206ApplicationoctetStreamResponse struct {
@@ -1061,6 +1593,7 @@ func (response GetFile206ApplicationoctetStreamResponse) VisitGetFileResponse(w
 	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
 	w.Header().Set("Surrogate-Control", fmt.Sprint(response.Headers.SurrogateControl))
 	w.Header().Set("Surrogate-Key", fmt.Sprint(response.Headers.SurrogateKey))
+	w.Header().Set("Vary", fmt.Sprint(response.Headers.Vary))
 	w.WriteHeader(206)
 
 	if closer, ok := response.Body.(io.ReadCloser); ok {
@@ -1074,6 +1607,7 @@ type GetFile304ResponseHeaders struct {
 	CacheControl     string
 	Etag             string
 	SurrogateControl string
+	Vary             string
 }
 
 type GetFile304Response struct {
@@ -1084,6 +1618,7 @@ func (response GetFile304Response) VisitGetFileResponse(w http.ResponseWriter) e
 	w.Header().Set("Cache-Control", fmt.Sprint(response.Headers.CacheControl))
 	w.Header().Set("Etag", fmt.Sprint(response.Headers.Etag))
 	w.Header().Set("Surrogate-Control", fmt.Sprint(response.Headers.SurrogateControl))
+	w.Header().Set("Vary", fmt.Sprint(response.Headers.Vary))
 	w.WriteHeader(304)
 	return nil
 }
@@ -1141,6 +1676,7 @@ type GetFileMetadataHeaders200ResponseHeaders struct {
 	LastModified       time.Time
 	SurrogateControl   string
 	SurrogateKey       string
+	Vary               string
 }
 
 type GetFileMetadataHeaders200Response struct {
@@ -1157,6 +1693,7 @@ func (response GetFileMetadataHeaders200Response) VisitGetFileMetadataHeadersRes
 	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
 	w.Header().Set("Surrogate-Control", fmt.Sprint(response.Headers.SurrogateControl))
 	w.Header().Set("Surrogate-Key", fmt.Sprint(response.Headers.SurrogateKey))
+	w.Header().Set("Vary", fmt.Sprint(response.Headers.Vary))
 	w.WriteHeader(200)
 	return nil
 }
@@ -1165,6 +1702,7 @@ type GetFileMetadataHeaders304ResponseHeaders struct {
 	CacheControl     string
 	Etag             string
 	SurrogateControl string
+	Vary             string
 }
 
 type GetFileMetadataHeaders304Response struct {
@@ -1175,6 +1713,7 @@ func (response GetFileMetadataHeaders304Response) VisitGetFileMetadataHeadersRes
 	w.Header().Set("Cache-Control", fmt.Sprint(response.Headers.CacheControl))
 	w.Header().Set("Etag", fmt.Sprint(response.Headers.Etag))
 	w.Header().Set("Surrogate-Control", fmt.Sprint(response.Headers.SurrogateControl))
+	w.Header().Set("Vary", fmt.Sprint(response.Headers.Vary))
 	w.WriteHeader(304)
 	return nil
 }
@@ -1243,6 +1782,65 @@ func (response ReplaceFiledefaultJSONResponse) VisitReplaceFileResponse(w http.R
 	return json.NewEncoder(w).Encode(response.Body)
 }
 
+type GetFileImageInfoRequestObject struct {
+	Id string `json:"id"`
+}
+
+type GetFileImageInfoResponseObject interface {
+	VisitGetFileImageInfoResponse(w http.ResponseWriter) error
+}
+
+type GetFileImageInfo200JSONResponse ImageInfo
+
+func (response GetFileImageInfo200JSONResponse) VisitGetFileImageInfoResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetFileImageInfodefaultJSONResponse struct {
+	Body       ErrorResponse
+	StatusCode int
+}
+
+func (response GetFileImageInfodefaultJSONResponse) VisitGetFileImageInfoResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type CompletePresignedUploadRequestObject struct {
+	Id     string `json:"id"`
+	Params CompletePresignedUploadParams
+}
+
+type CompletePresignedUploadResponseObject interface {
+	VisitCompletePresignedUploadResponse(w http.ResponseWriter) error
+}
+
+type CompletePresignedUpload200JSONResponse FileMetadata
+
+func (response CompletePresignedUpload200JSONResponse) VisitCompletePresignedUploadResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CompletePresignedUploaddefaultJSONResponse struct {
+	Body       ErrorResponse
+	StatusCode int
+}
+
+func (response CompletePresignedUploaddefaultJSONResponse) VisitCompletePresignedUploadResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
 type GetFilePresignedURLRequestObject struct {
 	Id string `json:"id"`
 }
@@ -1290,6 +1888,7 @@ type GetFileWithPresignedURL200ResponseHeaders struct {
 	LastModified       time.Time
 	SurrogateControl   string
 	SurrogateKey       string
+	Vary               string
 }
 
 type GetFileWithPresignedURL200ApplicationoctetStreamResponse struct {
@@ -1311,6 +1910,7 @@ func (response GetFileWithPresignedURL200ApplicationoctetStreamResponse) VisitGe
 	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
 	w.Header().Set("Surrogate-Control", fmt.Sprint(response.Headers.SurrogateControl))
 	w.Header().Set("Surrogate-Key", fmt.Sprint(response.Headers.SurrogateKey))
+	w.Header().Set("Vary", fmt.Sprint(response.Headers.Vary))
 	w.WriteHeader(200)
 
 	if closer, ok := response.Body.(io.ReadCloser); ok {
@@ -1329,6 +1929,7 @@ type GetFileWithPresignedURL206ResponseHeaders struct {
 	LastModified       time.Time
 	SurrogateControl   string
 	SurrogateKey       string
+	Vary               string
 }
 
 type GetFileWithPresignedURL206ApplicationoctetStreamResponse struct {
@@ -1350,6 +1951,7 @@ func (response GetFileWithPresignedURL206ApplicationoctetStreamResponse) VisitGe
 	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
 	w.Header().Set("Surrogate-Control", fmt.Sprint(response.Headers.SurrogateControl))
 	w.Header().Set("Surrogate-Key", fmt.Sprint(response.Headers.SurrogateKey))
+	w.Header().Set("Vary", fmt.Sprint(response.Headers.Vary))
 	w.WriteHeader(206)
 
 	if closer, ok := response.Body.(io.ReadCloser); ok {
@@ -1363,6 +1965,7 @@ type GetFileWithPresignedURL304ResponseHeaders struct {
 	CacheControl     string
 	Etag             string
 	SurrogateControl string
+	Vary             string
 }
 
 type GetFileWithPresignedURL304Response struct {
@@ -1373,6 +1976,7 @@ func (response GetFileWithPresignedURL304Response) VisitGetFileWithPresignedURLR
 	w.Header().Set("Cache-Control", fmt.Sprint(response.Headers.CacheControl))
 	w.Header().Set("Etag", fmt.Sprint(response.Headers.Etag))
 	w.Header().Set("Surrogate-Control", fmt.Sprint(response.Headers.SurrogateControl))
+	w.Header().Set("Vary", fmt.Sprint(response.Headers.Vary))
 	w.WriteHeader(304)
 	return nil
 }
@@ -1450,6 +2054,7 @@ func (response GetOpenAPISpecdefaultJSONResponse) VisitGetOpenAPISpecResponse(w
 }
 
 type DeleteBrokenMetadataRequestObject struct {
+	Params DeleteBrokenMetadataParams
 }
 
 type DeleteBrokenMetadataResponseObject interface {
@@ -1457,7 +2062,9 @@ type DeleteBrokenMetadataResponseObject interface {
 }
 
 type DeleteBrokenMetadata200JSONResponse struct {
-	Metadata *[]FileSummary `json:"metadata,omitempty"`
+	// Metadata Metadata deleted, or that would be deleted on dry runs
+	Metadata *[]FileSummary            `json:"metadata,omitempty"`
+	Results  *[]BrokenMetadataDeletion `json:"results,omitempty"`
 }
 
 func (response DeleteBrokenMetadata200JSONResponse) VisitDeleteBrokenMetadataResponse(w http.ResponseWriter) error {
@@ -1467,6 +2074,25 @@ func (response DeleteBrokenMetadata200JSONResponse) VisitDeleteBrokenMetadataRes
 	return json.NewEncoder(w).Encode(response)
 }
 
+type DeleteBrokenMetadata200ApplicationxNdjsonResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response DeleteBrokenMetadata200ApplicationxNdjsonResponse) VisitDeleteBrokenMetadataResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
 type DeleteBrokenMetadatadefaultJSONResponse struct {
 	Body       ErrorResponse
 	StatusCode int
@@ -1480,6 +2106,7 @@ func (response DeleteBrokenMetadatadefaultJSONResponse) VisitDeleteBrokenMetadat
 }
 
 type DeleteOrphanedFilesRequestObject struct {
+	Params DeleteOrphanedFilesParams
 }
 
 type DeleteOrphanedFilesResponseObject interface {
@@ -1487,7 +2114,9 @@ type DeleteOrphanedFilesResponseObject interface {
 }
 
 type DeleteOrphanedFiles200JSONResponse struct {
-	Files *[]string `json:"files,omitempty"`
+	// Files Files deleted, or that would be deleted on dry runs
+	Files   *[]string               `json:"files,omitempty"`
+	Results *[]OrphanedFileDeletion `json:"results,omitempty"`
 }
 
 func (response DeleteOrphanedFiles200JSONResponse) VisitDeleteOrphanedFilesResponse(w http.ResponseWriter) error {
@@ -1497,6 +2126,25 @@ func (response DeleteOrphanedFiles200JSONResponse) VisitDeleteOrphanedFilesRespo
 	return json.NewEncoder(w).Encode(response)
 }
 
+type DeleteOrphanedFiles200ApplicationxNdjsonResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response DeleteOrphanedFiles200ApplicationxNdjsonResponse) VisitDeleteOrphanedFilesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
 type DeleteOrphanedFilesdefaultJSONResponse struct {
 	Body       ErrorResponse
 	StatusCode int
@@ -1527,6 +2175,25 @@ func (response ListBrokenMetadata200JSONResponse) VisitListBrokenMetadataRespons
 	return json.NewEncoder(w).Encode(response)
 }
 
+type ListBrokenMetadata200ApplicationxNdjsonResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response ListBrokenMetadata200ApplicationxNdjsonResponse) VisitListBrokenMetadataResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
 type ListBrokenMetadatadefaultJSONResponse struct {
 	Body       ErrorResponse
 	StatusCode int
@@ -1557,6 +2224,25 @@ func (response ListFilesNotUploaded200JSONResponse) VisitListFilesNotUploadedRes
 	return json.NewEncoder(w).Encode(response)
 }
 
+type ListFilesNotUploaded200ApplicationxNdjsonResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response ListFilesNotUploaded200ApplicationxNdjsonResponse) VisitListFilesNotUploadedResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
 type ListFilesNotUploadeddefaultJSONResponse struct {
 	Body       ErrorResponse
 	StatusCode int
@@ -1587,6 +2273,25 @@ func (response ListOrphanedFiles200JSONResponse) VisitListOrphanedFilesResponse(
 	return json.NewEncoder(w).Encode(response)
 }
 
+type ListOrphanedFiles200ApplicationxNdjsonResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response ListOrphanedFiles200ApplicationxNdjsonResponse) VisitListOrphanedFilesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
 type ListOrphanedFilesdefaultJSONResponse struct {
 	Body       ErrorResponse
 	StatusCode int
@@ -1599,6 +2304,58 @@ func (response ListOrphanedFilesdefaultJSONResponse) VisitListOrphanedFilesRespo
 	return json.NewEncoder(w).Encode(response.Body)
 }
 
+type RescanFilesRequestObject struct {
+	Params RescanFilesParams
+}
+
+type RescanFilesResponseObject interface {
+	VisitRescanFilesResponse(w http.ResponseWriter) error
+}
+
+type RescanFiles200JSONResponse struct {
+	// Infected Files where a virus was found
+	Infected *[]FileSummary `json:"infected,omitempty"`
+	Results  *[]FileRescan  `json:"results,omitempty"`
+}
+
+func (response RescanFiles200JSONResponse) VisitRescanFilesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RescanFiles200ApplicationxNdjsonResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response RescanFiles200ApplicationxNdjsonResponse) VisitRescanFilesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type RescanFilesdefaultJSONResponse struct {
+	Body       ErrorResponse
+	StatusCode int
+}
+
+func (response RescanFilesdefaultJSONResponse) VisitRescanFilesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
 type GetVersionRequestObject struct {
 }
 
@@ -1632,6 +2389,9 @@ type StrictServerInterface interface {
 	// Upload files
 	// (POST /files)
 	UploadFiles(ctx context.Context, request UploadFilesRequestObject) (UploadFilesResponseObject, error)
+	// Create presigned upload
+	// (POST /files/presignedupload)
+	CreatePresignedUpload(ctx context.Context, request CreatePresignedUploadRequestObject) (CreatePresignedUploadResponseObject, error)
 	// Delete file
 	// (DELETE /files/{id})
 	DeleteFile(ctx context.Context, request DeleteFileRequestObject) (DeleteFileResponseObject, error)
@@ -1644,6 +2404,12 @@ type StrictServerInterface interface {
 	// Replace file
 	// (PUT /files/{id})
 	ReplaceFile(ctx context.Context, request ReplaceFileRequestObject) (ReplaceFileResponseObject, error)
+	// Retrieve information about an image
+	// (GET /files/{id}/image-info)
+	GetFileImageInfo(ctx context.Context, request GetFileImageInfoRequestObject) (GetFileImageInfoResponseObject, error)
+	// Complete presigned upload
+	// (POST /files/{id}/presignedupload/complete)
+	CompletePresignedUpload(ctx context.Context, request CompletePresignedUploadRequestObject) (CompletePresignedUploadResponseObject, error)
 	// Retrieve presigned URL to retrieve the file
 	// (GET /files/{id}/presignedurl)
 	GetFilePresignedURL(ctx context.Context, request GetFilePresignedURLRequestObject) (GetFilePresignedURLResponseObject, error)
@@ -1668,6 +2434,9 @@ type StrictServerInterface interface {
 	// Lists orphaned files
 	// (POST /ops/list-orphans)
 	ListOrphanedFiles(ctx context.Context, request ListOrphanedFilesRequestObject) (ListOrphanedFilesResponseObject, error)
+	// Rescan files for viruses
+	// (POST /ops/rescan-files)
+	RescanFiles(ctx context.Context, request RescanFilesRequestObject) (RescanFilesResponseObject, error)
 	// Get service version information
 	// (GET /version)
 	GetVersion(ctx context.Context, request GetVersionRequestObject) (GetVersionResponseObject, error)
@@ -1717,6 +2486,39 @@ func (sh *strictHandler) UploadFiles(ctx *gin.Context) {
 	}
 }
 
+// CreatePresignedUpload operation middleware
+func (sh *strictHandler) CreatePresignedUpload(ctx *gin.Context) {
+	var request CreatePresignedUploadRequestObject
+
+	var body CreatePresignedUploadJSONRequestBody
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.Status(http.StatusBadRequest)
+		ctx.Error(err)
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.CreatePresignedUpload(ctx, request.(CreatePresignedUploadRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreatePresignedUpload")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(CreatePresignedUploadResponseObject); ok {
+		if err := validResponse.VisitCreatePresignedUploadResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // DeleteFile operation middleware
 func (sh *strictHandler) DeleteFile(ctx *gin.Context, id string) {
 	var request DeleteFileRequestObject
@@ -1834,6 +2636,61 @@ func (sh *strictHandler) ReplaceFile(ctx *gin.Context, id string) {
 	}
 }
 
+// GetFileImageInfo operation middleware
+func (sh *strictHandler) GetFileImageInfo(ctx *gin.Context, id string) {
+	var request GetFileImageInfoRequestObject
+
+	request.Id = id
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetFileImageInfo(ctx, request.(GetFileImageInfoRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetFileImageInfo")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetFileImageInfoResponseObject); ok {
+		if err := validResponse.VisitGetFileImageInfoResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// CompletePresignedUpload operation middleware
+func (sh *strictHandler) CompletePresignedUpload(ctx *gin.Context, id string, params CompletePresignedUploadParams) {
+	var request CompletePresignedUploadRequestObject
+
+	request.Id = id
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.CompletePresignedUpload(ctx, request.(CompletePresignedUploadRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CompletePresignedUpload")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(CompletePresignedUploadResponseObject); ok {
+		if err := validResponse.VisitCompletePresignedUploadResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // GetFilePresignedURL operation middleware
 func (sh *strictHandler) GetFilePresignedURL(ctx *gin.Context, id string) {
 	var request GetFilePresignedURLRequestObject
@@ -1915,9 +2772,11 @@ func (sh *strictHandler) GetOpenAPISpec(ctx *gin.Context) {
 }
 
 // DeleteBrokenMetadata operation middleware
-func (sh *strictHandler) DeleteBrokenMetadata(ctx *gin.Context) {
+func (sh *strictHandler) DeleteBrokenMetadata(ctx *gin.Context, params DeleteBrokenMetadataParams) {
 	var request DeleteBrokenMetadataRequestObject
 
+	request.Params = params
+
 	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
 		return sh.ssi.DeleteBrokenMetadata(ctx, request.(DeleteBrokenMetadataRequestObject))
 	}
@@ -1940,9 +2799,11 @@ func (sh *strictHandler) DeleteBrokenMetadata(ctx *gin.Context) {
 }
 
 // DeleteOrphanedFiles operation middleware
-func (sh *strictHandler) DeleteOrphanedFiles(ctx *gin.Context) {
+func (sh *strictHandler) DeleteOrphanedFiles(ctx *gin.Context, params DeleteOrphanedFilesParams) {
 	var request DeleteOrphanedFilesRequestObject
 
+	request.Params = params
+
 	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
 		return sh.ssi.DeleteOrphanedFiles(ctx, request.(DeleteOrphanedFilesRequestObject))
 	}
@@ -2039,6 +2900,33 @@ func (sh *strictHandler) ListOrphanedFiles(ctx *gin.Context) {
 	}
 }
 
+// RescanFiles operation middleware
+func (sh *strictHandler) RescanFiles(ctx *gin.Context, params RescanFilesParams) {
+	var request RescanFilesRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.RescanFiles(ctx, request.(RescanFilesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "RescanFiles")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(RescanFilesResponseObject); ok {
+		if err := validResponse.VisitRescanFilesResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // GetVersion operation middleware
 func (sh *strictHandler) GetVersion(ctx *gin.Context) {
 	var request GetVersionRequestObject
@@ -2067,83 +2955,132 @@ func (sh *strictHandler) GetVersion(ctx *gin.Context) {
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xde3PbtrL/KhjezjQ5FSVLjpNzNdO5102c1j1x4ontk86tc2cgciWiIQEWACUrtr/7",
-	"mQXAlwj5FSd1E/3TOhIei90f9oUFdB5EIssFB65VMD4PVJRARs2fe1IK+RZULrgC/IDGMdNMcJoeSpGD",
-	"1AxUMJ7SVEEviEFFkuX4fTC2fQnjUyEzip8RCbqQHGIyWRKdANk93O8HvSBvjHQeAHa701QxaMpS1R0y",
-	"ppquH1HLojPgbtWSYGciIaUaYqKFIdzQ2CNsSihf4nx6mUMwDsTkD4h0cNkLMlCKzgzL2iP/UmSUhxJo",
-	"TCepG4m41jgSnNEsT3GwlywFwoUmU1HwuJ5Eacn4LLi87AUS/iyYhDgY/17N+L5DzaWHvpZg3zGdHEoR",
-	"gVIQ47RqI+q/p6gNO1bk2CbqFVOaiCmZ4tdEJ1STBUggqoiw37RI0yWpBiETmAoJNSeIiKJCSohxAUxD",
-	"Zqb4TsI0GAf/NagVycBpkQHScQCaGsnUUKRS0qUfm60et0PHc5HlEhLgis2BZG6QFjLpRBSaUMMAwjhR",
-	"WkgnkDaUJkX0AfR+3OXh/gvkIPLEtiGR4JoyzvjMfIpDtwVcKJAqtK274u0FkQQE3a7uTnbMMlCaZjlZ",
-	"JMCr8cmCKuK6tecabY22w61hONw5Ho7G20/GO0//L+gFlgPBGLcIhJpl4CMENJ11adjjmukl0XRGpkKS",
-	"iEYJkDlNWWx42p7/NKDDySjajp/AzvTpaeCbhnm4esLZnwUQFgPXbMpAmrn8/Ix34NnTCCbhs2d0FD4Z",
-	"7myHk2cxDYfTZ9FwuDMZTacj77zqJE8FjcEz/7sEdAL1jCShikwAeHtvFG6AFkFWsbjpJkKkQLnVDdfB",
-	"2KeSnhdKi6yGL1VKRMxopQXTiZ8n5wFNUbyHUhjqcxbpQqKQI6phJuQSd92caioD367LWAbH5sNVxhzs",
-	"H+wRbF+ivisPltEZDP7IYebjOqeZZ9jXNGuNSBiP0iLGTQRnGrfwKrJyu7TQLa3/R+6dTrGPnumO2MfV",
-	"6chkqUG15hg92Xn67J+N3cK4fvqknoVxDTMwHCzy+C57NqVKE9d3zcZ9erz13+MnO+Pt0c03bgnLn5Yn",
-	"CuTVWgu1EVkkosLyGqnSSTQcbccwfbLz9FqjxNBuGUk7CfRqDer0SlPPNfnX2pcNJL5fYxyOiiyjiOdb",
-	"2YafqGLRwzcF34pq/KJK4QqsNkDaYIEPem8KnRd6H1XdS7cnkfwpLYziVXa89npsH2IxZ6RmVKV1v/rk",
-	"RAH5nhZafG8Nq+AauCYcZkIzC9IJRT9McLIbRZBrkgCNQSILeJHhYrA77jk7vdPBC5jkCGOO/6BzNsUV",
-	"1UxzjTsgOJSg2IxDfPL21R3Dvud2AyhCSV6ORk7evjILjJmESFvJ4jhmiR43Hs5yZr/0KNcECGpBs2Mh",
-	"EjxWpOCapQY0OJPpvaLYt59ubXm1uEz9U3SJ91BdczTROlfjwaDUIe6bfiSygRH2wGrT/8FBKSL1x7Pl",
-	"x2uRiuT1muzwAfPty+ejf45GL6j27Cj8FFn19uVzgq0cFlvEHxfQI8MR2S1mZLQ12iHD0Xhre7yzRX4+",
-	"OEbhUK1B4mj//+hA8IvjAi7eQXxxnBQXLyW7OKL64qjgj3vk9DQ+H/ZGl+TRr5RfvITJxQGVF7u5vDig",
-	"y4tfC37xa5Fe7BaziyPIL95E+uK1mF+8gOix6frk0vxvdDlu/Y+cni5++K7DrF5wFs5E6D5Eo4vcODF2",
-	"5ROCiLKbjY4iyskESottgEA5gTOmNGqlUgG38Xs3v+/EzRGt+H9a1C7gDTzAchiraZpTtHxBpzi9zuAa",
-	"5QwLgt84gtiMl1Fy1wxJwJZxaL5Zp5M781r9ex+yy6WYM3RtjAdmLRPKixIOizVS85neN7lLDjih7L9o",
-	"216yPzWRezlfj1BycrL/gixYmiJwZsBRY6w6e3a4kMXhcLTtU8X3EzrcEjquu0FOGy2xiIoMuL4NXNZD",
-	"pcs1/EpINmPIa2xjgFYysVBr+Fe27FtLdwOI/RskOhH7tR94VwM3tyN5XEpcjDMERIGcs8jrVLI0dtT4",
-	"TVA5AS+yCcjSLVoZmJhx2swZ9kf97WttS4sAb9pQQVRIppdHUQKZpXq30ImQ7GPFuQlQCbJ0iIJf3x13",
-	"nKDdw33yAZYGC647EKQElDZm1OSKjH9oBqspR6uKQvst/IWqQtJwN84YD48gkuCJu2wjQrER+gYStJkY",
-	"d+yERh+AxwPzJVMa7el81Z4zHKXysCys10xeZ7Jy9i9YBpfIMIQCkmWigMhQCBllKQqhyHMh9f/yRCjd",
-	"Z6Ie/zV+Qo7s94FzSSqHomp/ucpW18/BAZkckt0KFrjmjHI6M1qPx/YLZ7GU1QW5WICcFimhxos3/qcU",
-	"KYloTicsZQaqvSBlEThH0JG8m5sU0Cv7BRn1tzp0LxaLPjXN+kLOBm4MNXi1/3zv9dFeiH1wfzKdgm8x",
-	"QS+Yl5sjGPa3bHORA6c5C8bBtvnI+CaJQaZ1s/CvXCgPOKxtIYKjoiGZkFAmQQWhROUQYWgVu0CuXwpE",
-	"kQnVUdIwIYZ1YsUuVBoX+Q40SmpFV/fMilSzPHUT9wgwE1Y5Ldgeg6apo09IwgU3GqRCK8aiDWuJUnIb",
-	"6icRL0sIor5Gb8RMS6UeoKIKS6tiM7SGYZ5oN/RZw2MqZ6DLWLcRjy4SqPhZKm1UVKtqG6Pf0DJE+awe",
-	"jvD7++7Eu1LSZSNvXeYuWmnoKlMyYZzKpW/8dva5trK+Od9cK+Iqf2JlfVAoTTKDFmvO4lpr24URM/GN",
-	"c+ced8iXQW8qdcdAjzrv6A9z6mCXQFyavIy1LZdRa1Q4r/yR5nzoghgCbKhoFjTaGq7gj+Z5yiKD28Ef",
-	"ypqNdeC76fnFmvOKhnLTCTBZkX1/5xVNbq9Qe1OuK3/exKp4l0+4BQevWs51B30eCvdaBz0kLnDzOCJb",
-	"bkEw/r3jEPz+/vJ9L1Blhq7UulOnpDSdqRKmKniPo7nw+JzFl1bcKfji2EOQGcW1pUti25SZu6kUWZW7",
-	"I8cJU0RCJuagyEQ0PN4qvYK4Zlo18+pNmLSV7AszFzLLGBtJM9AglVn7dVm6ZiJLC0d26Wag4aqdAJOS",
-	"am+sXkPEq57c+86me9JlmdngLaRZCj4/0G4OK8MbQxa2ui26rHDMIB5w9YKZz0t8C1oymIPBQSwW3CAU",
-	"BYXLqgYs4dLwBCLBq8Pi0n/tuThbS8pVFQionhldUj6rXV1jONAOM5pWE6su5H4GfT94c1PcC+J6HfPI",
-	"06U752+kay0FRsZck71jOrMWEf0YXqV25zQtQFXh3zrPm01D0zn4PITFApQJQ63Npnx5e/rQM7tPIpmu",
-	"k+uZiK1LSqfaZd9nbA4crTVcxTPXL1SMR9Ci66od3Ewj3p5i5OMnUV3wz0O3SdiTPwuaMr0kj4bhcGvr",
-	"cZ+Y5Rh1Z/3JXw/3fu6RdzA5NDv38PXPldEyFP9ZgHEpHcF/tsjL6BnLiiwYD7e2egEGmPZf3Yxzl74D",
-	"25ckwGaJRlIkKIyQnV4RZJEgqzPKqqMliuGKJkZndJfSOGJYQ30brHejd8FiNK5fgtzFJ5D7U1pYENpp",
-	"CmWP5pgiis0yarf6XYia+ImqTxls2sa7lT7fqZCP0umNt1L3iMtD/Vtj1MTUHp9bBDiLalyxOstnxxjb",
-	"hj8qTaUOga9Vp2bgFq2Ngwc7xqPT0/iH8PQ0/scF/gf/+uHxo57348f/+M6TA+t6TltX+EAi0qBDpSXQ",
-	"LBifrwmiSgmVfIhbXlfQcyu1CTQjsNCs1RPf7KYLulREgdEEtkCBlDmVGOaQoq/Qz8RHlqbUpFaAhydH",
-	"g1hEavAOJoNfjo8PB7/YCQft2a60UMFzGiUQPrc5IM9Bkjm7Y+hWl0VAJqcBUUI5U9m1w1smhS+YyoVi",
-	"/sO9fR4j60FVBtuxViWiSGMyARIzlad0CTFhPGU2m0MxWCVUaxolJkt9M1JuUe9yzYh7d6mbumbMV1Tp",
-	"8MDZxDUHe2iqzGFot8aktKatWW5STHLZC44KKcUMm6yFg0FLlTGM2+BQZf8SJtcstZ7vX7BcN1eZyb3d",
-	"4Dj8aOvpp+zxQ+eyT2+717+9HWVV+Pgak1FVBzPrSpYKebNtN9u2sW231yY1TNBWxhkmWiihbePzRjqf",
-	"cbI/reQRHpnGQuKHrzF2OzCxX7lpv+T2/Rzg+7IIwBmfDEeeZJ2EWhZTylJXOeFJoJSsJ49QTCiMHsrm",
-	"pI4Gjch6bYE93kjq1pJqZPyuysy1OftbuFfe0vB1clcRygsZ19Bw2+xemZtbm99DQq9I8BnNWh3blEBb",
-	"MJ2IQlfpsWat5g2TfkaBu+yQqvDUKARYm9QrjxZ+qRTOp+X4ogSiD5sE3ybBt0nwbRJ8mwTfJsF3+wTf",
-	"moyYx+1uVrqV1nST8/p7ReivgM90cov7Ub5xG9txE5VvovJNVP6NRuWbMPwbCMOfY4hZ2oS6at4Xj+eF",
-	"t94mT2kEnWsztkyPw6IyeNaxzCWUtcqVIt5/0SfHSaNcm0xFmoqFwiYKiNKQq/EpH9pm9U0+Mk3pjLDK",
-	"uzAl/fhHRuWHenwTkdkyN3N55pSP7EitnL+pLDOLqVLXZTW+Ky8/5dt98rKVdmCqujz0CP3oHslYBubW",
-	"Uq9BaI+AjvqPT/kp36NRYlaEfakWGYt6ZFJo8wqF/QJ3r+ohq+ZMFMqu31be2jiNoHuJkopoimGdSHG3",
-	"I5X9U95JTjgR3UvVkePQ/ZW53VNZs3E8vHeaWjKuV2BfvWgitnkbe32NcfPmztXVvZ2Lap4bK2uOnB2N",
-	"6PitFu/y1SXdtY53696KA1cW6V9Sq1Kx3GcPrVSxwfdbVyuWinBNOrNdCzuoLqC6K6pX1zK2r6s2azLq",
-	"coy96hppuWexLVOnPMbdnjH3YE99lX3KZoXt4VEaLqPZvDP8ScrjMxXG3h+Kvbej16F5RnUCcjUs/ivQ",
-	"fCSy1dd77gDdG+OsgWxnHK/G9sBxQV0P8rJleRFkHSRtnftDgmUnQ3SiGrwsZEqAx7lg1gCVd0ZtGqtl",
-	"yddkgH4Ld7OP4W46E5LpJHuAtD2XYNhL0wdI3AubuX5oZO3ZhwweIGVH5SsGD5Q2iOsDtge3EzCeUkUW",
-	"Hoj4QfLP2YXwWHwAHvyVBJ2Fm0PMzSHm5hBzc4i5OcTcHGJubilsbilsbilsDlY3txQ2txQ2txQ223ZT",
-	"D7E5dN/cUtiUR/y15RFXnU6sHoT0AjiL0iKGrDwUcQ+a9Zc0u/KUr5Dc1DqQNznw3cN9YldAYpgy7hBs",
-	"3qVkiuwe7vcITVOxQPxEKTNUaUEKjgzTRq8nQOicstT+NoXL0tmLDZmIIfW/S+JmP8ohCm4VopyF5Qo7",
-	"rF9/0r12rQ/gVNgeoVUo+Bl0LRqr1qPVOpnyDc3y8y4W1MC+0BNOpPgAPGyWEvhftfvJNGxVmxg2QYzO",
-	"UdZ6yjahqlkZ8yPRsgBTWGKOKrEvF1XxZ7O6xaQOzRUX7V5YYtbzMi8t1kU5ZhqXrbVw9TzJuO6NJbuU",
-	"g2Z9wicc5K5/kPfGz4CV78zf4FdLPKeunneXyKQtrb8Gxrx5GOw7C17z2uaax5dW11TjvX5bswtwIfOE",
-	"8iuea3xjGlRPutH6uUbEGP7T1Ilx3anEKhDpc3S/vC98fTb8lhTXDzLeG3yrpy0r7F7zsuGdESpabP8a",
-	"AKpW13QNQFOm9Demf18xpb9i7YsTFF+d+kWhqdtqXwNuLnRYNH7sw4/sY8QSU73rQGwqaj8XKo0ufS10",
-	"4+dhviJcJhB9qCwcF/rLvQT6BZDZsNZojvn37uS1qEV5PVS/KjcB+fK3dhIqRfo1eQkWrTf3Eeb1k/1X",
-	"Visq+zK/iahv91MB5ETBtEjtE+6CMy1k+Yh7DJNiNmN85o3Oy8f8P2MNrOfnEzzC+bdnvSvl3e5c4mFG",
-	"8uUj+h65NbM6S6UhQ1gY6Mm5v7q0+07/kWnbeTL/XBWTWGSU8ct++Z7xuYQZE/yyb38HQBZ8MB8GiGBH",
-	"xbnnxxZWEw3u5Lr9sacGhWuQnKaNH0UgLkcR20PzvJikLMLxVT1sncboDmmvwlBOZ/aqQmM31Qf/Tod0",
-	"VrLulxrqro3Puv1LjjdWY5/taFQwN8Yqc3SegYycV0BQ9rIYuHx/+Z8AAAD//yyNKChHeQAA",
+	"H4sIAAAAAAAC/+x9aXMbt7LoX0HNPVWxzxlSi5eco1en7lO8xMr1VpYcp57lVxecaZKIZ4AJgJHMWPrv",
+	"t7oBzEKCIinLia7DLzZFYmkAvXej8TnJVFkpCdKa5OBzYrIplJw+/qDVR5AvwPKcW/4YCrBCSfyF57nA",
+	"z7x4rVUF2gowycGYFwbSJAeTaVG5tskbMHVhmRqznAaQEzaicVnpBx4maVJ1hvmcUEvI3cfuYO+mYKeg",
+	"mZ1C052dc8N8j5Tx4pzPDCNQmJIs1zOma2lwEjurIDlIRkoVwGVymSagtdKxaWb9KTJVF7n8zrIRtFOJ",
+	"MROWjbkoIO8Mb6wWcoKjh+44wd80jJOD5D922t3e8Vu981QUcFyXJdez5PIyTTT8VguN63/fjpE2u/Kh",
+	"mUqNfoXM4lSPNHALrzUYMZGQv60KxfM38FsNxm54YI/BclEYPDHOxqIAZhWraUB2LuyUcVaFefz3iyc4",
+	"qrOPYI/8EY55XdjkoPk0P+UJ1xOwzHViIgdpxViAZudT0EBHQYCci6LAIzBWabfn8ImXVQHJQVIb0AMH",
+	"jokdhoig06vK7QnLamNVyY4es7HSzXxDdjRmUllWaXUmckIv9vbt0eMGkAlI0NzOw+KGG4h8sLd/bxVm",
+	"xI/G6nrhZB45KENnPBdujMoEt+COpoG8C87nhBe27S5KPoEkTTJuYaL0DM9FZXUJkjBpAbVKUcIJfdk9",
+	"Sl5Vhcg4QrajMgt2YKwGXi6c7YujF08YDooIFeBL6ZNHqrI2ltUGz1kYlilpQVrq0t9VAnynkpPYjkpe",
+	"wuIBv+Ql+G0SE4mfojsUDgx/wIGG0UnmKJNmjNHiE2Qqb8BUShrYkPioLxNyrHRJm8s02FojqY0cTzp8",
+	"fbRIbg0f23iq3FH74pCbo+dh05JhZ6ahQNIIu04wEtfkcjZMIhtXgjGImwvH+KwuuRxo4DkfFX4k5lv3",
+	"DxJZKZHsWNUyX3mGYcbFY7xcdbDvhJ2+1ioDYyDHac32qP93HjVtx9w59oF6LgxpMMgfDLNTbtk5aGCm",
+	"zrDfuC6KGWsGYSMYKy+2HPwqy2rtJZawUJp1NIKgd3WYMteaz+K42euxGXY8UmWlYQrSiLOOztPFTD5S",
+	"tQ3qgJAkgP2BXCX3u7McPQ4CwLUhRs+FRIUwzpRRopuBax1j+RnpPPmhXZzsRJRgLC8r1CBkR4Hghvlu",
+	"/bn2d/fvDXb3BnsPTvb2D+7dP3jw8P8laeJ2IDlAEoGBFSXEAAHLJ4swPJFW2BmzfEJKRcazKbAzXoic",
+	"9rQ//2nC90b72b38PjwYPzxN1tVg3krxWw1dlamnwPTmyB/A9w8zGA2+/57vD+7vPbg3GH2f88He+Pts",
+	"b+/BaH883o/Oa5w6uUohpx2ecsNGALJPG7UfoAeQYyyLSvnNKEeNZpSvoRq91oqgr0Rmaz2nHfEzbrle",
+	"QzdaqfbE9JlfK9hUoemMyITMijpHIoJPFkl4HrMqt7SBX9rw1yo6ncm4PLbc1it503HbEvuJ3yNgHovf",
+	"58Fko5kF04Nt//6Dh9//s0NlQtqH91vohLQwAdr5usqvQ+sFR9XS9V1C8A9Pdv91cP/Bwb399Qk+oPMP",
+	"s7cG9NXcDrkYO5+qhgaWYAMfZXv793IY33/wcKUwE3niMcSfQNpyXs+Puvyxu389eu5g8IclQuUNIGpc",
+	"2+jHzsTjvfBA9nQmdG3AMD7hQl6h4cSNchqma5DTFGsZ5Nh1I2M8TX6ruebSCrkW90OcGxd8MoGccYMi",
+	"FDKLn2XOSnXmNKR2yE3dFbRxVzMFauJUoogCtgSdaF/6a12GDmFrNsOHH7gR2e3XKP4qEvYPlS1XsK4O",
+	"z+psQQz1jlBWPhW274TI1BnoBY/DM3VOayD5yoRhGpBH5k4+jJSdsnOiyCnjGthEnIEcMhqLZVpVptPb",
+	"KtyKwvXKRel2waQB19hY2G57VDUECaGSVUggcsKEbTWQEc8+TjRSJ8tUgUYSDW+sBptNoTuUmEilXX/D",
+	"uKkgs0wj8RDsQhqRw1XT42ekMQ9GkiYg6xKPIGybX0NCjLHAQ6BBkw/dMw6NF5DWHUkhqkVMeiw0ZPiZ",
+	"9q8QVXdZsgPJVGnxO0KBs58h0Wf0Ebe7D0avZRyWHzU/E3Y2hyIgbQRH3k1FNmUV1zZgfIMtH6GyDlWE",
+	"xb8RJSrIh4xbC5JW9RHAY0mpjGWGFwKkZWPgSAaGDgik1apyIotr4A4HqL0zxLsHEoBspqAfaYC50wgt",
+	"41twJMdqQ9Z8tMiUpd8MXAZnVcEzmKoiB43HaabqnJ1PiT9YRu7WCNMuaj3lZrqIGj8UtX7GzZTdmVpb",
+	"mYOdHdd2aKZ3e1N1j6XPdZ4/efbzQ/nuh/3Zx39WM7XL8zd/H37/8dGLXP4atROR0I4rnkV43iP8jRn8",
+	"sTdjysQQhszoyShlo8E5QwuunH3sQ4I/x2bMVSkkl5ZGj5CH/9mxgDkElGwKn5g7k/5s/3GPPwT+IDbh",
+	"FMRkGtGNn9H3OGglPkFh+nNxw3JhqoLPyLU9tqAZ+nVngesojXi9aK7u7f5zN6ald9pH7OFfjp52R5zb",
+	"7z2vvuUKDCp2U34GTMn+2e/FZj0XuY1g2jv8+uaX/q/9yNLnZJyDqDmW/sb0EHIeV9KWdGJi8JWuplw6",
+	"P9XNxeO4ZMoP3Cg11wjINRrw1wnGLej96wfigt7fH/i/YDZvo+Lnjia6ltZ8VVjuVW2r2jpJ6S3LrnAy",
+	"Tg2aC0pRH88B8D+PsjidGbK3Bth3vLbqO/othEskTJQVjrBG3ECO+32YZVBZNgWeg+7IG+yepGF674E4",
+	"h1GFB0+qAj8T4yRNJvSvFWP879dPRV8Y+f4L292GId88v2Yc5JFTTUwv2Pj2zXNac07qhTs0HIdWHZFC",
+	"8KkSegk3OpkCs6J0tgdkSuaG1dKKgnAAZ6Lecx6Lew93o4yv1kV8ikXgI1B3tBwvEwMO+l+GmSp36Px3",
+	"nJvgP3FQUjb+/Wn2+0pMRfDS7nbEcHUheHxzB6ddILoTSPaGnzvJYhYCBwGbl1qD1z/S+Zj1Ruc7FlDk",
+	"VwR4Pkd0gB5sSP3MjUJKFKB5zkmHnDGuIe0GDZyjBBtHYyfrWalqfMNGahTHEav9epw+XBdWoFq9g/xr",
+	"QJ7Y16+OTzoosATj7/WQfZmNHjMmHXb7I1qJ5m+ePtr/5/7+Y24jIgG/RfR58/QRw1aeC/cgPqlRW9ln",
+	"h/WE7e/uP2B7+we79w4e7LIfX5wgwnJrQeNo///OCyUvTmq4eAf5xcm0vniqxcUxtxfHtbybstPT/PNe",
+	"un/J7vzE5cVTGF284PrisNIXL/js4qdaXvxUFxeH9eTiGKqLV5m9eKnOLh5Ddpe63r+k//YvD3r/sdPT",
+	"83/8bWHr0uTTYKIG/kt0muJuHPfcviudeC12tm68IaOoGatAkrlrVccpx5RuvWAZD5JbnUvvrmgifFya",
+	"K917HfHlZ0rSJCPdIU3CFEnQIvpGk2+1gNJvySv6BaGz0M3FBDMuEWDva6U94pLBJ2FIzYqrVteLdrz1",
+	"c2RflhIShnEaRneKXgTE+3miIZAlviQ4Z3LN5AtNORc5ZV8sdSEtzOuk1E2cXcjucUa/kw7OYy3hfMmp",
+	"bbOJrplNtHGezuKu4U9Ki4nAvQ4pO80m1mbJ/q1M7lmA9WfQ6O3reEiuqwyduZEiHvCOxcEM6DORRX3g",
+	"osg9NHG9J0wg63LUCv+5gRmN09+cveH+8N5KOdsDIJosYyCrtbCzYwyjOKgPa0teu2bnRsA16GAIJT+9",
+	"O1kwfg5fH7GPQPog990h6A6kqlGYhgxHGqyFHBUJPLRfBs+4qTUfHOalkINjyDTEPCPUiPG8dNqiBksT",
+	"I8WikxZkvkM/CmM1t+JsXmkXOEpjWTm0XjJ5AyOvxH8BJnBcksgiVx05YTOCEEouCjyEuqqUtv9XTpWx",
+	"Q6Ha8V/iN+zY/e5Vn1aHatovqJ++n0cH3OQBO2zQAtdccsknzhmQux+8xDKOF1TqHPS4LhinoANp6loV",
+	"LOMVH4lCEKqmSSEy8EaDB/mwosSH5+4Htj/cXYD7/Px8yKnZUOnJjh/D7Dw/evTk5fGTAfZB+hS2gNhi",
+	"nO/YEUeyN9x1zVUFklciOUju0VekmU0JM50thZ8qZSLI4WQLUxIZDSuV9tY3oSUzFWRiLDDRh7TUYTgQ",
+	"w0bcZtOOCKGtU3NyoeG4uO/As2nL6NqeTpEu/MQpA0F+Fs8F+2PwovDwKc2k95k12HqUNyty6U2OtMHY",
+	"H1Q+CygIkvYhor+3SeH4KRacG8Sk4Yqc3oBavYRe9qI2TucDaectIrNZvi92ef9hEa5DzKHqJHOpTg5z",
+	"k5vVpAGMhOR6Fhu/n5LVCuH3H1ZrBosY0CQHOFSgfSgJmZy0y/sW3fsPjCYmaqUBQFo9m986YU0jwNfK",
+	"O4soVbHss3kv2PsPMaGwaASLokn69SlmIcDoDgNX01BLJ/O9nQ8VGQLAOSdoQfu7e3NY3E1O/tU44bMM",
+	"hdfN/VuS69dhkXYKQvduNdxMrl93t+egXXfXTTxY7ASF90ZusINXLWdVkmwEwie9JEmW1xT2dED2lIvk",
+	"4P2CWvH+w+WHNDEhLSHw7rFndZZPTEBTk3zA0bwnrXEI+YmWSgN3tyK4rBBHNVgt4AxWOLmQ/PyeNp6u",
+	"Uxl3daVsNKtQ/SUrW5hGGWSo3oWxhWHENErnVEGr81QCKpWZjx07fvudYRjyZoUohTVD9kpm0JtVmH4+",
+	"kvuDSYDcEIgjbIus1vYTWmkPRpCpEun1jIsC83SHp3JB5ESvpFwpfK6PdFdef4lgXOeOS7Oo5txunOFc",
+	"Bfkyr2sE6NfzHkyf4NWj7a9O0msQMMEV3EXzfteNCdqT38I4LW17EupR92eRX7aRq0Wifg265LjMYuYD",
+	"SU2GmlZl44BmJ1NKIMHkLeOyQDpOKkdLlI5hTWs95z0h0KcKitqRy4kUUs1LsKANbcQGLl3EVr80b4qg",
+	"ctsaCmIRi9POac9bex8WMPz+4paR+O7JkRD6ugU454WGiyqEyOimqOYOh/nA3rzoSJNJzJJ80wgDmTee",
+	"Tc9sy6oZMKBLx1rAEEW4RhFs3DQkHmkuTeMsMKmTO1xOWnOYtEfU1QUvmonNIsr9CPZm8M1PcSMYly7o",
+	"yLKY+Rsw7azCQUBnLC17csInTi0G46wz9/sZL2owjYtomXUuxgPqnHwdwHIFhlxVNAnjcrY5fGi93SSQ",
+	"wrb5gqXKndnq0h3s1Oe+sZxbuAKm0G9ghMwgSdek4G6gZXOIcR+/COpafh24KZiPObyFsDN2Z2+wt7t7",
+	"FzWsYkYZJMIZlT+9fvJjyt7B6DVR7uuXPzYqKUH8Ww161gL8Ww+8kn8SJcY69jAciU4o91cs8WTh4oHr",
+	"y1zuCYLi0h/bhEaXvFVy0WTLdrMLF5fSST9YAn0fWa8HL+XM/DHgnn8BuJi/1skjqjsKu5iU3JH6dYAa",
+	"xYFqw9HOtRslpa+XMRKDdLw2KS2mv0Sgf6RV1c+4tU7nyiyXkwLSxRyuxvvvc8e8fUKY4xK4hsxNeMA+",
+	"pbOUcCt1FHGds8EU0N6iOzHe09P8H2n/n7/F/OmfbyRLeXPYx8KufV5NlnUE3s0zZhE+l1XtF3Id8Cc+",
+	"qXejJYRM4Mgy3ijLLXTgzwqVfTwXBtytW2E6MZQcJhrAXAduTfPEufr+912uvpt6r2sBr8bJwb9212FD",
+	"T3tJ1dfCi0JUGyIG9ojAcjzluvI3r26aKxo39vV54yMlMa+8z2Amms9Mxgu4Jka6zjENrUlnjGAeGZEE",
+	"CKbCpuyXF043OHp98qh1CfezYns5qQsZtGOhzbUYGrKlakP4Xao0hldxjsrbOP7eARL/2FO/C3pSehOm",
+	"Mms9mYxGTGn/ifMhe+ysRePlu72exGzuUizjze93B//ig/Hh4OmHzw8v73T/3L+8+59r8enHQDEyEkBO",
+	"5Uh7jNnTrnDX9sW1jiOvdJxTPIhqf8uxPVzh4XP2I3lPwPbvSw2Z02VbqzCkwBJ4DFm3Fjk0bhz0K15n",
+	"ea7vZgbNGzJ11dhdG3V6obezyUHT5gcEQU8N/20s13YAcqmRRQMvQxg3xh0U4oPT0/zvF/gPfvrH3Ttp",
+	"9Ou7f49h0aI/ZfcKz0ivfsrB5yWBE9+52Yc5v1/qV+pC76TGDWitkZjGocvLRoxAVy9dzGUhGpvDGRSq",
+	"Aj0s1e+iKDgFZUEO3h7v5CozO+9gtPPs5OT1zjM34U5/tiuPOXnEsykMHrno8dKbQ+hsC5fmKRoK2ZRL",
+	"YcqVw7tNGjwWplJGxBNFj2SOWw+mMeODL36KeWeUoRYuBzAhC+HiwNwwLhm3lmdTym9ZD5QN7oevGPHJ",
+	"deoMrBjzOTd28MJbyksSIpHZUWLt4t3qYGP3ZlnnEjXmHtZaqwk2WYoOhC1NrkHeRw4T+gc0WbHUdj5M",
+	"wlgyV8gB2XTwn7mOjOlDEN6echGawBhYDhXIHB1Zw2B3NTfYPVN1Fh0K1akyIF2ccfzvkL+/FB6EaH/3",
+	"4ZfwnNfesTjelPf89SjciZSDFSKsqe4jZA8Ptmxky0ZuMRu5tzQUJFV7cIx8rIHUXFSjkyglJDsaN/gx",
+	"OKbGSuOXL9Hj/YI85n55fyg7+RrE8Edj5O1Dmvt7+5GQq4YWN9xtveCQnA+DNUDfORo75EgRV97KsodC",
+	"aR+B7m4xZyPMuezHba+Kr/Z39pfBk/hdzSfdcm2h4NwKGDaN0YYI69IoLQJ6RZh2TOGE4G4JiBaqJYQg",
+	"Z++2y3qhWxJwPsZnGnzqpHwvDc2G9K9nDQP8skhtNoXs4zZMuw3TbsO02zDtNky7DdNuw7TbMO02TLsN",
+	"027DtNsw7TZMuw3TXjdMuySuGXEOdm86Bxt7G7n83xXXeA5yYqcbVHeOjduRjttYxjaWsY1lbD3S21hG",
+	"JJaxDV78BYIXj9AxH2RmoyBFoxhVHb1rRiVyF8pKOYSWcN4oBM4dRyqdq+XRCKqjx+5OcROJYGNVFOqc",
+	"qMoAMxYqc3Aq91yztjA3VbZnotG+XFlPq1jJ9cd2fPJjuwvcVFzqVO67kXqZJORJocXkc4U3Q/mVU3nP",
+	"lRZrjS5hwqDsDnphUlaKEqimWdoBNGVgs+HdU3kqn/BsSivCvtyqUmQpG9WWSuO7H5B6TYpbdSZUbdz6",
+	"XWUK591myCbwpDKOlx61KpDaEcrYtWd/RDdy487v0M1d8byhsh/xeqov4bx/xu0KaFU9jE3SdYpsrPuC",
+	"YqSQW6Si05LESg8jKsbzZSnk/JKuW6Fi98Yuxs4tMr6k3i3dQGe37ZpuZ983vqkbGOGSIHD/HvgOaQGD",
+	"UHHp6lu8iKndQv8dR07KsrZceMryfhlvVy091Dc/lXPFzJuq6kaxjOrFGyoY6Di0q9VCA9NAVGWdO6YE",
+	"vt76qWwKrjvr3teJ8qpH+5xQypqnEsNHLO5LA7s/sbyvjzucymVR6bai/Jfwsa90P/3mCKpdZgR9j3xR",
+	"9taZMeF2Cvo2VF04VuX8A3PXoCOP9mLpMwBrFFqYr6WyE26+X1FUxbfAaVwnRqn7sPyRWVIimqolrSJx",
+	"KinfAXL3oJCxSwuhpD3+Xcy8WKpceVKwfJKeSiI/X720+1rRfBEUYVjJc1hRBMUvc7EMyp9MUYsB8zEz",
+	"YHsPs5JmxGpZgDGNhtbZ+ikVMBaG+QenYk5F/9OfQ9qrZKU7jE6hm1tXScVBFvT361ZQCbR2jRoqHdLW",
+	"xWrp2a933r21017YedIUaA5YjW2RjnOwoEvhn0BtX3Uai0nteiyXVt2i89+2wIqW11+mCX6j4mo1nm2K",
+	"2zt+F8xqJA8tQxXBZSjpqp/dJrRc4PqYdNLdBQYyr5RwxluoR+yYfM8KXsLtfxkclr8PDouJ0sJOy1sI",
+	"2yMNtL28uIXAPXa5CLcNrCfupYRbCNlxeAbjlsIGeZvSe+soAZVmU5cYyriV++flwuBEfQSZ/JkAfRps",
+	"06a3adPbtOlt2vQ2bXqbNr1Nm96mTW/Tprdp09u06W3a9DZteps2va1uta1uta1utU3l3qZyb6tbbatb",
+	"batbbdnI9kbI9kbI9kbItrrV9oLItRNcIjkm8+ksaQKfMFEdypDa4p88Hc54eWWuVq39Y+uvKpD4Gqxb",
+	"ActhLKTHYHq5Whh8XzVlHC+IIP6EzGWrWC1xwyz3L/83aZBNrNUVxCpVDkX8VSI/+3EFWbKRSflpEFa4",
+	"sPXLc/2XrvUWJPq5RKgGC34E2x6NEzPZ/E2h8Mp2+H4RF8yOe59rMNIYQR90L1PEk3J/oIa9+za0TZAz",
+	"btqv6bF7DGN27gb9m1ldA12toYQz7CtVcz24e7+HAsAhkEHvqwmnmdJbzA2WuGl8zN2ha+TR5iF7ykWB",
+	"o1k195BbrsDI7+iFRedNboZOg7ioC/IaNY/w+mtJlFQ/ZG+ogSFHmLMl3EZIOCedOgdKK4ac/XT86mVK",
+	"MXthoWQVaIYt0qCgB8bPSRgZ9t99bJY5Ytd//x/cBZchN0bHXyco3Ii2kCRN17Ck9Up9D93CfpPuiWAs",
+	"e4XOHfeL9hbLldlpb2hf2DmeynljZ4BLnG3K54FPV+VyZj2LjvoE9exN3ZcXDQn6p9RX+2yP8WDdHvl3",
+	"URvHfMnlLDyd3F7nISSQhEzceiG9BMCSf3rsn5EzXxBQJXdi3j4rN2cRMG5ZAdzYDtSG9AjD+ESlzCjf",
+	"LVyp8+SGKFnA2DJeKAnL1iDk4QTi0O9uCn3Xhe9AIjQTxvtbl7mx6cej/KvmgPdvp3X53Jw9Gdbg0TZl",
+	"JOViCK0ky/WM6ZqOf+2HfI89D488EO3YDY2y1nB96nzcvGkYeSF48Tn+GH9ZX3otmzqS1Bt5DpKN+mLk",
+	"z5GvsptrHEs1jr/Vv+xNyPk1tYK44axmUfIqXU25vOKl+VfUoHlHmrcvzSNW4p8UXpB24ZJsjSL4DFDI",
+	"xh4eZYc4BsyaRh3pDTOWcZSMI5rNgu4m3G9l8p8pkwNChKf6tyL5Ly2Sb1YyjuNP3BOuXV8mLtza/lLJ",
+	"16WBP1TuxSdeU+qpHiv/FoSemV/TCqFXCGO/dWOzEdPflvB5LoxdMAe/kk7+5cr0VyD93nwrKB6719+c",
+	"oosoYDbVc4nkpbKDIAaX0/vJlBzu6SrSJl1jS6tX0yqt7aWyYd+21LqcWn1xAKe0SWUble1boNeOtYiW",
+	"nrPqQLZrXIeA/0QzdUvmV5H5vDX4NWyBdfX4a5LxQkhsPdH6LWnTjlI306U1mIzLQXNKcbo8zriP5HnC",
+	"LDTwfNYxSieUrCyGMOxgKpdWUFkTNlEWSYWZcJHWBII6J8Wau/IniMoe3ZH0Cz6ZODITcgwZWY0aMqXz",
+	"NgHI9bMUEXRxwDOXW/1bzTUCICFMVYHMvbdoBE3tFaWb5YRZHJV/FFUF+Q27qXDWrZNqJVN6Q2i5lnPK",
+	"lajCbb3pcEG6aqoW/S2hkd8NtDA71yrnpg6dDrH1NVK11gaqKSS0Hjw/UPOvAJCrsySZyNlEA3d7xP0p",
+	"qZY8uW6JEqlM+4pmImdGEcKIPCToEep4+sCZRtDcqcBvS6AJpQWt6wrR0fG5JVvAF45i5SJfNteb3CID",
+	"4NxdkjG8BOY3LDZjpqS79ZzN4p7Q+2l7f+He/qbXVw/NxzkGTBuDB013YNx3LTMOuEKrWO66dSM0xRDM",
+	"FzpxvVu54eIQmH9wKZMA6BYVNV1ZgCRXqlB0tOzz/Bt0Pt+sbzRAv8w92peH59zvyZ8UHMThHC/+w+yt",
+	"MN0KDU5DILlvQHNza/Yo3ikGd5X2dgba+LTtK8sIoSQURU7Kke8TqcLXNaaolG8GdOd5XBcEUKmksEq7",
+	"+E7OchjVRJrRhKufPWhfsTiVn+KoXUjsgH6OrHeuZqnbpPx2Jmf5k4idWzdRb2YslIgWOADos7ii9HKq",
+	"jGXH/pAx5+uY2iZpQjXXknB36bOpR7kquZCXQ48Tw88aJkLJy6HEUYa6ljtne8nlhwaKBRH0+ojN544F",
+	"Jtz7OlIcAuW25EWrDxvm085yd2+tqkeFyHB80w7bZqZFbvq66geST1z93Xbk7h1vx0cWVoKkK4zFDmcQ",
+	"7dr5LhqrpB3vrMa94NkpLWa6t0qpeWwgOuc5JAi96Lfk8sPl/wwAb89GdGnLAAA=",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
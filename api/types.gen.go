@@ -14,16 +14,79 @@ const (
 	X_Hasura_Admin_SecretScopes = "X_Hasura_Admin_Secret.Scopes"
 )
 
+// Defines values for ImageFit.
+const (
+	Contain ImageFit = "contain"
+	Cover   ImageFit = "cover"
+	Fill    ImageFit = "fill"
+	Inside  ImageFit = "inside"
+)
+
+// Defines values for ImageFlip.
+const (
+	Both       ImageFlip = "both"
+	Horizontal ImageFlip = "horizontal"
+	Vertical   ImageFlip = "vertical"
+)
+
+// Defines values for ImageGravity.
+const (
+	Attention ImageGravity = "attention"
+	Center    ImageGravity = "center"
+	Entropy   ImageGravity = "entropy"
+)
+
 // Defines values for OutputImageFormat.
 const (
 	Auto OutputImageFormat = "auto"
 	Avif OutputImageFormat = "avif"
+	Gif  OutputImageFormat = "gif"
 	Jpeg OutputImageFormat = "jpeg"
+	Jxl  OutputImageFormat = "jxl"
 	Png  OutputImageFormat = "png"
 	Same OutputImageFormat = "same"
+	Tiff OutputImageFormat = "tiff"
 	Webp OutputImageFormat = "webp"
 )
 
+// Defines values for ScanStatus.
+const (
+	Clean    ScanStatus = "clean"
+	Error    ScanStatus = "error"
+	Infected ScanStatus = "infected"
+	Pending  ScanStatus = "pending"
+)
+
+// BrokenMetadataDeletion Result of deleting broken metadata.
+type BrokenMetadataDeletion struct {
+	// Deleted Whether the metadata was deleted, always false on dry runs.
+	Deleted bool `json:"deleted"`
+
+	// Error Why the metadata couldn't be deleted, if it failed.
+	Error *string `json:"error,omitempty"`
+
+	// Metadata Basic information about a file in storage.
+	Metadata FileSummary `json:"metadata"`
+}
+
+// CreatePresignedUploadRequest Details of a file to upload with a presigned upload.
+type CreatePresignedUploadRequest struct {
+	// BucketId Target bucket identifier where the file will be stored.
+	BucketId *string `json:"bucketId,omitempty"`
+
+	// Id Optional custom ID for the file. If not provided, a UUID will be generated.
+	Id *string `json:"id,omitempty"`
+
+	// Metadata Custom metadata to associate with the file.
+	Metadata *map[string]interface{} `json:"metadata,omitempty"`
+
+	// MimeType MIME type of the file, the upload must use this content type.
+	MimeType *string `json:"mimeType,omitempty"`
+
+	// Name Name to assign to the file.
+	Name string `json:"name"`
+}
+
 // ErrorResponse Error information returned by the API.
 type ErrorResponse struct {
 	// Error Error details.
@@ -77,6 +140,9 @@ type FileMetadata struct {
 	// Name Name of the file including extension.
 	Name string `json:"name"`
 
+	// ScanStatus Result of scanning the file for viruses. Files pending to be scanned or infected can't be downloaded. error means the file couldn't be scanned
+	ScanStatus *ScanStatus `json:"scanStatus,omitempty"`
+
 	// Size Size of the file in bytes.
 	Size int64 `json:"size"`
 
@@ -87,6 +153,21 @@ type FileMetadata struct {
 	UploadedByUserId *string `json:"uploadedByUserId,omitempty"`
 }
 
+// FileRescan Result of scanning a file for viruses again.
+type FileRescan struct {
+	// Error Why the file couldn't be scanned, if it failed.
+	Error *string `json:"error,omitempty"`
+
+	// File Basic information about a file in storage.
+	File FileSummary `json:"file"`
+
+	// Quarantined Whether the file was flagged as infected and moved to quarantine, always false on dry runs.
+	Quarantined bool `json:"quarantined"`
+
+	// Virus Name of the virus found, if any.
+	Virus *string `json:"virus,omitempty"`
+}
+
 // FileSummary Basic information about a file in storage.
 type FileSummary struct {
 	// BucketId ID of the bucket containing the file.
@@ -102,6 +183,48 @@ type FileSummary struct {
 	Name string `json:"name"`
 }
 
+// ImageFit How the image is resized when both w and h are given. cover crops the image to fill both dimensions, contain fits the image within them padding it with the background color, fill stretches the image ignoring its aspect ratio and inside fits the image within them without padding
+type ImageFit string
+
+// ImageFlip Direction to flip the image in
+type ImageFlip string
+
+// ImageGravity Which part of the image is kept when it is cropped. attention keeps the most salient features and entropy the area with most detail
+type ImageGravity string
+
+// ImageInfo Information about an image and a placeholder to show while it loads.
+type ImageInfo struct {
+	// Blurhash BlurHash (https://blurha.sh) placeholder of the image.
+	Blurhash string `json:"blurhash"`
+
+	// ColorSpace Color space of the image, i.e. srgb, b-w or cmyk.
+	ColorSpace string `json:"colorSpace"`
+
+	// DominantColor Dominant color of the image in hex format.
+	DominantColor string `json:"dominantColor"`
+
+	// Height Height in pixels of the image as displayed, after applying its orientation.
+	Height int `json:"height"`
+
+	// Orientation EXIF orientation of the image, 1 if it doesn't have one.
+	Orientation int `json:"orientation"`
+
+	// Width Width in pixels of the image as displayed, after applying its orientation.
+	Width int `json:"width"`
+}
+
+// OrphanedFileDeletion Result of deleting an orphaned file.
+type OrphanedFileDeletion struct {
+	// Deleted Whether the file was deleted, always false on dry runs.
+	Deleted bool `json:"deleted"`
+
+	// Error Why the file couldn't be deleted, if it failed.
+	Error *string `json:"error,omitempty"`
+
+	// File Key of the file in the storage.
+	File string `json:"file"`
+}
+
 // OutputImageFormat Output format for image files. Use 'auto' for content negotiation based on Accept header
 type OutputImageFormat string
 
@@ -114,9 +237,27 @@ type PresignedURLResponse struct {
 	Url string `json:"url"`
 }
 
+// PresignedUploadResponse Contains a presigned request to upload a file directly to the content storage.
+type PresignedUploadResponse struct {
+	// Expiration The time in seconds until the presigned upload expires.
+	Expiration int `json:"expiration"`
+
+	// Fields Form fields to send, as they are, before the file field.
+	Fields map[string]string `json:"fields"`
+
+	// Id Unique identifier of the file.
+	Id string `json:"id"`
+
+	// Url URL to send the multipart/form-data POST request to.
+	Url string `json:"url"`
+}
+
 // RFC2822Date Date in RFC 2822 format
 type RFC2822Date = Time
 
+// ScanStatus Result of scanning the file for viruses. Files pending to be scanned or infected can't be downloaded. error means the file couldn't be scanned
+type ScanStatus string
+
 // UpdateFileMetadata Metadata that can be updated for an existing file.
 type UpdateFileMetadata struct {
 	// Metadata Updated custom metadata to associate with the file.
@@ -146,13 +287,13 @@ type VersionInformation struct {
 
 // UploadFilesMultipartBody defines parameters for UploadFiles.
 type UploadFilesMultipartBody struct {
-	// BucketId Target bucket identifier where files will be stored.
+	// BucketId Target bucket identifier where files will be stored. Must be sent before the files.
 	BucketId *string `json:"bucket-id,omitempty"`
 
 	// File Array of files to upload.
 	File []openapi_types.File `json:"file[]"`
 
-	// Metadata Optional custom metadata for each uploaded file. Must match the order of the file[] array.
+	// Metadata Optional custom metadata for each uploaded file. Must match the order of the file[] array and each entry be sent before its file.
 	Metadata *[]UploadFileMetadata `json:"metadata[],omitempty"`
 }
 
@@ -173,6 +314,39 @@ type GetFileParams struct {
 	// F Output format for image files. Use 'auto' for content negotiation based on Accept header
 	F *OutputImageFormat `form:"f,omitempty" json:"f,omitempty"`
 
+	// Crop Crop the image to this rectangle, in pixels of the original image, before resizing it. Format: x,y,width,height. Only applies to image files
+	Crop *string `form:"crop,omitempty" json:"crop,omitempty"`
+
+	// Fit How the image is resized when both w and h are given. Only applies to image files
+	Fit *ImageFit `form:"fit,omitempty" json:"fit,omitempty"`
+
+	// Gravity Which part of the image is kept when it is cropped to cover w and h. Only applies to image files
+	Gravity *ImageGravity `form:"gravity,omitempty" json:"gravity,omitempty"`
+
+	// Rotate Rotate the image clockwise by this number of degrees. Only applies to image files
+	Rotate *int `form:"rotate,omitempty" json:"rotate,omitempty"`
+
+	// Flip Flip the image. Only applies to image files
+	Flip *ImageFlip `form:"flip,omitempty" json:"flip,omitempty"`
+
+	// Sharpen Sharpen the image using this sigma value. Only applies to image files
+	Sharpen *float32 `form:"sharpen,omitempty" json:"sharpen,omitempty"`
+
+	// Grayscale Convert the image to grayscale. Only applies to image files
+	Grayscale *bool `form:"grayscale,omitempty" json:"grayscale,omitempty"`
+
+	// Strip Remove the EXIF, XMP and IPTC metadata of the image, applying its EXIF orientation first. Only applies to image files
+	Strip *bool `form:"strip,omitempty" json:"strip,omitempty"`
+
+	// Background Color used to pad the image when fit is contain, as hex rrggbb or rrggbbaa. Defaults to white. Only applies to image files
+	Background *string `form:"background,omitempty" json:"background,omitempty"`
+
+	// Dpr Device pixel ratio, w and h are multiplied by it. Only applies to image files
+	Dpr *float32 `form:"dpr,omitempty" json:"dpr,omitempty"`
+
+	// Preset Name of a transformation preset of the bucket. Image parameters in the query override the preset's. Only applies to image files
+	Preset *string `form:"preset,omitempty" json:"preset,omitempty"`
+
 	// IfMatch Only return the file if the current ETag matches one of the values provided
 	IfMatch *string `json:"if-match,omitempty"`
 
@@ -206,6 +380,39 @@ type GetFileMetadataHeadersParams struct {
 	// F Output format for image files. Use 'auto' for content negotiation based on Accept header
 	F *OutputImageFormat `form:"f,omitempty" json:"f,omitempty"`
 
+	// Crop Crop the image to this rectangle, in pixels of the original image, before resizing it. Format: x,y,width,height. Only applies to image files
+	Crop *string `form:"crop,omitempty" json:"crop,omitempty"`
+
+	// Fit How the image is resized when both w and h are given. Only applies to image files
+	Fit *ImageFit `form:"fit,omitempty" json:"fit,omitempty"`
+
+	// Gravity Which part of the image is kept when it is cropped to cover w and h. Only applies to image files
+	Gravity *ImageGravity `form:"gravity,omitempty" json:"gravity,omitempty"`
+
+	// Rotate Rotate the image clockwise by this number of degrees. Only applies to image files
+	Rotate *int `form:"rotate,omitempty" json:"rotate,omitempty"`
+
+	// Flip Flip the image. Only applies to image files
+	Flip *ImageFlip `form:"flip,omitempty" json:"flip,omitempty"`
+
+	// Sharpen Sharpen the image using this sigma value. Only applies to image files
+	Sharpen *float32 `form:"sharpen,omitempty" json:"sharpen,omitempty"`
+
+	// Grayscale Convert the image to grayscale. Only applies to image files
+	Grayscale *bool `form:"grayscale,omitempty" json:"grayscale,omitempty"`
+
+	// Strip Remove the EXIF, XMP and IPTC metadata of the image, applying its EXIF orientation first. Only applies to image files
+	Strip *bool `form:"strip,omitempty" json:"strip,omitempty"`
+
+	// Background Color used to pad the image when fit is contain, as hex rrggbb or rrggbbaa. Defaults to white. Only applies to image files
+	Background *string `form:"background,omitempty" json:"background,omitempty"`
+
+	// Dpr Device pixel ratio, w and h are multiplied by it. Only applies to image files
+	Dpr *float32 `form:"dpr,omitempty" json:"dpr,omitempty"`
+
+	// Preset Name of a transformation preset of the bucket. Image parameters in the query override the preset's. Only applies to image files
+	Preset *string `form:"preset,omitempty" json:"preset,omitempty"`
+
 	// IfMatch Only return the file if the current ETag matches one of the values provided
 	IfMatch *string `json:"if-match,omitempty"`
 
@@ -228,6 +435,12 @@ type ReplaceFileMultipartBody struct {
 	Metadata *UpdateFileMetadata `json:"metadata,omitempty"`
 }
 
+// CompletePresignedUploadParams defines parameters for CompletePresignedUpload.
+type CompletePresignedUploadParams struct {
+	// Etag If set, the upload fails unless the stored content has this etag
+	Etag *string `form:"etag,omitempty" json:"etag,omitempty"`
+}
+
 // GetFileWithPresignedURLParams defines parameters for GetFileWithPresignedURL.
 type GetFileWithPresignedURLParams struct {
 	// XAmzAlgorithm Use presignedurl endpoint to generate this automatically
@@ -272,6 +485,39 @@ type GetFileWithPresignedURLParams struct {
 	// F Output format for image files. Use 'auto' for content negotiation based on Accept header
 	F *OutputImageFormat `form:"f,omitempty" json:"f,omitempty"`
 
+	// Crop Crop the image to this rectangle, in pixels of the original image, before resizing it. Format: x,y,width,height. Only applies to image files
+	Crop *string `form:"crop,omitempty" json:"crop,omitempty"`
+
+	// Fit How the image is resized when both w and h are given. Only applies to image files
+	Fit *ImageFit `form:"fit,omitempty" json:"fit,omitempty"`
+
+	// Gravity Which part of the image is kept when it is cropped to cover w and h. Only applies to image files
+	Gravity *ImageGravity `form:"gravity,omitempty" json:"gravity,omitempty"`
+
+	// Rotate Rotate the image clockwise by this number of degrees. Only applies to image files
+	Rotate *int `form:"rotate,omitempty" json:"rotate,omitempty"`
+
+	// Flip Flip the image. Only applies to image files
+	Flip *ImageFlip `form:"flip,omitempty" json:"flip,omitempty"`
+
+	// Sharpen Sharpen the image using this sigma value. Only applies to image files
+	Sharpen *float32 `form:"sharpen,omitempty" json:"sharpen,omitempty"`
+
+	// Grayscale Convert the image to grayscale. Only applies to image files
+	Grayscale *bool `form:"grayscale,omitempty" json:"grayscale,omitempty"`
+
+	// Strip Remove the EXIF, XMP and IPTC metadata of the image, applying its EXIF orientation first. Only applies to image files
+	Strip *bool `form:"strip,omitempty" json:"strip,omitempty"`
+
+	// Background Color used to pad the image when fit is contain, as hex rrggbb or rrggbbaa. Defaults to white. Only applies to image files
+	Background *string `form:"background,omitempty" json:"background,omitempty"`
+
+	// Dpr Device pixel ratio, w and h are multiplied by it. Only applies to image files
+	Dpr *float32 `form:"dpr,omitempty" json:"dpr,omitempty"`
+
+	// Preset Name of a transformation preset of the bucket. Image parameters in the query override the preset's. Only applies to image files
+	Preset *string `form:"preset,omitempty" json:"preset,omitempty"`
+
 	// IfMatch Only return the file if the current ETag matches one of the values provided
 	IfMatch *string `json:"if-match,omitempty"`
 
@@ -288,8 +534,62 @@ type GetFileWithPresignedURLParams struct {
 	Range *string `json:"Range,omitempty"`
 }
 
+// DeleteBrokenMetadataParams defines parameters for DeleteBrokenMetadata.
+type DeleteBrokenMetadataParams struct {
+	// DryRun Report what would be deleted without deleting anything
+	DryRun *bool `form:"dryRun,omitempty" json:"dryRun,omitempty"`
+
+	// MaxDeletions Stop after processing this many files, including the ones that failed
+	MaxDeletions *int `form:"maxDeletions,omitempty" json:"maxDeletions,omitempty"`
+
+	// MinAge Only delete files last modified at least this many seconds ago, so files being uploaded are left alone
+	MinAge *int `form:"minAge,omitempty" json:"minAge,omitempty"`
+
+	// BucketId Only delete metadata of files in this bucket
+	BucketId *string `form:"bucketId,omitempty" json:"bucketId,omitempty"`
+}
+
+// DeleteOrphanedFilesParams defines parameters for DeleteOrphanedFiles.
+type DeleteOrphanedFilesParams struct {
+	// DryRun Report what would be deleted without deleting anything
+	DryRun *bool `form:"dryRun,omitempty" json:"dryRun,omitempty"`
+
+	// MaxDeletions Stop after processing this many files, including the ones that failed
+	MaxDeletions *int `form:"maxDeletions,omitempty" json:"maxDeletions,omitempty"`
+
+	// MinAge Only delete files last modified at least this many seconds ago, so files being uploaded are left alone
+	MinAge *int `form:"minAge,omitempty" json:"minAge,omitempty"`
+}
+
+// RescanFilesParams defines parameters for RescanFiles.
+type RescanFilesParams struct {
+	// BucketId Only scan files in this bucket
+	BucketId *string `form:"bucketId,omitempty" json:"bucketId,omitempty"`
+
+	// UploadedAfter Only scan files uploaded at or after this date
+	UploadedAfter *time.Time `form:"uploadedAfter,omitempty" json:"uploadedAfter,omitempty"`
+
+	// UploadedBefore Only scan files uploaded before this date
+	UploadedBefore *time.Time `form:"uploadedBefore,omitempty" json:"uploadedBefore,omitempty"`
+
+	// After Only scan files with an id greater than this one. Files are scanned in order of id so the id of the last result can be used to resume an interrupted rescan
+	After *string `form:"after,omitempty" json:"after,omitempty"`
+
+	// Concurrency Number of files scanned at the same time
+	Concurrency *int `form:"concurrency,omitempty" json:"concurrency,omitempty"`
+
+	// ReloadSignatures Ask the antivirus to reload its virus signatures before scanning
+	ReloadSignatures *bool `form:"reloadSignatures,omitempty" json:"reloadSignatures,omitempty"`
+
+	// DryRun Report the viruses found without flagging the files as infected or moving them to quarantine
+	DryRun *bool `form:"dryRun,omitempty" json:"dryRun,omitempty"`
+}
+
 // UploadFilesMultipartRequestBody defines body for UploadFiles for multipart/form-data ContentType.
 type UploadFilesMultipartRequestBody UploadFilesMultipartBody
 
+// CreatePresignedUploadJSONRequestBody defines body for CreatePresignedUpload for application/json ContentType.
+type CreatePresignedUploadJSONRequestBody = CreatePresignedUploadRequest
+
 // ReplaceFileMultipartRequestBody defines body for ReplaceFile for multipart/form-data ContentType.
 type ReplaceFileMultipartRequestBody ReplaceFileMultipartBody
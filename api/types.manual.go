@@ -25,8 +25,65 @@ func (g GetFileParams) GetF() *OutputImageFormat {
 	return g.F
 }
 
+// GetCrop returns the Crop field value.
+func (g GetFileParams) GetCrop() *string {
+	return g.Crop
+}
+
+// GetFit returns the Fit field value.
+func (g GetFileParams) GetFit() *ImageFit {
+	return g.Fit
+}
+
+// GetGravity returns the Gravity field value.
+func (g GetFileParams) GetGravity() *ImageGravity {
+	return g.Gravity
+}
+
+// GetRotate returns the Rotate field value.
+func (g GetFileParams) GetRotate() *int {
+	return g.Rotate
+}
+
+// GetFlip returns the Flip field value.
+func (g GetFileParams) GetFlip() *ImageFlip {
+	return g.Flip
+}
+
+// GetSharpen returns the Sharpen field value.
+func (g GetFileParams) GetSharpen() *float32 {
+	return g.Sharpen
+}
+
+// GetGrayscale returns the Grayscale field value.
+func (g GetFileParams) GetGrayscale() *bool {
+	return g.Grayscale
+}
+
+// GetStrip returns the Strip field value.
+func (g GetFileParams) GetStrip() *bool {
+	return g.Strip
+}
+
+// GetBackground returns the Background field value.
+func (g GetFileParams) GetBackground() *string {
+	return g.Background
+}
+
+// GetDpr returns the Dpr field value.
+func (g GetFileParams) GetDpr() *float32 {
+	return g.Dpr
+}
+
+func (g GetFileParams) GetPreset() *string {
+	return g.Preset
+}
+
 func (g GetFileParams) HasImageManipulationOptions() bool {
-	return g.Q != nil || g.H != nil || g.W != nil || g.B != nil || g.F != nil
+	return g.Q != nil || g.H != nil || g.W != nil || g.B != nil || g.F != nil ||
+		g.Crop != nil || g.Fit != nil || g.Gravity != nil || g.Rotate != nil || g.Flip != nil ||
+		g.Sharpen != nil || g.Grayscale != nil || g.Strip != nil || g.Background != nil ||
+		g.Dpr != nil || g.Preset != nil
 }
 
 // GetIfMatch returns the IfMatch field value.
@@ -74,8 +131,65 @@ func (g GetFileMetadataHeadersParams) GetF() *OutputImageFormat {
 	return g.F
 }
 
+// GetCrop returns the Crop field value.
+func (g GetFileMetadataHeadersParams) GetCrop() *string {
+	return g.Crop
+}
+
+// GetFit returns the Fit field value.
+func (g GetFileMetadataHeadersParams) GetFit() *ImageFit {
+	return g.Fit
+}
+
+// GetGravity returns the Gravity field value.
+func (g GetFileMetadataHeadersParams) GetGravity() *ImageGravity {
+	return g.Gravity
+}
+
+// GetRotate returns the Rotate field value.
+func (g GetFileMetadataHeadersParams) GetRotate() *int {
+	return g.Rotate
+}
+
+// GetFlip returns the Flip field value.
+func (g GetFileMetadataHeadersParams) GetFlip() *ImageFlip {
+	return g.Flip
+}
+
+// GetSharpen returns the Sharpen field value.
+func (g GetFileMetadataHeadersParams) GetSharpen() *float32 {
+	return g.Sharpen
+}
+
+// GetGrayscale returns the Grayscale field value.
+func (g GetFileMetadataHeadersParams) GetGrayscale() *bool {
+	return g.Grayscale
+}
+
+// GetStrip returns the Strip field value.
+func (g GetFileMetadataHeadersParams) GetStrip() *bool {
+	return g.Strip
+}
+
+// GetBackground returns the Background field value.
+func (g GetFileMetadataHeadersParams) GetBackground() *string {
+	return g.Background
+}
+
+// GetDpr returns the Dpr field value.
+func (g GetFileMetadataHeadersParams) GetDpr() *float32 {
+	return g.Dpr
+}
+
+func (g GetFileMetadataHeadersParams) GetPreset() *string {
+	return g.Preset
+}
+
 func (g GetFileMetadataHeadersParams) HasImageManipulationOptions() bool {
-	return g.Q != nil || g.H != nil || g.W != nil || g.B != nil || g.F != nil
+	return g.Q != nil || g.H != nil || g.W != nil || g.B != nil || g.F != nil ||
+		g.Crop != nil || g.Fit != nil || g.Gravity != nil || g.Rotate != nil || g.Flip != nil ||
+		g.Sharpen != nil || g.Grayscale != nil || g.Strip != nil || g.Background != nil ||
+		g.Dpr != nil || g.Preset != nil
 }
 
 // GetIfMatch returns the IfMatch field value.
@@ -123,8 +237,65 @@ func (g GetFileWithPresignedURLParams) GetF() *OutputImageFormat {
 	return g.F
 }
 
+// GetCrop returns the Crop field value.
+func (g GetFileWithPresignedURLParams) GetCrop() *string {
+	return g.Crop
+}
+
+// GetFit returns the Fit field value.
+func (g GetFileWithPresignedURLParams) GetFit() *ImageFit {
+	return g.Fit
+}
+
+// GetGravity returns the Gravity field value.
+func (g GetFileWithPresignedURLParams) GetGravity() *ImageGravity {
+	return g.Gravity
+}
+
+// GetRotate returns the Rotate field value.
+func (g GetFileWithPresignedURLParams) GetRotate() *int {
+	return g.Rotate
+}
+
+// GetFlip returns the Flip field value.
+func (g GetFileWithPresignedURLParams) GetFlip() *ImageFlip {
+	return g.Flip
+}
+
+// GetSharpen returns the Sharpen field value.
+func (g GetFileWithPresignedURLParams) GetSharpen() *float32 {
+	return g.Sharpen
+}
+
+// GetGrayscale returns the Grayscale field value.
+func (g GetFileWithPresignedURLParams) GetGrayscale() *bool {
+	return g.Grayscale
+}
+
+// GetStrip returns the Strip field value.
+func (g GetFileWithPresignedURLParams) GetStrip() *bool {
+	return g.Strip
+}
+
+// GetBackground returns the Background field value.
+func (g GetFileWithPresignedURLParams) GetBackground() *string {
+	return g.Background
+}
+
+// GetDpr returns the Dpr field value.
+func (g GetFileWithPresignedURLParams) GetDpr() *float32 {
+	return g.Dpr
+}
+
+func (g GetFileWithPresignedURLParams) GetPreset() *string {
+	return g.Preset
+}
+
 func (g GetFileWithPresignedURLParams) HasImageManipulationOptions() bool {
-	return g.Q != nil || g.H != nil || g.W != nil || g.B != nil || g.F != nil
+	return g.Q != nil || g.H != nil || g.W != nil || g.B != nil || g.F != nil ||
+		g.Crop != nil || g.Fit != nil || g.Gravity != nil || g.Rotate != nil || g.Flip != nil ||
+		g.Sharpen != nil || g.Grayscale != nil || g.Strip != nil || g.Background != nil ||
+		g.Dpr != nil || g.Preset != nil
 }
 
 // GetIfMatch returns the IfMatch field value.
@@ -1,39 +1,102 @@
 package clamd
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
 )
 
-const chunkSize = 1024
+const (
+	defaultChunkSize   = 64 << 10
+	defaultDialTimeout = 10 * time.Second
+	defaultTimeout     = time.Minute
+	// clamd closes sessions idle for longer than its IdleTimeout, 30s by default.
+	defaultIdleTimeout = 20 * time.Second
+)
+
+// Config tunes the client. Zero values use the defaults, except MaxIdleSessions.
+type Config struct {
+	// ChunkSize is the size of the chunks files are streamed in, 64 KiB by default.
+	ChunkSize int
+	// DialTimeout is how long connecting to clamd can take, 10s by default.
+	DialTimeout time.Duration
+	// Timeout is how long a command can take, including streaming the file and reading
+	// the reply, 1m by default.
+	Timeout time.Duration
+	// MaxIdleSessions is the number of IDSESSION connections kept open to be reused. If 0
+	// a new connection is opened for every command.
+	MaxIdleSessions int
+	// IdleTimeout is how long a session is kept open without being used, 20s by default.
+	// It needs to be shorter than clamd's IdleTimeout.
+	IdleTimeout time.Duration
+}
 
 type Client struct {
-	addr string
+	network string
+	addr    string
+	config  Config
+
+	mu   sync.Mutex
+	idle []*session
 }
 
-func NewClient(addr string) (*Client, error) {
+// NewClient returns a client for the clamd listening on addr, either tcp://host:port or
+// unix:///path/to/clamd.sock.
+func NewClient(addr string, config Config) (*Client, error) {
 	url, err := url.Parse(addr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse addr: %w", err)
 	}
 
-	if url.Scheme != "tcp" {
+	var network, address string
+
+	switch url.Scheme {
+	case "tcp":
+		network, address = "tcp", url.Host
+	case "unix":
+		network, address = "unix", url.Path
+	default:
 		return nil, fmt.Errorf("invalid scheme: %s", url.Scheme) //nolint:err113
 	}
 
-	return &Client{url.Host}, nil
+	if config.ChunkSize <= 0 {
+		config.ChunkSize = defaultChunkSize
+	}
+
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = defaultDialTimeout
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = defaultIdleTimeout
+	}
+
+	return &Client{
+		network: network,
+		addr:    address,
+		config:  config,
+		mu:      sync.Mutex{},
+		idle:    nil,
+	}, nil
 }
 
 func (c *Client) Dial(ctx context.Context) (net.Conn, error) {
 	dialer := net.Dialer{ //nolint:exhaustruct
-		Deadline: time.Now().Add(1 * time.Minute),
-		Timeout:  time.Minute,
+		Timeout: c.config.DialTimeout,
 	}
 
-	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	conn, err := dialer.DialContext(ctx, c.network, c.addr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to dial: %w", err)
 	}
@@ -41,6 +104,190 @@ func (c *Client) Dial(ctx context.Context) (net.Conn, error) {
 	return conn, nil
 }
 
+// Close closes the idle sessions.
+func (c *Client) Close() {
+	c.mu.Lock()
+	idle := c.idle
+	c.idle = nil
+	c.mu.Unlock()
+
+	for _, s := range idle {
+		s.close()
+	}
+}
+
+// session is a connection to clamd. In IDSESSION mode the connection is kept open after
+// each command and replies are prefixed with the number of the command.
+type session struct {
+	conn     net.Conn
+	reader   *bufio.Reader
+	pooled   bool
+	lastUsed time.Time
+}
+
+func (s *session) close() {
+	if s.pooled {
+		// clamd doesn't need it but it lets it free the session right away
+		_ = sendCommand(s.conn, "END")
+	}
+
+	s.conn.Close()
+}
+
+func (c *Client) newSession(ctx context.Context, pooled bool) (*session, error) {
+	conn, err := c.Dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &session{
+		conn:     conn,
+		reader:   bufio.NewReader(conn),
+		pooled:   pooled,
+		lastUsed: time.Time{},
+	}
+
+	if pooled {
+		if err := sendCommand(conn, "IDSESSION"); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to send IDSESSION command: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// getSession returns an idle session if there is one that isn't about to expire.
+func (c *Client) getSession() *session {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.idle) > 0 {
+		s := c.idle[len(c.idle)-1]
+		c.idle = c.idle[:len(c.idle)-1]
+
+		if time.Since(s.lastUsed) < c.config.IdleTimeout {
+			return s
+		}
+
+		go s.close()
+	}
+
+	return nil
+}
+
+func (c *Client) putSession(s *session) {
+	s.lastUsed = time.Now()
+
+	c.mu.Lock()
+	if len(c.idle) < c.config.MaxIdleSessions {
+		c.idle = append(c.idle, s)
+		s = nil
+	}
+	c.mu.Unlock()
+
+	if s != nil {
+		s.close()
+	}
+}
+
+// done returns s to the pool if it can still be used after err, closing it otherwise.
+func (c *Client) done(s *session, err error) {
+	virusFoundErr := &VirusFoundError{} //nolint:exhaustruct
+	if s.pooled && (err == nil || errors.As(err, &virusFoundErr)) {
+		c.putSession(s)
+		return
+	}
+
+	s.conn.Close()
+}
+
+// retryable returns whether a command that failed with err on a reused session might
+// succeed on a new one, as clamd may have closed the session in the meantime.
+func retryable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return !netErr.Timeout()
+	}
+
+	return errors.Is(err, io.EOF)
+}
+
+// do runs f on a session, reusing an idle one if possible. Sessions are discarded after
+// any error other than finding a virus. If f fails because clamd closed a reused session
+// it is run again on a new one, unless it isn't replayable, like commands streaming a
+// reader that can't be read again; reused sessions are checked with a PING before those.
+func (c *Client) do(
+	ctx context.Context, sessionable, replayable bool, f func(s *session) error,
+) error {
+	pooled := sessionable && c.config.MaxIdleSessions > 0
+
+	if pooled {
+		if s := c.getSession(); s != nil {
+			check := f
+			if !replayable {
+				check = (*session).ping
+			}
+
+			err := c.run(ctx, s, check)
+			if err == nil && !replayable {
+				err = c.run(ctx, s, f)
+				c.done(s, err)
+
+				return err
+			}
+
+			c.done(s, err)
+
+			if !retryable(ctx, err) {
+				return err
+			}
+		}
+	}
+
+	s, err := c.newSession(ctx, pooled)
+	if err != nil {
+		return err
+	}
+
+	err = c.run(ctx, s, f)
+	c.done(s, err)
+
+	return err
+}
+
+// run calls f with the deadline of the command set on the connection, interrupting it
+// if ctx is done.
+func (c *Client) run(ctx context.Context, s *session, f func(s *session) error) error {
+	deadline := time.Now().Add(c.config.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	if err := s.conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = s.conn.SetDeadline(time.Unix(1, 0))
+	})
+	defer stop()
+
+	if err := f(s); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w: %w", context.Cause(ctx), err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
 func sendCommand(conn net.Conn, command string) error {
 	if _, err := fmt.Fprintf(conn,
 		"n%s\n", command); err != nil {
@@ -50,15 +297,23 @@ func sendCommand(conn net.Conn, command string) error {
 	return nil
 }
 
-func readResponse(conn net.Conn) ([]byte, error) {
-	buf := make([]byte, 1024) //nolint:mnd
-
-	n, err := conn.Read(buf)
+// readResponse returns the reply to a command without the trailing newline and, in
+// IDSESSION mode, without the number of the command.
+func (s *session) readResponse() (string, error) {
+	response, err := s.reader.ReadString('\n')
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return "", fmt.Errorf("failed to read response: %w", err)
 	}
 
-	return buf[:n], nil
+	response = strings.TrimSuffix(response, "\n")
+
+	if s.pooled {
+		if _, r, ok := strings.Cut(response, ": "); ok {
+			response = r
+		}
+	}
+
+	return response, nil
 }
 
 func sendChunk(conn net.Conn, data []byte) error {
@@ -70,12 +325,7 @@ func sendChunk(conn net.Conn, data []byte) error {
 	buf[2] = byte(lenData >> 8)  //nolint:mnd
 	buf[3] = byte(lenData >> 0)
 
-	a := buf
-
-	b := make([]byte, len(a))
-	copy(b, a[:])
-
-	if _, err := conn.Write(b); err != nil {
+	if _, err := conn.Write(buf[:]); err != nil {
 		return fmt.Errorf("failed to write chunk size: %w", err)
 	}
 
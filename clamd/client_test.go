@@ -0,0 +1,244 @@
+package clamd_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nhost/hasura-storage/clamd"
+)
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		addr      string
+		expectErr bool
+	}{
+		{name: "tcp", addr: "tcp://localhost:3310", expectErr: false},
+		{name: "unix", addr: "unix:///var/run/clamav/clamd.ctl", expectErr: false},
+		{name: "http", addr: "http://localhost:3310", expectErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := clamd.NewClient(tc.addr, clamd.Config{})
+			if (err != nil) != tc.expectErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientInStream(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		content       string
+		expectedError error
+	}{
+		{
+			name:          "clean",
+			content:       "hello world",
+			expectedError: nil,
+		},
+		{
+			name:          "infected",
+			content:       "hello EICAR world",
+			expectedError: &clamd.VirusFoundError{Name: "Eicar-Test-Signature"},
+		},
+		{
+			name:          "size limit exceeded",
+			content:       strings.Repeat("a", 100),
+			expectedError: &clamd.SizeLimitExceededError{},
+		},
+		{
+			name:          "error",
+			content:       "ERROR",
+			expectedError: &clamd.ResponseError{Response: "Can't allocate memory ERROR"},
+		},
+	}
+
+	for _, network := range []string{"tcp", "unix"} {
+		for _, sessions := range []int{0, 2} {
+			server := newFakeClamd(t, network, 64, 0)
+
+			client, err := clamd.NewClient(
+				server.addr, clamd.Config{ChunkSize: 16, MaxIdleSessions: sessions},
+			)
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			for _, tc := range cases {
+				t.Run(network+"/"+tc.name, func(t *testing.T) {
+					err := client.InStream(t.Context(), strings.NewReader(tc.content))
+					if diff := cmp.Diff(tc.expectedError, err); diff != "" {
+						t.Errorf("unexpected error (-want +got):\n%s", diff)
+					}
+
+					// sessions are still usable after finding a virus
+					if err := client.Ping(t.Context()); err != nil {
+						t.Errorf("failed to ping: %v", err)
+					}
+				})
+			}
+
+			client.Close()
+		}
+	}
+}
+
+func TestClientReusesSessions(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeClamd(t, "tcp", 1024, 0)
+
+	client, err := clamd.NewClient(server.addr, clamd.Config{MaxIdleSessions: 1})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	cases := []struct {
+		content       string
+		expectedError error
+	}{
+		{content: "clean", expectedError: nil},
+		{content: "EICAR", expectedError: &clamd.VirusFoundError{Name: "Eicar-Test-Signature"}},
+	}
+
+	for _, tc := range cases {
+		if err := client.Ping(t.Context()); err != nil {
+			t.Fatalf("failed to ping: %v", err)
+		}
+
+		err := client.InStream(t.Context(), strings.NewReader(tc.content))
+		if diff := cmp.Diff(tc.expectedError, err); diff != "" {
+			t.Errorf("unexpected error (-want +got):\n%s", diff)
+		}
+
+		if _, err := client.Version(t.Context()); err != nil {
+			t.Fatalf("failed to get version: %v", err)
+		}
+	}
+
+	if n := server.accepted.Load(); n != 1 {
+		t.Errorf("expected 1 connection, got %d", n)
+	}
+
+	// clamd closes sessions that are idle for too long
+	server.closeConns()
+
+	if err := client.Ping(t.Context()); err != nil {
+		t.Fatalf("failed to ping after the session was closed: %v", err)
+	}
+
+	// RELOAD isn't allowed in sessions so it needs its own connection
+	if err := client.Reload(t.Context()); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	if n := server.accepted.Load(); n != 3 {
+		t.Errorf("expected 3 connections, got %d", n)
+	}
+}
+
+func TestClientInStreamClosedSession(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeClamd(t, "tcp", 1024, 0)
+
+	client, err := clamd.NewClient(
+		server.addr, clamd.Config{ChunkSize: 4, MaxIdleSessions: 1},
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Ping(t.Context()); err != nil {
+		t.Fatalf("failed to ping: %v", err)
+	}
+
+	// clamd closes sessions that are idle for too long
+	server.closeConns()
+
+	// the file can only be read once so the closed session has to be noticed before
+	// streaming it
+	err = client.InStream(
+		t.Context(), &sequentialReader{Reader: strings.NewReader("hello EICAR world"), offset: 0},
+	)
+	if diff := cmp.Diff(&clamd.VirusFoundError{Name: "Eicar-Test-Signature"}, err); diff != "" {
+		t.Errorf("unexpected error (-want +got):\n%s", diff)
+	}
+
+	if n := server.accepted.Load(); n != 2 {
+		t.Errorf("expected 2 connections, got %d", n)
+	}
+}
+
+func TestClientVersion(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeClamd(t, "unix", 1024, 0)
+
+	client, err := clamd.NewClient(server.addr, clamd.Config{})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	version, err := client.Version(t.Context())
+	if err != nil {
+		t.Fatalf("failed to get version: %v", err)
+	}
+
+	expected := clamd.Version{Version: "1.1.0/27000/Mon Jul 31 07:26:30 2023"}
+	if diff := cmp.Diff(expected, version); diff != "" {
+		t.Errorf("unexpected version (-want +got):\n%s", diff)
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeClamd(t, "tcp", 1024, time.Second)
+
+	client, err := clamd.NewClient(server.addr, clamd.Config{Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	err = client.InStream(t.Context(), strings.NewReader("clean"))
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
+
+func TestClientReadError(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeClamd(t, "tcp", 1024, 0)
+
+	client, err := clamd.NewClient(
+		server.addr, clamd.Config{ChunkSize: 4, MaxIdleSessions: 1},
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	err = client.InStream(
+		t.Context(), failingReader{Reader: strings.NewReader("hello world"), n: 8},
+	)
+	if !errors.Is(err, errFakeReader) {
+		t.Errorf("expected %v, got %v", errFakeReader, err)
+	}
+}
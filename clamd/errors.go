@@ -7,3 +7,20 @@ type VirusFoundError struct {
 func (e *VirusFoundError) Error() string {
 	return "virus found: " + e.Name
 }
+
+// SizeLimitExceededError is returned when the file is larger than clamd's
+// StreamMaxLength.
+type SizeLimitExceededError struct{}
+
+func (e *SizeLimitExceededError) Error() string {
+	return "file exceeds the stream size limit of clamd"
+}
+
+// ResponseError is returned when clamd replies with an error or an unexpected response.
+type ResponseError struct {
+	Response string
+}
+
+func (e *ResponseError) Error() string {
+	return "unexpected response from clamd: " + e.Response
+}
@@ -0,0 +1,260 @@
+package clamd_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClamd implements enough of the clamd protocol to test the client. Files containing
+// "EICAR" are infected and files containing "ERROR" make it reply with an error.
+type fakeClamd struct {
+	addr string
+	// maxStream is the StreamMaxLength of clamd.
+	maxStream int
+	// delay is added before every reply.
+	delay time.Duration
+
+	listener net.Listener
+	accepted atomic.Int32
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+func newFakeClamd(t *testing.T, network string, maxStream int, delay time.Duration) *fakeClamd {
+	t.Helper()
+
+	var (
+		listener net.Listener
+		addr     string
+		err      error
+	)
+
+	switch network {
+	case "tcp":
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		if err == nil {
+			addr = "tcp://" + listener.Addr().String()
+		}
+	case "unix":
+		path := filepath.Join(t.TempDir(), "clamd.sock")
+		listener, err = net.Listen("unix", path)
+		addr = "unix://" + path
+	}
+
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	f := &fakeClamd{
+		addr:      addr,
+		maxStream: maxStream,
+		delay:     delay,
+		listener:  listener,
+		accepted:  atomic.Int32{},
+		mu:        sync.Mutex{},
+		conns:     make(map[net.Conn]struct{}),
+		wg:        sync.WaitGroup{},
+	}
+
+	f.wg.Add(1)
+
+	go f.serve()
+
+	t.Cleanup(f.close)
+
+	return f
+}
+
+func (f *fakeClamd) serve() {
+	defer f.wg.Done()
+
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		f.accepted.Add(1)
+
+		f.mu.Lock()
+		f.conns[conn] = struct{}{}
+		f.mu.Unlock()
+
+		f.wg.Add(1)
+
+		go func() {
+			defer f.wg.Done()
+			defer f.closeConn(conn)
+
+			f.handle(conn)
+		}()
+	}
+}
+
+func (f *fakeClamd) closeConn(conn net.Conn) {
+	f.mu.Lock()
+	delete(f.conns, conn)
+	f.mu.Unlock()
+
+	conn.Close()
+}
+
+// closeConns closes the open connections, like clamd does with idle sessions.
+func (f *fakeClamd) closeConns() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for conn := range f.conns {
+		conn.Close()
+	}
+}
+
+func (f *fakeClamd) close() {
+	f.listener.Close()
+	f.closeConns()
+	f.wg.Wait()
+}
+
+func (f *fakeClamd) handle(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	session := false
+
+	for id := 1; ; id++ {
+		command, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		reply, keepOpen := f.reply(r, strings.TrimSuffix(strings.TrimPrefix(command, "n"), "\n"))
+
+		switch {
+		case reply == "IDSESSION":
+			session = true
+			id--
+
+			continue
+		case reply == "END":
+			return
+		case session && reply == "RELOADING":
+			reply, keepOpen = "Command invalid inside IDSESSION. ERROR", false
+		}
+
+		time.Sleep(f.delay)
+
+		if session {
+			reply = fmt.Sprintf("%d: %s", id, reply)
+		}
+
+		if _, err := fmt.Fprintf(conn, "%s\n", reply); err != nil {
+			return
+		}
+
+		if !keepOpen {
+			// let the client read the reply even if it is still sending data
+			_, _ = io.Copy(io.Discard, r)
+			return
+		}
+
+		if !session {
+			return
+		}
+	}
+}
+
+// reply returns the reply to command and whether the connection can be kept open.
+func (f *fakeClamd) reply(r *bufio.Reader, command string) (string, bool) {
+	switch command {
+	case "IDSESSION", "END":
+		return command, true
+	case "PING":
+		return "PONG", true
+	case "VERSION":
+		return "ClamAV 1.1.0/27000/Mon Jul 31 07:26:30 2023", true
+	case "RELOAD":
+		return "RELOADING", false
+	case "INSTREAM":
+		return f.inStream(r)
+	}
+
+	return "UNKNOWN COMMAND", false
+}
+
+func (f *fakeClamd) inStream(r *bufio.Reader) (string, bool) {
+	var content bytes.Buffer
+
+	for {
+		var size uint32
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			return "", false
+		}
+
+		if size == 0 {
+			break
+		}
+
+		if content.Len()+int(size) > f.maxStream {
+			return "INSTREAM size limit exceeded. ERROR", false
+		}
+
+		if _, err := io.CopyN(&content, r, int64(size)); err != nil {
+			return "", false
+		}
+	}
+
+	switch {
+	case bytes.Contains(content.Bytes(), []byte("EICAR")):
+		return "stream: Eicar-Test-Signature FOUND", true
+	case bytes.Contains(content.Bytes(), []byte("ERROR")):
+		return "Can't allocate memory ERROR", false
+	}
+
+	return "stream: OK", true
+}
+
+var errFakeReader = errors.New("fake reader error")
+
+// failingReader fails after reading the first n bytes of its content.
+type failingReader struct {
+	*strings.Reader
+	n int64
+}
+
+func (r failingReader) ReadAt(p []byte, off int64) (int, error) {
+	if off >= r.n {
+		return 0, errFakeReader
+	}
+
+	return r.Reader.ReadAt(p, off) //nolint:wrapcheck
+}
+
+var errRewound = errors.New("reader can't be read again")
+
+// sequentialReader can only be read in order, once, like a file being uploaded.
+type sequentialReader struct {
+	*strings.Reader
+	offset int64
+}
+
+func (r *sequentialReader) ReadAt(p []byte, off int64) (int, error) {
+	if off != r.offset {
+		return 0, errRewound
+	}
+
+	n, err := r.Reader.ReadAt(p, off)
+	r.offset += int64(n)
+
+	return n, err //nolint:wrapcheck
+}
@@ -5,31 +5,61 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
+
+	"github.com/nhost/hasura-storage/telemetry"
 )
 
-func (c *Client) InStream(ctx context.Context, r io.ReaderAt) error { //nolint: cyclop
-	conn, err := c.Dial(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to dial: %w", err)
+func (c *Client) InStream(ctx context.Context, r io.ReaderAt) (err error) {
+	ctx, span := telemetry.Start(ctx, "clamd.InStream")
+	defer func() { telemetry.End(span, err) }()
+
+	// r may not be readable twice, like a file being uploaded
+	return c.do(ctx, true, false, func(s *session) error {
+		return c.inStream(s, r)
+	})
+}
+
+// parseInStreamResponse returns the error corresponding to the reply to INSTREAM, nil if
+// the file is clean.
+func parseInStreamResponse(response string) error {
+	switch {
+	case response == "stream: OK":
+		return nil
+	case strings.HasPrefix(response, "INSTREAM size limit exceeded"):
+		return &SizeLimitExceededError{}
+	case strings.HasPrefix(response, "stream: ") && strings.HasSuffix(response, " FOUND"):
+		return &VirusFoundError{
+			Name: strings.TrimSuffix(strings.TrimPrefix(response, "stream: "), " FOUND"),
+		}
 	}
-	defer conn.Close()
 
-	if err := sendCommand(conn, "INSTREAM"); err != nil {
+	return &ResponseError{Response: response}
+}
+
+func (c *Client) inStream(s *session, r io.ReaderAt) error {
+	if err := sendCommand(s.conn, "INSTREAM"); err != nil {
 		return fmt.Errorf("failed to send INSTREAM command: %w", err)
 	}
 
-	var iter int64
+	buf := make([]byte, c.config.ChunkSize)
 
-	for {
-		buf := make([]byte, chunkSize)
+	var sent int64
 
-		nr, err := r.ReadAt(buf, iter*chunkSize)
-		iter++
+	for {
+		nr, err := r.ReadAt(buf, sent)
 
 		if nr > 0 {
-			if err := sendChunk(conn, buf[0:nr]); err != nil {
+			if err := sendChunk(s.conn, buf[0:nr]); err != nil {
+				// clamd replies and closes the connection when the file is too large
+				if response, rerr := s.readResponse(); rerr == nil {
+					return parseInStreamResponse(response)
+				}
+
 				return fmt.Errorf("failed to send chunk: %w", err)
 			}
+
+			sent += int64(nr)
 		}
 
 		if errors.Is(err, io.EOF) {
@@ -41,18 +71,14 @@ func (c *Client) InStream(ctx context.Context, r io.ReaderAt) error { //nolint:
 		}
 	}
 
-	if err := sendEOF(conn); err != nil {
+	if err := sendEOF(s.conn); err != nil {
 		return fmt.Errorf("failed to send EOF: %w", err)
 	}
 
-	response, err := readResponse(conn)
+	response, err := s.readResponse()
 	if err != nil {
 		return fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if string(response) == "stream: OK\n" {
-		return nil
-	}
-
-	return &VirusFoundError{string(response[8 : len(response)-7])}
+	return parseInStreamResponse(response)
 }
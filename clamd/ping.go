@@ -6,23 +6,21 @@ import (
 )
 
 func (c *Client) Ping(ctx context.Context) error {
-	conn, err := c.Dial(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to dial: %w", err)
-	}
-	defer conn.Close()
+	return c.do(ctx, true, true, (*session).ping)
+}
 
-	if err := sendCommand(conn, "PING"); err != nil {
+func (s *session) ping() error {
+	if err := sendCommand(s.conn, "PING"); err != nil {
 		return fmt.Errorf("failed to send PING command: %w", err)
 	}
 
-	response, err := readResponse(conn)
+	response, err := s.readResponse()
 	if err != nil {
 		return fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if string(response) != "PONG\n" {
-		return fmt.Errorf("unknown response: %s", string(response)) //nolint:err113
+	if response != "PONG" {
+		return &ResponseError{Response: response}
 	}
 
 	return nil
@@ -6,24 +6,21 @@ import (
 )
 
 func (c *Client) Reload(ctx context.Context) error {
-	conn, err := c.Dial(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to dial: %w", err)
-	}
-	defer conn.Close()
+	// RELOAD isn't allowed in sessions
+	return c.do(ctx, false, true, func(s *session) error {
+		if err := sendCommand(s.conn, "RELOAD"); err != nil {
+			return fmt.Errorf("failed to send RELOAD command: %w", err)
+		}
 
-	if err := sendCommand(conn, "RELOAD"); err != nil {
-		return fmt.Errorf("failed to send RELOAD command: %w", err)
-	}
+		response, err := s.readResponse()
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
 
-	response, err := readResponse(conn)
-	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
-	}
+		if response != "RELOADING" {
+			return &ResponseError{Response: response}
+		}
 
-	if string(response) != "RELOADING\n" {
-		return fmt.Errorf("unknown response: %s", string(response)) //nolint:err113
-	}
-
-	return nil
+		return nil
+	})
 }
@@ -10,29 +10,35 @@ type Version struct {
 	Version string
 }
 
-func parseVersion(response []byte) Version {
-	parts := strings.SplitN(string(response), " ", 2) //nolint:mnd
+// parseVersion parses replies like "ClamAV 1.1.0/27000/Mon Jul 31 07:26:30 2023".
+func parseVersion(response string) (Version, error) {
+	_, version, ok := strings.Cut(response, " ")
+	if !ok {
+		return Version{}, &ResponseError{Response: response}
+	}
 
 	return Version{
-		Version: parts[1],
-	}
+		Version: version,
+	}, nil
 }
 
 func (c *Client) Version(ctx context.Context) (Version, error) {
-	conn, err := c.Dial(ctx)
-	if err != nil {
-		return Version{}, fmt.Errorf("failed to dial: %w", err)
-	}
-	defer conn.Close()
+	var version Version
 
-	if err := sendCommand(conn, "VERSION"); err != nil {
-		return Version{}, fmt.Errorf("failed to send VERSION command: %w", err)
-	}
+	err := c.do(ctx, true, true, func(s *session) error {
+		if err := sendCommand(s.conn, "VERSION"); err != nil {
+			return fmt.Errorf("failed to send VERSION command: %w", err)
+		}
 
-	response, err := readResponse(conn)
-	if err != nil {
-		return Version{}, fmt.Errorf("failed to read response: %w", err)
-	}
+		response, err := s.readResponse()
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		version, err = parseVersion(response)
+
+		return err
+	})
 
-	return parseVersion(response), nil
+	return version, err
 }
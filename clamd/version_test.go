@@ -25,7 +25,7 @@ func TestClamdVersion(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			client, err := clamd.NewClient("tcp://localhost:3310")
+			client, err := clamd.NewClient("tcp://localhost:3310", clamd.Config{})
 			if err != nil {
 				t.Fatalf("failed to dial: %v", err)
 			}
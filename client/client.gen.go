@@ -4,6 +4,7 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -24,16 +25,79 @@ const (
 	X_Hasura_Admin_SecretScopes = "X_Hasura_Admin_Secret.Scopes"
 )
 
+// Defines values for ImageFit.
+const (
+	Contain ImageFit = "contain"
+	Cover   ImageFit = "cover"
+	Fill    ImageFit = "fill"
+	Inside  ImageFit = "inside"
+)
+
+// Defines values for ImageFlip.
+const (
+	Both       ImageFlip = "both"
+	Horizontal ImageFlip = "horizontal"
+	Vertical   ImageFlip = "vertical"
+)
+
+// Defines values for ImageGravity.
+const (
+	Attention ImageGravity = "attention"
+	Center    ImageGravity = "center"
+	Entropy   ImageGravity = "entropy"
+)
+
 // Defines values for OutputImageFormat.
 const (
 	Auto OutputImageFormat = "auto"
 	Avif OutputImageFormat = "avif"
+	Gif  OutputImageFormat = "gif"
 	Jpeg OutputImageFormat = "jpeg"
+	Jxl  OutputImageFormat = "jxl"
 	Png  OutputImageFormat = "png"
 	Same OutputImageFormat = "same"
+	Tiff OutputImageFormat = "tiff"
 	Webp OutputImageFormat = "webp"
 )
 
+// Defines values for ScanStatus.
+const (
+	Clean    ScanStatus = "clean"
+	Error    ScanStatus = "error"
+	Infected ScanStatus = "infected"
+	Pending  ScanStatus = "pending"
+)
+
+// BrokenMetadataDeletion Result of deleting broken metadata.
+type BrokenMetadataDeletion struct {
+	// Deleted Whether the metadata was deleted, always false on dry runs.
+	Deleted bool `json:"deleted"`
+
+	// Error Why the metadata couldn't be deleted, if it failed.
+	Error *string `json:"error,omitempty"`
+
+	// Metadata Basic information about a file in storage.
+	Metadata FileSummary `json:"metadata"`
+}
+
+// CreatePresignedUploadRequest Details of a file to upload with a presigned upload.
+type CreatePresignedUploadRequest struct {
+	// BucketId Target bucket identifier where the file will be stored.
+	BucketId *string `json:"bucketId,omitempty"`
+
+	// Id Optional custom ID for the file. If not provided, a UUID will be generated.
+	Id *string `json:"id,omitempty"`
+
+	// Metadata Custom metadata to associate with the file.
+	Metadata *map[string]interface{} `json:"metadata,omitempty"`
+
+	// MimeType MIME type of the file, the upload must use this content type.
+	MimeType *string `json:"mimeType,omitempty"`
+
+	// Name Name to assign to the file.
+	Name string `json:"name"`
+}
+
 // ErrorResponse Error information returned by the API.
 type ErrorResponse struct {
 	// Error Error details.
@@ -87,6 +151,9 @@ type FileMetadata struct {
 	// Name Name of the file including extension.
 	Name string `json:"name"`
 
+	// ScanStatus Result of scanning the file for viruses. Files pending to be scanned or infected can't be downloaded. error means the file couldn't be scanned
+	ScanStatus *ScanStatus `json:"scanStatus,omitempty"`
+
 	// Size Size of the file in bytes.
 	Size int64 `json:"size"`
 
@@ -97,6 +164,21 @@ type FileMetadata struct {
 	UploadedByUserId *string `json:"uploadedByUserId,omitempty"`
 }
 
+// FileRescan Result of scanning a file for viruses again.
+type FileRescan struct {
+	// Error Why the file couldn't be scanned, if it failed.
+	Error *string `json:"error,omitempty"`
+
+	// File Basic information about a file in storage.
+	File FileSummary `json:"file"`
+
+	// Quarantined Whether the file was flagged as infected and moved to quarantine, always false on dry runs.
+	Quarantined bool `json:"quarantined"`
+
+	// Virus Name of the virus found, if any.
+	Virus *string `json:"virus,omitempty"`
+}
+
 // FileSummary Basic information about a file in storage.
 type FileSummary struct {
 	// BucketId ID of the bucket containing the file.
@@ -112,6 +194,48 @@ type FileSummary struct {
 	Name string `json:"name"`
 }
 
+// ImageFit How the image is resized when both w and h are given. cover crops the image to fill both dimensions, contain fits the image within them padding it with the background color, fill stretches the image ignoring its aspect ratio and inside fits the image within them without padding
+type ImageFit string
+
+// ImageFlip Direction to flip the image in
+type ImageFlip string
+
+// ImageGravity Which part of the image is kept when it is cropped. attention keeps the most salient features and entropy the area with most detail
+type ImageGravity string
+
+// ImageInfo Information about an image and a placeholder to show while it loads.
+type ImageInfo struct {
+	// Blurhash BlurHash (https://blurha.sh) placeholder of the image.
+	Blurhash string `json:"blurhash"`
+
+	// ColorSpace Color space of the image, i.e. srgb, b-w or cmyk.
+	ColorSpace string `json:"colorSpace"`
+
+	// DominantColor Dominant color of the image in hex format.
+	DominantColor string `json:"dominantColor"`
+
+	// Height Height in pixels of the image as displayed, after applying its orientation.
+	Height int `json:"height"`
+
+	// Orientation EXIF orientation of the image, 1 if it doesn't have one.
+	Orientation int `json:"orientation"`
+
+	// Width Width in pixels of the image as displayed, after applying its orientation.
+	Width int `json:"width"`
+}
+
+// OrphanedFileDeletion Result of deleting an orphaned file.
+type OrphanedFileDeletion struct {
+	// Deleted Whether the file was deleted, always false on dry runs.
+	Deleted bool `json:"deleted"`
+
+	// Error Why the file couldn't be deleted, if it failed.
+	Error *string `json:"error,omitempty"`
+
+	// File Key of the file in the storage.
+	File string `json:"file"`
+}
+
 // OutputImageFormat Output format for image files. Use 'auto' for content negotiation based on Accept header
 type OutputImageFormat string
 
@@ -124,9 +248,27 @@ type PresignedURLResponse struct {
 	Url string `json:"url"`
 }
 
+// PresignedUploadResponse Contains a presigned request to upload a file directly to the content storage.
+type PresignedUploadResponse struct {
+	// Expiration The time in seconds until the presigned upload expires.
+	Expiration int `json:"expiration"`
+
+	// Fields Form fields to send, as they are, before the file field.
+	Fields map[string]string `json:"fields"`
+
+	// Id Unique identifier of the file.
+	Id string `json:"id"`
+
+	// Url URL to send the multipart/form-data POST request to.
+	Url string `json:"url"`
+}
+
 // RFC2822Date Date in RFC 2822 format
 type RFC2822Date = Time
 
+// ScanStatus Result of scanning the file for viruses. Files pending to be scanned or infected can't be downloaded. error means the file couldn't be scanned
+type ScanStatus string
+
 // UpdateFileMetadata Metadata that can be updated for an existing file.
 type UpdateFileMetadata struct {
 	// Metadata Updated custom metadata to associate with the file.
@@ -156,13 +298,13 @@ type VersionInformation struct {
 
 // UploadFilesMultipartBody defines parameters for UploadFiles.
 type UploadFilesMultipartBody struct {
-	// BucketId Target bucket identifier where files will be stored.
+	// BucketId Target bucket identifier where files will be stored. Must be sent before the files.
 	BucketId *string `json:"bucket-id,omitempty"`
 
 	// File Array of files to upload.
 	File []openapi_types.File `json:"file[]"`
 
-	// Metadata Optional custom metadata for each uploaded file. Must match the order of the file[] array.
+	// Metadata Optional custom metadata for each uploaded file. Must match the order of the file[] array and each entry be sent before its file.
 	Metadata *[]UploadFileMetadata `json:"metadata[],omitempty"`
 }
 
@@ -183,6 +325,39 @@ type GetFileParams struct {
 	// F Output format for image files. Use 'auto' for content negotiation based on Accept header
 	F *OutputImageFormat `form:"f,omitempty" json:"f,omitempty"`
 
+	// Crop Crop the image to this rectangle, in pixels of the original image, before resizing it. Format: x,y,width,height. Only applies to image files
+	Crop *string `form:"crop,omitempty" json:"crop,omitempty"`
+
+	// Fit How the image is resized when both w and h are given. Only applies to image files
+	Fit *ImageFit `form:"fit,omitempty" json:"fit,omitempty"`
+
+	// Gravity Which part of the image is kept when it is cropped to cover w and h. Only applies to image files
+	Gravity *ImageGravity `form:"gravity,omitempty" json:"gravity,omitempty"`
+
+	// Rotate Rotate the image clockwise by this number of degrees. Only applies to image files
+	Rotate *int `form:"rotate,omitempty" json:"rotate,omitempty"`
+
+	// Flip Flip the image. Only applies to image files
+	Flip *ImageFlip `form:"flip,omitempty" json:"flip,omitempty"`
+
+	// Sharpen Sharpen the image using this sigma value. Only applies to image files
+	Sharpen *float32 `form:"sharpen,omitempty" json:"sharpen,omitempty"`
+
+	// Grayscale Convert the image to grayscale. Only applies to image files
+	Grayscale *bool `form:"grayscale,omitempty" json:"grayscale,omitempty"`
+
+	// Strip Remove the EXIF, XMP and IPTC metadata of the image, applying its EXIF orientation first. Only applies to image files
+	Strip *bool `form:"strip,omitempty" json:"strip,omitempty"`
+
+	// Background Color used to pad the image when fit is contain, as hex rrggbb or rrggbbaa. Defaults to white. Only applies to image files
+	Background *string `form:"background,omitempty" json:"background,omitempty"`
+
+	// Dpr Device pixel ratio, w and h are multiplied by it. Only applies to image files
+	Dpr *float32 `form:"dpr,omitempty" json:"dpr,omitempty"`
+
+	// Preset Name of a transformation preset of the bucket. Image parameters in the query override the preset's. Only applies to image files
+	Preset *string `form:"preset,omitempty" json:"preset,omitempty"`
+
 	// IfMatch Only return the file if the current ETag matches one of the values provided
 	IfMatch *string `json:"if-match,omitempty"`
 
@@ -216,6 +391,39 @@ type GetFileMetadataHeadersParams struct {
 	// F Output format for image files. Use 'auto' for content negotiation based on Accept header
 	F *OutputImageFormat `form:"f,omitempty" json:"f,omitempty"`
 
+	// Crop Crop the image to this rectangle, in pixels of the original image, before resizing it. Format: x,y,width,height. Only applies to image files
+	Crop *string `form:"crop,omitempty" json:"crop,omitempty"`
+
+	// Fit How the image is resized when both w and h are given. Only applies to image files
+	Fit *ImageFit `form:"fit,omitempty" json:"fit,omitempty"`
+
+	// Gravity Which part of the image is kept when it is cropped to cover w and h. Only applies to image files
+	Gravity *ImageGravity `form:"gravity,omitempty" json:"gravity,omitempty"`
+
+	// Rotate Rotate the image clockwise by this number of degrees. Only applies to image files
+	Rotate *int `form:"rotate,omitempty" json:"rotate,omitempty"`
+
+	// Flip Flip the image. Only applies to image files
+	Flip *ImageFlip `form:"flip,omitempty" json:"flip,omitempty"`
+
+	// Sharpen Sharpen the image using this sigma value. Only applies to image files
+	Sharpen *float32 `form:"sharpen,omitempty" json:"sharpen,omitempty"`
+
+	// Grayscale Convert the image to grayscale. Only applies to image files
+	Grayscale *bool `form:"grayscale,omitempty" json:"grayscale,omitempty"`
+
+	// Strip Remove the EXIF, XMP and IPTC metadata of the image, applying its EXIF orientation first. Only applies to image files
+	Strip *bool `form:"strip,omitempty" json:"strip,omitempty"`
+
+	// Background Color used to pad the image when fit is contain, as hex rrggbb or rrggbbaa. Defaults to white. Only applies to image files
+	Background *string `form:"background,omitempty" json:"background,omitempty"`
+
+	// Dpr Device pixel ratio, w and h are multiplied by it. Only applies to image files
+	Dpr *float32 `form:"dpr,omitempty" json:"dpr,omitempty"`
+
+	// Preset Name of a transformation preset of the bucket. Image parameters in the query override the preset's. Only applies to image files
+	Preset *string `form:"preset,omitempty" json:"preset,omitempty"`
+
 	// IfMatch Only return the file if the current ETag matches one of the values provided
 	IfMatch *string `json:"if-match,omitempty"`
 
@@ -238,6 +446,12 @@ type ReplaceFileMultipartBody struct {
 	Metadata *UpdateFileMetadata `json:"metadata,omitempty"`
 }
 
+// CompletePresignedUploadParams defines parameters for CompletePresignedUpload.
+type CompletePresignedUploadParams struct {
+	// Etag If set, the upload fails unless the stored content has this etag
+	Etag *string `form:"etag,omitempty" json:"etag,omitempty"`
+}
+
 // GetFileWithPresignedURLParams defines parameters for GetFileWithPresignedURL.
 type GetFileWithPresignedURLParams struct {
 	// XAmzAlgorithm Use presignedurl endpoint to generate this automatically
@@ -282,6 +496,39 @@ type GetFileWithPresignedURLParams struct {
 	// F Output format for image files. Use 'auto' for content negotiation based on Accept header
 	F *OutputImageFormat `form:"f,omitempty" json:"f,omitempty"`
 
+	// Crop Crop the image to this rectangle, in pixels of the original image, before resizing it. Format: x,y,width,height. Only applies to image files
+	Crop *string `form:"crop,omitempty" json:"crop,omitempty"`
+
+	// Fit How the image is resized when both w and h are given. Only applies to image files
+	Fit *ImageFit `form:"fit,omitempty" json:"fit,omitempty"`
+
+	// Gravity Which part of the image is kept when it is cropped to cover w and h. Only applies to image files
+	Gravity *ImageGravity `form:"gravity,omitempty" json:"gravity,omitempty"`
+
+	// Rotate Rotate the image clockwise by this number of degrees. Only applies to image files
+	Rotate *int `form:"rotate,omitempty" json:"rotate,omitempty"`
+
+	// Flip Flip the image. Only applies to image files
+	Flip *ImageFlip `form:"flip,omitempty" json:"flip,omitempty"`
+
+	// Sharpen Sharpen the image using this sigma value. Only applies to image files
+	Sharpen *float32 `form:"sharpen,omitempty" json:"sharpen,omitempty"`
+
+	// Grayscale Convert the image to grayscale. Only applies to image files
+	Grayscale *bool `form:"grayscale,omitempty" json:"grayscale,omitempty"`
+
+	// Strip Remove the EXIF, XMP and IPTC metadata of the image, applying its EXIF orientation first. Only applies to image files
+	Strip *bool `form:"strip,omitempty" json:"strip,omitempty"`
+
+	// Background Color used to pad the image when fit is contain, as hex rrggbb or rrggbbaa. Defaults to white. Only applies to image files
+	Background *string `form:"background,omitempty" json:"background,omitempty"`
+
+	// Dpr Device pixel ratio, w and h are multiplied by it. Only applies to image files
+	Dpr *float32 `form:"dpr,omitempty" json:"dpr,omitempty"`
+
+	// Preset Name of a transformation preset of the bucket. Image parameters in the query override the preset's. Only applies to image files
+	Preset *string `form:"preset,omitempty" json:"preset,omitempty"`
+
 	// IfMatch Only return the file if the current ETag matches one of the values provided
 	IfMatch *string `json:"if-match,omitempty"`
 
@@ -298,9 +545,63 @@ type GetFileWithPresignedURLParams struct {
 	Range *string `json:"Range,omitempty"`
 }
 
+// DeleteBrokenMetadataParams defines parameters for DeleteBrokenMetadata.
+type DeleteBrokenMetadataParams struct {
+	// DryRun Report what would be deleted without deleting anything
+	DryRun *bool `form:"dryRun,omitempty" json:"dryRun,omitempty"`
+
+	// MaxDeletions Stop after processing this many files, including the ones that failed
+	MaxDeletions *int `form:"maxDeletions,omitempty" json:"maxDeletions,omitempty"`
+
+	// MinAge Only delete files last modified at least this many seconds ago, so files being uploaded are left alone
+	MinAge *int `form:"minAge,omitempty" json:"minAge,omitempty"`
+
+	// BucketId Only delete metadata of files in this bucket
+	BucketId *string `form:"bucketId,omitempty" json:"bucketId,omitempty"`
+}
+
+// DeleteOrphanedFilesParams defines parameters for DeleteOrphanedFiles.
+type DeleteOrphanedFilesParams struct {
+	// DryRun Report what would be deleted without deleting anything
+	DryRun *bool `form:"dryRun,omitempty" json:"dryRun,omitempty"`
+
+	// MaxDeletions Stop after processing this many files, including the ones that failed
+	MaxDeletions *int `form:"maxDeletions,omitempty" json:"maxDeletions,omitempty"`
+
+	// MinAge Only delete files last modified at least this many seconds ago, so files being uploaded are left alone
+	MinAge *int `form:"minAge,omitempty" json:"minAge,omitempty"`
+}
+
+// RescanFilesParams defines parameters for RescanFiles.
+type RescanFilesParams struct {
+	// BucketId Only scan files in this bucket
+	BucketId *string `form:"bucketId,omitempty" json:"bucketId,omitempty"`
+
+	// UploadedAfter Only scan files uploaded at or after this date
+	UploadedAfter *time.Time `form:"uploadedAfter,omitempty" json:"uploadedAfter,omitempty"`
+
+	// UploadedBefore Only scan files uploaded before this date
+	UploadedBefore *time.Time `form:"uploadedBefore,omitempty" json:"uploadedBefore,omitempty"`
+
+	// After Only scan files with an id greater than this one. Files are scanned in order of id so the id of the last result can be used to resume an interrupted rescan
+	After *string `form:"after,omitempty" json:"after,omitempty"`
+
+	// Concurrency Number of files scanned at the same time
+	Concurrency *int `form:"concurrency,omitempty" json:"concurrency,omitempty"`
+
+	// ReloadSignatures Ask the antivirus to reload its virus signatures before scanning
+	ReloadSignatures *bool `form:"reloadSignatures,omitempty" json:"reloadSignatures,omitempty"`
+
+	// DryRun Report the viruses found without flagging the files as infected or moving them to quarantine
+	DryRun *bool `form:"dryRun,omitempty" json:"dryRun,omitempty"`
+}
+
 // UploadFilesMultipartRequestBody defines body for UploadFiles for multipart/form-data ContentType.
 type UploadFilesMultipartRequestBody UploadFilesMultipartBody
 
+// CreatePresignedUploadJSONRequestBody defines body for CreatePresignedUpload for application/json ContentType.
+type CreatePresignedUploadJSONRequestBody = CreatePresignedUploadRequest
+
 // ReplaceFileMultipartRequestBody defines body for ReplaceFile for multipart/form-data ContentType.
 type ReplaceFileMultipartRequestBody ReplaceFileMultipartBody
 
@@ -380,6 +681,11 @@ type ClientInterface interface {
 	// UploadFilesWithBody request with any body
 	UploadFilesWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
 
+	// CreatePresignedUploadWithBody request with any body
+	CreatePresignedUploadWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	CreatePresignedUpload(ctx context.Context, body CreatePresignedUploadJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
 	// DeleteFile request
 	DeleteFile(ctx context.Context, id string, reqEditors ...RequestEditorFn) (*http.Response, error)
 
@@ -392,6 +698,12 @@ type ClientInterface interface {
 	// ReplaceFileWithBody request with any body
 	ReplaceFileWithBody(ctx context.Context, id string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
 
+	// GetFileImageInfo request
+	GetFileImageInfo(ctx context.Context, id string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// CompletePresignedUpload request
+	CompletePresignedUpload(ctx context.Context, id string, params *CompletePresignedUploadParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
 	// GetFilePresignedURL request
 	GetFilePresignedURL(ctx context.Context, id string, reqEditors ...RequestEditorFn) (*http.Response, error)
 
@@ -402,10 +714,10 @@ type ClientInterface interface {
 	GetOpenAPISpec(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
 
 	// DeleteBrokenMetadata request
-	DeleteBrokenMetadata(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+	DeleteBrokenMetadata(ctx context.Context, params *DeleteBrokenMetadataParams, reqEditors ...RequestEditorFn) (*http.Response, error)
 
 	// DeleteOrphanedFiles request
-	DeleteOrphanedFiles(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+	DeleteOrphanedFiles(ctx context.Context, params *DeleteOrphanedFilesParams, reqEditors ...RequestEditorFn) (*http.Response, error)
 
 	// ListBrokenMetadata request
 	ListBrokenMetadata(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
@@ -416,6 +728,9 @@ type ClientInterface interface {
 	// ListOrphanedFiles request
 	ListOrphanedFiles(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
 
+	// RescanFiles request
+	RescanFiles(ctx context.Context, params *RescanFilesParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
 	// GetVersion request
 	GetVersion(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
 }
@@ -432,6 +747,30 @@ func (c *Client) UploadFilesWithBody(ctx context.Context, contentType string, bo
 	return c.Client.Do(req)
 }
 
+func (c *Client) CreatePresignedUploadWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewCreatePresignedUploadRequestWithBody(c.Server, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) CreatePresignedUpload(ctx context.Context, body CreatePresignedUploadJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewCreatePresignedUploadRequest(c.Server, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
 func (c *Client) DeleteFile(ctx context.Context, id string, reqEditors ...RequestEditorFn) (*http.Response, error) {
 	req, err := NewDeleteFileRequest(c.Server, id)
 	if err != nil {
@@ -480,6 +819,30 @@ func (c *Client) ReplaceFileWithBody(ctx context.Context, id string, contentType
 	return c.Client.Do(req)
 }
 
+func (c *Client) GetFileImageInfo(ctx context.Context, id string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetFileImageInfoRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) CompletePresignedUpload(ctx context.Context, id string, params *CompletePresignedUploadParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewCompletePresignedUploadRequest(c.Server, id, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
 func (c *Client) GetFilePresignedURL(ctx context.Context, id string, reqEditors ...RequestEditorFn) (*http.Response, error) {
 	req, err := NewGetFilePresignedURLRequest(c.Server, id)
 	if err != nil {
@@ -516,8 +879,8 @@ func (c *Client) GetOpenAPISpec(ctx context.Context, reqEditors ...RequestEditor
 	return c.Client.Do(req)
 }
 
-func (c *Client) DeleteBrokenMetadata(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
-	req, err := NewDeleteBrokenMetadataRequest(c.Server)
+func (c *Client) DeleteBrokenMetadata(ctx context.Context, params *DeleteBrokenMetadataParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewDeleteBrokenMetadataRequest(c.Server, params)
 	if err != nil {
 		return nil, err
 	}
@@ -528,8 +891,8 @@ func (c *Client) DeleteBrokenMetadata(ctx context.Context, reqEditors ...Request
 	return c.Client.Do(req)
 }
 
-func (c *Client) DeleteOrphanedFiles(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
-	req, err := NewDeleteOrphanedFilesRequest(c.Server)
+func (c *Client) DeleteOrphanedFiles(ctx context.Context, params *DeleteOrphanedFilesParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewDeleteOrphanedFilesRequest(c.Server, params)
 	if err != nil {
 		return nil, err
 	}
@@ -576,6 +939,18 @@ func (c *Client) ListOrphanedFiles(ctx context.Context, reqEditors ...RequestEdi
 	return c.Client.Do(req)
 }
 
+func (c *Client) RescanFiles(ctx context.Context, params *RescanFilesParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewRescanFilesRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
 func (c *Client) GetVersion(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
 	req, err := NewGetVersionRequest(c.Server)
 	if err != nil {
@@ -617,6 +992,46 @@ func NewUploadFilesRequestWithBody(server string, contentType string, body io.Re
 	return req, nil
 }
 
+// NewCreatePresignedUploadRequest calls the generic CreatePresignedUpload builder with application/json body
+func NewCreatePresignedUploadRequest(server string, body CreatePresignedUploadJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewCreatePresignedUploadRequestWithBody(server, "application/json", bodyReader)
+}
+
+// NewCreatePresignedUploadRequestWithBody generates requests for CreatePresignedUpload with any type of body
+func NewCreatePresignedUploadRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/files/presignedupload")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
 // NewDeleteFileRequest generates requests for DeleteFile
 func NewDeleteFileRequest(server string, id string) (*http.Request, error) {
 	var err error
@@ -760,54 +1175,230 @@ func NewGetFileRequest(server string, id string, params *GetFileParams) (*http.R
 
 		}
 
-		queryURL.RawQuery = queryValues.Encode()
-	}
+		if params.Crop != nil {
 
-	req, err := http.NewRequest("GET", queryURL.String(), nil)
-	if err != nil {
-		return nil, err
-	}
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "crop", runtime.ParamLocationQuery, *params.Crop); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
 
-	if params != nil {
+		}
 
-		if params.IfMatch != nil {
-			var headerParam0 string
+		if params.Fit != nil {
 
-			headerParam0, err = runtime.StyleParamWithLocation("simple", false, "if-match", runtime.ParamLocationHeader, *params.IfMatch)
-			if err != nil {
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "fit", runtime.ParamLocationQuery, *params.Fit); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
 				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
 			}
 
-			req.Header.Set("if-match", headerParam0)
 		}
 
-		if params.IfNoneMatch != nil {
-			var headerParam1 string
+		if params.Gravity != nil {
 
-			headerParam1, err = runtime.StyleParamWithLocation("simple", false, "if-none-match", runtime.ParamLocationHeader, *params.IfNoneMatch)
-			if err != nil {
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "gravity", runtime.ParamLocationQuery, *params.Gravity); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
 				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
 			}
 
-			req.Header.Set("if-none-match", headerParam1)
 		}
 
-		if params.IfModifiedSince != nil {
-			var headerParam2 string
+		if params.Rotate != nil {
 
-			headerParam2, err = runtime.StyleParamWithLocation("simple", false, "if-modified-since", runtime.ParamLocationHeader, *params.IfModifiedSince)
-			if err != nil {
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "rotate", runtime.ParamLocationQuery, *params.Rotate); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
 				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
 			}
 
-			req.Header.Set("if-modified-since", headerParam2)
 		}
 
-		if params.IfUnmodifiedSince != nil {
-			var headerParam3 string
+		if params.Flip != nil {
 
-			headerParam3, err = runtime.StyleParamWithLocation("simple", false, "if-unmodified-since", runtime.ParamLocationHeader, *params.IfUnmodifiedSince)
-			if err != nil {
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "flip", runtime.ParamLocationQuery, *params.Flip); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Sharpen != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "sharpen", runtime.ParamLocationQuery, *params.Sharpen); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Grayscale != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "grayscale", runtime.ParamLocationQuery, *params.Grayscale); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Strip != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "strip", runtime.ParamLocationQuery, *params.Strip); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Background != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "background", runtime.ParamLocationQuery, *params.Background); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Dpr != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "dpr", runtime.ParamLocationQuery, *params.Dpr); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Preset != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "preset", runtime.ParamLocationQuery, *params.Preset); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+
+		if params.IfMatch != nil {
+			var headerParam0 string
+
+			headerParam0, err = runtime.StyleParamWithLocation("simple", false, "if-match", runtime.ParamLocationHeader, *params.IfMatch)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("if-match", headerParam0)
+		}
+
+		if params.IfNoneMatch != nil {
+			var headerParam1 string
+
+			headerParam1, err = runtime.StyleParamWithLocation("simple", false, "if-none-match", runtime.ParamLocationHeader, *params.IfNoneMatch)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("if-none-match", headerParam1)
+		}
+
+		if params.IfModifiedSince != nil {
+			var headerParam2 string
+
+			headerParam2, err = runtime.StyleParamWithLocation("simple", false, "if-modified-since", runtime.ParamLocationHeader, *params.IfModifiedSince)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("if-modified-since", headerParam2)
+		}
+
+		if params.IfUnmodifiedSince != nil {
+			var headerParam3 string
+
+			headerParam3, err = runtime.StyleParamWithLocation("simple", false, "if-unmodified-since", runtime.ParamLocationHeader, *params.IfUnmodifiedSince)
+			if err != nil {
 				return nil, err
 			}
 
@@ -939,6 +1530,182 @@ func NewGetFileMetadataHeadersRequest(server string, id string, params *GetFileM
 
 		}
 
+		if params.Crop != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "crop", runtime.ParamLocationQuery, *params.Crop); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Fit != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "fit", runtime.ParamLocationQuery, *params.Fit); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Gravity != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "gravity", runtime.ParamLocationQuery, *params.Gravity); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Rotate != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "rotate", runtime.ParamLocationQuery, *params.Rotate); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Flip != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "flip", runtime.ParamLocationQuery, *params.Flip); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Sharpen != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "sharpen", runtime.ParamLocationQuery, *params.Sharpen); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Grayscale != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "grayscale", runtime.ParamLocationQuery, *params.Grayscale); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Strip != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "strip", runtime.ParamLocationQuery, *params.Strip); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Background != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "background", runtime.ParamLocationQuery, *params.Background); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Dpr != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "dpr", runtime.ParamLocationQuery, *params.Dpr); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Preset != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "preset", runtime.ParamLocationQuery, *params.Preset); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
 		queryURL.RawQuery = queryValues.Encode()
 	}
 
@@ -960,46 +1727,116 @@ func NewGetFileMetadataHeadersRequest(server string, id string, params *GetFileM
 			req.Header.Set("if-match", headerParam0)
 		}
 
-		if params.IfNoneMatch != nil {
-			var headerParam1 string
+		if params.IfNoneMatch != nil {
+			var headerParam1 string
+
+			headerParam1, err = runtime.StyleParamWithLocation("simple", false, "if-none-match", runtime.ParamLocationHeader, *params.IfNoneMatch)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("if-none-match", headerParam1)
+		}
+
+		if params.IfModifiedSince != nil {
+			var headerParam2 string
+
+			headerParam2, err = runtime.StyleParamWithLocation("simple", false, "if-modified-since", runtime.ParamLocationHeader, *params.IfModifiedSince)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("if-modified-since", headerParam2)
+		}
+
+		if params.IfUnmodifiedSince != nil {
+			var headerParam3 string
+
+			headerParam3, err = runtime.StyleParamWithLocation("simple", false, "if-unmodified-since", runtime.ParamLocationHeader, *params.IfUnmodifiedSince)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("if-unmodified-since", headerParam3)
+		}
+
+	}
+
+	return req, nil
+}
+
+// NewReplaceFileRequestWithBody generates requests for ReplaceFile with any type of body
+func NewReplaceFileRequestWithBody(server string, id string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/files/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PUT", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
 
-			headerParam1, err = runtime.StyleParamWithLocation("simple", false, "if-none-match", runtime.ParamLocationHeader, *params.IfNoneMatch)
-			if err != nil {
-				return nil, err
-			}
+	req.Header.Add("Content-Type", contentType)
 
-			req.Header.Set("if-none-match", headerParam1)
-		}
+	return req, nil
+}
 
-		if params.IfModifiedSince != nil {
-			var headerParam2 string
+// NewGetFileImageInfoRequest generates requests for GetFileImageInfo
+func NewGetFileImageInfoRequest(server string, id string) (*http.Request, error) {
+	var err error
 
-			headerParam2, err = runtime.StyleParamWithLocation("simple", false, "if-modified-since", runtime.ParamLocationHeader, *params.IfModifiedSince)
-			if err != nil {
-				return nil, err
-			}
+	var pathParam0 string
 
-			req.Header.Set("if-modified-since", headerParam2)
-		}
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
 
-		if params.IfUnmodifiedSince != nil {
-			var headerParam3 string
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
 
-			headerParam3, err = runtime.StyleParamWithLocation("simple", false, "if-unmodified-since", runtime.ParamLocationHeader, *params.IfUnmodifiedSince)
-			if err != nil {
-				return nil, err
-			}
+	operationPath := fmt.Sprintf("/files/%s/image-info", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
 
-			req.Header.Set("if-unmodified-since", headerParam3)
-		}
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
 
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
 	}
 
 	return req, nil
 }
 
-// NewReplaceFileRequestWithBody generates requests for ReplaceFile with any type of body
-func NewReplaceFileRequestWithBody(server string, id string, contentType string, body io.Reader) (*http.Request, error) {
+// NewCompletePresignedUploadRequest generates requests for CompletePresignedUpload
+func NewCompletePresignedUploadRequest(server string, id string, params *CompletePresignedUploadParams) (*http.Request, error) {
 	var err error
 
 	var pathParam0 string
@@ -1014,7 +1851,7 @@ func NewReplaceFileRequestWithBody(server string, id string, contentType string,
 		return nil, err
 	}
 
-	operationPath := fmt.Sprintf("/files/%s", pathParam0)
+	operationPath := fmt.Sprintf("/files/%s/presignedupload/complete", pathParam0)
 	if operationPath[0] == '/' {
 		operationPath = "." + operationPath
 	}
@@ -1024,13 +1861,33 @@ func NewReplaceFileRequestWithBody(server string, id string, contentType string,
 		return nil, err
 	}
 
-	req, err := http.NewRequest("PUT", queryURL.String(), body)
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Etag != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "etag", runtime.ParamLocationQuery, *params.Etag); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Content-Type", contentType)
-
 	return req, nil
 }
 
@@ -1197,21 +2054,197 @@ func NewGetFileWithPresignedURLRequest(server string, id string, params *GetFile
 
 		}
 
-		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "x-id", runtime.ParamLocationQuery, params.XId); err != nil {
-			return nil, err
-		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
-			return nil, err
-		} else {
-			for k, v := range parsed {
-				for _, v2 := range v {
-					queryValues.Add(k, v2)
-				}
-			}
-		}
-
-		if params.Q != nil {
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "x-id", runtime.ParamLocationQuery, params.XId); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if params.Q != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "q", runtime.ParamLocationQuery, *params.Q); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.H != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "h", runtime.ParamLocationQuery, *params.H); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.W != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "w", runtime.ParamLocationQuery, *params.W); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.B != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "b", runtime.ParamLocationQuery, *params.B); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.F != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "f", runtime.ParamLocationQuery, *params.F); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Crop != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "crop", runtime.ParamLocationQuery, *params.Crop); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Fit != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "fit", runtime.ParamLocationQuery, *params.Fit); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Gravity != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "gravity", runtime.ParamLocationQuery, *params.Gravity); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Rotate != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "rotate", runtime.ParamLocationQuery, *params.Rotate); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Flip != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "flip", runtime.ParamLocationQuery, *params.Flip); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Sharpen != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "sharpen", runtime.ParamLocationQuery, *params.Sharpen); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Grayscale != nil {
 
-			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "q", runtime.ParamLocationQuery, *params.Q); err != nil {
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "grayscale", runtime.ParamLocationQuery, *params.Grayscale); err != nil {
 				return nil, err
 			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
 				return nil, err
@@ -1225,9 +2258,9 @@ func NewGetFileWithPresignedURLRequest(server string, id string, params *GetFile
 
 		}
 
-		if params.H != nil {
+		if params.Strip != nil {
 
-			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "h", runtime.ParamLocationQuery, *params.H); err != nil {
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "strip", runtime.ParamLocationQuery, *params.Strip); err != nil {
 				return nil, err
 			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
 				return nil, err
@@ -1241,9 +2274,9 @@ func NewGetFileWithPresignedURLRequest(server string, id string, params *GetFile
 
 		}
 
-		if params.W != nil {
+		if params.Background != nil {
 
-			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "w", runtime.ParamLocationQuery, *params.W); err != nil {
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "background", runtime.ParamLocationQuery, *params.Background); err != nil {
 				return nil, err
 			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
 				return nil, err
@@ -1257,9 +2290,9 @@ func NewGetFileWithPresignedURLRequest(server string, id string, params *GetFile
 
 		}
 
-		if params.B != nil {
+		if params.Dpr != nil {
 
-			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "b", runtime.ParamLocationQuery, *params.B); err != nil {
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "dpr", runtime.ParamLocationQuery, *params.Dpr); err != nil {
 				return nil, err
 			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
 				return nil, err
@@ -1273,9 +2306,9 @@ func NewGetFileWithPresignedURLRequest(server string, id string, params *GetFile
 
 		}
 
-		if params.F != nil {
+		if params.Preset != nil {
 
-			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "f", runtime.ParamLocationQuery, *params.F); err != nil {
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "preset", runtime.ParamLocationQuery, *params.Preset); err != nil {
 				return nil, err
 			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
 				return nil, err
@@ -1387,7 +2420,7 @@ func NewGetOpenAPISpecRequest(server string) (*http.Request, error) {
 }
 
 // NewDeleteBrokenMetadataRequest generates requests for DeleteBrokenMetadata
-func NewDeleteBrokenMetadataRequest(server string) (*http.Request, error) {
+func NewDeleteBrokenMetadataRequest(server string, params *DeleteBrokenMetadataParams) (*http.Request, error) {
 	var err error
 
 	serverURL, err := url.Parse(server)
@@ -1405,6 +2438,76 @@ func NewDeleteBrokenMetadataRequest(server string) (*http.Request, error) {
 		return nil, err
 	}
 
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.DryRun != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "dryRun", runtime.ParamLocationQuery, *params.DryRun); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.MaxDeletions != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "maxDeletions", runtime.ParamLocationQuery, *params.MaxDeletions); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.MinAge != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "minAge", runtime.ParamLocationQuery, *params.MinAge); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.BucketId != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "bucketId", runtime.ParamLocationQuery, *params.BucketId); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
 	req, err := http.NewRequest("POST", queryURL.String(), nil)
 	if err != nil {
 		return nil, err
@@ -1414,7 +2517,7 @@ func NewDeleteBrokenMetadataRequest(server string) (*http.Request, error) {
 }
 
 // NewDeleteOrphanedFilesRequest generates requests for DeleteOrphanedFiles
-func NewDeleteOrphanedFilesRequest(server string) (*http.Request, error) {
+func NewDeleteOrphanedFilesRequest(server string, params *DeleteOrphanedFilesParams) (*http.Request, error) {
 	var err error
 
 	serverURL, err := url.Parse(server)
@@ -1432,6 +2535,60 @@ func NewDeleteOrphanedFilesRequest(server string) (*http.Request, error) {
 		return nil, err
 	}
 
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.DryRun != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "dryRun", runtime.ParamLocationQuery, *params.DryRun); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.MaxDeletions != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "maxDeletions", runtime.ParamLocationQuery, *params.MaxDeletions); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.MinAge != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "minAge", runtime.ParamLocationQuery, *params.MinAge); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
 	req, err := http.NewRequest("POST", queryURL.String(), nil)
 	if err != nil {
 		return nil, err
@@ -1503,14 +2660,159 @@ func NewListOrphanedFilesRequest(server string) (*http.Request, error) {
 		return nil, err
 	}
 
-	operationPath := fmt.Sprintf("/ops/list-orphans")
-	if operationPath[0] == '/' {
-		operationPath = "." + operationPath
-	}
+	operationPath := fmt.Sprintf("/ops/list-orphans")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewRescanFilesRequest generates requests for RescanFiles
+func NewRescanFilesRequest(server string, params *RescanFilesParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/ops/rescan-files")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.BucketId != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "bucketId", runtime.ParamLocationQuery, *params.BucketId); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.UploadedAfter != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "uploadedAfter", runtime.ParamLocationQuery, *params.UploadedAfter); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.UploadedBefore != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "uploadedBefore", runtime.ParamLocationQuery, *params.UploadedBefore); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.After != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "after", runtime.ParamLocationQuery, *params.After); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Concurrency != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "concurrency", runtime.ParamLocationQuery, *params.Concurrency); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.ReloadSignatures != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "reloadSignatures", runtime.ParamLocationQuery, *params.ReloadSignatures); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.DryRun != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "dryRun", runtime.ParamLocationQuery, *params.DryRun); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
 
-	queryURL, err := serverURL.Parse(operationPath)
-	if err != nil {
-		return nil, err
+		queryURL.RawQuery = queryValues.Encode()
 	}
 
 	req, err := http.NewRequest("POST", queryURL.String(), nil)
@@ -1594,6 +2896,11 @@ type ClientWithResponsesInterface interface {
 	// UploadFilesWithBodyWithResponse request with any body
 	UploadFilesWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*UploadFilesR, error)
 
+	// CreatePresignedUploadWithBodyWithResponse request with any body
+	CreatePresignedUploadWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*CreatePresignedUploadR, error)
+
+	CreatePresignedUploadWithResponse(ctx context.Context, body CreatePresignedUploadJSONRequestBody, reqEditors ...RequestEditorFn) (*CreatePresignedUploadR, error)
+
 	// DeleteFileWithResponse request
 	DeleteFileWithResponse(ctx context.Context, id string, reqEditors ...RequestEditorFn) (*DeleteFileR, error)
 
@@ -1606,6 +2913,12 @@ type ClientWithResponsesInterface interface {
 	// ReplaceFileWithBodyWithResponse request with any body
 	ReplaceFileWithBodyWithResponse(ctx context.Context, id string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*ReplaceFileR, error)
 
+	// GetFileImageInfoWithResponse request
+	GetFileImageInfoWithResponse(ctx context.Context, id string, reqEditors ...RequestEditorFn) (*GetFileImageInfoR, error)
+
+	// CompletePresignedUploadWithResponse request
+	CompletePresignedUploadWithResponse(ctx context.Context, id string, params *CompletePresignedUploadParams, reqEditors ...RequestEditorFn) (*CompletePresignedUploadR, error)
+
 	// GetFilePresignedURLWithResponse request
 	GetFilePresignedURLWithResponse(ctx context.Context, id string, reqEditors ...RequestEditorFn) (*GetFilePresignedURLR, error)
 
@@ -1616,10 +2929,10 @@ type ClientWithResponsesInterface interface {
 	GetOpenAPISpecWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetOpenAPISpecR, error)
 
 	// DeleteBrokenMetadataWithResponse request
-	DeleteBrokenMetadataWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*DeleteBrokenMetadataR, error)
+	DeleteBrokenMetadataWithResponse(ctx context.Context, params *DeleteBrokenMetadataParams, reqEditors ...RequestEditorFn) (*DeleteBrokenMetadataR, error)
 
 	// DeleteOrphanedFilesWithResponse request
-	DeleteOrphanedFilesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*DeleteOrphanedFilesR, error)
+	DeleteOrphanedFilesWithResponse(ctx context.Context, params *DeleteOrphanedFilesParams, reqEditors ...RequestEditorFn) (*DeleteOrphanedFilesR, error)
 
 	// ListBrokenMetadataWithResponse request
 	ListBrokenMetadataWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListBrokenMetadataR, error)
@@ -1630,6 +2943,9 @@ type ClientWithResponsesInterface interface {
 	// ListOrphanedFilesWithResponse request
 	ListOrphanedFilesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListOrphanedFilesR, error)
 
+	// RescanFilesWithResponse request
+	RescanFilesWithResponse(ctx context.Context, params *RescanFilesParams, reqEditors ...RequestEditorFn) (*RescanFilesR, error)
+
 	// GetVersionWithResponse request
 	GetVersionWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetVersionR, error)
 }
@@ -1660,6 +2976,29 @@ func (r UploadFilesR) StatusCode() int {
 	return 0
 }
 
+type CreatePresignedUploadR struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *PresignedUploadResponse
+	JSONDefault  *ErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r CreatePresignedUploadR) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r CreatePresignedUploadR) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
 type DeleteFileR struct {
 	Body         []byte
 	HTTPResponse *http.Response
@@ -1747,6 +3086,52 @@ func (r ReplaceFileR) StatusCode() int {
 	return 0
 }
 
+type GetFileImageInfoR struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ImageInfo
+	JSONDefault  *ErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r GetFileImageInfoR) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetFileImageInfoR) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type CompletePresignedUploadR struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *FileMetadata
+	JSONDefault  *ErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r CompletePresignedUploadR) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r CompletePresignedUploadR) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
 type GetFilePresignedURLR struct {
 	Body         []byte
 	HTTPResponse *http.Response
@@ -1818,7 +3203,9 @@ type DeleteBrokenMetadataR struct {
 	Body         []byte
 	HTTPResponse *http.Response
 	JSON200      *struct {
-		Metadata *[]FileSummary `json:"metadata,omitempty"`
+		// Metadata Metadata deleted, or that would be deleted on dry runs
+		Metadata *[]FileSummary            `json:"metadata,omitempty"`
+		Results  *[]BrokenMetadataDeletion `json:"results,omitempty"`
 	}
 	JSONDefault *ErrorResponse
 }
@@ -1843,7 +3230,9 @@ type DeleteOrphanedFilesR struct {
 	Body         []byte
 	HTTPResponse *http.Response
 	JSON200      *struct {
-		Files *[]string `json:"files,omitempty"`
+		// Files Files deleted, or that would be deleted on dry runs
+		Files   *[]string               `json:"files,omitempty"`
+		Results *[]OrphanedFileDeletion `json:"results,omitempty"`
 	}
 	JSONDefault *ErrorResponse
 }
@@ -1939,6 +3328,33 @@ func (r ListOrphanedFilesR) StatusCode() int {
 	return 0
 }
 
+type RescanFilesR struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *struct {
+		// Infected Files where a virus was found
+		Infected *[]FileSummary `json:"infected,omitempty"`
+		Results  *[]FileRescan  `json:"results,omitempty"`
+	}
+	JSONDefault *ErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r RescanFilesR) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r RescanFilesR) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
 type GetVersionR struct {
 	Body         []byte
 	HTTPResponse *http.Response
@@ -1971,6 +3387,23 @@ func (c *ClientWithResponses) UploadFilesWithBodyWithResponse(ctx context.Contex
 	return ParseUploadFilesR(rsp)
 }
 
+// CreatePresignedUploadWithBodyWithResponse request with arbitrary body returning *CreatePresignedUploadR
+func (c *ClientWithResponses) CreatePresignedUploadWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*CreatePresignedUploadR, error) {
+	rsp, err := c.CreatePresignedUploadWithBody(ctx, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCreatePresignedUploadR(rsp)
+}
+
+func (c *ClientWithResponses) CreatePresignedUploadWithResponse(ctx context.Context, body CreatePresignedUploadJSONRequestBody, reqEditors ...RequestEditorFn) (*CreatePresignedUploadR, error) {
+	rsp, err := c.CreatePresignedUpload(ctx, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCreatePresignedUploadR(rsp)
+}
+
 // DeleteFileWithResponse request returning *DeleteFileR
 func (c *ClientWithResponses) DeleteFileWithResponse(ctx context.Context, id string, reqEditors ...RequestEditorFn) (*DeleteFileR, error) {
 	rsp, err := c.DeleteFile(ctx, id, reqEditors...)
@@ -2007,6 +3440,24 @@ func (c *ClientWithResponses) ReplaceFileWithBodyWithResponse(ctx context.Contex
 	return ParseReplaceFileR(rsp)
 }
 
+// GetFileImageInfoWithResponse request returning *GetFileImageInfoR
+func (c *ClientWithResponses) GetFileImageInfoWithResponse(ctx context.Context, id string, reqEditors ...RequestEditorFn) (*GetFileImageInfoR, error) {
+	rsp, err := c.GetFileImageInfo(ctx, id, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetFileImageInfoR(rsp)
+}
+
+// CompletePresignedUploadWithResponse request returning *CompletePresignedUploadR
+func (c *ClientWithResponses) CompletePresignedUploadWithResponse(ctx context.Context, id string, params *CompletePresignedUploadParams, reqEditors ...RequestEditorFn) (*CompletePresignedUploadR, error) {
+	rsp, err := c.CompletePresignedUpload(ctx, id, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCompletePresignedUploadR(rsp)
+}
+
 // GetFilePresignedURLWithResponse request returning *GetFilePresignedURLR
 func (c *ClientWithResponses) GetFilePresignedURLWithResponse(ctx context.Context, id string, reqEditors ...RequestEditorFn) (*GetFilePresignedURLR, error) {
 	rsp, err := c.GetFilePresignedURL(ctx, id, reqEditors...)
@@ -2035,8 +3486,8 @@ func (c *ClientWithResponses) GetOpenAPISpecWithResponse(ctx context.Context, re
 }
 
 // DeleteBrokenMetadataWithResponse request returning *DeleteBrokenMetadataR
-func (c *ClientWithResponses) DeleteBrokenMetadataWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*DeleteBrokenMetadataR, error) {
-	rsp, err := c.DeleteBrokenMetadata(ctx, reqEditors...)
+func (c *ClientWithResponses) DeleteBrokenMetadataWithResponse(ctx context.Context, params *DeleteBrokenMetadataParams, reqEditors ...RequestEditorFn) (*DeleteBrokenMetadataR, error) {
+	rsp, err := c.DeleteBrokenMetadata(ctx, params, reqEditors...)
 	if err != nil {
 		return nil, err
 	}
@@ -2044,8 +3495,8 @@ func (c *ClientWithResponses) DeleteBrokenMetadataWithResponse(ctx context.Conte
 }
 
 // DeleteOrphanedFilesWithResponse request returning *DeleteOrphanedFilesR
-func (c *ClientWithResponses) DeleteOrphanedFilesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*DeleteOrphanedFilesR, error) {
-	rsp, err := c.DeleteOrphanedFiles(ctx, reqEditors...)
+func (c *ClientWithResponses) DeleteOrphanedFilesWithResponse(ctx context.Context, params *DeleteOrphanedFilesParams, reqEditors ...RequestEditorFn) (*DeleteOrphanedFilesR, error) {
+	rsp, err := c.DeleteOrphanedFiles(ctx, params, reqEditors...)
 	if err != nil {
 		return nil, err
 	}
@@ -2079,6 +3530,15 @@ func (c *ClientWithResponses) ListOrphanedFilesWithResponse(ctx context.Context,
 	return ParseListOrphanedFilesR(rsp)
 }
 
+// RescanFilesWithResponse request returning *RescanFilesR
+func (c *ClientWithResponses) RescanFilesWithResponse(ctx context.Context, params *RescanFilesParams, reqEditors ...RequestEditorFn) (*RescanFilesR, error) {
+	rsp, err := c.RescanFiles(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRescanFilesR(rsp)
+}
+
 // GetVersionWithResponse request returning *GetVersionR
 func (c *ClientWithResponses) GetVersionWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetVersionR, error) {
 	rsp, err := c.GetVersion(ctx, reqEditors...)
@@ -2124,6 +3584,39 @@ func ParseUploadFilesR(rsp *http.Response) (*UploadFilesR, error) {
 	return response, nil
 }
 
+// ParseCreatePresignedUploadR parses an HTTP response from a CreatePresignedUploadWithResponse call
+func ParseCreatePresignedUploadR(rsp *http.Response) (*CreatePresignedUploadR, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CreatePresignedUploadR{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest PresignedUploadResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
 // ParseDeleteFileR parses an HTTP response from a DeleteFileWithResponse call
 func ParseDeleteFileR(rsp *http.Response) (*DeleteFileR, error) {
 	bodyBytes, err := io.ReadAll(rsp.Body)
@@ -2215,6 +3708,72 @@ func ParseReplaceFileR(rsp *http.Response) (*ReplaceFileR, error) {
 	return response, nil
 }
 
+// ParseGetFileImageInfoR parses an HTTP response from a GetFileImageInfoWithResponse call
+func ParseGetFileImageInfoR(rsp *http.Response) (*GetFileImageInfoR, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetFileImageInfoR{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ImageInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseCompletePresignedUploadR parses an HTTP response from a CompletePresignedUploadWithResponse call
+func ParseCompletePresignedUploadR(rsp *http.Response) (*CompletePresignedUploadR, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CompletePresignedUploadR{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest FileMetadata
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
 // ParseGetFilePresignedURLR parses an HTTP response from a GetFilePresignedURLWithResponse call
 func ParseGetFilePresignedURLR(rsp *http.Response) (*GetFilePresignedURLR, error) {
 	bodyBytes, err := io.ReadAll(rsp.Body)
@@ -2313,7 +3872,9 @@ func ParseDeleteBrokenMetadataR(rsp *http.Response) (*DeleteBrokenMetadataR, err
 	switch {
 	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
 		var dest struct {
-			Metadata *[]FileSummary `json:"metadata,omitempty"`
+			// Metadata Metadata deleted, or that would be deleted on dry runs
+			Metadata *[]FileSummary            `json:"metadata,omitempty"`
+			Results  *[]BrokenMetadataDeletion `json:"results,omitempty"`
 		}
 		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
 			return nil, err
@@ -2327,6 +3888,9 @@ func ParseDeleteBrokenMetadataR(rsp *http.Response) (*DeleteBrokenMetadataR, err
 		}
 		response.JSONDefault = &dest
 
+	case rsp.StatusCode == 200:
+		// Content-type (application/x-ndjson) unsupported
+
 	}
 
 	return response, nil
@@ -2348,7 +3912,9 @@ func ParseDeleteOrphanedFilesR(rsp *http.Response) (*DeleteOrphanedFilesR, error
 	switch {
 	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
 		var dest struct {
-			Files *[]string `json:"files,omitempty"`
+			// Files Files deleted, or that would be deleted on dry runs
+			Files   *[]string               `json:"files,omitempty"`
+			Results *[]OrphanedFileDeletion `json:"results,omitempty"`
 		}
 		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
 			return nil, err
@@ -2362,6 +3928,9 @@ func ParseDeleteOrphanedFilesR(rsp *http.Response) (*DeleteOrphanedFilesR, error
 		}
 		response.JSONDefault = &dest
 
+	case rsp.StatusCode == 200:
+		// Content-type (application/x-ndjson) unsupported
+
 	}
 
 	return response, nil
@@ -2397,6 +3966,9 @@ func ParseListBrokenMetadataR(rsp *http.Response) (*ListBrokenMetadataR, error)
 		}
 		response.JSONDefault = &dest
 
+	case rsp.StatusCode == 200:
+		// Content-type (application/x-ndjson) unsupported
+
 	}
 
 	return response, nil
@@ -2432,6 +4004,9 @@ func ParseListFilesNotUploadedR(rsp *http.Response) (*ListFilesNotUploadedR, err
 		}
 		response.JSONDefault = &dest
 
+	case rsp.StatusCode == 200:
+		// Content-type (application/x-ndjson) unsupported
+
 	}
 
 	return response, nil
@@ -2467,6 +4042,49 @@ func ParseListOrphanedFilesR(rsp *http.Response) (*ListOrphanedFilesR, error) {
 		}
 		response.JSONDefault = &dest
 
+	case rsp.StatusCode == 200:
+		// Content-type (application/x-ndjson) unsupported
+
+	}
+
+	return response, nil
+}
+
+// ParseRescanFilesR parses an HTTP response from a RescanFilesWithResponse call
+func ParseRescanFilesR(rsp *http.Response) (*RescanFilesR, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &RescanFilesR{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest struct {
+			// Infected Files where a virus was found
+			Infected *[]FileSummary `json:"infected,omitempty"`
+			Results  *[]FileRescan  `json:"results,omitempty"`
+		}
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	case rsp.StatusCode == 200:
+		// Content-type (application/x-ndjson) unsupported
+
 	}
 
 	return response, nil
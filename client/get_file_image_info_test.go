@@ -0,0 +1,91 @@
+package client_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/uuid"
+	"github.com/nhost/hasura-storage/client"
+)
+
+func TestGetFileImageInfo(t *testing.T) {
+	t.Parallel()
+
+	cl, err := client.NewClientWithResponses(testBaseURL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	id1 := uuid.NewString()
+	id2 := uuid.NewString()
+
+	uploadInitialFile(t, cl, id1, id2)
+
+	cases := []struct {
+		name               string
+		id                 string
+		expectedStatusCode int
+		expected           *client.ImageInfo
+	}{
+		{
+			name:               "image",
+			id:                 id2,
+			expectedStatusCode: http.StatusOK,
+			expected: &client.ImageInfo{
+				Width:       678,
+				Height:      258,
+				Orientation: 1,
+				ColorSpace:  "srgb",
+			},
+		},
+		{
+			name:               "not an image",
+			id:                 id1,
+			expectedStatusCode: http.StatusBadRequest,
+			expected:           nil,
+		},
+		{
+			name:               "not found",
+			id:                 uuid.NewString(),
+			expectedStatusCode: http.StatusNotFound,
+			expected:           nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, err := cl.GetFileImageInfoWithResponse(
+				t.Context(),
+				tc.id,
+				WithAccessToken(accessTokenValidUser),
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if resp.StatusCode() != tc.expectedStatusCode {
+				t.Errorf(
+					"expected status code %d, got %d", tc.expectedStatusCode, resp.StatusCode(),
+				)
+			}
+
+			if resp.JSON200 != nil && (resp.JSON200.Blurhash == "" ||
+				resp.JSON200.DominantColor == "") {
+				t.Errorf("expected a placeholder, got %+v", resp.JSON200)
+			}
+
+			// the placeholder depends on the version of libvips
+			if diff := cmp.Diff(
+				resp.JSON200,
+				tc.expected,
+				cmpopts.IgnoreFields(client.ImageInfo{}, "Blurhash", "DominantColor"),
+			); diff != "" {
+				t.Errorf("unexpected response: %s", diff)
+			}
+		})
+	}
+}
@@ -36,16 +36,7 @@ func createMultiForm(
 	file *File,
 	multiple bool,
 ) error {
-	formWriter, err := writer.CreateFormFile(fieldName, file.name)
-	if err != nil {
-		return fmt.Errorf("problem create part: %w", err)
-	}
-
-	_, err = io.Copy(formWriter, file.r)
-	if err != nil {
-		return fmt.Errorf("problem copying file into the form: %w", err)
-	}
-
+	// metadata goes first so the server can stream the file as it reads it
 	if file.md != nil { //nolint:nestif
 		h := make(textproto.MIMEHeader)
 		if multiple {
@@ -56,7 +47,7 @@ func createMultiForm(
 
 		h.Set("Content-Type", "application/json")
 
-		formWriter, err = writer.CreatePart(h)
+		formWriter, err := writer.CreatePart(h)
 		if err != nil {
 			return fmt.Errorf("problem creating part for metadata: %w", err)
 		}
@@ -72,6 +63,16 @@ func createMultiForm(
 		}
 	}
 
+	formWriter, err := writer.CreateFormFile(fieldName, file.name)
+	if err != nil {
+		return fmt.Errorf("problem create part: %w", err)
+	}
+
+	_, err = io.Copy(formWriter, file.r)
+	if err != nil {
+		return fmt.Errorf("problem copying file into the form: %w", err)
+	}
+
 	return nil
 }
 
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"io"
+	"time"
 
 	"github.com/nhost/hasura-storage/clamd"
 	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/icap"
+	"github.com/nhost/hasura-storage/metrics"
 )
 
 type DummyAntivirus struct{}
@@ -20,11 +23,16 @@ type ClamavWrapper struct {
 }
 
 func (c *ClamavWrapper) ScanReader(ctx context.Context, r io.ReaderAt) *controller.APIError {
+	start := time.Now()
 	err := c.clamav.InStream(ctx, r)
 
 	virusFoundErr := &clamd.VirusFoundError{} //nolint:exhaustruct
+	sizeLimitErr := &clamd.SizeLimitExceededError{}
+
 	switch {
 	case errors.As(err, &virusFoundErr):
+		metrics.ObserveAntivirusScan(metrics.VerdictInfected, time.Since(start))
+
 		err := controller.ForbiddenError(
 			err,
 			err.Error(),
@@ -32,22 +40,91 @@ func (c *ClamavWrapper) ScanReader(ctx context.Context, r io.ReaderAt) *controll
 		err.SetData("virus", virusFoundErr.Name)
 
 		return err
+	case errors.As(err, &sizeLimitErr):
+		metrics.ObserveAntivirusScan(metrics.VerdictError, time.Since(start))
+		return controller.BadDataError(err, "file too big to be scanned for viruses")
 	case err != nil:
+		metrics.ObserveAntivirusScan(metrics.VerdictError, time.Since(start))
 		return controller.InternalServerError(err)
 	}
 
+	metrics.ObserveAntivirusScan(metrics.VerdictClean, time.Since(start))
+
 	return nil
 }
 
-func getAv(addr string) (controller.Antivirus, error) { //nolint:ireturn
-	if addr == "" {
-		return &DummyAntivirus{}, nil
+// ReloadSignatures tells clamd to reload its virus signatures, it replies before they
+// are loaded.
+func (c *ClamavWrapper) ReloadSignatures(ctx context.Context) *controller.APIError {
+	if err := c.clamav.Reload(ctx); err != nil {
+		return controller.InternalServerError(err)
 	}
 
-	c, err := clamd.NewClient(addr)
-	if err != nil {
-		return nil, controller.InternalServerError(err)
+	return nil
+}
+
+func (c *ClamavWrapper) Ping(ctx context.Context) error {
+	return c.clamav.Ping(ctx) //nolint:wrapcheck
+}
+
+type IcapWrapper struct {
+	icap *icap.Client
+}
+
+func (c *IcapWrapper) ScanReader(ctx context.Context, r io.ReaderAt) *controller.APIError {
+	start := time.Now()
+	err := c.icap.Scan(ctx, r)
+
+	virusFoundErr := &icap.VirusFoundError{} //nolint:exhaustruct
+
+	switch {
+	case errors.As(err, &virusFoundErr):
+		metrics.ObserveAntivirusScan(metrics.VerdictInfected, time.Since(start))
+
+		err := controller.ForbiddenError(
+			err,
+			err.Error(),
+		)
+		err.SetData("virus", virusFoundErr.Name)
+
+		return err
+	case err != nil:
+		metrics.ObserveAntivirusScan(metrics.VerdictError, time.Since(start))
+		return controller.InternalServerError(err)
+	}
+
+	metrics.ObserveAntivirusScan(metrics.VerdictClean, time.Since(start))
+
+	return nil
+}
+
+func (c *IcapWrapper) Ping(ctx context.Context) error {
+	return c.icap.Ping(ctx) //nolint:wrapcheck
+}
+
+func getAv( //nolint:ireturn
+	clamavAddr string, clamavConfig clamd.Config, icapAddr string, icapConfig icap.Config,
+) (controller.Antivirus, error) {
+	switch {
+	case clamavAddr != "" && icapAddr != "":
+		return nil, errors.New( //nolint:err113
+			"only one of " + clamavServerFlag + " and " + icapServerFlag + " can be set",
+		)
+	case icapAddr != "":
+		c, err := icap.NewClient(icapAddr, icapConfig)
+		if err != nil {
+			return nil, controller.InternalServerError(err)
+		}
+
+		return &IcapWrapper{icap: c}, nil
+	case clamavAddr != "":
+		c, err := clamd.NewClient(clamavAddr, clamavConfig)
+		if err != nil {
+			return nil, controller.InternalServerError(err)
+		}
+
+		return &ClamavWrapper{clamav: c}, nil
 	}
 
-	return &ClamavWrapper{clamav: c}, nil
+	return &DummyAntivirus{}, nil
 }
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -9,7 +11,7 @@ import (
 func addBoolFlag(
 	flags *pflag.FlagSet,
 	name string,
-	defaultValue bool, //nolint:unparam
+	defaultValue bool,
 	help string,
 ) {
 	flags.Bool(name, defaultValue, help)
@@ -34,3 +36,24 @@ func addStringArrayFlag(flags *pflag.FlagSet, name string, defaultValue []string
 		cobra.CheckErr(err)
 	}
 }
+
+func addIntFlag(flags *pflag.FlagSet, name string, defaultValue int, help string) {
+	flags.Int(name, defaultValue, help)
+
+	if err := viper.BindPFlag(name, flags.Lookup(name)); err != nil {
+		cobra.CheckErr(err)
+	}
+}
+
+func addDurationFlag(
+	flags *pflag.FlagSet,
+	name string,
+	defaultValue time.Duration,
+	help string,
+) {
+	flags.Duration(name, defaultValue, help)
+
+	if err := viper.BindPFlag(name, flags.Lookup(name)); err != nil {
+		cobra.CheckErr(err)
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/health"
+	"github.com/nhost/hasura-storage/image"
+	"github.com/nhost/hasura-storage/metadata"
+	"github.com/spf13/viper"
+)
+
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// getReadiness checks the dependencies the service was configured with.
+func getReadiness(
+	metadataStorage controller.MetadataStorage,
+	contentStorage controller.ContentStorage,
+	av controller.Antivirus,
+	imageTransformer *image.Transformer,
+) *health.Readiness {
+	checks := map[string]health.Check{
+		"libvips": imageTransformer.Ping,
+	}
+
+	switch md := metadataStorage.(type) {
+	case *metadata.Hasura:
+		headers := http.Header{"x-hasura-admin-secret": []string{viper.GetString(hasuraAdminSecretFlag)}}
+		checks["hasura"] = func(ctx context.Context) error {
+			return md.Ping(ctx, headers) //nolint:wrapcheck
+		}
+	case pinger:
+		checks["postgres"] = md.Ping
+	}
+
+	if st, ok := contentStorage.(pinger); ok {
+		checks[viper.GetString(contentStorageFlag)] = st.Ping
+	}
+
+	switch av := av.(type) {
+	case *ClamavWrapper:
+		checks["clamd"] = av.Ping
+	case *IcapWrapper:
+		checks["icap"] = av.Ping
+	}
+
+	return health.NewReadiness(
+		checks, viper.GetDuration(readyzTimeoutFlag), viper.GetDuration(readyzCacheTTLFlag),
+	)
+}
@@ -0,0 +1,331 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nhost/hasura-storage/client"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	rescanURLFlag              = "rescan-url"
+	rescanBucketIDFlag         = "rescan-bucket-id"
+	rescanUploadedAfterFlag    = "rescan-uploaded-after"
+	rescanUploadedBeforeFlag   = "rescan-uploaded-before"
+	rescanConcurrencyFlag      = "rescan-concurrency"
+	rescanProgressFileFlag     = "rescan-progress-file"
+	rescanReloadSignaturesFlag = "rescan-reload-signatures"
+	rescanDryRunFlag           = "rescan-dry-run"
+)
+
+type rescanSummary struct {
+	scanned     int
+	infected    int
+	quarantined int
+	failed      int
+}
+
+// readRescanProgress returns the id of the last file rescanned, empty if there is no
+// rescan to resume.
+func readRescanProgress(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("problem reading rescan progress: %w", err)
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
+
+// writeRescanProgress records the id of the last file rescanned. The file is replaced
+// atomically so an interrupted rescan never leaves it half written.
+func writeRescanProgress(path, fileID string) error {
+	if path == "" {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("problem writing rescan progress: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(fileID + "\n"); err != nil {
+		tmp.Close()
+		return fmt.Errorf("problem writing rescan progress: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("problem writing rescan progress: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("problem writing rescan progress: %w", err)
+	}
+
+	return nil
+}
+
+func parseRescanTime(flag string) (*time.Time, error) {
+	v := viper.GetString(flag)
+	if v == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("problem parsing %s: %w", flag, err)
+	}
+
+	return &t, nil
+}
+
+func getRescanParams(after string) (*client.RescanFilesParams, error) {
+	uploadedAfter, err := parseRescanTime(rescanUploadedAfterFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadedBefore, err := parseRescanTime(rescanUploadedBeforeFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &client.RescanFilesParams{
+		BucketId:         nil,
+		UploadedAfter:    uploadedAfter,
+		UploadedBefore:   uploadedBefore,
+		After:            nil,
+		Concurrency:      ptr(viper.GetInt(rescanConcurrencyFlag)),
+		ReloadSignatures: ptr(viper.GetBool(rescanReloadSignaturesFlag)),
+		DryRun:           ptr(viper.GetBool(rescanDryRunFlag)),
+	}
+
+	if bucketID := viper.GetString(rescanBucketIDFlag); bucketID != "" {
+		params.BucketId = &bucketID
+	}
+
+	if after != "" {
+		params.After = &after
+	}
+
+	return params, nil
+}
+
+func responseError(body io.Reader) error {
+	var errResp client.ErrorResponse
+	if err := json.NewDecoder(body).Decode(&errResp); err != nil || errResp.Error == nil {
+		return errors.New("unexpected response") //nolint:err113
+	}
+
+	return errors.New(errResp.Error.Message) //nolint:err113
+}
+
+// readRescanResults reads the results streamed by the service, recording the progress
+// after each one. The service reports a failure that happens after the response started
+// as an error in the last line.
+func readRescanResults(
+	body io.Reader, progressFile string, logger logrus.FieldLogger,
+) (rescanSummary, error) {
+	summary := rescanSummary{} //nolint:exhaustruct
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(line, &fields); err != nil {
+			return summary, fmt.Errorf("problem parsing rescan result: %w", err)
+		}
+
+		if _, ok := fields["file"]; !ok {
+			return summary, responseError(strings.NewReader(string(line)))
+		}
+
+		var result client.FileRescan
+		if err := json.Unmarshal(line, &result); err != nil {
+			return summary, fmt.Errorf("problem parsing rescan result: %w", err)
+		}
+
+		summary.scanned++
+
+		log := logger.WithFields(logrus.Fields{
+			"file":   result.File.Id,
+			"bucket": result.File.BucketId,
+		})
+
+		switch {
+		case result.Error != nil:
+			summary.failed++
+
+			log.Errorf("problem rescanning file: %s", *result.Error)
+		case result.Virus != nil:
+			summary.infected++
+			if result.Quarantined {
+				summary.quarantined++
+			}
+
+			log.WithField("quarantined", result.Quarantined).Warnf("virus %s found", *result.Virus)
+		}
+
+		if err := writeRescanProgress(progressFile, result.File.Id); err != nil {
+			return summary, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return summary, fmt.Errorf("problem reading rescan results: %w", err)
+	}
+
+	return summary, nil
+}
+
+func rescan(ctx context.Context, logger logrus.FieldLogger) (rescanSummary, error) {
+	progressFile := viper.GetString(rescanProgressFileFlag)
+
+	after, err := readRescanProgress(progressFile)
+	if err != nil {
+		return rescanSummary{}, err
+	}
+
+	if after != "" {
+		logger.Infof("resuming rescan after file %s", after)
+	}
+
+	params, err := getRescanParams(after)
+	if err != nil {
+		return rescanSummary{}, err
+	}
+
+	cl, err := client.NewClient(viper.GetString(rescanURLFlag))
+	if err != nil {
+		return rescanSummary{}, fmt.Errorf("problem creating client: %w", err)
+	}
+
+	resp, err := cl.RescanFiles(
+		ctx,
+		params,
+		func(_ context.Context, req *http.Request) error {
+			req.Header.Set("Accept", "application/x-ndjson")
+			req.Header.Set("X-Hasura-Admin-Secret", viper.GetString(hasuraAdminSecretFlag))
+
+			return nil
+		},
+	)
+	if err != nil {
+		return rescanSummary{}, fmt.Errorf("problem requesting rescan: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rescanSummary{}, fmt.Errorf(
+			"problem requesting rescan: %s: %w", resp.Status, responseError(resp.Body),
+		)
+	}
+
+	return readRescanResults(resp.Body, progressFile, logger)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func init() {
+	rootCmd.AddCommand(rescanCmd)
+
+	addStringFlag(
+		rescanCmd.Flags(),
+		rescanURLFlag,
+		"http://localhost:8000/v1",
+		"URL of the running service, including the API root prefix",
+	)
+	addStringFlag(rescanCmd.Flags(), rescanBucketIDFlag, "", "Only rescan files in this bucket")
+	addStringFlag(
+		rescanCmd.Flags(),
+		rescanUploadedAfterFlag,
+		"",
+		"Only rescan files uploaded at or after this date, in RFC3339 format",
+	)
+	addStringFlag(
+		rescanCmd.Flags(),
+		rescanUploadedBeforeFlag,
+		"",
+		"Only rescan files uploaded before this date, in RFC3339 format",
+	)
+	addIntFlag(
+		rescanCmd.Flags(),
+		rescanConcurrencyFlag,
+		4, //nolint:mnd
+		"Number of files scanned at the same time",
+	)
+	addStringFlag(
+		rescanCmd.Flags(),
+		rescanProgressFileFlag,
+		"",
+		"File where the last file rescanned is recorded so an interrupted rescan is resumed. "+
+			"Delete it to start over",
+	)
+	addBoolFlag(
+		rescanCmd.Flags(),
+		rescanReloadSignaturesFlag,
+		false,
+		"Tell the antivirus to reload its virus signatures before rescanning",
+	)
+	addBoolFlag(
+		rescanCmd.Flags(),
+		rescanDryRunFlag,
+		false,
+		"Report the viruses found without flagging the files as infected or quarantining them",
+	)
+}
+
+var rescanCmd = &cobra.Command{ //nolint:exhaustruct
+	Use:   "rescan",
+	Short: "Scans the files already uploaded for viruses again",
+	Long: `Scans the files already uploaded for viruses again, i.e. after the antivirus got new
+signatures. Files are scanned by the running service, which flags the infected ones and
+moves them to quarantine. The admin secret is read from ` + hasuraAdminSecretFlag + `.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := getLogger()
+		if viper.GetBool(debugFlag) {
+			logger.SetLevel(logrus.DebugLevel)
+		}
+
+		start := time.Now()
+		summary, err := rescan(cmd.Context(), logger)
+
+		log := logger.WithFields(logrus.Fields{
+			"scanned":     summary.scanned,
+			"infected":    summary.infected,
+			"quarantined": summary.quarantined,
+			"failed":      summary.failed,
+			"duration":    time.Since(start).String(),
+		})
+
+		if err != nil {
+			log.WithError(err).Error("rescan interrupted")
+			os.Exit(1)
+		}
+
+		log.Info("rescan finished")
+	},
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/scanner"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// getScanQueue returns the queue of files to scan for viruses in the background and the
+// function to start scanning them, which returns a function that waits for the scans in
+// progress once ctx is done. The queue is nil if files are scanned while uploaded.
+func getScanQueue( //nolint:ireturn
+	ctx context.Context,
+	logger *logrus.Logger,
+) (controller.ScanQueue, func(scanner.Files) func(), error) {
+	workers := viper.GetInt(antivirusAsyncWorkersFlag)
+	if workers <= 0 {
+		return nil, func(scanner.Files) func() { return func() {} }, nil
+	}
+
+	if viper.GetString(clamavServerFlag) == "" && viper.GetString(icapServerFlag) == "" {
+		return nil, nil, errors.New( //nolint:err113
+			antivirusAsyncWorkersFlag + " needs " + clamavServerFlag + " or " + icapServerFlag,
+		)
+	}
+
+	source := viper.GetString(antivirusPostgresSourceFlag)
+	if source == "" {
+		source = viper.GetString(postgresMigrationsSourceFlag)
+	}
+
+	if source == "" {
+		return nil, nil, errors.New( //nolint:err113
+			"asynchronous antivirus scans need " + antivirusPostgresSourceFlag + " or " +
+				postgresMigrationsSourceFlag,
+		)
+	}
+
+	store, err := scanner.NewPostgres(ctx, source)
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem creating scan queue: %w", err)
+	}
+
+	logger.Infof("scanning files asynchronously with %d workers", workers)
+
+	s := scanner.New(store, workers, logger)
+
+	return s, func(files scanner.Files) func() {
+		wait := s.Start(ctx, files)
+
+		return func() {
+			wait()
+			store.Close()
+		}
+	}, nil
+}
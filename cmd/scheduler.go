@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/scheduler"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func getSchedulerJobs(ctrl *controller.Controller) []scheduler.Job {
+	minAge := viper.GetDuration(schedulerMinAgeFlag)
+
+	candidates := []scheduler.Job{
+		{
+			Name:     "delete-orphans",
+			Interval: viper.GetDuration(schedulerDeleteOrphansIntervalFlag),
+			Run: func(ctx context.Context) (int, []string, error) {
+				return ctrl.DeleteOrphansJob(ctx, minAge)
+			},
+		},
+		{
+			Name:     "delete-broken-metadata",
+			Interval: viper.GetDuration(schedulerDeleteBrokenMetadataIntervalFlag),
+			Run: func(ctx context.Context) (int, []string, error) {
+				return ctrl.DeleteBrokenMetadataJob(ctx, minAge)
+			},
+		},
+		{
+			Name:     "list-not-uploaded",
+			Interval: viper.GetDuration(schedulerListNotUploadedIntervalFlag),
+			Run:      ctrl.ListNotUploadedJob,
+		},
+	}
+
+	jobs := make([]scheduler.Job, 0, len(candidates))
+	for _, job := range candidates {
+		if job.Interval > 0 {
+			jobs = append(jobs, job)
+		}
+	}
+
+	return jobs
+}
+
+// startScheduler runs the maintenance jobs with an interval configured in the
+// background, the returned function waits for the jobs in progress once ctx is done.
+func startScheduler(
+	ctx context.Context,
+	ctrl *controller.Controller,
+	logger *logrus.Logger,
+) (func(), error) {
+	jobs := getSchedulerJobs(ctrl)
+	if len(jobs) == 0 {
+		return func() {}, nil
+	}
+
+	source := viper.GetString(schedulerPostgresSourceFlag)
+	if source == "" {
+		source = viper.GetString(postgresMigrationsSourceFlag)
+	}
+
+	if source == "" {
+		return nil, errors.New( //nolint:err113
+			"scheduled jobs need " + schedulerPostgresSourceFlag + " or " +
+				postgresMigrationsSourceFlag,
+		)
+	}
+
+	store, err := scheduler.NewPostgres(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("problem creating scheduler store: %w", err)
+	}
+
+	wait := scheduler.New(store, jobs, logger).Start(ctx)
+
+	return func() {
+		wait()
+		store.Close()
+	}, nil
+}
@@ -16,13 +16,19 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/clamd"
 	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/health"
+	"github.com/nhost/hasura-storage/icap"
 	"github.com/nhost/hasura-storage/image"
+	"github.com/nhost/hasura-storage/imagecache"
 	"github.com/nhost/hasura-storage/metadata"
+	"github.com/nhost/hasura-storage/metrics"
 	"github.com/nhost/hasura-storage/middleware"
 	"github.com/nhost/hasura-storage/middleware/cdn/fastly"
 	"github.com/nhost/hasura-storage/migrations"
 	"github.com/nhost/hasura-storage/storage"
+	"github.com/nhost/hasura-storage/telemetry"
 	ginmiddleware "github.com/oapi-codegen/gin-middleware"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -43,14 +49,56 @@ const (
 	s3BucketFlag                 = "s3-bucket"
 	s3RootFolderFlag             = "s3-root-folder"
 	s3DisableHTTPS               = "s3-disable-http"
+	s3MultipartPartSizeFlag      = "s3-multipart-part-size"
+	s3MultipartConcurrencyFlag   = "s3-multipart-concurrency"
+	contentStorageFlag           = "content-storage"
+	filesystemRootFolderFlag     = "filesystem-root-folder"
+	filesystemSigningKeyFlag     = "filesystem-signing-key" //nolint: gosec
 	postgresMigrationsFlag       = "postgres-migrations"
 	postgresMigrationsSourceFlag = "postgres-migrations-source"
+	metadataStorageFlag          = "metadata-storage"
+	postgresMetadataSourceFlag   = "postgres-metadata-source"
+	postgresMetadataPolicyFlag   = "postgres-metadata-policy"
+	hasuraJWTSecretFlag          = "hasura-graphql-jwt-secret" //nolint: gosec
+	hasuraUnauthorizedRoleFlag   = "hasura-graphql-unauthorized-role"
 	fastlyServiceFlag            = "fastly-service"
 	fastlyKeyFlag                = "fastly-key"
 	corsAllowOriginsFlag         = "cors-allow-origins"
 	corsAllowCredentialsFlag     = "cors-allow-credentials" //nolint: gosec
 	clamavServerFlag             = "clamav-server"
+	clamavChunkSizeFlag          = "clamav-chunk-size"
+	clamavTimeoutFlag            = "clamav-timeout"
+	clamavSessionsFlag           = "clamav-sessions"
+	icapServerFlag               = "icap-server"
+	icapMethodFlag               = "icap-method"
+	icapPreviewFlag              = "icap-preview"
+	icapTimeoutFlag              = "icap-timeout"
+	antivirusAsyncWorkersFlag    = "antivirus-async-workers"
+	antivirusPostgresSourceFlag  = "antivirus-postgres-source"
 	hasuraDBNameFlag             = "hasura-db-name"
+
+	schedulerPostgresSourceFlag               = "scheduler-postgres-source"
+	schedulerDeleteOrphansIntervalFlag        = "scheduler-delete-orphans-interval"
+	schedulerDeleteBrokenMetadataIntervalFlag = "scheduler-delete-broken-metadata-interval"
+	schedulerListNotUploadedIntervalFlag      = "scheduler-list-not-uploaded-interval"
+	schedulerMinAgeFlag                       = "scheduler-min-age"
+
+	webhooksConfigFlag         = "webhooks-config"
+	webhooksPostgresSourceFlag = "webhooks-postgres-source"
+	metricsFlag                = "metrics"
+	otlpEndpointFlag           = "otel-exporter-otlp-endpoint"
+	readyzTimeoutFlag          = "readyz-timeout"
+	readyzCacheTTLFlag         = "readyz-cache-ttl"
+	imageCacheFolderFlag       = "image-cache-folder"
+	imageCacheSizeFlag         = "image-cache-size"
+	imageInfoOnUploadFlag      = "image-info-on-upload"
+
+	imageWorkersFlag      = "image-workers"
+	imageQueueTimeoutFlag = "image-queue-timeout"
+	imageTimeoutFlag      = "image-timeout"
+	imageMaxInputSizeFlag = "image-max-input-size"
+	imageMaxPixelsFlag    = "image-max-pixels"
+	imageMaxDimensionFlag = "image-max-dimension"
 )
 
 func getCorsMiddleware(
@@ -59,14 +107,17 @@ func getCorsMiddleware(
 ) gin.HandlerFunc {
 	return cors.New(cors.Config{ //nolint:exhaustruct
 		AllowOrigins: corsAllowOrigins,
-		AllowMethods: []string{"GET", "PUT", "POST", "HEAD", "DELETE"},
+		AllowMethods: []string{"GET", "PUT", "POST", "HEAD", "DELETE", "PATCH"},
 		AllowHeaders: []string{
 			"Authorization", "Origin", "if-match", "if-none-match", "if-modified-since", "if-unmodified-since",
 			"x-hasura-admin-secret", "x-nhost-bucket-id", "x-nhost-file-name", "x-nhost-file-id",
-			"x-hasura-role",
+			"x-hasura-role", "content-type", "tus-resumable", "upload-length", "upload-metadata",
+			"upload-offset",
 		},
 		ExposeHeaders: []string{
 			"Content-Length", "Content-Type", "Cache-Control", "ETag", "Last-Modified", "X-Error",
+			"Location", "Tus-Resumable", "Tus-Version", "Tus-Extension", "Upload-Offset",
+			"Upload-Length", "Upload-Expires",
 		},
 		AllowCredentials: corsAllowCredentials,
 		MaxAge:           12 * time.Hour, //nolint: mnd
@@ -75,12 +126,10 @@ func getCorsMiddleware(
 
 func getGin( //nolint:funlen
 	bind string,
-	publicURL string,
 	apiRootPrefix string,
 	hasuraAdminSecret string,
-	metadataStorage controller.MetadataStorage,
-	contentStorage controller.ContentStorage,
-	imageTransformer *image.Transformer,
+	ctrl *controller.Controller,
+	readiness *health.Readiness,
 	logger *logrus.Logger,
 	debug bool,
 	corsAllowOrigins []string,
@@ -91,6 +140,12 @@ func getGin( //nolint:funlen
 	router.GET("/healthz", func(c *gin.Context) {
 		c.String(http.StatusOK, "ok")
 	})
+	router.GET("/readyz", readiness.Handler())
+
+	metricsEnabled := viper.GetBool(metricsFlag)
+	if metricsEnabled {
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
 
 	if !debug {
 		gin.SetMode(gin.ReleaseMode)
@@ -107,11 +162,19 @@ func getGin( //nolint:funlen
 		URL: apiRootPrefix,
 	})
 
-	handlers := []gin.HandlerFunc{
+	handlers := []gin.HandlerFunc{telemetry.Middleware()}
+	if metricsEnabled {
+		handlers = append(
+			handlers, metrics.Middleware(metrics.OperationsFromSpec(doc, apiRootPrefix)),
+		)
+	}
+
+	handlers = append(
+		handlers,
 		middleware.Logger(logger),
 		getCorsMiddleware(corsAllowOrigins, corsAllowCredentials),
 		gin.Recovery(),
-	}
+	)
 
 	fastlyService := viper.GetString(fastlyServiceFlag)
 	if fastlyService != "" {
@@ -124,32 +187,10 @@ func getGin( //nolint:funlen
 
 	router.Use(handlers...)
 
-	av, err := getAv(viper.GetString(clamavServerFlag))
-	if err != nil {
-		return nil, fmt.Errorf("problem trying to get av: %w", err)
-	}
-
-	ctrl := controller.New(
-		publicURL,
-		apiRootPrefix,
-		hasuraAdminSecret,
-		metadataStorage,
-		contentStorage,
-		imageTransformer,
-		av,
-		logger,
+	handler := api.NewStrictHandler(
+		ctrl, []api.StrictMiddlewareFunc{telemetry.StrictMiddleware},
 	)
-
-	handler := api.NewStrictHandler(ctrl, []api.StrictMiddlewareFunc{})
-	mw := api.MiddlewareFunc(ginmiddleware.OapiRequestValidatorWithOptions(
-		doc,
-		&ginmiddleware.Options{ //nolint:exhaustruct
-			Options: openapi3filter.Options{ //nolint:exhaustruct
-				AuthenticationFunc: middleware.AuthenticationFunc(hasuraAdminSecret),
-			},
-			SilenceServersWarning: true,
-		},
-	))
+	mw := getRequestValidator(doc, hasuraAdminSecret)
 	api.RegisterHandlersWithOptions(
 		router,
 		handler,
@@ -160,6 +201,8 @@ func getGin( //nolint:funlen
 		},
 	)
 
+	ctrl.SetupTusRouter(router.Group(apiRootPrefix + "/files/tus"))
+
 	server := &http.Server{ //nolint:exhaustruct
 		Addr:              bind,
 		Handler:           router,
@@ -169,14 +212,141 @@ func getGin( //nolint:funlen
 	return server, nil
 }
 
-func getMetadataStorage(endpoint string) *metadata.Hasura {
-	return metadata.NewHasura(endpoint)
+// getRequestValidator validates requests against the OpenAPI spec. Multipart bodies
+// are excluded as the validator reads them fully into memory, which would defeat
+// streaming uploads; the handlers validate the form while they read it.
+func getRequestValidator(doc *openapi3.T, hasuraAdminSecret string) api.MiddlewareFunc {
+	validator := func(excludeRequestBody bool) gin.HandlerFunc {
+		return ginmiddleware.OapiRequestValidatorWithOptions(
+			doc,
+			&ginmiddleware.Options{ //nolint:exhaustruct
+				Options: openapi3filter.Options{ //nolint:exhaustruct
+					AuthenticationFunc: middleware.AuthenticationFunc(hasuraAdminSecret),
+					ExcludeRequestBody: excludeRequestBody,
+				},
+				SilenceServersWarning: true,
+			},
+		)
+	}
+
+	validate := validator(false)
+	validateWithoutBody := validator(true)
+
+	return func(ctx *gin.Context) {
+		if ctx.ContentType() == "multipart/form-data" {
+			validateWithoutBody(ctx)
+			return
+		}
+
+		validate(ctx)
+	}
+}
+
+func getMetadataStorage( //nolint:ireturn
+	ctx context.Context,
+	backend string,
+	logger *logrus.Logger,
+) (controller.MetadataStorage, error) {
+	switch backend {
+	case "hasura":
+		return metadata.NewHasura(viper.GetString(hasuraEndpointFlag) + "/graphql"), nil
+	case "postgres":
+		logger.Info("Using postgres as metadata storage")
+
+		sessions, err := metadata.NewSessionResolver(
+			viper.GetString(hasuraAdminSecretFlag),
+			viper.GetString(hasuraJWTSecretFlag),
+			viper.GetString(hasuraUnauthorizedRoleFlag),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("problem configuring sessions: %w", err)
+		}
+
+		policy := metadata.Policy{}
+		if path := viper.GetString(postgresMetadataPolicyFlag); path != "" {
+			policy, err = metadata.LoadPolicy(path)
+			if err != nil {
+				return nil, fmt.Errorf("problem loading metadata policy: %w", err)
+			}
+		} else {
+			logger.Warn("no metadata policy configured, only admin requests will be allowed")
+		}
+
+		source := viper.GetString(postgresMetadataSourceFlag)
+		if source == "" {
+			source = viper.GetString(postgresMigrationsSourceFlag)
+		}
+
+		st, err := metadata.NewPostgres(ctx, source, sessions, policy)
+		if err != nil {
+			return nil, fmt.Errorf("problem creating postgres metadata storage: %w", err)
+		}
+
+		return st, nil
+	default:
+		return nil, fmt.Errorf("unknown metadata storage: %s", backend) //nolint:err113
+	}
+}
+
+func getImageCache( //nolint:ireturn
+	folder string, maxSize int64, logger *logrus.Logger,
+) (controller.ImageCache, error) {
+	if folder == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	cache, err := imagecache.NewDisk(folder, maxSize, logger)
+	if err != nil {
+		return nil, fmt.Errorf("problem initializing image cache: %w", err)
+	}
+
+	return cache, nil
+}
+
+func getContentStorage( //nolint:ireturn
+	ctx context.Context,
+	backend string,
+	logger *logrus.Logger,
+) (controller.ContentStorage, error) {
+	switch backend {
+	case "s3":
+		return getS3Storage(
+			ctx,
+			viper.GetString(s3EndpointFlag),
+			viper.GetString(s3RegionFlag),
+			viper.GetString(s3AccessKeyFlag),
+			viper.GetString(s3SecretKeyFlag),
+			viper.GetString(s3BucketFlag),
+			viper.GetString(s3RootFolderFlag),
+			viper.GetBool(s3DisableHTTPS),
+			int64(viper.GetInt(s3MultipartPartSizeFlag))<<20, //nolint:mnd
+			viper.GetInt(s3MultipartConcurrencyFlag),
+			logger,
+		), nil
+	case "filesystem":
+		logger.Info("Using local filesystem as content storage")
+
+		st, err := storage.NewFilesystem(
+			viper.GetString(filesystemRootFolderFlag),
+			[]byte(viper.GetString(filesystemSigningKeyFlag)),
+			logger,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("problem creating filesystem storage: %w", err)
+		}
+
+		return st, nil
+	default:
+		return nil, fmt.Errorf("unknown content storage: %s", backend) //nolint:err113
+	}
 }
 
-func getContentStorage(
+func getS3Storage(
 	ctx context.Context,
 	s3Endpoint, region, s3AccessKey, s3SecretKey, bucket, rootFolder string,
 	disableHTTPS bool,
+	partSize int64,
+	concurrency int,
 	logger *logrus.Logger,
 ) *storage.S3 {
 	var (
@@ -210,9 +380,10 @@ func getContentStorage(
 			o.BaseEndpoint = aws.String(s3Endpoint)
 			o.UsePathStyle = true
 			o.EndpointOptions.DisableHTTPS = disableHTTPS
+			o.HTTPClient = metrics.InstrumentS3Client(o.HTTPClient)
 		},
 	)
-	st := storage.NewS3(client, bucket, rootFolder, s3Endpoint, logger)
+	st := storage.NewS3(client, bucket, rootFolder, s3Endpoint, partSize, concurrency, logger)
 
 	return st
 }
@@ -273,6 +444,40 @@ func init() { //nolint:funlen
 		)
 	}
 
+	{
+		addStringFlag(
+			serveCmd.Flags(),
+			metadataStorageFlag,
+			"hasura",
+			"Where to store the files' metadata. One of: hasura, postgres",
+		)
+		addStringFlag(
+			serveCmd.Flags(),
+			postgresMetadataSourceFlag,
+			"",
+			"postgres connection used by the postgres metadata storage, defaults to "+
+				postgresMigrationsSourceFlag,
+		)
+		addStringFlag(
+			serveCmd.Flags(),
+			postgresMetadataPolicyFlag,
+			"",
+			"YAML file with the per-role permissions enforced by the postgres metadata storage",
+		)
+		addStringFlag(
+			serveCmd.Flags(),
+			hasuraJWTSecretFlag,
+			"",
+			"JWT secret, same format as hasura's, used by the postgres metadata storage",
+		)
+		addStringFlag(
+			serveCmd.Flags(),
+			hasuraUnauthorizedRoleFlag,
+			"",
+			"Role for unauthenticated requests when using the postgres metadata storage",
+		)
+	}
+
 	{
 		addStringFlag(serveCmd.Flags(), s3EndpointFlag, "", "S3 Endpoint")
 		addStringFlag(serveCmd.Flags(), s3AccessKeyFlag, "", "S3 Access key")
@@ -285,6 +490,99 @@ func init() { //nolint:funlen
 			"",
 			"All buckets will be created inside this root",
 		)
+		addIntFlag(
+			serveCmd.Flags(),
+			s3MultipartPartSizeFlag,
+			16, //nolint:mnd
+			"Size in MB of each part when streaming uploads to S3, minimum 5",
+		)
+		addIntFlag(
+			serveCmd.Flags(),
+			s3MultipartConcurrencyFlag,
+			4, //nolint:mnd
+			"Number of parts uploaded to S3 concurrently when streaming uploads",
+		)
+	}
+
+	{
+		addStringFlag(
+			serveCmd.Flags(),
+			contentStorageFlag,
+			"s3",
+			"Where to store the files' content. One of: s3, filesystem",
+		)
+		addStringFlag(
+			serveCmd.Flags(),
+			filesystemRootFolderFlag,
+			"./data",
+			"Folder where files are stored when using the filesystem content storage",
+		)
+		addStringFlag(
+			serveCmd.Flags(),
+			filesystemSigningKeyFlag,
+			"",
+			"Key used to sign presigned URLs when using the filesystem content storage",
+		)
+	}
+
+	{
+		addStringFlag(
+			serveCmd.Flags(),
+			imageCacheFolderFlag,
+			"",
+			"Folder where transformed images are cached. Disabled if empty",
+		)
+		addIntFlag(
+			serveCmd.Flags(),
+			imageCacheSizeFlag,
+			1024, //nolint:mnd
+			"Maximum size in MB of the transformed images cache",
+		)
+		addBoolFlag(
+			serveCmd.Flags(),
+			imageInfoOnUploadFlag,
+			false,
+			"Store the dimensions and placeholder of images in their metadata when uploaded",
+		)
+	}
+
+	{
+		addIntFlag(
+			serveCmd.Flags(),
+			imageWorkersFlag,
+			3, //nolint:mnd
+			"Number of images transformed at the same time",
+		)
+		addDurationFlag(
+			serveCmd.Flags(),
+			imageQueueTimeoutFlag,
+			30*time.Second, //nolint:mnd
+			"How long a transformation waits for a free worker before returning 503. 0 to wait forever",
+		)
+		addDurationFlag(
+			serveCmd.Flags(),
+			imageTimeoutFlag,
+			time.Minute,
+			"How long a transformation can take before returning 503. 0 to disable",
+		)
+		addIntFlag(
+			serveCmd.Flags(),
+			imageMaxInputSizeFlag,
+			100, //nolint:mnd
+			"Maximum size in MB of the images to transform. 0 to disable",
+		)
+		addIntFlag(
+			serveCmd.Flags(),
+			imageMaxPixelsFlag,
+			100_000_000, //nolint:mnd
+			"Maximum pixels of the images to transform, counting all their frames. 0 to disable",
+		)
+		addIntFlag(
+			serveCmd.Flags(),
+			imageMaxDimensionFlag,
+			16384, //nolint:mnd
+			"Maximum width or height of the images to transform. 0 to disable",
+		)
 	}
 
 	{
@@ -321,11 +619,140 @@ func init() { //nolint:funlen
 			"CORS allow origins",
 		)
 		addBoolFlag(serveCmd.Flags(), corsAllowCredentialsFlag, false, "CORS allow credentials")
+		addBoolFlag(serveCmd.Flags(), metricsFlag, false, "Expose prometheus metrics in /metrics")
+		addDurationFlag(
+			serveCmd.Flags(),
+			readyzTimeoutFlag,
+			5*time.Second, //nolint:mnd
+			"Timeout of each dependency check done by /readyz",
+		)
+		addDurationFlag(
+			serveCmd.Flags(),
+			readyzCacheTTLFlag,
+			10*time.Second, //nolint:mnd
+			"How long /readyz reuses the result of the dependency checks",
+		)
+		addStringFlag(
+			serveCmd.Flags(),
+			otlpEndpointFlag,
+			"",
+			"Export traces via OTLP/HTTP to this endpoint, i.e. http://otel-collector:4318. "+
+				"The standard OTEL_EXPORTER_OTLP_* environment variables are also supported",
+		)
 		addStringFlag(
 			serveCmd.Flags(),
 			clamavServerFlag,
 			"",
-			"If set, use ClamAV to scan files. Example: tcp://clamavd:3310",
+			"If set, use ClamAV to scan files. Example: tcp://clamavd:3310 or unix:///run/clamd.sock",
+		)
+		addIntFlag(
+			serveCmd.Flags(),
+			clamavChunkSizeFlag,
+			64<<10, //nolint:mnd
+			"Size in bytes of the chunks files are sent to ClamAV in",
+		)
+		addDurationFlag(
+			serveCmd.Flags(),
+			clamavTimeoutFlag,
+			time.Minute,
+			"How long scanning a file with ClamAV can take",
+		)
+		addIntFlag(
+			serveCmd.Flags(),
+			clamavSessionsFlag,
+			4, //nolint:mnd
+			"Number of idle connections to ClamAV kept open to be reused. 0 to disable",
+		)
+		addStringFlag(
+			serveCmd.Flags(),
+			icapServerFlag,
+			"",
+			"If set, use this ICAP service to scan files instead of ClamAV. Example: "+
+				"icap://scanner:1344/avscan",
+		)
+		addStringFlag(
+			serveCmd.Flags(),
+			icapMethodFlag,
+			icap.MethodRespMod,
+			"ICAP method files are sent with. One of: RESPMOD, REQMOD",
+		)
+		addIntFlag(
+			serveCmd.Flags(),
+			icapPreviewFlag,
+			0,
+			"Bytes of each file sent as ICAP preview. 0 uses the size advertised by the "+
+				"service, -1 disables previews",
+		)
+		addDurationFlag(
+			serveCmd.Flags(),
+			icapTimeoutFlag,
+			time.Minute,
+			"How long scanning a file with the ICAP service can take",
+		)
+		addIntFlag(
+			serveCmd.Flags(),
+			antivirusAsyncWorkersFlag,
+			0,
+			"If set, files are scanned for viruses after being uploaded by this number of "+
+				"workers instead of while uploading them. Files can't be downloaded until "+
+				"they are found to be clean and infected ones are moved to quarantine",
+		)
+		addStringFlag(
+			serveCmd.Flags(),
+			antivirusPostgresSourceFlag,
+			"",
+			"postgres connection used to queue the files to scan asynchronously, defaults to "+
+				postgresMigrationsSourceFlag,
+		)
+	}
+
+	{
+		addStringFlag(
+			serveCmd.Flags(),
+			schedulerPostgresSourceFlag,
+			"",
+			"postgres connection used to coordinate scheduled jobs and keep their history, "+
+				"defaults to "+postgresMigrationsSourceFlag,
+		)
+		addDurationFlag(
+			serveCmd.Flags(),
+			schedulerDeleteOrphansIntervalFlag,
+			0,
+			"Delete orphaned files with this interval, i.e. 24h. Disabled if 0",
+		)
+		addDurationFlag(
+			serveCmd.Flags(),
+			schedulerDeleteBrokenMetadataIntervalFlag,
+			0,
+			"Delete broken metadata with this interval, i.e. 24h. Disabled if 0",
+		)
+		addDurationFlag(
+			serveCmd.Flags(),
+			schedulerListNotUploadedIntervalFlag,
+			0,
+			"Record the files not uploaded with this interval, i.e. 24h. Disabled if 0",
+		)
+		addDurationFlag(
+			serveCmd.Flags(),
+			schedulerMinAgeFlag,
+			time.Hour,
+			"Scheduled jobs skip files modified more recently than this",
+		)
+	}
+
+	{
+		addStringFlag(
+			serveCmd.Flags(),
+			webhooksConfigFlag,
+			"",
+			"YAML file with the webhooks notified when files are uploaded, replaced or deleted",
+		)
+		addStringFlag(
+			serveCmd.Flags(),
+			webhooksPostgresSourceFlag,
+			"",
+			"postgres connection used to store the webhooks outbox, defaults to "+
+				postgresMigrationsSourceFlag,
 		)
 	}
 }
@@ -349,36 +776,76 @@ var serveCmd = &cobra.Command{ //nolint:exhaustruct
 			gin.SetMode(gin.ReleaseMode)
 		}
 
-		imageTransformer := image.NewTransformer()
+		shutdownTracing, err := telemetry.Setup(
+			ctx, viper.GetString(otlpEndpointFlag), "hasura-storage", controller.Version(),
+		)
+		cobra.CheckErr(err)
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second) //nolint:mnd
+			defer cancel()
+
+			if err := shutdownTracing(ctx); err != nil {
+				logger.WithError(err).Error("problem flushing traces")
+			}
+		}()
+
+		imageTransformer := image.NewTransformer(image.Config{
+			Workers:      viper.GetInt(imageWorkersFlag),
+			QueueTimeout: viper.GetDuration(imageQueueTimeoutFlag),
+			Timeout:      viper.GetDuration(imageTimeoutFlag),
+			MaxInputSize: uint64(max(viper.GetInt(imageMaxInputSizeFlag), 0)) << 20, //nolint:mnd
+			MaxPixels:    viper.GetInt(imageMaxPixelsFlag),
+			MaxDimension: viper.GetInt(imageMaxDimensionFlag),
+		})
 		defer imageTransformer.Shutdown()
 
 		logger.WithFields(
 			logrus.Fields{
-				debugFlag:              viper.GetBool(debugFlag),
-				bindFlag:               viper.GetString(bindFlag),
-				hasuraEndpointFlag:     viper.GetString(hasuraEndpointFlag),
-				postgresMigrationsFlag: viper.GetBool(postgresMigrationsFlag),
-				hasuraMetadataFlag:     viper.GetBool(hasuraMetadataFlag),
-				s3EndpointFlag:         viper.GetString(s3EndpointFlag),
-				s3RegionFlag:           viper.GetString(s3RegionFlag),
-				s3BucketFlag:           viper.GetString(s3BucketFlag),
-				s3RootFolderFlag:       viper.GetString(s3RootFolderFlag),
-				clamavServerFlag:       viper.GetString(clamavServerFlag),
-				hasuraDBNameFlag:       viper.GetString(hasuraDBNameFlag),
+				debugFlag:                  viper.GetBool(debugFlag),
+				bindFlag:                   viper.GetString(bindFlag),
+				hasuraEndpointFlag:         viper.GetString(hasuraEndpointFlag),
+				metadataStorageFlag:        viper.GetString(metadataStorageFlag),
+				postgresMetadataPolicyFlag: viper.GetString(postgresMetadataPolicyFlag),
+				hasuraUnauthorizedRoleFlag: viper.GetString(hasuraUnauthorizedRoleFlag),
+				postgresMigrationsFlag:     viper.GetBool(postgresMigrationsFlag),
+				hasuraMetadataFlag:         viper.GetBool(hasuraMetadataFlag),
+				s3EndpointFlag:             viper.GetString(s3EndpointFlag),
+				s3RegionFlag:               viper.GetString(s3RegionFlag),
+				s3BucketFlag:               viper.GetString(s3BucketFlag),
+				s3RootFolderFlag:           viper.GetString(s3RootFolderFlag),
+				contentStorageFlag:         viper.GetString(contentStorageFlag),
+				filesystemRootFolderFlag:   viper.GetString(filesystemRootFolderFlag),
+				imageCacheFolderFlag:       viper.GetString(imageCacheFolderFlag),
+				imageCacheSizeFlag:         viper.GetInt(imageCacheSizeFlag),
+				imageInfoOnUploadFlag:      viper.GetBool(imageInfoOnUploadFlag),
+				imageWorkersFlag:           viper.GetInt(imageWorkersFlag),
+				imageQueueTimeoutFlag:      viper.GetDuration(imageQueueTimeoutFlag),
+				imageTimeoutFlag:           viper.GetDuration(imageTimeoutFlag),
+				imageMaxInputSizeFlag:      viper.GetInt(imageMaxInputSizeFlag),
+				imageMaxPixelsFlag:         viper.GetInt(imageMaxPixelsFlag),
+				imageMaxDimensionFlag:      viper.GetInt(imageMaxDimensionFlag),
+				clamavServerFlag:           viper.GetString(clamavServerFlag),
+				clamavChunkSizeFlag:        viper.GetInt(clamavChunkSizeFlag),
+				clamavTimeoutFlag:          viper.GetDuration(clamavTimeoutFlag),
+				clamavSessionsFlag:         viper.GetInt(clamavSessionsFlag),
+				icapServerFlag:             viper.GetString(icapServerFlag),
+				icapMethodFlag:             viper.GetString(icapMethodFlag),
+				icapPreviewFlag:            viper.GetInt(icapPreviewFlag),
+				icapTimeoutFlag:            viper.GetDuration(icapTimeoutFlag),
+				antivirusAsyncWorkersFlag:  viper.GetInt(antivirusAsyncWorkersFlag),
+				hasuraDBNameFlag:           viper.GetString(hasuraDBNameFlag),
+				webhooksConfigFlag:         viper.GetString(webhooksConfigFlag),
+				otlpEndpointFlag:           viper.GetString(otlpEndpointFlag),
 			},
 		).Debug("parameters")
 
-		contentStorage := getContentStorage(
+		contentStorage, err := getContentStorage(
 			ctx,
-			viper.GetString(s3EndpointFlag),
-			viper.GetString(s3RegionFlag),
-			viper.GetString(s3AccessKeyFlag),
-			viper.GetString(s3SecretKeyFlag),
-			viper.GetString(s3BucketFlag),
-			viper.GetString(s3RootFolderFlag),
-			viper.GetBool(s3DisableHTTPS),
+			viper.GetString(contentStorageFlag),
 			logger,
 		)
+		cobra.CheckErr(err)
 
 		applymigrations(
 			viper.GetBool(postgresMigrationsFlag),
@@ -390,17 +857,63 @@ var serveCmd = &cobra.Command{ //nolint:exhaustruct
 			logger,
 		)
 
-		metadataStorage := getMetadataStorage(
-			viper.GetString(hasuraEndpointFlag) + "/graphql",
+		metadataStorage, err := getMetadataStorage(
+			ctx,
+			viper.GetString(metadataStorageFlag),
+			logger,
+		)
+		cobra.CheckErr(err)
+
+		imageCache, err := getImageCache(
+			viper.GetString(imageCacheFolderFlag),
+			int64(viper.GetInt(imageCacheSizeFlag))<<20, //nolint:mnd
+			logger,
 		)
-		server, err := getGin(
-			viper.GetString(bindFlag),
+		cobra.CheckErr(err)
+
+		av, err := getAv(
+			viper.GetString(clamavServerFlag),
+			clamd.Config{ //nolint:exhaustruct
+				ChunkSize:       viper.GetInt(clamavChunkSizeFlag),
+				Timeout:         viper.GetDuration(clamavTimeoutFlag),
+				MaxIdleSessions: viper.GetInt(clamavSessionsFlag),
+			},
+			viper.GetString(icapServerFlag),
+			icap.Config{ //nolint:exhaustruct
+				Method:  viper.GetString(icapMethodFlag),
+				Preview: viper.GetInt(icapPreviewFlag),
+				Timeout: viper.GetDuration(icapTimeoutFlag),
+			},
+		)
+		cobra.CheckErr(err)
+
+		scanQueue, startScanner, err := getScanQueue(ctx, logger)
+		cobra.CheckErr(err)
+
+		events, waitWebhooks, err := startWebhooks(ctx, logger)
+		cobra.CheckErr(err)
+
+		ctrl := controller.New(
 			viper.GetString(publicURLFlag),
 			viper.GetString(apiRootPrefixFlag),
 			viper.GetString(hasuraAdminSecretFlag),
 			metadataStorage,
 			contentStorage,
 			imageTransformer,
+			av,
+			logger,
+			controller.WithImageCache(imageCache),
+			controller.WithImageInfoOnUpload(viper.GetBool(imageInfoOnUploadFlag)),
+			controller.WithScanQueue(scanQueue),
+			controller.WithEvents(events),
+		)
+
+		server, err := getGin(
+			viper.GetString(bindFlag),
+			viper.GetString(apiRootPrefixFlag),
+			viper.GetString(hasuraAdminSecretFlag),
+			ctrl,
+			getReadiness(metadataStorage, contentStorage, av, imageTransformer),
 			logger,
 			viper.GetBool(debugFlag),
 			viper.GetStringSlice(corsAllowOriginsFlag),
@@ -408,6 +921,11 @@ var serveCmd = &cobra.Command{ //nolint:exhaustruct
 		)
 		cobra.CheckErr(err)
 
+		waitScheduler, err := startScheduler(ctx, ctrl, logger)
+		cobra.CheckErr(err)
+
+		waitScanner := startScanner(ctrl)
+
 		go func() {
 			defer cancel()
 			logger.Info("starting server")
@@ -423,5 +941,9 @@ var serveCmd = &cobra.Command{ //nolint:exhaustruct
 		logger.Info("shutting down server")
 		err = server.Shutdown(ctx)
 		cobra.CheckErr(err)
+
+		waitScheduler()
+		waitScanner()
+		waitWebhooks()
 	},
 }
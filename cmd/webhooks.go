@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/webhooks"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// startWebhooks returns the publisher of file events and starts sending them in the
+// background, the returned function waits for the deliveries in progress once ctx is
+// done. The publisher is nil if no webhooks are configured.
+func startWebhooks( //nolint:ireturn
+	ctx context.Context,
+	logger *logrus.Logger,
+) (controller.EventPublisher, func(), error) {
+	path := viper.GetString(webhooksConfigFlag)
+	if path == "" {
+		return nil, func() {}, nil
+	}
+
+	config, err := webhooks.LoadConfig(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem loading webhooks: %w", err)
+	}
+
+	source := viper.GetString(webhooksPostgresSourceFlag)
+	if source == "" {
+		source = viper.GetString(postgresMigrationsSourceFlag)
+	}
+
+	if source == "" {
+		return nil, nil, errors.New( //nolint:err113
+			"webhooks need " + webhooksPostgresSourceFlag + " or " + postgresMigrationsSourceFlag,
+		)
+	}
+
+	store, err := webhooks.NewPostgres(ctx, source)
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem creating webhooks outbox: %w", err)
+	}
+
+	logger.Infof("sending file events to %d webhooks", len(config))
+
+	dispatcher := webhooks.NewDispatcher(store, config, logger)
+	wait := dispatcher.Start(ctx)
+
+	return dispatcher, func() {
+		wait()
+		store.Close()
+	}, nil
+}
@@ -4,19 +4,23 @@ package controller
 import (
 	"context"
 	"io"
+	"iter"
 	"net/http"
 	"time"
 
 	"github.com/nhost/hasura-storage/api"
 	"github.com/nhost/hasura-storage/image"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
 )
 
 type FileSummary struct {
-	ID         string `json:"id"`
-	Name       string `json:"name"`
-	IsUploaded bool   `json:"isUploaded"`
-	BucketID   string `json:"bucketId"`
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	IsUploaded bool      `json:"isUploaded"`
+	BucketID   string    `json:"bucketId"`
+	ScanStatus string    `json:"scanStatus"`
+	CreatedAt  time.Time `json:"createdAt"`
 }
 
 type BucketMetadata struct {
@@ -28,11 +32,35 @@ type BucketMetadata struct {
 	CreatedAt            string
 	UpdatedAt            string
 	CacheControl         string
+	// PresetsOnly rejects image transformations that don't use one of the Presets.
+	PresetsOnly bool
+	Presets     map[string]ImagePreset
+	// StripImageMetadata removes the EXIF, XMP and IPTC metadata of images when they are
+	// uploaded, keeping their ICC profile if KeepICCProfile is set.
+	StripImageMetadata bool
+	KeepICCProfile     bool
+	// AllowedMimeTypes and DeniedMimeTypes are patterns like image/* files need to match
+	// to be uploaded. Files are accepted if they match any of AllowedMimeTypes, or if it
+	// is empty, unless they match any of DeniedMimeTypes.
+	AllowedMimeTypes []string
+	DeniedMimeTypes  []string
+}
+
+// PresignedUpload is a form POST clients can use to upload a file straight to the
+// content storage.
+type PresignedUpload struct {
+	URL    string
+	Fields map[string]string
 }
 
 type MetadataStorage interface {
 	GetBucketByID(ctx context.Context, id string, headers http.Header) (BucketMetadata, *APIError)
 	GetFileByID(ctx context.Context, id string, headers http.Header) (api.FileMetadata, *APIError)
+	// GetFileScanStatus returns the result of scanning the file for viruses. It isn't
+	// part of the metadata returned by GetFileByID as users may not be allowed to read it.
+	GetFileScanStatus(
+		ctx context.Context, id string, headers http.Header,
+	) (api.ScanStatus, *APIError)
 	InitializeFile(
 		ctx context.Context,
 		id, name string, size int64, bucketID, mimeType string,
@@ -50,8 +78,20 @@ type MetadataStorage interface {
 		isUploaded bool,
 		headers http.Header,
 	) *APIError
-	DeleteFileByID(ctx context.Context, fileID string, headers http.Header) *APIError
-	ListFiles(ctx context.Context, headers http.Header) ([]FileSummary, *APIError)
+	// SetScanStatus records the result of scanning the file for viruses.
+	SetScanStatus(
+		ctx context.Context,
+		fileID string,
+		status api.ScanStatus,
+		headers http.Header,
+	) *APIError
+	// DeleteFileByID returns the metadata the file had before it was deleted.
+	DeleteFileByID(
+		ctx context.Context, fileID string, headers http.Header,
+	) (api.FileMetadata, *APIError)
+	// ListFiles yields all the files sorted by id. Files are fetched in pages as the
+	// iteration goes, if there is a problem the error is yielded and the iteration stops.
+	ListFiles(ctx context.Context, headers http.Header) iter.Seq2[FileSummary, *APIError]
 	InsertVirus(
 		ctx context.Context,
 		fileID, filename, virus string,
@@ -66,6 +106,22 @@ type ContentStorage interface {
 		content io.ReadSeeker,
 		filepath, contentType string,
 	) (string, *APIError)
+	// PutFileIfMatch stores content only if the file still has the given etag, or if it
+	// doesn't exist when etag is empty. Otherwise it returns ErrFileModified.
+	PutFileIfMatch(
+		ctx context.Context,
+		content io.ReadSeeker,
+		filepath, contentType, etag string,
+	) (string, *APIError)
+	// PutFileStream stores content as it is read, without buffering it. Once all the
+	// content is stored, and before the file becomes available, validate is called if set;
+	// if it returns an error the upload is discarded and that error returned.
+	PutFileStream(
+		ctx context.Context,
+		content io.Reader,
+		filepath, contentType string,
+		validate func() *APIError,
+	) (string, *APIError)
 	GetFile(ctx context.Context, filepath string, downloadRange *string) (*File, *APIError)
 	CreatePresignedURL(
 		ctx context.Context,
@@ -75,14 +131,50 @@ type ContentStorage interface {
 	GetFileWithPresignedURL(
 		ctx context.Context, filepath, signature string, headers http.Header,
 	) (*File, *APIError)
+	// CreatePresignedUpload returns a presigned request to upload a file of the given
+	// content type, the storage rejects it unless its size is within minSize and maxSize.
+	CreatePresignedUpload(
+		ctx context.Context,
+		filepath, contentType string,
+		minSize, maxSize int,
+		expire time.Duration,
+	) (*PresignedUpload, *APIError)
+	// HeadFile returns the information about a file without its content, Body is empty.
+	HeadFile(ctx context.Context, filepath string) (*File, *APIError)
 	DeleteFile(ctx context.Context, filepath string) *APIError
-	ListFiles(ctx context.Context) ([]string, *APIError)
+	// MoveFile moves a file to another path, replacing whatever was there.
+	MoveFile(ctx context.Context, filepath, newFilepath string) *APIError
+	// ListFiles yields the keys of all the files, keys in the same folder are yielded in
+	// lexicographical order. Files are fetched in pages as the iteration goes, if there
+	// is a problem the error is yielded and the iteration stops.
+	ListFiles(ctx context.Context) iter.Seq2[string, *APIError]
 }
 
 type Antivirus interface {
 	ScanReader(ctx context.Context, r io.ReaderAt) *APIError
 }
 
+// ScanQueue schedules files to be scanned for viruses once they are uploaded. Enqueue
+// flags the file as pending, it can't be downloaded until it is found to be clean.
+type ScanQueue interface {
+	Enqueue(ctx context.Context, fileID string) *APIError
+}
+
+// EventPublisher is notified when files change. Events are delivered asynchronously,
+// Publish needs to store them durably before returning.
+type EventPublisher interface {
+	Publish(ctx context.Context, event FileEvent) *APIError
+}
+
+// ImageCache stores transformed images so they don't need to be transformed again. Each
+// transformation of a file is identified by a key.
+type ImageCache interface {
+	Get(ctx context.Context, fileID, key string) ([]byte, bool)
+	Put(ctx context.Context, fileID, key string, content []byte) *APIError
+	// DeleteFile removes all the transformations of a file.
+	DeleteFile(ctx context.Context, fileID string) *APIError
+}
+
 type Controller struct {
 	publicURL         string
 	apiRootPrefix     string
@@ -90,8 +182,45 @@ type Controller struct {
 	metadataStorage   MetadataStorage
 	contentStorage    ContentStorage
 	imageTransformer  *image.Transformer
+	imageCache        ImageCache
+	imageInfoOnUpload bool
 	av                Antivirus
+	scanQueue         ScanQueue
+	events            EventPublisher
 	logger            *logrus.Logger
+	transformations   singleflight.Group
+}
+
+// Option enables an optional feature of the Controller.
+type Option func(*Controller)
+
+// WithImageCache stores image transformations in cache so they are only computed once.
+func WithImageCache(cache ImageCache) Option {
+	return func(ctrl *Controller) {
+		ctrl.imageCache = cache
+	}
+}
+
+// WithImageInfoOnUpload adds the dimensions and format of images to their metadata
+// when they are uploaded.
+func WithImageInfoOnUpload(enabled bool) Option {
+	return func(ctrl *Controller) {
+		ctrl.imageInfoOnUpload = enabled
+	}
+}
+
+// WithScanQueue scans uploaded files in the background instead of during the upload.
+func WithScanQueue(queue ScanQueue) Option {
+	return func(ctrl *Controller) {
+		ctrl.scanQueue = queue
+	}
+}
+
+// WithEvents publishes an event every time a file is uploaded, replaced or deleted.
+func WithEvents(events EventPublisher) Option {
+	return func(ctrl *Controller) {
+		ctrl.events = events
+	}
 }
 
 func New(
@@ -103,15 +232,27 @@ func New(
 	imageTransformer *image.Transformer,
 	av Antivirus,
 	logger *logrus.Logger,
+	opts ...Option,
 ) *Controller {
-	return &Controller{
+	ctrl := &Controller{
 		publicURL,
 		apiRootPrefix,
 		hasuraAdminSecret,
 		metadataStorage,
 		contentStorage,
 		imageTransformer,
+		nil,
+		false,
 		av,
+		nil,
+		nil,
 		logger,
+		singleflight.Group{},
+	}
+
+	for _, opt := range opts {
+		opt(ctrl)
 	}
+
+	return ctrl
 }
@@ -2,41 +2,145 @@ package controller
 
 import (
 	"context"
+	"iter"
+	"net/http"
 
 	"github.com/nhost/hasura-storage/api"
 	"github.com/nhost/hasura-storage/middleware"
 )
 
-func (ctrl *Controller) deleteBrokenMetadata(
-	ctx context.Context,
-) ([]FileSummary, *APIError) {
-	missing, apiErr := ctrl.listBrokenMetadata(ctx)
+// isRecentMetadata returns if the metadata was updated within the minimum age, metadata
+// that is gone by now is considered recent so it is skipped.
+func (ctrl *Controller) isRecentMetadata(
+	ctx context.Context, id string, opts cleanupOptions,
+) (bool, *APIError) {
+	if opts.minAge == 0 {
+		return false, nil
+	}
+
+	fileMetadata, apiErr := ctrl.metadataStorage.GetFileByID(
+		ctx,
+		id,
+		http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
+	)
+	if apiErr == ErrFileNotFound { //nolint:errorlint
+		return true, nil
+	}
+
 	if apiErr != nil {
-		return nil, apiErr
+		return false, apiErr.ExtendError("problem getting file metadata")
 	}
 
-	for _, m := range missing {
-		if apiErr := ctrl.metadataStorage.DeleteFileByID(ctx, m.ID, nil); apiErr != nil {
-			return nil, apiErr
-		}
+	return opts.tooRecent(fileMetadata.UpdatedAt), nil
+}
+
+func (ctrl *Controller) deleteMetadata(
+	ctx context.Context, file FileSummary, opts cleanupOptions,
+) api.BrokenMetadataDeletion {
+	logger := middleware.LoggerFromContext(ctx)
+
+	result := api.BrokenMetadataDeletion{
+		Metadata: fileSummary(file),
+		Deleted:  false,
+		Error:    nil,
 	}
 
-	return missing, nil
+	if opts.dryRun {
+		return result
+	}
+
+	if _, apiErr := ctrl.metadataStorage.DeleteFileByID(
+		ctx,
+		file.ID,
+		http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
+	); apiErr != nil {
+		logger.WithError(apiErr).Errorf("problem deleting broken metadata %s", file.ID)
+		result.Error = publicMessage(apiErr)
+
+		return result
+	}
+
+	result.Deleted = true
+
+	return result
+}
+
+func (ctrl *Controller) deleteBrokenMetadata(
+	ctx context.Context, opts cleanupOptions,
+) iter.Seq2[api.BrokenMetadataDeletion, *APIError] {
+	return func(yield func(api.BrokenMetadataDeletion, *APIError) bool) {
+		processed := 0
+
+		for m, apiErr := range ctrl.listBrokenMetadata(ctx) {
+			if apiErr != nil {
+				yield(api.BrokenMetadataDeletion{}, apiErr) //nolint:exhaustruct
+				return
+			}
+
+			if opts.bucketID != "" && m.BucketID != opts.bucketID {
+				continue
+			}
+
+			recent, apiErr := ctrl.isRecentMetadata(ctx, m.ID, opts)
+
+			var result api.BrokenMetadataDeletion
+
+			switch {
+			case apiErr != nil:
+				result = api.BrokenMetadataDeletion{
+					Metadata: fileSummary(m),
+					Deleted:  false,
+					Error:    publicMessage(apiErr),
+				}
+			case recent:
+				continue
+			default:
+				result = ctrl.deleteMetadata(ctx, m, opts)
+			}
+
+			processed++
+
+			if !yield(result, nil) || opts.limitReached(processed) {
+				return
+			}
+		}
+	}
 }
 
 func (ctrl *Controller) DeleteBrokenMetadata( //nolint:ireturn
 	ctx context.Context,
-	_ api.DeleteBrokenMetadataRequestObject,
+	request api.DeleteBrokenMetadataRequestObject,
 ) (api.DeleteBrokenMetadataResponseObject, error) {
 	logger := middleware.LoggerFromContext(ctx)
 
-	files, apiErr := ctrl.deleteBrokenMetadata(ctx)
+	opts := newCleanupOptions(
+		request.Params.DryRun,
+		request.Params.MaxDeletions,
+		request.Params.MinAge,
+		request.Params.BucketId,
+	)
+
+	if acceptsNDJSON(ctx) {
+		return ndjsonResponse[api.BrokenMetadataDeletion]{
+			ctrl.deleteBrokenMetadata(ctx, opts), logger,
+		}, nil
+	}
+
+	results, apiErr := collect(ctrl.deleteBrokenMetadata(ctx, opts))
 	if apiErr != nil {
 		logger.WithError(apiErr).Error("failed to delete broken metadata")
 		return apiErr, nil
 	}
 
+	files := make([]api.FileSummary, 0, len(results))
+	for _, r := range results {
+		if r.Error == nil {
+			files = append(files, r.Metadata)
+		}
+	}
+
 	return api.DeleteBrokenMetadata200JSONResponse{
-		Metadata: fileListSummary(files),
+		Metadata: &files,
+		Results:  &results,
 	}, nil
 }
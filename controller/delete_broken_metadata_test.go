@@ -2,6 +2,7 @@ package controller_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/nhost/hasura-storage/api"
 	"github.com/nhost/hasura-storage/controller"
@@ -10,30 +11,100 @@ import (
 	gomock "go.uber.org/mock/gomock"
 )
 
-func TestDeleteBrokenMetadata(t *testing.T) {
+func TestDeleteBrokenMetadata(t *testing.T) { //nolint:funlen,maintidx
 	t.Parallel()
 
+	file1 := api.FileSummary{
+		Id:         "b3b4e653-ca59-412c-a165-92d251c3fe86",
+		Name:       "file-1.txt",
+		IsUploaded: true,
+		BucketId:   "default",
+	}
+	file2 := api.FileSummary{
+		Id:         "e6aad336-ad79-4df7-a09b-5782f71948f4",
+		Name:       "file-1.txt",
+		IsUploaded: true,
+		BucketId:   "other",
+	}
+
 	cases := []struct {
 		name     string
-		request  api.DeleteBrokenMetadataRequestObject
+		params   api.DeleteBrokenMetadataParams
+		setup    func(metadataStorage *mock.MockMetadataStorage)
 		expected api.DeleteBrokenMetadata200JSONResponse
 	}{
 		{
-			name: "successful",
+			name:   "successful",
+			params: api.DeleteBrokenMetadataParams{}, //nolint:exhaustruct
+			setup: func(metadataStorage *mock.MockMetadataStorage) {
+				metadataStorage.EXPECT().DeleteFileByID(
+					gomock.Any(), file1.Id, gomock.Any(),
+				).Return(api.FileMetadata{}, nil) //nolint:exhaustruct
+				metadataStorage.EXPECT().DeleteFileByID(
+					gomock.Any(), file2.Id, gomock.Any(),
+				).Return(api.FileMetadata{}, controller.ErrFileNotFound) //nolint:exhaustruct
+			},
 			expected: api.DeleteBrokenMetadata200JSONResponse{
-				Metadata: &[]api.FileSummary{
-					{
-						Id:         "b3b4e653-ca59-412c-a165-92d251c3fe86",
-						Name:       "file-1.txt",
-						IsUploaded: true,
-						BucketId:   "default",
-					},
-					{
-						Id:         "e6aad336-ad79-4df7-a09b-5782f71948f4",
-						Name:       "file-1.txt",
-						IsUploaded: true,
-						BucketId:   "default",
-					},
+				Metadata: &[]api.FileSummary{file1},
+				Results: &[]api.BrokenMetadataDeletion{
+					{Metadata: file1, Deleted: true, Error: nil},
+					{Metadata: file2, Deleted: false, Error: ptr("file not found")},
+				},
+			},
+		},
+		{
+			name: "dry run in bucket",
+			params: api.DeleteBrokenMetadataParams{ //nolint:exhaustruct
+				DryRun:   ptr(true),
+				BucketId: ptr("other"),
+			},
+			setup: func(_ *mock.MockMetadataStorage) {},
+			expected: api.DeleteBrokenMetadata200JSONResponse{
+				Metadata: &[]api.FileSummary{file2},
+				Results: &[]api.BrokenMetadataDeletion{
+					{Metadata: file2, Deleted: false, Error: nil},
+				},
+			},
+		},
+		{
+			name:   "max deletions",
+			params: api.DeleteBrokenMetadataParams{MaxDeletions: ptr(1)}, //nolint:exhaustruct
+			setup: func(metadataStorage *mock.MockMetadataStorage) {
+				metadataStorage.EXPECT().DeleteFileByID(
+					gomock.Any(), file1.Id, gomock.Any(),
+				).Return(api.FileMetadata{}, nil) //nolint:exhaustruct
+			},
+			expected: api.DeleteBrokenMetadata200JSONResponse{
+				Metadata: &[]api.FileSummary{file1},
+				Results: &[]api.BrokenMetadataDeletion{
+					{Metadata: file1, Deleted: true, Error: nil},
+				},
+			},
+		},
+		{
+			name:   "min age",
+			params: api.DeleteBrokenMetadataParams{MinAge: ptr(3600)}, //nolint:exhaustruct
+			setup: func(metadataStorage *mock.MockMetadataStorage) {
+				metadataStorage.EXPECT().GetFileByID(
+					gomock.Any(), file1.Id, gomock.Any(),
+				).Return(api.FileMetadata{ //nolint:exhaustruct
+					Id:        file1.Id,
+					UpdatedAt: time.Now().Add(-48 * time.Hour),
+				}, nil)
+				metadataStorage.EXPECT().GetFileByID(
+					gomock.Any(), file2.Id, gomock.Any(),
+				).Return(api.FileMetadata{ //nolint:exhaustruct
+					Id:        file2.Id,
+					UpdatedAt: time.Now().Add(-time.Minute),
+				}, nil)
+				metadataStorage.EXPECT().DeleteFileByID(
+					gomock.Any(), file1.Id, gomock.Any(),
+				).Return(api.FileMetadata{}, nil) //nolint:exhaustruct
+			},
+			expected: api.DeleteBrokenMetadata200JSONResponse{
+				Metadata: &[]api.FileSummary{file1},
+				Results: &[]api.BrokenMetadataDeletion{
+					{Metadata: file1, Deleted: true, Error: nil},
 				},
 			},
 		},
@@ -55,46 +126,42 @@ func TestDeleteBrokenMetadata(t *testing.T) {
 			metadataStorage.EXPECT().ListFiles(
 				gomock.Any(), gomock.Any(),
 			).Return(
-				[]controller.FileSummary{
-					{
-						ID:         "b3b4e653-ca59-412c-a165-92d251c3fe86",
-						Name:       "file-1.txt",
-						IsUploaded: true,
-						BucketID:   "default",
-					},
-					{
-						ID:         "e6aad336-ad79-4df7-a09b-5782f71948f4",
-						Name:       "file-1.txt",
-						IsUploaded: true,
-						BucketID:   "default",
-					},
-					{
+				listOf(
+					controller.FileSummary{
 						ID:         "7dc0b0d0-b100-4667-89f1-0434942d9c15",
 						Name:       "file-two.txt",
 						IsUploaded: true,
 						BucketID:   "default",
 					},
-					{
+					controller.FileSummary{
 						ID:         "a184ad10-58e2-4619-9a22-04a90b9c4b5f",
 						Name:       "file-three.txt",
 						IsUploaded: false,
 						BucketID:   "default",
 					},
-				}, nil,
+					controller.FileSummary{
+						ID:         "b3b4e653-ca59-412c-a165-92d251c3fe86",
+						Name:       "file-1.txt",
+						IsUploaded: true,
+						BucketID:   "default",
+					},
+					controller.FileSummary{
+						ID:         "e6aad336-ad79-4df7-a09b-5782f71948f4",
+						Name:       "file-1.txt",
+						IsUploaded: true,
+						BucketID:   "other",
+					},
+				),
 			)
 
 			contentStorage.EXPECT().ListFiles(gomock.Any()).Return(
-				[]string{
-					"default/7dc0b0d0-b100-4667-89f1-0434942d9c15",
-				}, nil,
+				listOf(
+					"7dc0b0d0-b100-4667-89f1-0434942d9c15",
+					"tus/e6aad336-ad79-4df7-a09b-5782f71948f4/info",
+				),
 			)
 
-			metadataStorage.EXPECT().DeleteFileByID(
-				gomock.Any(), "b3b4e653-ca59-412c-a165-92d251c3fe86", gomock.Any(),
-			).Return(nil)
-			metadataStorage.EXPECT().DeleteFileByID(
-				gomock.Any(), "e6aad336-ad79-4df7-a09b-5782f71948f4", gomock.Any(),
-			).Return(nil)
+			tc.setup(metadataStorage)
 
 			ctrl := controller.New(
 				"http://asd",
@@ -109,7 +176,7 @@ func TestDeleteBrokenMetadata(t *testing.T) {
 
 			resp, err := ctrl.DeleteBrokenMetadata(
 				t.Context(),
-				api.DeleteBrokenMetadataRequestObject{},
+				api.DeleteBrokenMetadataRequestObject{Params: tc.params},
 			)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
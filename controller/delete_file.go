@@ -15,7 +15,8 @@ func (ctrl *Controller) DeleteFile( //nolint:ireturn
 	logger := middleware.LoggerFromContext(ctx)
 	sessionHeaders := middleware.SessionHeadersFromContext(ctx)
 
-	apiErr := ctrl.metadataStorage.DeleteFileByID(ctx, request.Id, sessionHeaders)
+	// the event carries the metadata the file had before deleting it
+	fileMetadata, apiErr := ctrl.metadataStorage.DeleteFileByID(ctx, request.Id, sessionHeaders)
 	if apiErr != nil {
 		logger.WithError(apiErr).Error("problem deleting file metadata")
 		return apiErr, nil
@@ -27,6 +28,9 @@ func (ctrl *Controller) DeleteFile( //nolint:ireturn
 	}
 
 	fastly.FileChangedToContext(ctx, request.Id)
+	ctrl.invalidateImageCache(ctx, request.Id)
+
+	ctrl.publishEvent(ctx, EventFileDeleted, fileMetadata, sessionHeaders)
 
 	return api.DeleteFile204Response{}, nil
 }
@@ -14,12 +14,28 @@ func TestDeleteFile(t *testing.T) {
 	t.Parallel()
 
 	cases := []struct {
-		name     string
-		expected api.DeleteFileResponseObject
+		name       string
+		events     bool
+		imageCache bool
+		expected   api.DeleteFileResponseObject
 	}{
 		{
-			name:     "success",
-			expected: api.DeleteFile204Response{},
+			name:       "success",
+			events:     false,
+			imageCache: false,
+			expected:   api.DeleteFile204Response{},
+		},
+		{
+			name:       "with events",
+			events:     true,
+			imageCache: false,
+			expected:   api.DeleteFile204Response{},
+		},
+		{
+			name:       "with image cache",
+			events:     false,
+			imageCache: true,
+			expected:   api.DeleteFile204Response{},
 		},
 	}
 
@@ -36,9 +52,34 @@ func TestDeleteFile(t *testing.T) {
 			metadataStorage := mock.NewMockMetadataStorage(c)
 			contentStorage := mock.NewMockContentStorage(c)
 
+			var events controller.EventPublisher
+			if tc.events {
+				publisher := mock.NewMockEventPublisher(c)
+				publisher.EXPECT().Publish(gomock.Any(), fileEventMatcher{
+					eventType: controller.EventFileDeleted,
+					fileID:    "55af1e60-0f28-454e-885e-ea6aab2bb288",
+				}).Return(nil)
+
+				events = publisher
+			}
+
+			var imageCache controller.ImageCache
+			if tc.imageCache {
+				cache := mock.NewMockImageCache(c)
+				cache.EXPECT().DeleteFile(
+					gomock.Any(), "55af1e60-0f28-454e-885e-ea6aab2bb288",
+				).Return(nil)
+
+				imageCache = cache
+			}
+
+			// the metadata of the deleted file is used for the event
 			metadataStorage.EXPECT().DeleteFileByID(
 				gomock.Any(), "55af1e60-0f28-454e-885e-ea6aab2bb288", gomock.Any(),
-			).Return(nil)
+			).Return(api.FileMetadata{ //nolint:exhaustruct
+				Id:       "55af1e60-0f28-454e-885e-ea6aab2bb288",
+				BucketId: "default",
+			}, nil)
 
 			contentStorage.EXPECT().DeleteFile(
 				gomock.Any(),
@@ -56,6 +97,8 @@ func TestDeleteFile(t *testing.T) {
 				nil,
 				nil,
 				logger,
+				controller.WithImageCache(imageCache),
+				controller.WithEvents(events),
 			)
 
 			resp, err := ctrl.DeleteFile(
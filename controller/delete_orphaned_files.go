@@ -2,39 +2,129 @@ package controller
 
 import (
 	"context"
+	"iter"
 
 	"github.com/nhost/hasura-storage/api"
 	"github.com/nhost/hasura-storage/middleware"
 )
 
-func (ctrl *Controller) deleteOrphans(ctx context.Context) ([]string, *APIError) {
-	toDelete, apiErr := ctrl.listOrphans(ctx)
+// isRecentOrphan returns if the file was modified within the minimum age, files that
+// are gone by now are considered recent so they are skipped.
+func (ctrl *Controller) isRecentOrphan(
+	ctx context.Context, key string, opts cleanupOptions,
+) (bool, *APIError) {
+	if opts.minAge == 0 {
+		return false, nil
+	}
+
+	file, apiErr := ctrl.contentStorage.HeadFile(ctx, key)
+	if apiErr == ErrFileNotFound { //nolint:errorlint
+		return true, nil
+	}
+
 	if apiErr != nil {
-		return nil, apiErr
+		return false, apiErr.ExtendError("problem getting file information")
 	}
 
-	for _, f := range toDelete {
-		if apiErr := ctrl.contentStorage.DeleteFile(ctx, f); apiErr != nil {
-			return nil, apiErr
-		}
+	return opts.tooRecent(file.LastModified), nil
+}
+
+func (ctrl *Controller) deleteOrphan(
+	ctx context.Context, key string, opts cleanupOptions,
+) api.OrphanedFileDeletion {
+	logger := middleware.LoggerFromContext(ctx)
+
+	result := api.OrphanedFileDeletion{
+		File:    key,
+		Deleted: false,
+		Error:   nil,
+	}
+
+	if opts.dryRun {
+		return result
+	}
+
+	if apiErr := ctrl.contentStorage.DeleteFile(ctx, key); apiErr != nil {
+		logger.WithError(apiErr).Errorf("problem deleting orphaned file %s", key)
+		result.Error = publicMessage(apiErr)
+
+		return result
 	}
 
-	return toDelete, nil
+	ctrl.invalidateImageCache(ctx, key)
+
+	result.Deleted = true
+
+	return result
+}
+
+func (ctrl *Controller) deleteOrphans(
+	ctx context.Context, opts cleanupOptions,
+) iter.Seq2[api.OrphanedFileDeletion, *APIError] {
+	return func(yield func(api.OrphanedFileDeletion, *APIError) bool) {
+		processed := 0
+
+		for f, apiErr := range ctrl.listOrphans(ctx) {
+			if apiErr != nil {
+				yield(api.OrphanedFileDeletion{}, apiErr) //nolint:exhaustruct
+				return
+			}
+
+			recent, apiErr := ctrl.isRecentOrphan(ctx, f, opts)
+
+			var result api.OrphanedFileDeletion
+
+			switch {
+			case apiErr != nil:
+				result = api.OrphanedFileDeletion{
+					File:    f,
+					Deleted: false,
+					Error:   publicMessage(apiErr),
+				}
+			case recent:
+				continue
+			default:
+				result = ctrl.deleteOrphan(ctx, f, opts)
+			}
+
+			processed++
+
+			if !yield(result, nil) || opts.limitReached(processed) {
+				return
+			}
+		}
+	}
 }
 
 func (ctrl *Controller) DeleteOrphanedFiles( //nolint:ireturn
 	ctx context.Context,
-	_ api.DeleteOrphanedFilesRequestObject,
+	request api.DeleteOrphanedFilesRequestObject,
 ) (api.DeleteOrphanedFilesResponseObject, error) {
 	logger := middleware.LoggerFromContext(ctx)
 
-	files, apiErr := ctrl.deleteOrphans(ctx)
+	opts := newCleanupOptions(
+		request.Params.DryRun, request.Params.MaxDeletions, request.Params.MinAge, nil,
+	)
+
+	if acceptsNDJSON(ctx) {
+		return ndjsonResponse[api.OrphanedFileDeletion]{ctrl.deleteOrphans(ctx, opts), logger}, nil
+	}
+
+	results, apiErr := collect(ctrl.deleteOrphans(ctx, opts))
 	if apiErr != nil {
 		logger.WithError(apiErr).Error("failed to delete orphaned files")
 		return apiErr, nil
 	}
 
+	files := make([]string, 0, len(results))
+	for _, r := range results {
+		if r.Error == nil {
+			files = append(files, r.File)
+		}
+	}
+
 	return api.DeleteOrphanedFiles200JSONResponse{
-		Files: &files,
+		Files:   &files,
+		Results: &results,
 	}, nil
 }
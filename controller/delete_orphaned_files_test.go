@@ -1,7 +1,9 @@
 package controller_test
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/nhost/hasura-storage/api"
 	"github.com/nhost/hasura-storage/controller"
@@ -10,17 +12,92 @@ import (
 	gomock "go.uber.org/mock/gomock"
 )
 
-func TestDeleteOrphans(t *testing.T) {
+func TestDeleteOrphans(t *testing.T) { //nolint:funlen,maintidx
 	t.Parallel()
 
+	deleted := func(file string) api.OrphanedFileDeletion {
+		return api.OrphanedFileDeletion{File: file, Deleted: true, Error: nil}
+	}
+	notDeleted := func(file string) api.OrphanedFileDeletion {
+		return api.OrphanedFileDeletion{File: file, Deleted: false, Error: nil}
+	}
+
 	cases := []struct {
 		name     string
+		params   api.DeleteOrphanedFilesParams
+		setup    func(contentStorage *mock.MockContentStorage)
 		expected api.DeleteOrphanedFiles200JSONResponse
 	}{
 		{
-			name: "successful",
+			name:   "successful",
+			params: api.DeleteOrphanedFilesParams{}, //nolint:exhaustruct
+			setup: func(contentStorage *mock.MockContentStorage) {
+				contentStorage.EXPECT().DeleteFile(gomock.Any(), "garbage").Return(nil)
+				contentStorage.EXPECT().DeleteFile(gomock.Any(), "garbage-2").Return(
+					controller.InternalServerError(errors.New("some error")), //nolint:err113
+				)
+				contentStorage.EXPECT().DeleteFile(gomock.Any(), "new-garbage").Return(nil)
+			},
 			expected: api.DeleteOrphanedFiles200JSONResponse{
-				Files: &[]string{"default/garbage"},
+				Files: &[]string{"garbage", "new-garbage"},
+				Results: &[]api.OrphanedFileDeletion{
+					deleted("garbage"),
+					{
+						File:    "garbage-2",
+						Deleted: false,
+						Error:   ptr("an internal server error occurred"),
+					},
+					deleted("new-garbage"),
+				},
+			},
+		},
+		{
+			name:   "dry run",
+			params: api.DeleteOrphanedFilesParams{DryRun: ptr(true)}, //nolint:exhaustruct
+			setup:  func(_ *mock.MockContentStorage) {},
+			expected: api.DeleteOrphanedFiles200JSONResponse{
+				Files: &[]string{"garbage", "garbage-2", "new-garbage"},
+				Results: &[]api.OrphanedFileDeletion{
+					notDeleted("garbage"),
+					notDeleted("garbage-2"),
+					notDeleted("new-garbage"),
+				},
+			},
+		},
+		{
+			name:   "max deletions",
+			params: api.DeleteOrphanedFilesParams{MaxDeletions: ptr(1)}, //nolint:exhaustruct
+			setup: func(contentStorage *mock.MockContentStorage) {
+				contentStorage.EXPECT().DeleteFile(gomock.Any(), "garbage").Return(nil)
+			},
+			expected: api.DeleteOrphanedFiles200JSONResponse{
+				Files:   &[]string{"garbage"},
+				Results: &[]api.OrphanedFileDeletion{deleted("garbage")},
+			},
+		},
+		{
+			name:   "min age",
+			params: api.DeleteOrphanedFilesParams{MinAge: ptr(3600)}, //nolint:exhaustruct
+			setup: func(contentStorage *mock.MockContentStorage) {
+				for file, modified := range map[string]time.Time{
+					"garbage":     time.Now().Add(-48 * time.Hour),
+					"garbage-2":   time.Now().Add(-2 * time.Hour),
+					"new-garbage": time.Now().Add(-time.Minute),
+				} {
+					contentStorage.EXPECT().HeadFile(gomock.Any(), file).Return(
+						&controller.File{LastModified: modified}, nil, //nolint:exhaustruct
+					)
+				}
+
+				contentStorage.EXPECT().DeleteFile(gomock.Any(), "garbage").Return(nil)
+				contentStorage.EXPECT().DeleteFile(gomock.Any(), "garbage-2").Return(nil)
+			},
+			expected: api.DeleteOrphanedFiles200JSONResponse{
+				Files: &[]string{"garbage", "garbage-2"},
+				Results: &[]api.OrphanedFileDeletion{
+					deleted("garbage"),
+					deleted("garbage-2"),
+				},
 			},
 		},
 	}
@@ -41,31 +118,35 @@ func TestDeleteOrphans(t *testing.T) {
 			metadataStorage.EXPECT().ListFiles(
 				gomock.Any(), gomock.Any(),
 			).Return(
-				[]controller.FileSummary{
-					{
-						ID:         "b3b4e653-ca59-412c-a165-92d251c3fe86",
-						Name:       "file-1.txt",
+				listOf(
+					controller.FileSummary{
+						ID:         "7dc0b0d0-b100-4667-89f1-0434942d9c15",
+						Name:       "file-two.txt",
 						IsUploaded: true,
 						BucketID:   "default",
 					},
-					{
-						ID:         "7dc0b0d0-b100-4667-89f1-0434942d9c15",
-						Name:       "file-two.txt",
+					controller.FileSummary{
+						ID:         "b3b4e653-ca59-412c-a165-92d251c3fe86",
+						Name:       "file-1.txt",
 						IsUploaded: true,
 						BucketID:   "default",
 					},
-				}, nil,
+				),
 			)
 
 			contentStorage.EXPECT().ListFiles(gomock.Any()).Return(
-				[]string{
-					"default/b3b4e653-ca59-412c-a165-92d251c3fe86",
-					"default/7dc0b0d0-b100-4667-89f1-0434942d9c15",
-					"default/garbage",
-				}, nil,
+				listOf(
+					"7dc0b0d0-b100-4667-89f1-0434942d9c15",
+					"b3b4e653-ca59-412c-a165-92d251c3fe86",
+					"garbage",
+					"garbage-2",
+					"new-garbage",
+					// quarantined files are never deleted
+					"quarantine/3c9d7a52-0e8f-4f4c-9d4b-6a1e8f2b7c13",
+				),
 			)
 
-			contentStorage.EXPECT().DeleteFile(gomock.Any(), "default/garbage").Return(nil)
+			tc.setup(contentStorage)
 
 			ctrl := controller.New(
 				"http://asd",
@@ -80,7 +161,7 @@ func TestDeleteOrphans(t *testing.T) {
 
 			resp, err := ctrl.DeleteOrphanedFiles(
 				t.Context(),
-				api.DeleteOrphanedFilesRequestObject{},
+				api.DeleteOrphanedFilesRequestObject{Params: tc.params},
 			)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
@@ -90,3 +171,77 @@ func TestDeleteOrphans(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteOrphansInFolders(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	metadataStorage := mock.NewMockMetadataStorage(c)
+	contentStorage := mock.NewMockContentStorage(c)
+
+	metadataStorage.EXPECT().ListFiles(
+		gomock.Any(), gomock.Any(),
+	).Return(
+		listOf(
+			controller.FileSummary{
+				ID:         "7dc0b0d0-b100-4667-89f1-0434942d9c15",
+				Name:       "file-two.txt",
+				IsUploaded: true,
+				BucketID:   "default",
+			},
+			controller.FileSummary{
+				ID:         "b3b4e653-ca59-412c-a165-92d251c3fe86",
+				Name:       "file-1.txt",
+				IsUploaded: true,
+				BucketID:   "default",
+			},
+		),
+	)
+
+	contentStorage.EXPECT().ListFiles(gomock.Any()).Return(
+		listOf(
+			"default/7dc0b0d0-b100-4667-89f1-0434942d9c15",
+			"default/b3b4e653-ca59-412c-a165-92d251c3fe86",
+			"default/garbage",
+		),
+	)
+
+	// keys outside the root are matched by their name
+	for _, id := range []string{
+		"7dc0b0d0-b100-4667-89f1-0434942d9c15", "b3b4e653-ca59-412c-a165-92d251c3fe86",
+	} {
+		metadataStorage.EXPECT().GetFileByID(gomock.Any(), id, gomock.Any()).Return(
+			api.FileMetadata{Id: id}, nil, //nolint:exhaustruct
+		)
+	}
+
+	contentStorage.EXPECT().DeleteFile(gomock.Any(), "default/garbage").Return(nil)
+
+	ctrl := controller.New(
+		"http://asd",
+		"/v1",
+		"asdasd",
+		metadataStorage,
+		contentStorage,
+		nil,
+		nil,
+		logger,
+	)
+
+	resp, err := ctrl.DeleteOrphanedFiles(t.Context(), api.DeleteOrphanedFilesRequestObject{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert(t, api.DeleteOrphanedFiles200JSONResponse{
+		Files: &[]string{"default/garbage"},
+		Results: &[]api.OrphanedFileDeletion{
+			{File: "default/garbage", Deleted: true, Error: nil},
+		},
+	}, resp)
+}
@@ -52,6 +52,12 @@ var (
 		errors.New("file not found"), //nolint
 		nil,
 	}
+	ErrFileModified = &APIError{
+		http.StatusPreconditionFailed,
+		"file was modified",
+		errors.New("file was modified"), //nolint
+		nil,
+	}
 	ErrFileNotUploaded = &APIError{
 		http.StatusForbidden,
 		"file not uploaded",
@@ -78,7 +84,11 @@ func (a *APIError) visit(w http.ResponseWriter) error {
 	w.Header().Set("X-Error", a.publicMessage)
 	w.WriteHeader(a.statusCode)
 
-	errorResponse := api.ErrorResponse{
+	return json.NewEncoder(w).Encode(a.errorResponse()) //nolint:wrapcheck
+}
+
+func (a *APIError) errorResponse() api.ErrorResponse {
+	return api.ErrorResponse{
 		Error: &struct {
 			Data    *map[string]any `json:"data,omitempty"`
 			Message string          `json:"message"`
@@ -87,8 +97,6 @@ func (a *APIError) visit(w http.ResponseWriter) error {
 			Message: a.publicMessage,
 		},
 	}
-
-	return json.NewEncoder(w).Encode(errorResponse) //nolint:wrapcheck
 }
 
 func (a *APIError) VisitUploadFilesResponse(w http.ResponseWriter) error {
@@ -111,6 +119,18 @@ func (a *APIError) VisitGetFilePresignedURLResponse(w http.ResponseWriter) error
 	return a.visit(w)
 }
 
+func (a *APIError) VisitGetFileImageInfoResponse(w http.ResponseWriter) error {
+	return a.visit(w)
+}
+
+func (a *APIError) VisitCreatePresignedUploadResponse(w http.ResponseWriter) error {
+	return a.visit(w)
+}
+
+func (a *APIError) VisitCompletePresignedUploadResponse(w http.ResponseWriter) error {
+	return a.visit(w)
+}
+
 func (a *APIError) VisitGetFileWithPresignedURLResponse(w http.ResponseWriter) error {
 	return a.visit(w)
 }
@@ -139,6 +159,10 @@ func (a *APIError) VisitListFilesNotUploadedResponse(w http.ResponseWriter) erro
 	return a.visit(w)
 }
 
+func (a *APIError) VisitRescanFilesResponse(w http.ResponseWriter) error {
+	return a.visit(w)
+}
+
 func InternalServerError(err error) *APIError {
 	return &APIError{
 		statusCode:    http.StatusInternalServerError,
@@ -192,6 +216,33 @@ func FileTooSmallError(filename string, size, minSize int) *APIError {
 	}
 }
 
+func MimeTypeMismatchError(filename, mimeType, detectedMimeType string) *APIError {
+	return &APIError{
+		statusCode:    http.StatusBadRequest,
+		publicMessage: "file content doesn't match its type",
+		err: fmt.Errorf( //nolint:err113
+			"file %s declared as %s looks like %s", filename, mimeType, detectedMimeType,
+		),
+		data: map[string]any{
+			"filename":         filename,
+			"mimeType":         mimeType,
+			"detectedMimeType": detectedMimeType,
+		},
+	}
+}
+
+func MimeTypeNotAllowedError(filename, mimeType string) *APIError {
+	return &APIError{
+		statusCode:    http.StatusUnsupportedMediaType,
+		publicMessage: "file type not allowed",
+		err:           fmt.Errorf("file %s of type %s not allowed", filename, mimeType), //nolint
+		data: map[string]any{
+			"filename": filename,
+			"mimeType": mimeType,
+		},
+	}
+}
+
 func WrongMetadataFormatError(err error) *APIError {
 	return &APIError{
 		statusCode:    http.StatusBadRequest,
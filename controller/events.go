@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/middleware"
+)
+
+const (
+	EventFileUploaded = "file.uploaded"
+	EventFileReplaced = "file.replaced"
+	EventFileDeleted  = "file.deleted"
+)
+
+// FileEvent describes a change to a file, it is sent as is to the subscribers.
+type FileEvent struct {
+	ID        string           `json:"id"`
+	Type      string           `json:"type"`
+	CreatedAt time.Time        `json:"createdAt"`
+	File      api.FileMetadata `json:"file"`
+	// Session is the session of the user that made the change, same as the one stored
+	// when a virus is found
+	Session map[string]any `json:"session"`
+}
+
+// publishEvent notifies the subscribers of a change to a file. The change already
+// happened so problems are only logged.
+func (ctrl *Controller) publishEvent(
+	ctx context.Context, eventType string, file api.FileMetadata, sessionHeaders http.Header,
+) {
+	if ctrl.events == nil {
+		return
+	}
+
+	if apiErr := ctrl.events.Publish(ctx, FileEvent{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		CreatedAt: time.Now(),
+		File:      file,
+		Session:   GetUserSession(sessionHeaders),
+	}); apiErr != nil {
+		middleware.LoggerFromContext(ctx).WithError(apiErr).Errorf(
+			"problem publishing %s event for file %s", eventType, file.Id,
+		)
+	}
+}
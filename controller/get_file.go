@@ -3,18 +3,22 @@ package controller
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
-	"slices"
-	"strings"
 	"time"
 
 	"github.com/nhost/hasura-storage/api"
 	"github.com/nhost/hasura-storage/image"
 	"github.com/nhost/hasura-storage/middleware"
+	"github.com/nhost/hasura-storage/telemetry"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func deptr[T any](v *T) T { //nolint:ireturn
@@ -36,6 +40,16 @@ func mimeTypeToImageType(mimeType string) (image.ImageType, *APIError) {
 		return image.ImageTypeJPEG, nil
 	case "image/avif":
 		return image.ImageTypeAVIF, nil
+	case "image/gif":
+		return image.ImageTypeGIF, nil
+	case "image/tiff":
+		return image.ImageTypeTIFF, nil
+	case "image/jxl":
+		return image.ImageTypeJXL, nil
+	case "image/heic", "image/heif", "image/heic-sequence", "image/heif-sequence":
+		return image.ImageTypeHEIF, nil
+	case "image/svg+xml":
+		return image.ImageTypeSVG, nil
 	default:
 		return 0, BadDataError(
 			fmt.Errorf( //nolint: err113
@@ -46,10 +60,14 @@ func mimeTypeToImageType(mimeType string) (image.ImageType, *APIError) {
 	}
 }
 
+// chooseImageFormat returns the format of the image and the one to serve it in.
+// headFunc downloads the beginning of the image, it is only called for f=auto to tell
+// whether the image is animated.
 func chooseImageFormat( //nolint: cyclop
 	params ImageManipulationOptionsGetter,
 	mimeType string,
 	acceptHeader []string,
+	headFunc getFileFunc,
 ) (image.ImageType, image.ImageType, *APIError) {
 	format := deptr(params.GetF())
 	if format == "" {
@@ -63,7 +81,7 @@ func chooseImageFormat( //nolint: cyclop
 
 	switch format {
 	case "", api.Same:
-		return originalFormat, originalFormat, nil
+		return originalFormat, sameFormat(originalFormat), nil
 	case api.Webp:
 		return originalFormat, image.ImageTypeWEBP, nil
 	case api.Png:
@@ -72,27 +90,27 @@ func chooseImageFormat( //nolint: cyclop
 		return originalFormat, image.ImageTypeJPEG, nil
 	case api.Avif:
 		return originalFormat, image.ImageTypeAVIF, nil
+	case api.Gif:
+		return originalFormat, image.ImageTypeGIF, nil
+	case api.Tiff:
+		return originalFormat, image.ImageTypeTIFF, nil
+	case api.Jxl:
+		return originalFormat, image.ImageTypeJXL, nil
 	case api.Auto:
-		for _, acceptHeader := range acceptHeader {
-			acceptedTypes := strings.Split(acceptHeader, ",")
-			switch {
-			case slices.Contains(acceptedTypes, "image/avif"):
-				return originalFormat, image.ImageTypeAVIF, nil
-			case slices.Contains(acceptedTypes, "image/webp"):
-				return originalFormat, image.ImageTypeWEBP, nil
-			case slices.Contains(acceptedTypes, "image/jpeg"):
-				return originalFormat, image.ImageTypeJPEG, nil
-			case slices.Contains(acceptedTypes, "image/png"):
-				return originalFormat, image.ImageTypePNG, nil
-			}
+		animated, err := isAnimatedImage(originalFormat, headFunc)
+		if err != nil {
+			return 0, 0, err
 		}
 
-		return originalFormat, originalFormat, nil
+		return originalFormat, negotiateImageFormat(originalFormat, animated, acceptHeader), nil
 	default:
 		return 0, 0, BadDataError(
-			//nolint: err113
-			fmt.Errorf("format must be one of: same, webp, png, jpeg, avif, auto. Got: %s", format),
-			"format must be one of: same, webp, png, jpeg, avif, auto. Got: "+string(format),
+			fmt.Errorf( //nolint: err113
+				"format must be one of: same, webp, png, jpeg, avif, gif, tiff, jxl, auto. Got: %s",
+				format,
+			),
+			"format must be one of: same, webp, png, jpeg, avif, gif, tiff, jxl, auto. Got: "+
+				string(format),
 		)
 	}
 }
@@ -103,12 +121,94 @@ type ImageManipulationOptionsGetter interface {
 	GetB() *float32
 	GetQ() *int
 	GetF() *api.OutputImageFormat
+	GetCrop() *string
+	GetFit() *api.ImageFit
+	GetGravity() *api.ImageGravity
+	GetRotate() *int
+	GetFlip() *api.ImageFlip
+	GetSharpen() *float32
+	GetGrayscale() *bool
+	GetStrip() *bool
+	GetBackground() *string
+	GetDpr() *float32
+}
+
+func parseCrop(crop string) (image.Rect, *APIError) {
+	var rect image.Rect
+	if _, err := fmt.Sscanf(
+		crop, "%d,%d,%d,%d", &rect.X, &rect.Y, &rect.Width, &rect.Height,
+	); err != nil || rect.IsEmpty() {
+		return image.Rect{}, BadDataError(
+			fmt.Errorf("invalid crop %q", crop), //nolint: err113
+			"crop must be x,y,width,height with a width and height bigger than 0",
+		)
+	}
+
+	return rect, nil
+}
+
+// parseColor parses a color in hex, rrggbb or rrggbbaa.
+func parseColor(color string) (image.Color, *APIError) {
+	b, err := hex.DecodeString(color)
+	if err != nil || (len(b) != 3 && len(b) != 4) { //nolint: mnd
+		return image.Color{}, BadDataError(
+			fmt.Errorf("invalid color %q", color), //nolint: err113
+			"background must be an hex color, rrggbb or rrggbbaa",
+		)
+	}
+
+	c := image.Color{R: b[0], G: b[1], B: b[2], A: math.MaxUint8}
+	if len(b) == 4 { //nolint: mnd
+		c.A = b[3]
+	}
+
+	return c, nil
 }
 
-func getImageManipulationOptions(
+func imageFit(fit api.ImageFit) image.Fit {
+	switch fit {
+	case api.Contain:
+		return image.FitContain
+	case api.Fill:
+		return image.FitFill
+	case api.Inside:
+		return image.FitInside
+	case api.Cover:
+	}
+
+	return image.FitCover
+}
+
+func imageGravity(gravity api.ImageGravity) image.Gravity {
+	switch gravity {
+	case api.Attention:
+		return image.GravityAttention
+	case api.Entropy:
+		return image.GravityEntropy
+	case api.Center:
+	}
+
+	return image.GravityCenter
+}
+
+func imageFlip(flip api.ImageFlip) image.Flip {
+	switch flip {
+	case api.Horizontal:
+		return image.FlipHorizontal
+	case api.Vertical:
+		return image.FlipVertical
+	case api.Both:
+		return image.FlipBoth
+	}
+
+	return image.FlipNone
+}
+
+func getImageManipulationOptions( //nolint: cyclop
 	params ImageManipulationOptionsGetter,
 	mimeType string,
 	acceptHeader []string,
+	headFunc getFileFunc,
 ) (image.Options, *APIError) {
 	outputFormatFound := deptr(params.GetF()) != ""
 
@@ -119,9 +219,41 @@ func getImageManipulationOptions(
 		Quality:        deptr(params.GetQ()),
 		OriginalFormat: 0,
 		Format:         0,
+		Crop:           image.Rect{}, //nolint: exhaustruct
+		Fit:            imageFit(deptr(params.GetFit())),
+		Gravity:        imageGravity(deptr(params.GetGravity())),
+		Rotate:         deptr(params.GetRotate()),
+		Flip:           imageFlip(deptr(params.GetFlip())),
+		Sharpen:        deptr(params.GetSharpen()),
+		Grayscale:      deptr(params.GetGrayscale()),
+		Background: image.Color{
+			R: math.MaxUint8, G: math.MaxUint8, B: math.MaxUint8, A: math.MaxUint8,
+		},
+		DPR:            deptr(params.GetDpr()),
+		StripMetadata:  deptr(params.GetStrip()),
+		KeepICCProfile: true,
+	}
+
+	if crop := params.GetCrop(); crop != nil {
+		rect, apiErr := parseCrop(*crop)
+		if apiErr != nil {
+			return image.Options{}, apiErr
+		}
+
+		opts.Crop = rect
+	}
+
+	if background := params.GetBackground(); background != nil {
+		color, apiErr := parseColor(*background)
+		if apiErr != nil {
+			return image.Options{}, apiErr
+		}
+
+		opts.Background = color
 	}
+
 	if !opts.IsEmpty() || outputFormatFound {
-		orig, format, err := chooseImageFormat(params, mimeType, acceptHeader)
+		orig, format, err := chooseImageFormat(params, mimeType, acceptHeader, headFunc)
 		opts.Format = format
 		opts.OriginalFormat = orig
 
@@ -145,17 +277,139 @@ func (p *FakeReadCloserWrapper) Close() error {
 	return nil
 }
 
+// imageTransformerError returns the response for an error of the image transformer.
+func imageTransformerError(err error) *APIError {
+	switch {
+	case errors.Is(err, image.ErrInvalidOptions), errors.Is(err, image.ErrTooLarge):
+		return BadDataError(err, err.Error())
+	case errors.Is(err, image.ErrBusy), errors.Is(err, image.ErrTimeout):
+		return NewAPIError(
+			http.StatusServiceUnavailable,
+			"the server is too busy to transform the image, try again later",
+			err,
+			nil,
+		)
+	}
+
+	return InternalServerError(err)
+}
+
 func (ctrl *Controller) manipulateImage(
-	object io.ReadCloser, size uint64, opts image.Options,
-) (io.ReadCloser, int64, *APIError) {
+	ctx context.Context, object io.ReadCloser, size uint64, opts image.Options,
+) ([]byte, *APIError) {
 	defer object.Close()
 
+	ctx, span := telemetry.Start(
+		ctx,
+		"image.Transform",
+		attribute.String("image.format", opts.FormatMimeType()),
+		attribute.Int64("image.size", int64(size)), //nolint:gosec
+	)
+
 	buf := &bytes.Buffer{}
-	if err := ctrl.imageTransformer.Run(object, size, buf, opts); err != nil {
-		return nil, 0, InternalServerError(err)
+	if err := ctrl.imageTransformer.Run(ctx, object, size, buf, opts); err != nil {
+		telemetry.End(span, err)
+		return nil, imageTransformerError(err)
 	}
 
-	return NewP(buf.Bytes()), int64(buf.Len()), nil
+	span.End()
+
+	return buf.Bytes(), nil
+}
+
+// imageCacheKey identifies a transformation of a file's content.
+func imageCacheKey(etag string, opts image.Options) string {
+	h := sha256.Sum256(fmt.Appendf(nil, "%s\n%+v", etag, opts.Normalized()))
+	return hex.EncodeToString(h[:])
+}
+
+// representation returns the etag and Vary header of the version of the file served.
+// Transformed images get their own strong etag so they aren't mistaken for the original
+// or for other transformations.
+func representation(
+	fileMetadata api.FileMetadata, params ImageManipulationOptionsGetter, opts image.Options,
+) (string, string) {
+	etag := fileMetadata.Etag
+	if !opts.IsEmpty() {
+		etag = `"` + imageCacheKey(fileMetadata.Etag, opts) + `"`
+	}
+
+	vary := ""
+	if deptr(params.GetF()) == api.Auto {
+		vary = "Accept"
+	}
+
+	return etag, vary
+}
+
+// transformImage returns the image downloaded with downloadFunc transformed with opts,
+// from the cache if possible. Concurrent requests for the same transformation share
+// the work.
+func (ctrl *Controller) transformImage(
+	ctx context.Context,
+	downloadFunc getFileFunc,
+	fileMetadata api.FileMetadata,
+	opts image.Options,
+) ([]byte, *APIError) {
+	opts = opts.Normalized()
+	key := imageCacheKey(fileMetadata.Etag, opts)
+
+	if ctrl.imageCache != nil {
+		if b, ok := ctrl.imageCache.Get(ctx, fileMetadata.Id, key); ok {
+			return b, nil
+		}
+	}
+
+	v, err, _ := ctrl.transformations.Do(fileMetadata.Id+"/"+key, func() (any, error) {
+		download, apiErr := downloadFunc()
+		if apiErr != nil {
+			return nil, apiErr
+		}
+
+		b, apiErr := ctrl.manipulateImage(
+			ctx, download.Body, uint64(download.ContentLength), opts, //nolint:gosec
+		)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+
+		if ctrl.imageCache != nil {
+			if apiErr := ctrl.imageCache.Put(
+				context.WithoutCancel(ctx), fileMetadata.Id, key, b,
+			); apiErr != nil {
+				middleware.LoggerFromContext(ctx).WithError(apiErr).Error(
+					"problem caching transformed image",
+				)
+			}
+		}
+
+		return b, nil
+	})
+	if err != nil {
+		apiErr := &APIError{} //nolint:exhaustruct
+		if errors.As(err, &apiErr) {
+			return nil, apiErr
+		}
+
+		return nil, InternalServerError(err)
+	}
+
+	b, _ := v.([]byte)
+
+	return b, nil
+}
+
+// invalidateImageCache removes the transformations of a file that changed.
+func (ctrl *Controller) invalidateImageCache(ctx context.Context, fileID string) {
+	if ctrl.imageCache == nil {
+		return
+	}
+
+	if apiErr := ctrl.imageCache.DeleteFile(ctx, fileID); apiErr != nil {
+		middleware.LoggerFromContext(ctx).WithError(apiErr).Error(
+			"problem removing cached transformations",
+		)
+	}
 }
 
 func getFileNameAndMimeType(fileMetadata api.FileMetadata, opts image.Options) (string, string) {
@@ -175,6 +429,7 @@ type getFileFunc func() (*File, *APIError)
 type processFiler interface {
 	ImageManipulationOptionsGetter
 	ConditionalChecksGetter
+	GetPreset() *string
 }
 
 type processedFile struct {
@@ -185,72 +440,104 @@ type processedFile struct {
 	cacheControl  string
 	mimeType      string
 	contentLength int64
+	etag          string
+	vary          string
 	extraHeaders  http.Header
 }
 
+// processFileToDownload prepares the response to a download. checkAccess, if set, is
+// called before serving anything that doesn't come from downloadFunc, for downloads where
+// the content storage is the one checking access. headFunc downloads the beginning of
+// the file, see chooseImageFormat.
 func (ctrl *Controller) processFileToDownload(
+	ctx context.Context,
 	downloadFunc getFileFunc,
+	headFunc getFileFunc,
+	checkAccess func() *APIError,
 	fileMetadata api.FileMetadata,
+	bucketMetadata BucketMetadata,
 	cacheControl string,
 	params processFiler,
 	acceptHeader []string,
 ) (*processedFile, *APIError) {
-	opts, apiErr := getImageManipulationOptions(params, fileMetadata.MimeType, acceptHeader)
+	params, apiErr := applyPreset(bucketMetadata, params)
 	if apiErr != nil {
 		return nil, apiErr
 	}
 
-	download, apiErr := downloadFunc()
+	opts, apiErr := getImageManipulationOptions(
+		params, fileMetadata.MimeType, acceptHeader, headFunc,
+	)
 	if apiErr != nil {
 		return nil, apiErr
 	}
 
-	if download.ContentLength == fileMetadata.Size {
-		// we force this in case they included a Content-Range header
-		// but the file is not actually partial
-		download.StatusCode = http.StatusOK
+	etag, vary := representation(fileMetadata, params, opts)
+	filename, mimeType := getFileNameAndMimeType(fileMetadata, opts)
+
+	file := &processedFile{
+		statusCode:    http.StatusOK,
+		body:          nil,
+		fileMetadata:  fileMetadata,
+		filename:      filename,
+		cacheControl:  cacheControl,
+		mimeType:      mimeType,
+		contentLength: 0,
+		etag:          etag,
+		vary:          vary,
+		extraHeaders:  make(http.Header),
 	}
 
-	updateAt := fileMetadata.UpdatedAt.Format(time.RFC1123)
+	// conditional requests and cached or shared transformations don't call downloadFunc
+	if checkAccess != nil && (!opts.IsEmpty() || hasConditionals(params)) {
+		if apiErr := checkAccess(); apiErr != nil {
+			return nil, apiErr
+		}
+	}
 
-	body := download.Body
-	contentLength := download.ContentLength
+	// checked before downloading and transforming the file so it isn't done in vain
+	file.statusCode, apiErr = checkConditionals(
+		etag,
+		fileMetadata.UpdatedAt.Format(time.RFC1123),
+		params,
+		http.StatusOK,
+	)
+	if apiErr != nil {
+		return nil, apiErr
+	}
 
-	if !opts.IsEmpty() {
-		defer body.Close()
+	if file.statusCode != http.StatusOK {
+		return file, nil
+	}
 
-		body, contentLength, apiErr = ctrl.manipulateImage(
-			body, uint64(contentLength), opts, //nolint:gosec
-		)
+	if !opts.IsEmpty() {
+		b, apiErr := ctrl.transformImage(ctx, downloadFunc, fileMetadata, opts)
 		if apiErr != nil {
 			return nil, apiErr
 		}
 
-		updateAt = time.Now().Format(time.RFC3339)
+		file.body = NewP(b)
+		file.contentLength = int64(len(b))
+
+		return file, nil
 	}
 
-	statusCode, apiErr := checkConditionals(
-		fileMetadata.Etag,
-		updateAt,
-		params,
-		download.StatusCode,
-	)
+	download, apiErr := downloadFunc()
 	if apiErr != nil {
 		return nil, apiErr
 	}
 
-	filename, mimeType := getFileNameAndMimeType(fileMetadata, opts)
+	file.body = download.Body
+	file.contentLength = download.ContentLength
+	file.extraHeaders = download.ExtraHeaders
 
-	return &processedFile{
-		statusCode:    statusCode,
-		body:          body,
-		fileMetadata:  fileMetadata,
-		filename:      filename,
-		cacheControl:  cacheControl,
-		mimeType:      mimeType,
-		contentLength: contentLength,
-		extraHeaders:  download.ExtraHeaders,
-	}, nil
+	// we force this in case they included a Content-Range header
+	// but the file is not actually partial
+	if download.ContentLength != fileMetadata.Size {
+		file.statusCode = download.StatusCode
+	}
+
+	return file, nil
 }
 
 func (ctrl *Controller) getFileResponse( //nolint: ireturn,funlen,dupl
@@ -269,10 +556,11 @@ func (ctrl *Controller) getFileResponse( //nolint: ireturn,funlen,dupl
 					url.QueryEscape(file.filename),
 				),
 				ContentType:      file.mimeType,
-				Etag:             file.fileMetadata.Etag,
+				Etag:             file.etag,
 				LastModified:     file.fileMetadata.UpdatedAt,
 				SurrogateControl: file.cacheControl,
 				SurrogateKey:     file.fileMetadata.Id,
+				Vary:             file.vary,
 			},
 			ContentLength: file.contentLength,
 		}
@@ -287,10 +575,11 @@ func (ctrl *Controller) getFileResponse( //nolint: ireturn,funlen,dupl
 				),
 				ContentRange:     file.extraHeaders.Get("Content-Range"),
 				ContentType:      file.mimeType,
-				Etag:             file.fileMetadata.Etag,
+				Etag:             file.etag,
 				LastModified:     file.fileMetadata.UpdatedAt,
 				SurrogateControl: file.cacheControl,
 				SurrogateKey:     file.fileMetadata.Id,
+				Vary:             file.vary,
 			},
 			ContentLength: file.contentLength,
 		}
@@ -298,15 +587,16 @@ func (ctrl *Controller) getFileResponse( //nolint: ireturn,funlen,dupl
 		return api.GetFile304Response{
 			Headers: api.GetFile304ResponseHeaders{
 				CacheControl:     file.cacheControl,
-				Etag:             file.fileMetadata.Etag,
+				Etag:             file.etag,
 				SurrogateControl: file.cacheControl,
+				Vary:             file.vary,
 			},
 		}
 	case http.StatusPreconditionFailed:
 		return api.GetFile412Response{
 			Headers: api.GetFile412ResponseHeaders{
 				CacheControl:     file.cacheControl,
-				Etag:             file.fileMetadata.Etag,
+				Etag:             file.etag,
 				SurrogateControl: file.cacheControl,
 			},
 		}
@@ -342,9 +632,17 @@ func (ctrl *Controller) GetFile( //nolint:ireturn
 		return ctrl.contentStorage.GetFile(ctx, fileMetadata.Id, request.Params.Range)
 	}
 
+	headFunc := func() (*File, *APIError) {
+		return ctrl.contentStorage.GetFile(ctx, fileMetadata.Id, probeRange(fileMetadata.Size))
+	}
+
 	processedFile, apiErr := ctrl.processFileToDownload(
+		ctx,
 		downloadFunc,
+		headFunc,
+		nil,
 		fileMetadata,
+		bucketMetadata,
 		bucketMetadata.CacheControl,
 		request.Params,
 		acceptHeader,
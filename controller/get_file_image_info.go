@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/image"
+	"github.com/nhost/hasura-storage/middleware"
+	"github.com/nhost/hasura-storage/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// imageInfoMetadataKey is where the image info is kept in the metadata of the file when
+// it is computed at upload time.
+const imageInfoMetadataKey = "imageInfo"
+
+// storedImageInfo is the image info kept in the metadata of a file along with the etag
+// of the content it was computed from, so it isn't used once the file is replaced.
+type storedImageInfo struct {
+	api.ImageInfo
+
+	Etag string `json:"etag"`
+}
+
+func toAPIImageInfo(info image.Info) api.ImageInfo {
+	return api.ImageInfo{
+		Width:       info.Width,
+		Height:      info.Height,
+		Orientation: info.Orientation,
+		ColorSpace:  info.ColorSpace,
+		DominantColor: fmt.Sprintf(
+			"#%02x%02x%02x", info.DominantColor.R, info.DominantColor.G, info.DominantColor.B,
+		),
+		Blurhash: info.BlurHash,
+	}
+}
+
+// imageInfoFromMetadata returns the image info stored in the metadata of the file if it
+// was computed from its current content.
+func imageInfoFromMetadata(fileMetadata api.FileMetadata) (api.ImageInfo, bool) {
+	if fileMetadata.Metadata == nil {
+		return api.ImageInfo{}, false //nolint:exhaustruct
+	}
+
+	v, ok := (*fileMetadata.Metadata)[imageInfoMetadataKey]
+	if !ok {
+		return api.ImageInfo{}, false //nolint:exhaustruct
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return api.ImageInfo{}, false //nolint:exhaustruct
+	}
+
+	var stored storedImageInfo
+	if err := json.Unmarshal(b, &stored); err != nil || stored.Etag != fileMetadata.Etag {
+		return api.ImageInfo{}, false //nolint:exhaustruct
+	}
+
+	return stored.ImageInfo, true
+}
+
+func (ctrl *Controller) imageInfo(
+	ctx context.Context, object io.ReadCloser, size uint64,
+) (api.ImageInfo, *APIError) {
+	defer object.Close()
+
+	ctx, span := telemetry.Start(
+		ctx,
+		"image.Info",
+		attribute.Int64("image.size", int64(size)), //nolint:gosec
+	)
+
+	info, err := ctrl.imageTransformer.Info(ctx, object, size)
+	if err != nil {
+		telemetry.End(span, err)
+		return api.ImageInfo{}, imageTransformerError(err) //nolint:exhaustruct
+	}
+
+	span.End()
+
+	return toAPIImageInfo(info), nil
+}
+
+// uploadedImageInfo returns the metadata of an uploaded file with its image info added,
+// leaving it unchanged for files that aren't images.
+func (ctrl *Controller) uploadedImageInfo(
+	ctx context.Context, fileID, etag, mimeType string, metadata map[string]any,
+) (map[string]any, *APIError) {
+	if _, apiErr := mimeTypeToImageType(mimeType); apiErr != nil {
+		return metadata, nil
+	}
+
+	download, apiErr := ctrl.contentStorage.GetFile(ctx, fileID, nil)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	info, apiErr := ctrl.imageInfo(
+		ctx, download.Body, uint64(download.ContentLength), //nolint:gosec
+	)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	withInfo := make(map[string]any, len(metadata)+1)
+	for k, v := range metadata {
+		withInfo[k] = v
+	}
+
+	withInfo[imageInfoMetadataKey] = storedImageInfo{ImageInfo: info, Etag: etag}
+
+	return withInfo, nil
+}
+
+func (ctrl *Controller) GetFileImageInfo( //nolint:ireturn
+	ctx context.Context, request api.GetFileImageInfoRequestObject,
+) (api.GetFileImageInfoResponseObject, error) {
+	logger := middleware.LoggerFromContext(ctx)
+	sessionHeaders := middleware.SessionHeadersFromContext(ctx)
+
+	fileMetadata, _, apiErr := ctrl.getFileMetadata(ctx, request.Id, true, sessionHeaders)
+	if apiErr != nil {
+		logger.WithError(apiErr).Error("error getting file metadata")
+		return apiErr, nil
+	}
+
+	if _, apiErr := mimeTypeToImageType(fileMetadata.MimeType); apiErr != nil {
+		logger.WithError(apiErr).Error("file is not an image")
+		return apiErr, nil
+	}
+
+	if info, ok := imageInfoFromMetadata(fileMetadata); ok {
+		return api.GetFileImageInfo200JSONResponse(info), nil
+	}
+
+	download, apiErr := ctrl.contentStorage.GetFile(ctx, fileMetadata.Id, nil)
+	if apiErr != nil {
+		logger.WithError(apiErr).Error("error downloading file")
+		return apiErr, nil
+	}
+
+	info, apiErr := ctrl.imageInfo(
+		ctx, download.Body, uint64(download.ContentLength), //nolint:gosec
+	)
+	if apiErr != nil {
+		logger.WithError(apiErr).Error("error getting image info")
+		return apiErr, nil
+	}
+
+	return api.GetFileImageInfo200JSONResponse(info), nil
+}
@@ -0,0 +1,130 @@
+package controller_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/controller/mock"
+	"github.com/sirupsen/logrus"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestGetFileImageInfo(t *testing.T) {
+	t.Parallel()
+
+	storedInfo := map[string]any{
+		"width":         1920,
+		"height":        1080,
+		"orientation":   1,
+		"colorSpace":    "srgb",
+		"dominantColor": "#3a6ea5",
+		"blurhash":      "LEHV6nWB2yk8pyo0adR*.7kCMdnj",
+		"etag":          `"55af1e60-0f28-454e-885e-ea6aab2bb288"`,
+	}
+
+	cases := []struct {
+		name           string
+		mimeType       string
+		metadata       *map[string]any
+		expected       api.GetFileImageInfoResponseObject
+		expectedStatus int
+	}{
+		{
+			name:     "stored at upload time",
+			mimeType: "image/jpeg",
+			metadata: &map[string]any{
+				"imageInfo": storedInfo,
+			},
+			expected: api.GetFileImageInfo200JSONResponse{
+				Width:         1920,
+				Height:        1080,
+				Orientation:   1,
+				ColorSpace:    "srgb",
+				DominantColor: "#3a6ea5",
+				Blurhash:      "LEHV6nWB2yk8pyo0adR*.7kCMdnj",
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:           "not an image",
+			mimeType:       "text/plain; charset=utf-8",
+			metadata:       &map[string]any{"imageInfo": storedInfo},
+			expected:       nil,
+			expectedStatus: 400,
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			metadataStorage := mock.NewMockMetadataStorage(c)
+			contentStorage := mock.NewMockContentStorage(c)
+
+			metadataStorage.EXPECT().GetFileByID(
+				gomock.Any(), "55af1e60-0f28-454e-885e-ea6aab2bb288", gomock.Any(),
+			).Return(api.FileMetadata{
+				Id:               "55af1e60-0f28-454e-885e-ea6aab2bb288",
+				Name:             "my-file.jpg",
+				Size:             64,
+				BucketId:         "default",
+				Etag:             `"55af1e60-0f28-454e-885e-ea6aab2bb288"`,
+				CreatedAt:        time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+				UpdatedAt:        time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+				IsUploaded:       true,
+				MimeType:         tc.mimeType,
+				Metadata:         tc.metadata,
+				UploadedByUserId: nil,
+			}, nil)
+
+			metadataStorage.EXPECT().GetBucketByID(
+				gomock.Any(), "default", gomock.Any(),
+			).Return(controller.BucketMetadata{
+				ID:                   "default",
+				MinUploadFile:        0,
+				MaxUploadFile:        100,
+				PresignedURLsEnabled: true,
+				DownloadExpiration:   30,
+				CreatedAt:            "2021-12-15T13:26:52.082485+00:00",
+				UpdatedAt:            "2021-12-15T13:26:52.082485+00:00",
+				CacheControl:         "max-age=3600",
+			}, nil)
+
+			ctrl := controller.New(
+				"http://asd",
+				"/v1",
+				"asdasd",
+				metadataStorage,
+				contentStorage,
+				nil,
+				nil,
+				logger,
+			)
+
+			resp, err := ctrl.GetFileImageInfo(
+				t.Context(),
+				api.GetFileImageInfoRequestObject{
+					Id: "55af1e60-0f28-454e-885e-ea6aab2bb288",
+				},
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if apiErr, ok := resp.(*controller.APIError); ok {
+				assert(t, tc.expectedStatus, apiErr.StatusCode())
+				return
+			}
+
+			assert(t, tc.expected, resp)
+		})
+	}
+}
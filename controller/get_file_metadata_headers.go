@@ -4,15 +4,45 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"time"
 
 	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/metrics"
 	"github.com/nhost/hasura-storage/middleware"
 )
 
+// checkScanStatus makes sure the file isn't pending to be scanned for viruses or
+// infected. Files are only scanned if there is an antivirus. The status is read as admin
+// because the roles of the users downloading files may not be allowed to select it.
+func (ctrl *Controller) checkScanStatus(ctx context.Context, fileID string) *APIError {
+	if ctrl.av == nil {
+		return nil
+	}
+
+	status, apiErr := ctrl.metadataStorage.GetFileScanStatus(
+		ctx,
+		fileID,
+		http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
+	)
+	if apiErr != nil {
+		return apiErr.ExtendError("problem getting file scan status")
+	}
+
+	switch status {
+	case api.Pending:
+		msg := "file is pending to be scanned for viruses"
+		return ForbiddenError(errors.New(msg), msg) //nolint:err113
+	case api.Infected:
+		msg := "file is infected"
+		return ForbiddenError(errors.New(msg), msg) //nolint:err113
+	case api.Clean, api.Error:
+	}
+
+	return nil
+}
+
 func (ctrl *Controller) getFileMetadata(
 	ctx context.Context,
 	fileID string,
@@ -31,6 +61,12 @@ func (ctrl *Controller) getFileMetadata(
 			ForbiddenError(errors.New(msg), msg) //nolint:err113
 	}
 
+	if checkIsUploaded {
+		if apiErr := ctrl.checkScanStatus(ctx, fileID); apiErr != nil {
+			return api.FileMetadata{}, BucketMetadata{}, apiErr
+		}
+	}
+
 	bucketMetadata, apiErr := ctrl.metadataStorage.GetBucketByID(
 		ctx,
 		fileMetadata.BucketId,
@@ -40,6 +76,8 @@ func (ctrl *Controller) getFileMetadata(
 		return api.FileMetadata{}, BucketMetadata{}, apiErr
 	}
 
+	metrics.BucketToContext(ctx, bucketMetadata.ID)
+
 	return fileMetadata, bucketMetadata, nil
 }
 
@@ -63,6 +101,11 @@ type ConditionalChecksGetter interface {
 	GetIfUnmodifiedSince() *api.Time
 }
 
+func hasConditionals(params ConditionalChecksGetter) bool {
+	return params.GetIfMatch() != nil || params.GetIfNoneMatch() != nil ||
+		params.GetIfModifiedSince() != nil || params.GetIfUnmodifiedSince() != nil
+}
+
 func checkConditionals( //nolint: cyclop
 	etag string,
 	updatedAt string,
@@ -106,6 +149,7 @@ func (ctrl *Controller) getFileMetadataHeadersResponseObject( //nolint:ireturn
 	statusCode int,
 	bucketMetadata BucketMetadata,
 	fileMetadata api.FileMetadata,
+	vary string,
 ) (api.GetFileMetadataHeadersResponseObject, *APIError) {
 	switch statusCode {
 	case http.StatusOK:
@@ -118,6 +162,7 @@ func (ctrl *Controller) getFileMetadataHeadersResponseObject( //nolint:ireturn
 				LastModified:     fileMetadata.UpdatedAt,
 				SurrogateControl: bucketMetadata.CacheControl,
 				SurrogateKey:     fileMetadata.Id,
+				Vary:             vary,
 				ContentDisposition: fmt.Sprintf(
 					`inline; filename="%s"`,
 					url.QueryEscape(fileMetadata.Name),
@@ -131,6 +176,7 @@ func (ctrl *Controller) getFileMetadataHeadersResponseObject( //nolint:ireturn
 				CacheControl:     bucketMetadata.CacheControl,
 				Etag:             fileMetadata.Etag,
 				SurrogateControl: bucketMetadata.CacheControl,
+				Vary:             vary,
 			},
 		}, nil
 	case http.StatusPreconditionFailed:
@@ -159,39 +205,49 @@ func (ctrl *Controller) getFileMetadataHeaders( //nolint:ireturn
 		return nil, apiErr
 	}
 
-	updateAt := fileMetadata.UpdatedAt.Format(time.RFC1123)
+	params, apiErr := applyPreset(bucketMetadata, request.Params)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	headFunc := func() (*File, *APIError) {
+		return ctrl.contentStorage.GetFile(ctx, fileMetadata.Id, probeRange(fileMetadata.Size))
+	}
 
-	statusCode, apiErr := checkConditionals(
-		fileMetadata.Etag, updateAt, request.Params, http.StatusOK,
+	opts, apiErr := getImageManipulationOptions(
+		params, fileMetadata.MimeType, acceptHeader, headFunc,
 	)
 	if apiErr != nil {
 		return nil, apiErr
 	}
 
-	opts, apiErr := getImageManipulationOptions(request.Params, fileMetadata.MimeType, acceptHeader)
+	etag, vary := representation(fileMetadata, params, opts)
+
+	statusCode, apiErr := checkConditionals(
+		etag, fileMetadata.UpdatedAt.Format(time.RFC1123), params, http.StatusOK,
+	)
 	if apiErr != nil {
 		return nil, apiErr
 	}
 
-	if !opts.IsEmpty() {
-		download, apiErr := ctrl.contentStorage.GetFile(ctx, fileMetadata.Id, nil)
-		if apiErr != nil {
-			return nil, apiErr
+	// the size of a transformed image is only known after transforming it
+	if statusCode == http.StatusOK && !opts.IsEmpty() {
+		downloadFunc := func() (*File, *APIError) {
+			return ctrl.contentStorage.GetFile(ctx, fileMetadata.Id, nil)
 		}
-		defer download.Body.Close()
-
-		var object io.ReadCloser
 
-		object, fileMetadata.Size, apiErr = ctrl.manipulateImage(
-			download.Body, uint64(fileMetadata.Size), opts, //nolint:gosec
-		)
+		b, apiErr := ctrl.transformImage(ctx, downloadFunc, fileMetadata, opts)
 		if apiErr != nil {
 			return nil, apiErr
 		}
-		defer object.Close()
+
+		fileMetadata.Size = int64(len(b))
 	}
 
-	return ctrl.getFileMetadataHeadersResponseObject(statusCode, bucketMetadata, fileMetadata)
+	fileMetadata.Name, fileMetadata.MimeType = getFileNameAndMimeType(fileMetadata, opts)
+	fileMetadata.Etag = etag
+
+	return ctrl.getFileMetadataHeadersResponseObject(statusCode, bucketMetadata, fileMetadata, vary)
 }
 
 func (ctrl *Controller) GetFileMetadataHeaders( //nolint:ireturn
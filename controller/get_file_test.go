@@ -1,15 +1,23 @@
 package controller_test
 
 import (
+	"bytes"
+	"fmt"
+	goimage "image"
+	"image/color"
+	"image/gif"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/nhost/hasura-storage/api"
 	"github.com/nhost/hasura-storage/controller"
 	"github.com/nhost/hasura-storage/controller/mock"
+	"github.com/nhost/hasura-storage/middleware"
 	"github.com/sirupsen/logrus"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -241,20 +249,23 @@ func TestGetFile(t *testing.T) { //nolint:maintidx
 				CacheControl:         "max-age=3600",
 			}, nil)
 
-			contentStorage.EXPECT().GetFile(
-				gomock.Any(),
-				"55af1e60-0f28-454e-885e-ea6aab2bb288",
-				gomock.Any(),
-			).Return(
-				&controller.File{
-					StatusCode:    200,
-					Etag:          `"55af1e60-0f28-454e-885e-ea6aab2bb288"`,
-					Body:          io.NopCloser(strings.NewReader("Hello, world!")),
-					ContentLength: 64,
-					ExtraHeaders:  make(http.Header),
-				},
-				nil,
-			)
+			// the content isn't downloaded when the conditionals fail
+			if _, ok := tc.expected.(api.GetFile200ApplicationoctetStreamResponse); ok {
+				contentStorage.EXPECT().GetFile(
+					gomock.Any(),
+					"55af1e60-0f28-454e-885e-ea6aab2bb288",
+					gomock.Any(),
+				).Return(
+					&controller.File{
+						StatusCode:    200,
+						Etag:          `"55af1e60-0f28-454e-885e-ea6aab2bb288"`,
+						Body:          io.NopCloser(strings.NewReader("Hello, world!")),
+						ContentLength: 64,
+						ExtraHeaders:  make(http.Header),
+					},
+					nil,
+				)
+			}
 
 			ctrl := controller.New(
 				"http://asd",
@@ -299,3 +310,634 @@ func TestGetFile(t *testing.T) { //nolint:maintidx
 		})
 	}
 }
+
+func TestGetFileInvalidImageOptions(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		params   api.GetFileParams
+		expected string
+	}{
+		{
+			name:     "empty crop",
+			params:   api.GetFileParams{Crop: ptr("10,10,0,100")},
+			expected: "crop must be x,y,width,height with a width and height bigger than 0",
+		},
+		{
+			name:     "wrong background",
+			params:   api.GetFileParams{Fit: ptr(api.Contain), Background: ptr("fff")},
+			expected: "background must be an hex color, rrggbb or rrggbbaa",
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			metadataStorage := mock.NewMockMetadataStorage(c)
+
+			metadataStorage.EXPECT().GetFileByID(
+				gomock.Any(), "55af1e60-0f28-454e-885e-ea6aab2bb288", gomock.Any(),
+			).Return(api.FileMetadata{
+				Id:         "55af1e60-0f28-454e-885e-ea6aab2bb288",
+				Name:       "my-image.png",
+				Size:       64,
+				BucketId:   "default",
+				Etag:       "\"55af1e60-0f28-454e-885e-ea6aab2bb288\"",
+				CreatedAt:  time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+				UpdatedAt:  time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+				IsUploaded: true,
+				MimeType:   "image/png",
+			}, nil)
+
+			metadataStorage.EXPECT().GetBucketByID(
+				gomock.Any(), "default", gomock.Any(),
+			).Return(controller.BucketMetadata{
+				ID:           "default",
+				CacheControl: "max-age=3600",
+			}, nil)
+
+			ctrl := controller.New(
+				"http://asd",
+				"/v1",
+				"asdasd",
+				metadataStorage,
+				mock.NewMockContentStorage(c),
+				nil,
+				nil,
+				logger,
+			)
+
+			resp, err := ctrl.GetFile(
+				t.Context(),
+				api.GetFileRequestObject{
+					Id:     "55af1e60-0f28-454e-885e-ea6aab2bb288",
+					Params: tc.params,
+				},
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			apiErr, ok := resp.(*controller.APIError)
+			if !ok {
+				t.Fatalf("expected *controller.APIError, got %T", resp)
+			}
+
+			assert(t, apiErr.StatusCode(), http.StatusBadRequest)
+			assert(t, apiErr.PublicMessage(), tc.expected)
+		})
+	}
+}
+
+func TestGetFileCachedImage(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	metadataStorage := mock.NewMockMetadataStorage(c)
+
+	metadataStorage.EXPECT().GetFileByID(
+		gomock.Any(), "55af1e60-0f28-454e-885e-ea6aab2bb288", gomock.Any(),
+	).Return(api.FileMetadata{
+		Id:         "55af1e60-0f28-454e-885e-ea6aab2bb288",
+		Name:       "my-image.png",
+		Size:       64,
+		BucketId:   "default",
+		Etag:       "\"55af1e60-0f28-454e-885e-ea6aab2bb288\"",
+		CreatedAt:  time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+		UpdatedAt:  time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+		IsUploaded: true,
+		MimeType:   "image/png",
+	}, nil)
+
+	metadataStorage.EXPECT().GetBucketByID(
+		gomock.Any(), "default", gomock.Any(),
+	).Return(controller.BucketMetadata{
+		ID:           "default",
+		CacheControl: "max-age=3600",
+	}, nil)
+
+	// the content storage isn't expected to be called as the image is already cached
+	imageCache := mock.NewMockImageCache(c)
+	imageCache.EXPECT().Get(
+		gomock.Any(), "55af1e60-0f28-454e-885e-ea6aab2bb288", gomock.Any(),
+	).Return([]byte("transformed image"), true)
+
+	ctrl := controller.New(
+		"http://asd",
+		"/v1",
+		"asdasd",
+		metadataStorage,
+		mock.NewMockContentStorage(c),
+		nil,
+		nil,
+		logger,
+		controller.WithImageCache(imageCache),
+	)
+
+	resp, err := ctrl.GetFile(
+		t.Context(),
+		api.GetFileRequestObject{
+			Id:     "55af1e60-0f28-454e-885e-ea6aab2bb288",
+			Params: api.GetFileParams{W: ptr(100)},
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, ok := resp.(api.GetFile200ApplicationoctetStreamResponse)
+	if !ok {
+		t.Fatalf("expected api.GetFile200ApplicationoctetStreamResponse, got %T", resp)
+	}
+
+	b, err := io.ReadAll(got.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert(t, string(b), "transformed image")
+	assert(t, got.ContentLength, int64(len("transformed image")))
+}
+
+func TestGetFileTransformedImageConditionals(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	fileMetadata := api.FileMetadata{ //nolint:exhaustruct
+		Id:         "55af1e60-0f28-454e-885e-ea6aab2bb288",
+		Name:       "my-image.png",
+		Size:       64,
+		BucketId:   "default",
+		Etag:       "\"55af1e60-0f28-454e-885e-ea6aab2bb288\"",
+		CreatedAt:  time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+		UpdatedAt:  time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+		IsUploaded: true,
+		MimeType:   "image/png",
+	}
+
+	// cached says whether the transformed image is expected to be requested
+	getFile := func(
+		t *testing.T, params api.GetFileParams, accept string, cached bool,
+	) api.GetFileResponseObject {
+		t.Helper()
+
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		metadataStorage := mock.NewMockMetadataStorage(c)
+		metadataStorage.EXPECT().GetFileByID(
+			gomock.Any(), fileMetadata.Id, gomock.Any(),
+		).Return(fileMetadata, nil)
+		metadataStorage.EXPECT().GetBucketByID(
+			gomock.Any(), "default", gomock.Any(),
+		).Return(controller.BucketMetadata{ //nolint:exhaustruct
+			ID:           "default",
+			CacheControl: "max-age=3600",
+		}, nil)
+
+		imageCache := mock.NewMockImageCache(c)
+		if cached {
+			imageCache.EXPECT().Get(
+				gomock.Any(), fileMetadata.Id, gomock.Any(),
+			).Return([]byte("transformed image"), true)
+		}
+
+		ctrl := controller.New(
+			"http://asd",
+			"/v1",
+			"asdasd",
+			metadataStorage,
+			mock.NewMockContentStorage(c),
+			nil,
+			nil,
+			logger,
+			controller.WithImageCache(imageCache),
+		)
+
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequestWithContext(
+			t.Context(), http.MethodGet, "/v1/files/"+fileMetadata.Id, nil,
+		)
+		ctx.Set(middleware.HeadersContextKey, http.Header{"Accept": []string{accept}})
+
+		resp, err := ctrl.GetFile(
+			ctx, api.GetFileRequestObject{Id: fileMetadata.Id, Params: params},
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		return resp
+	}
+
+	etagOf := func(t *testing.T, resp api.GetFileResponseObject) string {
+		t.Helper()
+
+		got, ok := resp.(api.GetFile200ApplicationoctetStreamResponse)
+		if !ok {
+			t.Fatalf("expected api.GetFile200ApplicationoctetStreamResponse, got %T", resp)
+		}
+
+		return got.Headers.Etag
+	}
+
+	resized := etagOf(t, getFile(t, api.GetFileParams{W: ptr(100)}, "", true))
+	if resized == fileMetadata.Etag {
+		t.Errorf("expected the transformed image to have its own etag, got %s", resized)
+	}
+
+	if etag := etagOf(
+		t, getFile(t, api.GetFileParams{W: ptr(200)}, "", true),
+	); etag == resized {
+		t.Errorf("expected different transformations to have different etags, got %s", etag)
+	}
+
+	t.Run("original etag", func(t *testing.T) {
+		t.Parallel()
+
+		resp := getFile(
+			t, api.GetFileParams{W: ptr(100), IfNoneMatch: ptr(fileMetadata.Etag)}, "", true,
+		)
+		assert(t, etagOf(t, resp), resized)
+	})
+
+	t.Run("transformation etag", func(t *testing.T) {
+		t.Parallel()
+
+		resp := getFile(t, api.GetFileParams{W: ptr(100), IfNoneMatch: ptr(resized)}, "", false)
+		assert(t, resp, api.GetFile304Response{
+			Headers: api.GetFile304ResponseHeaders{
+				CacheControl:     "max-age=3600",
+				Etag:             resized,
+				SurrogateControl: "max-age=3600",
+				Vary:             "",
+			},
+		})
+	})
+
+	t.Run("not modified since", func(t *testing.T) {
+		t.Parallel()
+
+		resp := getFile(
+			t,
+			api.GetFileParams{
+				W:               ptr(100),
+				IfModifiedSince: ptr(api.Date(2024, 1, 25, 10, 0, 0, 0, time.UTC)),
+			},
+			"",
+			false,
+		)
+		if _, ok := resp.(api.GetFile304Response); !ok {
+			t.Errorf("expected api.GetFile304Response, got %T", resp)
+		}
+	})
+
+	t.Run("format from accept", func(t *testing.T) {
+		t.Parallel()
+
+		webp := getFile(t, api.GetFileParams{W: ptr(100), F: ptr(api.Auto)}, "image/webp", true)
+		avif := getFile(t, api.GetFileParams{W: ptr(100), F: ptr(api.Auto)}, "image/avif", true)
+
+		if etagOf(t, webp) == etagOf(t, avif) {
+			t.Errorf("expected different formats to have different etags")
+		}
+
+		got, _ := webp.(api.GetFile200ApplicationoctetStreamResponse)
+		assert(t, got.Headers.Vary, "Accept")
+		assert(t, got.Headers.ContentType, "image/webp")
+	})
+
+	t.Run("format from accept quality", func(t *testing.T) {
+		t.Parallel()
+
+		cases := []struct {
+			accept   string
+			expected string
+		}{
+			{accept: "image/avif;q=0.5,image/webp", expected: "image/webp"},
+			{accept: "image/webp,image/avif", expected: "image/avif"},
+			{accept: "image/avif;q=0,image/webp;q=0.8", expected: "image/webp"},
+			{accept: "image/jxl, image/png;q=0.9", expected: "image/jxl"},
+			{accept: "image/avif;q=0.2,*/*;q=0.8", expected: "image/png"},
+			{accept: "image/avif;q=0,*/*", expected: "image/png"},
+			{accept: "text/html", expected: "image/png"},
+		}
+
+		for _, tc := range cases {
+			resp := getFile(t, api.GetFileParams{W: ptr(100), F: ptr(api.Auto)}, tc.accept, true)
+
+			got, _ := resp.(api.GetFile200ApplicationoctetStreamResponse)
+			if got.Headers.ContentType != tc.expected {
+				t.Errorf(
+					"accept %q: expected %s, got %s",
+					tc.accept, tc.expected, got.Headers.ContentType,
+				)
+			}
+		}
+	})
+}
+
+func TestGetFileAutoFormatAnimation(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	encodeGIF := func(t *testing.T, frames int) []byte {
+		t.Helper()
+
+		anim := &gif.GIF{} //nolint:exhaustruct
+		for range frames {
+			anim.Image = append(anim.Image, goimage.NewPaletted(
+				goimage.Rect(0, 0, 4, 4), color.Palette{color.White, color.Black},
+			))
+			anim.Delay = append(anim.Delay, 10) //nolint:mnd
+		}
+
+		buf := &bytes.Buffer{}
+		if err := gif.EncodeAll(buf, anim); err != nil {
+			t.Fatal(err)
+		}
+
+		return buf.Bytes()
+	}
+
+	cases := []struct {
+		name     string
+		frames   int
+		expected string
+	}{
+		{
+			name:     "static",
+			frames:   1,
+			expected: "image/avif",
+		},
+		{
+			// animations are only served in formats that keep them
+			name:     "animated",
+			frames:   3,
+			expected: "image/webp",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.ErrorLevel)
+
+			content := encodeGIF(t, tc.frames)
+
+			fileMetadata := api.FileMetadata{ //nolint:exhaustruct
+				Id:         "55af1e60-0f28-454e-885e-ea6aab2bb288",
+				Name:       "my-image.gif",
+				Size:       int64(len(content)),
+				BucketId:   "default",
+				Etag:       "\"55af1e60-0f28-454e-885e-ea6aab2bb288\"",
+				CreatedAt:  time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+				UpdatedAt:  time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+				IsUploaded: true,
+				MimeType:   "image/gif",
+			}
+
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			metadataStorage := mock.NewMockMetadataStorage(c)
+			metadataStorage.EXPECT().GetFileByID(
+				gomock.Any(), fileMetadata.Id, gomock.Any(),
+			).Return(fileMetadata, nil)
+			metadataStorage.EXPECT().GetBucketByID(
+				gomock.Any(), "default", gomock.Any(),
+			).Return(controller.BucketMetadata{ //nolint:exhaustruct
+				ID:           "default",
+				CacheControl: "max-age=3600",
+			}, nil)
+
+			// the beginning of the image tells whether it is animated
+			contentStorage := mock.NewMockContentStorage(c)
+			contentStorage.EXPECT().GetFile(
+				gomock.Any(), fileMetadata.Id, ptr(fmt.Sprintf("bytes=0-%d", len(content)-1)),
+			).Return(&controller.File{ //nolint:exhaustruct
+				StatusCode: http.StatusPartialContent,
+				Body:       io.NopCloser(bytes.NewReader(content)),
+			}, nil)
+
+			imageCache := mock.NewMockImageCache(c)
+			imageCache.EXPECT().Get(
+				gomock.Any(), fileMetadata.Id, gomock.Any(),
+			).Return([]byte("transformed image"), true)
+
+			ctrl := controller.New(
+				"http://asd",
+				"/v1",
+				"asdasd",
+				metadataStorage,
+				contentStorage,
+				nil,
+				nil,
+				logger,
+				controller.WithImageCache(imageCache),
+			)
+
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequestWithContext(
+				t.Context(), http.MethodGet, "/v1/files/"+fileMetadata.Id, nil,
+			)
+			ctx.Set(
+				middleware.HeadersContextKey,
+				http.Header{"Accept": []string{"image/avif,image/webp;q=0.8,image/*;q=0.5"}},
+			)
+
+			resp, err := ctrl.GetFile(ctx, api.GetFileRequestObject{
+				Id:     fileMetadata.Id,
+				Params: api.GetFileParams{W: ptr(2), F: ptr(api.Auto)}, //nolint:exhaustruct
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, ok := resp.(api.GetFile200ApplicationoctetStreamResponse)
+			if !ok {
+				t.Fatalf("expected api.GetFile200ApplicationoctetStreamResponse, got %T", resp)
+			}
+
+			assert(t, got.Headers.ContentType, tc.expected)
+		})
+	}
+}
+
+func TestGetFilePresets(t *testing.T) {
+	t.Parallel()
+
+	presets := map[string]controller.ImagePreset{
+		"avatar-128": {W: ptr(128), H: ptr(128), Fit: ptr(api.Cover)}, //nolint:exhaustruct
+	}
+
+	cases := []struct {
+		name        string
+		presetsOnly bool
+		params      api.GetFileParams
+		// sameAs are free-form parameters producing the same image, nil if the request fails
+		sameAs        *api.GetFileParams
+		expectedError string
+	}{
+		{
+			name:          "preset",
+			presetsOnly:   false,
+			params:        api.GetFileParams{Preset: ptr("avatar-128")},
+			sameAs:        &api.GetFileParams{W: ptr(128), H: ptr(128)},
+			expectedError: "",
+		},
+		{
+			name:          "parameters override the preset",
+			presetsOnly:   false,
+			params:        api.GetFileParams{Preset: ptr("avatar-128"), W: ptr(64)},
+			sameAs:        &api.GetFileParams{W: ptr(64), H: ptr(128)},
+			expectedError: "",
+		},
+		{
+			name:          "unknown preset",
+			presetsOnly:   false,
+			params:        api.GetFileParams{Preset: ptr("banner")},
+			sameAs:        nil,
+			expectedError: "unknown preset: banner",
+		},
+		{
+			name:        "presets only with a preset",
+			presetsOnly: true,
+			params:      api.GetFileParams{Preset: ptr("avatar-128"), F: ptr(api.Webp)},
+			sameAs: &api.GetFileParams{
+				W: ptr(128), H: ptr(128), F: ptr(api.Webp),
+			},
+			expectedError: "",
+		},
+		{
+			name:        "presets only stripping the metadata",
+			presetsOnly: true,
+			params: api.GetFileParams{
+				Preset: ptr("avatar-128"), Strip: ptr(true),
+			},
+			sameAs: &api.GetFileParams{
+				W: ptr(128), H: ptr(128), Strip: ptr(true),
+			},
+			expectedError: "",
+		},
+		{
+			name:          "presets only with free-form parameters",
+			presetsOnly:   true,
+			params:        api.GetFileParams{W: ptr(100)},
+			sameAs:        nil,
+			expectedError: "this bucket only allows image transformations with a preset",
+		},
+		{
+			name:          "presets only overriding the preset",
+			presetsOnly:   true,
+			params:        api.GetFileParams{Preset: ptr("avatar-128"), Q: ptr(10)},
+			sameAs:        nil,
+			expectedError: "this bucket only allows image transformations with a preset",
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	getFile := func(
+		t *testing.T, params api.GetFileParams, presetsOnly bool,
+	) api.GetFileResponseObject {
+		t.Helper()
+
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		metadataStorage := mock.NewMockMetadataStorage(c)
+		metadataStorage.EXPECT().GetFileByID(
+			gomock.Any(), "55af1e60-0f28-454e-885e-ea6aab2bb288", gomock.Any(),
+		).Return(api.FileMetadata{ //nolint:exhaustruct
+			Id:         "55af1e60-0f28-454e-885e-ea6aab2bb288",
+			Name:       "my-image.png",
+			Size:       64,
+			BucketId:   "default",
+			Etag:       "\"55af1e60-0f28-454e-885e-ea6aab2bb288\"",
+			UpdatedAt:  time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+			IsUploaded: true,
+			MimeType:   "image/png",
+		}, nil)
+		metadataStorage.EXPECT().GetBucketByID(
+			gomock.Any(), "default", gomock.Any(),
+		).Return(controller.BucketMetadata{ //nolint:exhaustruct
+			ID:           "default",
+			CacheControl: "max-age=3600",
+			PresetsOnly:  presetsOnly,
+			Presets:      presets,
+		}, nil)
+
+		imageCache := mock.NewMockImageCache(c)
+		imageCache.EXPECT().Get(
+			gomock.Any(), "55af1e60-0f28-454e-885e-ea6aab2bb288", gomock.Any(),
+		).Return([]byte("transformed image"), true).AnyTimes()
+
+		ctrl := controller.New(
+			"http://asd",
+			"/v1",
+			"asdasd",
+			metadataStorage,
+			mock.NewMockContentStorage(c),
+			nil,
+			nil,
+			logger,
+			controller.WithImageCache(imageCache),
+		)
+
+		resp, err := ctrl.GetFile(
+			t.Context(),
+			api.GetFileRequestObject{Id: "55af1e60-0f28-454e-885e-ea6aab2bb288", Params: params},
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		return resp
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := getFile(t, tc.params, tc.presetsOnly)
+
+			if tc.sameAs == nil {
+				apiErr, ok := resp.(*controller.APIError)
+				if !ok {
+					t.Fatalf("expected *controller.APIError, got %T", resp)
+				}
+
+				assert(t, apiErr.StatusCode(), http.StatusBadRequest)
+				assert(t, apiErr.PublicMessage(), tc.expectedError)
+
+				return
+			}
+
+			got, ok := resp.(api.GetFile200ApplicationoctetStreamResponse)
+			if !ok {
+				t.Fatalf("expected api.GetFile200ApplicationoctetStreamResponse, got %T", resp)
+			}
+
+			expected, _ := getFile(t, *tc.sameAs, false).(api.GetFile200ApplicationoctetStreamResponse)
+			assert(t, got.Headers, expected.Headers)
+		})
+	}
+}
@@ -23,6 +23,7 @@ type File struct {
 	ContentType   string
 	ContentLength int64
 	Etag          string
+	LastModified  time.Time
 	StatusCode    int
 	Body          io.ReadCloser
 	ExtraHeaders  http.Header
@@ -80,6 +81,32 @@ func getAmazonSignature(request api.GetFileWithPresignedURLRequestObject) string
 	)
 }
 
+// checkPresignedURL makes sure the signature of the request is valid by downloading the
+// first byte of the file. The content storage checks it when the file is downloaded,
+// this is for responses that are served without downloading it.
+func (ctrl *Controller) checkPresignedURL(
+	ctx context.Context,
+	request api.GetFileWithPresignedURLRequestObject,
+	fileMetadata api.FileMetadata,
+) *APIError {
+	// empty files can't be requested with a range
+	var headers http.Header
+	if fileMetadata.Size > 0 {
+		headers = http.Header{"Range": []string{"bytes=0-0"}}
+	}
+
+	file, apiErr := ctrl.contentStorage.GetFileWithPresignedURL(
+		ctx, request.Id, getAmazonSignature(request), headers,
+	)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	file.Body.Close()
+
+	return nil
+}
+
 func (ctrl *Controller) getFileWithPresignedURLResponseObject( //nolint: ireturn,funlen,dupl
 	file *processedFile,
 	logger logrus.FieldLogger,
@@ -96,10 +123,11 @@ func (ctrl *Controller) getFileWithPresignedURLResponseObject( //nolint: ireturn
 					url.QueryEscape(file.filename),
 				),
 				ContentType:      file.mimeType,
-				Etag:             file.fileMetadata.Etag,
+				Etag:             file.etag,
 				LastModified:     file.fileMetadata.UpdatedAt,
 				SurrogateControl: file.cacheControl,
 				SurrogateKey:     file.fileMetadata.Id,
+				Vary:             file.vary,
 			},
 			ContentLength: file.contentLength,
 		}
@@ -114,10 +142,11 @@ func (ctrl *Controller) getFileWithPresignedURLResponseObject( //nolint: ireturn
 				),
 				ContentRange:     file.extraHeaders.Get("Content-Range"),
 				ContentType:      file.mimeType,
-				Etag:             file.fileMetadata.Etag,
+				Etag:             file.etag,
 				LastModified:     file.fileMetadata.UpdatedAt,
 				SurrogateControl: file.cacheControl,
 				SurrogateKey:     file.fileMetadata.Id,
+				Vary:             file.vary,
 			},
 			ContentLength: file.contentLength,
 		}
@@ -125,15 +154,16 @@ func (ctrl *Controller) getFileWithPresignedURLResponseObject( //nolint: ireturn
 		return api.GetFileWithPresignedURL304Response{
 			Headers: api.GetFileWithPresignedURL304ResponseHeaders{
 				CacheControl:     file.cacheControl,
-				Etag:             file.fileMetadata.Etag,
+				Etag:             file.etag,
 				SurrogateControl: file.cacheControl,
+				Vary:             file.vary,
 			},
 		}
 	case http.StatusPreconditionFailed:
 		return api.GetFileWithPresignedURL412Response{
 			Headers: api.GetFileWithPresignedURL412ResponseHeaders{
 				CacheControl:     file.cacheControl,
-				Etag:             file.fileMetadata.Etag,
+				Etag:             file.etag,
 				SurrogateControl: file.cacheControl,
 			},
 		}
@@ -152,7 +182,7 @@ func (ctrl *Controller) GetFileWithPresignedURL( //nolint: ireturn
 	logger := middleware.LoggerFromContext(ctx)
 	acceptHeader := middleware.AcceptHeaderFromContext(ctx)
 
-	fileMetadata, _, apiErr := ctrl.getFileMetadata(
+	fileMetadata, bucketMetadata, apiErr := ctrl.getFileMetadata(
 		ctx,
 		request.Id,
 		true,
@@ -185,9 +215,26 @@ func (ctrl *Controller) GetFileWithPresignedURL( //nolint: ireturn
 		)
 	}
 
+	headFunc := func() (*File, *APIError) {
+		var headers http.Header
+		if downloadRange := probeRange(fileMetadata.Size); downloadRange != nil {
+			headers = http.Header{"Range": []string{*downloadRange}}
+		}
+
+		return ctrl.contentStorage.GetFileWithPresignedURL(
+			ctx, request.Id, getAmazonSignature(request), headers,
+		)
+	}
+
 	processedFile, apiErr := ctrl.processFileToDownload(
+		ctx,
 		downloadFunc,
+		headFunc,
+		func() *APIError {
+			return ctrl.checkPresignedURL(ctx, request, fileMetadata)
+		},
 		fileMetadata,
+		bucketMetadata,
 		fmt.Sprintf("max-age=%d", expires),
 		request.Params,
 		acceptHeader,
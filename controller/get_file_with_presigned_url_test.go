@@ -0,0 +1,271 @@
+package controller_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/controller/mock"
+	"github.com/sirupsen/logrus"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestGetFileWithPresignedURLCachedImage(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	fileMetadata := api.FileMetadata{ //nolint:exhaustruct
+		Id:         "55af1e60-0f28-454e-885e-ea6aab2bb288",
+		Name:       "my-image.png",
+		Size:       64,
+		BucketId:   "default",
+		Etag:       "\"55af1e60-0f28-454e-885e-ea6aab2bb288\"",
+		CreatedAt:  time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+		UpdatedAt:  time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+		IsUploaded: true,
+		MimeType:   "image/png",
+	}
+
+	cases := []struct {
+		name           string
+		signatureError *controller.APIError
+		expectedStatus int
+	}{
+		{
+			name:           "valid signature",
+			signatureError: nil,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "bad signature",
+			signatureError: controller.NewAPIError(
+				http.StatusForbidden,
+				"The request signature we calculated does not match the signature you provided.",
+				errors.New("signature mismatch"), //nolint:err113
+				nil,
+			),
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.ErrorLevel)
+
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			metadataStorage := mock.NewMockMetadataStorage(c)
+			metadataStorage.EXPECT().GetFileByID(
+				gomock.Any(), fileMetadata.Id, gomock.Any(),
+			).Return(fileMetadata, nil)
+			metadataStorage.EXPECT().GetBucketByID(
+				gomock.Any(), "default", gomock.Any(),
+			).Return(controller.BucketMetadata{ //nolint:exhaustruct
+				ID:           "default",
+				CacheControl: "max-age=3600",
+			}, nil)
+
+			// the signature is checked with the first byte of the file even though the
+			// transformed image is cached
+			contentStorage := mock.NewMockContentStorage(c)
+			check := contentStorage.EXPECT().GetFileWithPresignedURL(
+				gomock.Any(), fileMetadata.Id, gomock.Any(),
+				http.Header{"Range": []string{"bytes=0-0"}},
+			)
+
+			imageCache := mock.NewMockImageCache(c)
+
+			if tc.signatureError != nil {
+				check.Return(nil, tc.signatureError)
+			} else {
+				check.Return(&controller.File{ //nolint:exhaustruct
+					StatusCode: http.StatusPartialContent,
+					Body:       io.NopCloser(strings.NewReader("x")),
+				}, nil)
+				imageCache.EXPECT().Get(
+					gomock.Any(), fileMetadata.Id, gomock.Any(),
+				).Return([]byte("transformed image"), true)
+			}
+
+			ctrl := controller.New(
+				"http://asd",
+				"/v1",
+				"asdasd",
+				metadataStorage,
+				contentStorage,
+				nil,
+				nil,
+				logger,
+				controller.WithImageCache(imageCache),
+			)
+
+			resp, err := ctrl.GetFileWithPresignedURL(
+				t.Context(),
+				api.GetFileWithPresignedURLRequestObject{
+					Id: fileMetadata.Id,
+					Params: api.GetFileWithPresignedURLParams{ //nolint:exhaustruct
+						XAmzDate:      time.Now().UTC().Format("20060102T150405Z"),
+						XAmzExpires:   "3600",
+						XAmzSignature: "made-up",
+						W:             ptr(100),
+					},
+				},
+			)
+
+			if tc.expectedStatus != http.StatusOK {
+				apiErr := &controller.APIError{} //nolint:exhaustruct
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("expected an APIError, got %v", err)
+				}
+
+				assert(t, tc.expectedStatus, apiErr.StatusCode())
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, ok := resp.(api.GetFileWithPresignedURL200ApplicationoctetStreamResponse)
+			if !ok {
+				t.Fatalf("unexpected response: %T", resp)
+			}
+
+			b, err := io.ReadAll(got.Body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			assert(t, "transformed image", string(b))
+		})
+	}
+}
+
+func TestGetFileWithPresignedURLConditionals(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	fileMetadata := api.FileMetadata{ //nolint:exhaustruct
+		Id:         "55af1e60-0f28-454e-885e-ea6aab2bb288",
+		Name:       "my-file.txt",
+		Size:       64,
+		BucketId:   "default",
+		Etag:       "\"55af1e60-0f28-454e-885e-ea6aab2bb288\"",
+		CreatedAt:  time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+		UpdatedAt:  time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+		IsUploaded: true,
+		MimeType:   "text/plain",
+	}
+
+	cases := []struct {
+		name           string
+		signatureError *controller.APIError
+		expectedStatus int
+	}{
+		{
+			name:           "valid signature",
+			signatureError: nil,
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name: "bad signature",
+			signatureError: controller.NewAPIError(
+				http.StatusForbidden,
+				"The request signature we calculated does not match the signature you provided.",
+				errors.New("signature mismatch"), //nolint:err113
+				nil,
+			),
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.ErrorLevel)
+
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			metadataStorage := mock.NewMockMetadataStorage(c)
+			metadataStorage.EXPECT().GetFileByID(
+				gomock.Any(), fileMetadata.Id, gomock.Any(),
+			).Return(fileMetadata, nil)
+			metadataStorage.EXPECT().GetBucketByID(
+				gomock.Any(), "default", gomock.Any(),
+			).Return(controller.BucketMetadata{ //nolint:exhaustruct
+				ID:           "default",
+				CacheControl: "max-age=3600",
+			}, nil)
+
+			// the file isn't downloaded to answer with 304, the signature is still checked
+			contentStorage := mock.NewMockContentStorage(c)
+			check := contentStorage.EXPECT().GetFileWithPresignedURL(
+				gomock.Any(), fileMetadata.Id, gomock.Any(),
+				http.Header{"Range": []string{"bytes=0-0"}},
+			)
+
+			if tc.signatureError != nil {
+				check.Return(nil, tc.signatureError)
+			} else {
+				check.Return(&controller.File{ //nolint:exhaustruct
+					StatusCode: http.StatusPartialContent,
+					Body:       io.NopCloser(strings.NewReader("x")),
+				}, nil)
+			}
+
+			ctrl := controller.New(
+				"http://asd",
+				"/v1",
+				"asdasd",
+				metadataStorage,
+				contentStorage,
+				nil,
+				nil,
+				logger,
+			)
+
+			resp, err := ctrl.GetFileWithPresignedURL(
+				t.Context(),
+				api.GetFileWithPresignedURLRequestObject{
+					Id: fileMetadata.Id,
+					Params: api.GetFileWithPresignedURLParams{ //nolint:exhaustruct
+						XAmzDate:      time.Now().UTC().Format("20060102T150405Z"),
+						XAmzExpires:   "3600",
+						XAmzSignature: "made-up",
+						IfNoneMatch:   ptr(fileMetadata.Etag),
+					},
+				},
+			)
+
+			if tc.expectedStatus != http.StatusNotModified {
+				apiErr := &controller.APIError{} //nolint:exhaustruct
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("expected an APIError, got %v", err)
+				}
+
+				assert(t, tc.expectedStatus, apiErr.StatusCode())
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, ok := resp.(api.GetFileWithPresignedURL304Response); !ok {
+				t.Fatalf("unexpected response: %T", resp)
+			}
+		})
+	}
+}
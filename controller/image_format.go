@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/nhost/hasura-storage/image"
+)
+
+// sameFormat returns the format images are served in when they keep their format. The
+// formats that can only be read are converted to the closest one that can be written.
+func sameFormat(original image.ImageType) image.ImageType {
+	switch original { //nolint:exhaustive
+	case image.ImageTypeHEIF:
+		return image.ImageTypeJPEG
+	case image.ImageTypeSVG:
+		return image.ImageTypePNG
+	}
+
+	return original
+}
+
+// parseAccept returns the quality of each media range of the Accept headers. Ranges
+// with an invalid quality are ignored.
+func parseAccept(acceptHeader []string) map[string]float64 {
+	accepted := make(map[string]float64)
+
+	for _, header := range acceptHeader {
+		for mediaRange := range strings.SplitSeq(header, ",") {
+			mimeType, params, _ := strings.Cut(mediaRange, ";")
+
+			mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+			if mimeType == "" {
+				continue
+			}
+
+			q := 1.0
+			valid := true
+
+			for param := range strings.SplitSeq(params, ";") {
+				k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(k, "q") {
+					continue
+				}
+
+				f, err := strconv.ParseFloat(v, 64)
+				if err != nil || f < 0 || f > 1 {
+					valid = false
+					break
+				}
+
+				q = f
+			}
+
+			if valid {
+				accepted[mimeType] = max(accepted[mimeType], q)
+			}
+		}
+	}
+
+	return accepted
+}
+
+// probeRange returns the range of the file needed to tell whether it is animated, nil
+// for empty files as they can't be requested with a range.
+func probeRange(size int64) *string {
+	if size <= 0 {
+		return nil
+	}
+
+	downloadRange := fmt.Sprintf("bytes=0-%d", min(size, image.AnimationProbeSize)-1)
+
+	return &downloadRange
+}
+
+// isAnimatedImage returns whether the image is animated, downloading its beginning with
+// headFunc if its format supports animations.
+func isAnimatedImage(original image.ImageType, headFunc getFileFunc) (bool, *APIError) {
+	if !original.SupportsAnimation() {
+		return false, nil
+	}
+
+	head, apiErr := headFunc()
+	if apiErr != nil {
+		return false, apiErr.ExtendError("problem getting beginning of image")
+	}
+	defer head.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(head.Body, image.AnimationProbeSize))
+	if err != nil {
+		return false, InternalServerError(fmt.Errorf("problem reading image: %w", err))
+	}
+
+	return image.IsAnimated(original, b), nil
+}
+
+// negotiateImageFormat picks the format with the highest quality in the Accept headers,
+// preferring the most efficient one in case of a tie. Formats other than the original
+// need to be listed explicitly, as clients accepting */* may not support them, and if
+// none is acceptable the image keeps its format.
+func negotiateImageFormat(
+	original image.ImageType, animated bool, acceptHeader []string,
+) image.ImageType {
+	// animated images need a format that can keep the animation
+	candidates := []image.ImageType{
+		image.ImageTypeAVIF,
+		image.ImageTypeWEBP,
+		image.ImageTypeJXL,
+		image.ImageTypeJPEG,
+		image.ImageTypePNG,
+	}
+	if animated {
+		candidates = []image.ImageType{image.ImageTypeWEBP, image.ImageTypeGIF}
+	}
+
+	same := sameFormat(original)
+	accepted := parseAccept(acceptHeader)
+
+	best, bestQ := same, 0.0
+
+	for _, format := range candidates {
+		mimeType := image.Options{Format: format}.FormatMimeType() //nolint:exhaustruct
+
+		q := accepted[mimeType]
+		if format == same {
+			if _, ok := accepted[mimeType]; !ok {
+				q = max(accepted["image/*"], accepted["*/*"])
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = format, q
+		}
+	}
+
+	return best
+}
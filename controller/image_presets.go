@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/nhost/hasura-storage/api"
+)
+
+// ImagePreset is a named set of image transformations of a bucket. Its fields are named
+// after the query parameters of the transformations.
+type ImagePreset struct {
+	W          *int                   `json:"w,omitempty"`
+	H          *int                   `json:"h,omitempty"`
+	Q          *int                   `json:"q,omitempty"`
+	B          *float32               `json:"b,omitempty"`
+	F          *api.OutputImageFormat `json:"f,omitempty"`
+	Crop       *string                `json:"crop,omitempty"`
+	Fit        *api.ImageFit          `json:"fit,omitempty"`
+	Gravity    *api.ImageGravity      `json:"gravity,omitempty"`
+	Rotate     *int                   `json:"rotate,omitempty"`
+	Flip       *api.ImageFlip         `json:"flip,omitempty"`
+	Sharpen    *float32               `json:"sharpen,omitempty"`
+	Grayscale  *bool                  `json:"grayscale,omitempty"`
+	Strip      *bool                  `json:"strip,omitempty"`
+	Background *string                `json:"background,omitempty"`
+	Dpr        *float32               `json:"dpr,omitempty"`
+}
+
+// presetParams applies a preset to the image transformations of a request, the ones in
+// the request taking precedence.
+type presetParams struct {
+	processFiler
+
+	preset ImagePreset
+}
+
+func firstNonNil[T any](a, b *T) *T {
+	if a != nil {
+		return a
+	}
+
+	return b
+}
+
+func (p presetParams) GetW() *int {
+	return firstNonNil(p.processFiler.GetW(), p.preset.W)
+}
+
+func (p presetParams) GetH() *int {
+	return firstNonNil(p.processFiler.GetH(), p.preset.H)
+}
+
+func (p presetParams) GetQ() *int {
+	return firstNonNil(p.processFiler.GetQ(), p.preset.Q)
+}
+
+func (p presetParams) GetB() *float32 {
+	return firstNonNil(p.processFiler.GetB(), p.preset.B)
+}
+
+func (p presetParams) GetF() *api.OutputImageFormat {
+	return firstNonNil(p.processFiler.GetF(), p.preset.F)
+}
+
+func (p presetParams) GetCrop() *string {
+	return firstNonNil(p.processFiler.GetCrop(), p.preset.Crop)
+}
+
+func (p presetParams) GetFit() *api.ImageFit {
+	return firstNonNil(p.processFiler.GetFit(), p.preset.Fit)
+}
+
+func (p presetParams) GetGravity() *api.ImageGravity {
+	return firstNonNil(p.processFiler.GetGravity(), p.preset.Gravity)
+}
+
+func (p presetParams) GetRotate() *int {
+	return firstNonNil(p.processFiler.GetRotate(), p.preset.Rotate)
+}
+
+func (p presetParams) GetFlip() *api.ImageFlip {
+	return firstNonNil(p.processFiler.GetFlip(), p.preset.Flip)
+}
+
+func (p presetParams) GetSharpen() *float32 {
+	return firstNonNil(p.processFiler.GetSharpen(), p.preset.Sharpen)
+}
+
+func (p presetParams) GetGrayscale() *bool {
+	return firstNonNil(p.processFiler.GetGrayscale(), p.preset.Grayscale)
+}
+
+func (p presetParams) GetStrip() *bool {
+	return firstNonNil(p.processFiler.GetStrip(), p.preset.Strip)
+}
+
+func (p presetParams) GetBackground() *string {
+	return firstNonNil(p.processFiler.GetBackground(), p.preset.Background)
+}
+
+func (p presetParams) GetDpr() *float32 {
+	return firstNonNil(p.processFiler.GetDpr(), p.preset.Dpr)
+}
+
+// hasFreeFormOptions returns whether the request asks for transformations other than the
+// output format or stripping the metadata, which can't be abused to produce arbitrary
+// images.
+func hasFreeFormOptions(params ImageManipulationOptionsGetter) bool {
+	return params.GetW() != nil || params.GetH() != nil || params.GetQ() != nil ||
+		params.GetB() != nil || params.GetCrop() != nil || params.GetFit() != nil ||
+		params.GetGravity() != nil || params.GetRotate() != nil || params.GetFlip() != nil ||
+		params.GetSharpen() != nil || params.GetGrayscale() != nil ||
+		params.GetBackground() != nil || params.GetDpr() != nil
+}
+
+// applyPreset returns the image transformations of a request after applying the preset
+// it asks for, if any.
+func applyPreset(bucket BucketMetadata, params processFiler) (processFiler, *APIError) {
+	if bucket.PresetsOnly && hasFreeFormOptions(params) {
+		msg := "this bucket only allows image transformations with a preset"
+		return nil, BadDataError(errors.New(msg), msg) //nolint:err113
+	}
+
+	name := deptr(params.GetPreset())
+	if name == "" {
+		return params, nil
+	}
+
+	preset, ok := bucket.Presets[name]
+	if !ok {
+		msg := "unknown preset: " + name
+		return nil, BadDataError(errors.New(msg), msg) //nolint:err113
+	}
+
+	return presetParams{processFiler: params, preset: preset}, nil
+}
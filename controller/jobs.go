@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/nhost/hasura-storage/api"
+)
+
+// runJob consumes the results of a maintenance operation, counting the items it
+// processed successfully and collecting the problems found with the rest.
+func runJob[T any](
+	results iter.Seq2[T, *APIError], itemError func(T) (string, bool),
+) (int, []string, error) {
+	affected := 0
+	itemErrors := make([]string, 0)
+
+	for result, apiErr := range results {
+		if apiErr != nil {
+			return affected, itemErrors, apiErr
+		}
+
+		if msg, failed := itemError(result); failed {
+			itemErrors = append(itemErrors, msg)
+			continue
+		}
+
+		affected++
+	}
+
+	return affected, itemErrors, nil
+}
+
+// DeleteOrphansJob deletes the orphaned files that weren't modified within minAge.
+func (ctrl *Controller) DeleteOrphansJob(
+	ctx context.Context, minAge time.Duration,
+) (int, []string, error) {
+	opts := cleanupOptions{minAge: minAge} //nolint:exhaustruct
+
+	return runJob(
+		ctrl.deleteOrphans(ctx, opts),
+		func(r api.OrphanedFileDeletion) (string, bool) {
+			if r.Error == nil {
+				return "", false
+			}
+
+			return fmt.Sprintf("%s: %s", r.File, *r.Error), true
+		},
+	)
+}
+
+// DeleteBrokenMetadataJob deletes the broken metadata that wasn't updated within minAge.
+func (ctrl *Controller) DeleteBrokenMetadataJob(
+	ctx context.Context, minAge time.Duration,
+) (int, []string, error) {
+	opts := cleanupOptions{minAge: minAge} //nolint:exhaustruct
+
+	return runJob(
+		ctrl.deleteBrokenMetadata(ctx, opts),
+		func(r api.BrokenMetadataDeletion) (string, bool) {
+			if r.Error == nil {
+				return "", false
+			}
+
+			return fmt.Sprintf("%s: %s", r.Metadata.Id, *r.Error), true
+		},
+	)
+}
+
+// ListNotUploadedJob counts the files whose upload never completed so they show up in
+// the job history.
+func (ctrl *Controller) ListNotUploadedJob(ctx context.Context) (int, []string, error) {
+	return runJob(
+		ctrl.listNotUploaded(ctx),
+		func(FileSummary) (string, bool) { return "", false },
+	)
+}
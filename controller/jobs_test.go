@@ -0,0 +1,64 @@
+package controller_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/controller/mock"
+	"github.com/sirupsen/logrus"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestDeleteOrphansJob(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	metadataStorage := mock.NewMockMetadataStorage(c)
+	contentStorage := mock.NewMockContentStorage(c)
+
+	metadataStorage.EXPECT().ListFiles(gomock.Any(), gomock.Any()).Return(
+		listOf(
+			controller.FileSummary{
+				ID:         "7dc0b0d0-b100-4667-89f1-0434942d9c15",
+				Name:       "file-two.txt",
+				IsUploaded: true,
+				BucketID:   "default",
+			},
+		),
+	)
+
+	contentStorage.EXPECT().ListFiles(gomock.Any()).Return(
+		listOf("7dc0b0d0-b100-4667-89f1-0434942d9c15", "garbage", "garbage-2", "garbage-3"),
+	)
+
+	contentStorage.EXPECT().DeleteFile(gomock.Any(), "garbage").Return(nil)
+	contentStorage.EXPECT().DeleteFile(gomock.Any(), "garbage-2").Return(
+		controller.InternalServerError(errors.New("some error")), //nolint:err113
+	)
+	contentStorage.EXPECT().DeleteFile(gomock.Any(), "garbage-3").Return(nil)
+
+	ctrl := controller.New(
+		"http://asd",
+		"/v1",
+		"asdasd",
+		metadataStorage,
+		contentStorage,
+		nil,
+		nil,
+		logger,
+	)
+
+	affected, itemErrors, err := ctrl.DeleteOrphansJob(t.Context(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert(t, 2, affected)
+	assert(t, []string{"garbage-2: an internal server error occurred"}, itemErrors)
+}
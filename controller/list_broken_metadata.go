@@ -2,66 +2,101 @@ package controller
 
 import (
 	"context"
-	"path"
+	"iter"
+	"net/http"
 
 	"github.com/nhost/hasura-storage/api"
 	"github.com/nhost/hasura-storage/middleware"
+	"github.com/sirupsen/logrus"
 )
 
 type ListBrokenMetadataResponse struct {
 	Metadata []FileSummary `json:"metadata"`
 }
 
-func (ctrl *Controller) listBrokenMetadata(ctx context.Context) ([]FileSummary, *APIError) {
-	filesInHasura, apiErr := ctrl.metadataStorage.ListFiles(ctx, nil)
-	if apiErr != nil {
-		return nil, apiErr
-	}
-
-	filesInS3, apiErr := ctrl.contentStorage.ListFiles(ctx)
-	if apiErr != nil {
-		return nil, apiErr
-	}
+// listBrokenMetadata merges the files in the metadata storage with the ones in the
+// content storage, both are listed in order so they are walked only once.
+func (ctrl *Controller) listBrokenMetadata(
+	ctx context.Context,
+) iter.Seq2[FileSummary, *APIError] {
+	return func(yield func(FileSummary, *APIError) bool) {
+		filesInS3 := newSortedCursor(
+			rootKeys(ctrl.contentStorage.ListFiles(ctx)),
+			func(key string) string { return key },
+		)
+		defer filesInS3.stop()
 
-	missing := make([]FileSummary, 0, 10) //nolint: mnd
+		for fileHasura, apiErr := range ctrl.metadataStorage.ListFiles(
+			ctx,
+			http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
+		) {
+			if apiErr != nil {
+				yield(FileSummary{}, apiErr)
+				return
+			}
 
-	for _, fileHasura := range filesInHasura {
-		found := false
+			// the content of infected files is moved to quarantine, that's expected
+			if !fileHasura.IsUploaded || fileHasura.ScanStatus == string(api.Infected) {
+				continue
+			}
 
-		for _, fileS3 := range filesInS3 {
-			if path.Base(fileS3) == fileHasura.ID || !fileHasura.IsUploaded {
-				found = true
+			found, apiErr := filesInS3.seek(fileHasura.ID)
+			if apiErr != nil {
+				yield(FileSummary{}, apiErr)
+				return
 			}
-		}
 
-		if !found {
-			missing = append(missing, fileHasura)
+			if !found && !yield(fileHasura, nil) {
+				return
+			}
 		}
 	}
+}
 
-	return missing, nil
+func fileSummary(f FileSummary) api.FileSummary {
+	return api.FileSummary{
+		Id:         f.ID,
+		Name:       f.Name,
+		IsUploaded: f.IsUploaded,
+		BucketId:   f.BucketID,
+	}
 }
 
 func fileListSummary(files []FileSummary) *[]api.FileSummary {
 	apiFiles := make([]api.FileSummary, len(files))
 	for i, f := range files {
-		apiFiles[i] = api.FileSummary{
-			Id:         f.ID,
-			Name:       f.Name,
-			IsUploaded: f.IsUploaded,
-			BucketId:   f.BucketID,
-		}
+		apiFiles[i] = fileSummary(f)
 	}
 
 	return &apiFiles
 }
 
+// streamFileSummaries streams files as newline delimited JSON.
+func streamFileSummaries(
+	files iter.Seq2[FileSummary, *APIError], logger logrus.FieldLogger,
+) ndjsonResponse[api.FileSummary] {
+	return ndjsonResponse[api.FileSummary]{
+		items: func(yield func(api.FileSummary, *APIError) bool) {
+			for f, apiErr := range files {
+				if !yield(fileSummary(f), apiErr) {
+					return
+				}
+			}
+		},
+		logger: logger,
+	}
+}
+
 func (ctrl *Controller) ListBrokenMetadata( //nolint:ireturn
 	ctx context.Context, _ api.ListBrokenMetadataRequestObject,
 ) (api.ListBrokenMetadataResponseObject, error) {
 	logger := middleware.LoggerFromContext(ctx)
 
-	files, apiErr := ctrl.listBrokenMetadata(ctx)
+	if acceptsNDJSON(ctx) {
+		return streamFileSummaries(ctrl.listBrokenMetadata(ctx), logger), nil
+	}
+
+	files, apiErr := collect(ctrl.listBrokenMetadata(ctx))
 	if apiErr != nil {
 		logger.WithError(apiErr).Error("failed to list broken metadata")
 		return apiErr, nil
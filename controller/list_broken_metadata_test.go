@@ -1,11 +1,16 @@
 package controller_test
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/gin-gonic/gin"
 	"github.com/nhost/hasura-storage/api"
 	"github.com/nhost/hasura-storage/controller"
 	"github.com/nhost/hasura-storage/controller/mock"
+	"github.com/nhost/hasura-storage/middleware"
 	"github.com/sirupsen/logrus"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -54,38 +59,39 @@ func TestListBrokenMetadata(t *testing.T) {
 			metadataStorage.EXPECT().ListFiles(
 				gomock.Any(), gomock.Any(),
 			).Return(
-				[]controller.FileSummary{
-					{
-						ID:         "b3b4e653-ca59-412c-a165-92d251c3fe86",
-						Name:       "file-1.txt",
-						IsUploaded: true,
-						BucketID:   "default",
-					},
-					{
-						ID:         "e6aad336-ad79-4df7-a09b-5782f71948f4",
-						Name:       "file-1.txt",
-						IsUploaded: true,
-						BucketID:   "default",
-					},
-					{
+				listOf(
+					controller.FileSummary{
 						ID:         "7dc0b0d0-b100-4667-89f1-0434942d9c15",
 						Name:       "file-two.txt",
 						IsUploaded: true,
 						BucketID:   "default",
 					},
-					{
+					controller.FileSummary{
 						ID:         "a184ad10-58e2-4619-9a22-04a90b9c4b5f",
 						Name:       "file-three.txt",
 						IsUploaded: false,
 						BucketID:   "default",
 					},
-				}, nil,
+					controller.FileSummary{
+						ID:         "b3b4e653-ca59-412c-a165-92d251c3fe86",
+						Name:       "file-1.txt",
+						IsUploaded: true,
+						BucketID:   "default",
+					},
+					controller.FileSummary{
+						ID:         "e6aad336-ad79-4df7-a09b-5782f71948f4",
+						Name:       "file-1.txt",
+						IsUploaded: true,
+						BucketID:   "default",
+					},
+				),
 			)
 
 			contentStorage.EXPECT().ListFiles(gomock.Any()).Return(
-				[]string{
-					"app_id/7dc0b0d0-b100-4667-89f1-0434942d9c15",
-				}, nil,
+				listOf(
+					"7dc0b0d0-b100-4667-89f1-0434942d9c15",
+					"tus/e6aad336-ad79-4df7-a09b-5782f71948f4/info",
+				),
 			)
 
 			ctrl := controller.New(
@@ -111,3 +117,76 @@ func TestListBrokenMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestListBrokenMetadataNDJSON(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	metadataStorage := mock.NewMockMetadataStorage(c)
+	contentStorage := mock.NewMockContentStorage(c)
+
+	metadataStorage.EXPECT().ListFiles(
+		gomock.Any(), gomock.Any(),
+	).Return(
+		func(yield func(controller.FileSummary, *controller.APIError) bool) {
+			if !yield(controller.FileSummary{
+				ID:         "7dc0b0d0-b100-4667-89f1-0434942d9c15",
+				Name:       "file-two.txt",
+				IsUploaded: true,
+				BucketID:   "default",
+			}, nil) {
+				return
+			}
+
+			yield(controller.FileSummary{}, controller.InternalServerError(
+				errors.New("connection lost"), //nolint:err113
+			))
+		},
+	)
+
+	contentStorage.EXPECT().ListFiles(gomock.Any()).Return(listOf[string]())
+
+	ctrl := controller.New(
+		"http://asd",
+		"/v1",
+		"asdasd",
+		metadataStorage,
+		contentStorage,
+		nil,
+		nil,
+		logger,
+	)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequestWithContext(
+		t.Context(), http.MethodPost, "/v1/ops/list-broken-metadata", nil,
+	)
+	ctx.Set(middleware.HeadersContextKey, http.Header{
+		"Accept": []string{"application/x-ndjson"},
+	})
+
+	resp, err := ctrl.ListBrokenMetadata(ctx, api.ListBrokenMetadataRequestObject{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := resp.VisitListBrokenMetadataResponse(w); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert(t, http.StatusOK, w.Code)
+	assert(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert(
+		t,
+		`{"bucketId":"default","id":"7dc0b0d0-b100-4667-89f1-0434942d9c15","isUploaded":true,"name":"file-two.txt"}
+{"error":{"data":null,"message":"an internal server error occurred"}}
+`,
+		w.Body.String(),
+	)
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -10,28 +11,26 @@ import (
 	"github.com/nhost/hasura-storage/middleware"
 )
 
-func (ctrl *Controller) listNotUploaded(ctx context.Context) ([]FileSummary, *APIError) {
-	filesInHasura, apiErr := ctrl.metadataStorage.ListFiles(
-		ctx,
-		http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
-	)
-	if apiErr != nil {
-		return nil, apiErr
-	}
-
-	missing := make([]FileSummary, 0, 10) //nolint: mnd
+func (ctrl *Controller) listNotUploaded(ctx context.Context) iter.Seq2[FileSummary, *APIError] {
+	return func(yield func(FileSummary, *APIError) bool) {
+		for fileHasura, apiErr := range ctrl.metadataStorage.ListFiles(
+			ctx,
+			http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
+		) {
+			if apiErr != nil {
+				yield(FileSummary{}, apiErr)
+				return
+			}
 
-	for _, fileHasura := range filesInHasura {
-		if !fileHasura.IsUploaded {
-			missing = append(missing, fileHasura)
+			if !fileHasura.IsUploaded && !yield(fileHasura, nil) {
+				return
+			}
 		}
 	}
-
-	return missing, nil
 }
 
 func (ctrl *Controller) ListNotUploaded(ctx *gin.Context) {
-	files, apiErr := ctrl.listNotUploaded(ctx)
+	files, apiErr := collect(ctrl.listNotUploaded(ctx))
 	if apiErr != nil {
 		_ = ctx.Error(fmt.Errorf("problem processing request: %w", apiErr))
 
@@ -53,7 +52,11 @@ func (ctrl *Controller) ListFilesNotUploaded( //nolint:ireturn
 ) (api.ListFilesNotUploadedResponseObject, error) {
 	logger := middleware.LoggerFromContext(ctx)
 
-	files, apiErr := ctrl.listNotUploaded(ctx)
+	if acceptsNDJSON(ctx) {
+		return streamFileSummaries(ctrl.listNotUploaded(ctx), logger), nil
+	}
+
+	files, apiErr := collect(ctrl.listNotUploaded(ctx))
 	if apiErr != nil {
 		logger.WithError(apiErr).Error("failed to list not uploaded files")
 		return apiErr, nil
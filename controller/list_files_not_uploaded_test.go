@@ -48,32 +48,32 @@ func TestListNotUploaded(t *testing.T) {
 			metadataStorage.EXPECT().ListFiles(
 				gomock.Any(), gomock.Any(),
 			).Return(
-				[]controller.FileSummary{
-					{
+				listOf(
+					controller.FileSummary{
 						ID:         "b3b4e653-ca59-412c-a165-92d251c3fe86",
 						Name:       "file-1.txt",
 						IsUploaded: true,
 						BucketID:   "default",
 					},
-					{
+					controller.FileSummary{
 						ID:         "e6aad336-ad79-4df7-a09b-5782f71948f4",
 						Name:       "file-1.txt",
 						IsUploaded: true,
 						BucketID:   "default",
 					},
-					{
+					controller.FileSummary{
 						ID:         "7dc0b0d0-b100-4667-89f1-0434942d9c15",
 						Name:       "file-two.txt",
 						IsUploaded: true,
 						BucketID:   "default",
 					},
-					{
+					controller.FileSummary{
 						ID:         "a184ad10-58e2-4619-9a22-04a90b9c4b5f",
 						Name:       "file-three.txt",
 						IsUploaded: false,
 						BucketID:   "default",
 					},
-				}, nil,
+				),
 			)
 
 			ctrl := controller.New(
@@ -2,45 +2,127 @@ package controller
 
 import (
 	"context"
+	"iter"
 	"net/http"
 	"path"
+	"strings"
 
+	"github.com/google/uuid"
 	"github.com/nhost/hasura-storage/api"
 	"github.com/nhost/hasura-storage/middleware"
 )
 
-func (ctrl *Controller) listOrphans(ctx context.Context) ([]string, *APIError) {
-	filesInHasura, apiErr := ctrl.metadataStorage.ListFiles(
-		ctx,
-		http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
-	)
-	if apiErr != nil {
-		return nil, apiErr
-	}
+// listOrphans merges the files in the content storage with the ones in the metadata
+// storage, both are listed in order so they are walked only once.
+func (ctrl *Controller) listOrphans(ctx context.Context) iter.Seq2[string, *APIError] {
+	return func(yield func(string, *APIError) bool) {
+		adminHeaders := http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}}
 
-	filesInS3, apiErr := ctrl.contentStorage.ListFiles(ctx)
-	if apiErr != nil {
-		return nil, apiErr
-	}
+		filesInHasura := newSortedCursor(
+			ctrl.metadataStorage.ListFiles(ctx, adminHeaders),
+			func(f FileSummary) string { return f.ID },
+		)
+		defer filesInHasura.stop()
+
+		// partial tus uploads are kept until the file is either uploaded or deleted,
+		// keys of the same upload are listed together so the last one is remembered
+		lastUploadID := ""
+		keepUpload := false
+
+		for key, apiErr := range ctrl.contentStorage.ListFiles(ctx) {
+			if apiErr != nil {
+				yield("", apiErr)
+				return
+			}
 
-	missing := make([]string, 0, 10) //nolint: mnd
+			found := false
 
-	for _, fileS3 := range filesInS3 {
-		found := false
+			switch uploadID, isTusUpload := tusUploadIDFromKey(key); {
+			case isTusUpload:
+				if uploadID != lastUploadID {
+					keepUpload, apiErr = ctrl.keepPendingUpload(ctx, uploadID, adminHeaders)
+					if apiErr != nil {
+						yield("", apiErr)
+						return
+					}
 
-		for _, fileHasura := range filesInHasura {
-			if path.Base(fileS3) == fileHasura.ID {
+					lastUploadID = uploadID
+				}
+
+				found = keepUpload
+			case strings.HasPrefix(key, presignedUploadPrefix+"/"):
+				// presigned uploads are kept until they are completed, content uploaded
+				// again with the same policy afterwards is never used
+				found, apiErr = ctrl.keepPendingUpload(
+					ctx, strings.TrimPrefix(key, presignedUploadPrefix+"/"), adminHeaders,
+				)
+				if apiErr != nil {
+					yield("", apiErr)
+					return
+				}
+			case strings.HasPrefix(key, quarantinePrefix+"/"):
+				// infected files are kept as evidence even after their metadata is gone
 				found = true
-				break
+			case strings.Contains(key, "/"):
+				// files are stored at the root but, as they always have been, keys in other
+				// folders are matched by their name. They are not in the same order as the
+				// metadata so they are looked up one by one.
+				found, apiErr = ctrl.fileExists(ctx, path.Base(key), adminHeaders)
+				if apiErr != nil {
+					yield("", apiErr)
+					return
+				}
+			default:
+				found, apiErr = filesInHasura.seek(key)
+				if apiErr != nil {
+					yield("", apiErr)
+					return
+				}
 			}
-		}
 
-		if !found {
-			missing = append(missing, fileS3)
+			if !found && !yield(key, nil) {
+				return
+			}
 		}
 	}
+}
 
-	return missing, nil
+// keepPendingUpload returns whether the partial content of a tus or presigned upload
+// is still needed, that is until the file is either uploaded or deleted.
+func (ctrl *Controller) keepPendingUpload(
+	ctx context.Context, uploadID string, headers http.Header,
+) (bool, *APIError) {
+	file, apiErr := ctrl.metadataStorage.GetFileByID(ctx, uploadID, headers)
+	if apiErr == ErrFileNotFound { //nolint:errorlint
+		return false, nil
+	}
+
+	if apiErr != nil {
+		return false, apiErr.ExtendError("problem getting metadata of upload " + uploadID)
+	}
+
+	return !file.IsUploaded, nil
+}
+
+// fileExists returns whether there is metadata for the file, uploaded or not.
+func (ctrl *Controller) fileExists(
+	ctx context.Context, fileID string, headers http.Header,
+) (bool, *APIError) {
+	if uuid.Validate(fileID) != nil {
+		// not a file id, the metadata storage would reject it
+		return false, nil
+	}
+
+	_, apiErr := ctrl.metadataStorage.GetFileByID(ctx, fileID, headers)
+	if apiErr == ErrFileNotFound { //nolint:errorlint
+		return false, nil
+	}
+
+	if apiErr != nil {
+		return false, apiErr.ExtendError("problem getting metadata of file " + fileID)
+	}
+
+	return true, nil
 }
 
 func (ctrl *Controller) ListOrphanedFiles( //nolint:ireturn
@@ -48,7 +130,11 @@ func (ctrl *Controller) ListOrphanedFiles( //nolint:ireturn
 ) (api.ListOrphanedFilesResponseObject, error) {
 	logger := middleware.LoggerFromContext(ctx)
 
-	files, apiErr := ctrl.listOrphans(ctx)
+	if acceptsNDJSON(ctx) {
+		return ndjsonResponse[string]{ctrl.listOrphans(ctx), logger}, nil
+	}
+
+	files, apiErr := collect(ctrl.listOrphans(ctx))
 	if apiErr != nil {
 		logger.WithError(apiErr).Error("failed to delete orphaned files")
 		return apiErr, nil
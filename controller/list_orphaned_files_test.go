@@ -20,7 +20,12 @@ func TestListOrphans(t *testing.T) {
 		{
 			name: "successful",
 			expected: api.ListOrphanedFiles200JSONResponse{
-				Files: &[]string{"app_id/garbage"},
+				Files: &[]string{
+					"app_id/garbage",
+					"garbage",
+					"presigned/7dc0b0d0-b100-4667-89f1-0434942d9c15",
+					"tus/e6aad336-ad79-4df7-a09b-5782f71948f4/info",
+				},
 			},
 		},
 	}
@@ -41,30 +46,68 @@ func TestListOrphans(t *testing.T) {
 			metadataStorage.EXPECT().ListFiles(
 				gomock.Any(), gomock.Any(),
 			).Return(
-				[]controller.FileSummary{
-					{
-						ID:         "b3b4e653-ca59-412c-a165-92d251c3fe86",
-						Name:       "file-1.txt",
+				listOf(
+					controller.FileSummary{
+						ID:         "7dc0b0d0-b100-4667-89f1-0434942d9c15",
+						Name:       "file-two.txt",
 						IsUploaded: true,
 						BucketID:   "default",
 					},
-					{
-						ID:         "7dc0b0d0-b100-4667-89f1-0434942d9c15",
-						Name:       "file-two.txt",
+					controller.FileSummary{
+						ID:         "a184ad10-58e2-4619-9a22-04a90b9c4b5f",
+						Name:       "file-three.txt",
+						IsUploaded: false,
+						BucketID:   "default",
+					},
+					controller.FileSummary{
+						ID:         "b3b4e653-ca59-412c-a165-92d251c3fe86",
+						Name:       "file-1.txt",
 						IsUploaded: true,
 						BucketID:   "default",
 					},
-				}, nil,
+				),
 			)
 
 			contentStorage.EXPECT().ListFiles(gomock.Any()).Return(
-				[]string{
-					"app_id/b3b4e653-ca59-412c-a165-92d251c3fe86",
+				listOf(
+					"7dc0b0d0-b100-4667-89f1-0434942d9c15",
 					"app_id/7dc0b0d0-b100-4667-89f1-0434942d9c15",
+					"app_id/b3b4e653-ca59-412c-a165-92d251c3fe86",
 					"app_id/garbage",
-				}, nil,
+					"b3b4e653-ca59-412c-a165-92d251c3fe86",
+					"garbage",
+					"presigned/7dc0b0d0-b100-4667-89f1-0434942d9c15",
+					"quarantine/3c9d7a52-0e8f-4f4c-9d4b-6a1e8f2b7c13",
+					"tus/a184ad10-58e2-4619-9a22-04a90b9c4b5f/00000000000000000000",
+					"tus/a184ad10-58e2-4619-9a22-04a90b9c4b5f/info",
+					"tus/e6aad336-ad79-4df7-a09b-5782f71948f4/info",
+				),
 			)
 
+			// looked up for the file in app_id/ and for the content uploaded again after
+			// completing a presigned upload
+			metadataStorage.EXPECT().GetFileByID(
+				gomock.Any(), "7dc0b0d0-b100-4667-89f1-0434942d9c15", gomock.Any(),
+			).Return(api.FileMetadata{ //nolint:exhaustruct
+				Id:         "7dc0b0d0-b100-4667-89f1-0434942d9c15",
+				IsUploaded: true,
+			}, nil).Times(2)
+			metadataStorage.EXPECT().GetFileByID(
+				gomock.Any(), "b3b4e653-ca59-412c-a165-92d251c3fe86", gomock.Any(),
+			).Return(api.FileMetadata{ //nolint:exhaustruct
+				Id:         "b3b4e653-ca59-412c-a165-92d251c3fe86",
+				IsUploaded: true,
+			}, nil)
+			metadataStorage.EXPECT().GetFileByID(
+				gomock.Any(), "a184ad10-58e2-4619-9a22-04a90b9c4b5f", gomock.Any(),
+			).Return(api.FileMetadata{ //nolint:exhaustruct
+				Id:         "a184ad10-58e2-4619-9a22-04a90b9c4b5f",
+				IsUploaded: false,
+			}, nil)
+			metadataStorage.EXPECT().GetFileByID(
+				gomock.Any(), "e6aad336-ad79-4df7-a09b-5782f71948f4", gomock.Any(),
+			).Return(api.FileMetadata{}, controller.ErrFileNotFound) //nolint:exhaustruct
+
 			ctrl := controller.New(
 				"http://asd",
 				"/v1",
@@ -3,11 +3,13 @@ package controller_test
 import (
 	"fmt"
 	"io"
+	"iter"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/controller"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -61,6 +63,20 @@ func FileMetadataMatcher(v api.FileMetadata) gomock.Matcher {
 	return fileMetadataMatcher{v}
 }
 
+type fileEventMatcher struct {
+	eventType string
+	fileID    string
+}
+
+func (m fileEventMatcher) Matches(x any) bool {
+	event, ok := x.(controller.FileEvent)
+	return ok && event.Type == m.eventType && event.File.Id == m.fileID
+}
+
+func (m fileEventMatcher) String() string {
+	return m.eventType + " " + m.fileID
+}
+
 func assert(t *testing.T, got, wanted interface{}, opts ...cmp.Option) {
 	t.Helper()
 
@@ -68,3 +84,29 @@ func assert(t *testing.T, got, wanted interface{}, opts ...cmp.Option) {
 		t.Error(cmp.Diff(got, wanted, opts...))
 	}
 }
+
+// listOf returns an iterator over items like the ones returned by the storages.
+func listOf[T any](items ...T) iter.Seq2[T, *controller.APIError] {
+	return func(yield func(T, *controller.APIError) bool) {
+		for _, item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+func collect[T any](t *testing.T, seq iter.Seq2[T, *controller.APIError]) []T {
+	t.Helper()
+
+	res := make([]T, 0)
+	for item, apiErr := range seq {
+		if apiErr != nil {
+			t.Fatal(apiErr)
+		}
+
+		res = append(res, item)
+	}
+
+	return res
+}
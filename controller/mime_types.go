@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// baseMimeType returns the type/subtype of contentType without its parameters.
+func baseMimeType(contentType string) string {
+	base, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+
+	return base
+}
+
+// isUnspecifiedMimeType returns whether the client didn't tell the type of a file.
+func isUnspecifiedMimeType(contentType string) bool {
+	return contentType == "" || baseMimeType(contentType) == "application/octet-stream"
+}
+
+// isGenericMimeType returns whether mimetype fell back to a generic type because it
+// doesn't recognise the content.
+func isGenericMimeType(mt *mimetype.MIME) bool {
+	return mt.Parent() == nil || mt.Is("text/plain")
+}
+
+func isMimeTypeAncestor(mt *mimetype.MIME, ancestor string) bool {
+	for ; mt != nil; mt = mt.Parent() {
+		if mt.Is(ancestor) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mimeTypeMatchesContent returns whether contentType describes content detected as
+// detected. A more generic type is fine, like text/plain for JSON, and so is a more
+// specific one mimetype couldn't confirm from the beginning of the file, like a DOCX
+// detected as ZIP. Types mimetype doesn't know can only be checked against content it
+// doesn't recognise either.
+func mimeTypeMatchesContent(detected *mimetype.MIME, contentType string) bool {
+	base := baseMimeType(contentType)
+	if isMimeTypeAncestor(detected, base) {
+		return true
+	}
+
+	known := mimetype.Lookup(base)
+	if known == nil {
+		return isGenericMimeType(detected)
+	}
+
+	// anything descends from application/octet-stream, unknown binary content can't
+	// be claimed to be a known format
+	return detected.Parent() != nil && isMimeTypeAncestor(known, detected.String())
+}
+
+// matchesMimeTypePatterns returns whether contentType matches any of patterns, which
+// are types like image/png or wildcards like image/*.
+func matchesMimeTypePatterns(contentType string, patterns []string) bool {
+	base := baseMimeType(contentType)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if ok, err := path.Match(pattern, base); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkMimeTypeAllowed makes sure the bucket accepts files of contentType.
+func checkMimeTypeAllowed(bucket BucketMetadata, filename, contentType string) *APIError {
+	allowed := len(bucket.AllowedMimeTypes) == 0 ||
+		matchesMimeTypePatterns(contentType, bucket.AllowedMimeTypes)
+	if !allowed || matchesMimeTypePatterns(contentType, bucket.DeniedMimeTypes) {
+		return MimeTypeNotAllowedError(filename, contentType)
+	}
+
+	return nil
+}
+
+// checkMimeType makes sure the content type a file is declared with, the type detected
+// from its content and its extension agree and that the bucket accepts it. head is the
+// beginning of the file, up to mimetypeReadLimit bytes. It returns the content type the
+// file is stored with, the detected one if the client didn't declare any.
+func checkMimeType(
+	bucket BucketMetadata, filename, declared string, head []byte,
+) (string, *APIError) {
+	detected := mimetype.Detect(head)
+
+	contentType := declared
+	if isUnspecifiedMimeType(declared) {
+		contentType = detected.String()
+	}
+
+	if len(head) > 0 {
+		if !mimeTypeMatchesContent(detected, contentType) {
+			return "", MimeTypeMismatchError(filename, contentType, detected.String())
+		}
+
+		// the extension can't be verified against content mimetype doesn't recognise,
+		// nor can extensions of types it doesn't know
+		extType := mime.TypeByExtension(path.Ext(filename))
+		if extType != "" && !isGenericMimeType(detected) &&
+			mimetype.Lookup(baseMimeType(extType)) != nil &&
+			!mimeTypeMatchesContent(detected, extType) {
+			return "", MimeTypeMismatchError(filename, extType, detected.String())
+		}
+
+		// a denied type can't be uploaded declaring a more generic one
+		if matchesMimeTypePatterns(detected.String(), bucket.DeniedMimeTypes) {
+			return "", MimeTypeNotAllowedError(filename, detected.String())
+		}
+	}
+
+	if apiErr := checkMimeTypeAllowed(bucket, filename, contentType); apiErr != nil {
+		return "", apiErr
+	}
+
+	return contentType, nil
+}
+
+// checkDeclaredMimeType makes sure a file can be uploaded with contentType before its
+// content is available. The content is checked with checkMimeType once it is.
+func checkDeclaredMimeType(bucket BucketMetadata, filename, contentType string) *APIError {
+	if isUnspecifiedMimeType(contentType) {
+		return nil
+	}
+
+	// either type may be more generic than the other, like text/plain and text/html
+	known := mimetype.Lookup(baseMimeType(contentType))
+	extType := mime.TypeByExtension(path.Ext(filename))
+	extKnown := mimetype.Lookup(baseMimeType(extType))
+
+	if known != nil && extKnown != nil &&
+		!isMimeTypeAncestor(known, extKnown.String()) &&
+		!isMimeTypeAncestor(extKnown, known.String()) {
+		return MimeTypeMismatchError(filename, contentType, extType)
+	}
+
+	return checkMimeTypeAllowed(bucket, filename, contentType)
+}
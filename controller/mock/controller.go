@@ -12,6 +12,7 @@ package mock
 import (
 	context "context"
 	io "io"
+	iter "iter"
 	http "net/http"
 	reflect "reflect"
 	time "time"
@@ -46,11 +47,12 @@ func (m *MockMetadataStorage) EXPECT() *MockMetadataStorageMockRecorder {
 }
 
 // DeleteFileByID mocks base method.
-func (m *MockMetadataStorage) DeleteFileByID(ctx context.Context, fileID string, headers http.Header) *controller.APIError {
+func (m *MockMetadataStorage) DeleteFileByID(ctx context.Context, fileID string, headers http.Header) (api.FileMetadata, *controller.APIError) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteFileByID", ctx, fileID, headers)
-	ret0, _ := ret[0].(*controller.APIError)
-	return ret0
+	ret0, _ := ret[0].(api.FileMetadata)
+	ret1, _ := ret[1].(*controller.APIError)
+	return ret0, ret1
 }
 
 // DeleteFileByID indicates an expected call of DeleteFileByID.
@@ -89,6 +91,21 @@ func (mr *MockMetadataStorageMockRecorder) GetFileByID(ctx, id, headers any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileByID", reflect.TypeOf((*MockMetadataStorage)(nil).GetFileByID), ctx, id, headers)
 }
 
+// GetFileScanStatus mocks base method.
+func (m *MockMetadataStorage) GetFileScanStatus(ctx context.Context, id string, headers http.Header) (api.ScanStatus, *controller.APIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileScanStatus", ctx, id, headers)
+	ret0, _ := ret[0].(api.ScanStatus)
+	ret1, _ := ret[1].(*controller.APIError)
+	return ret0, ret1
+}
+
+// GetFileScanStatus indicates an expected call of GetFileScanStatus.
+func (mr *MockMetadataStorageMockRecorder) GetFileScanStatus(ctx, id, headers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileScanStatus", reflect.TypeOf((*MockMetadataStorage)(nil).GetFileScanStatus), ctx, id, headers)
+}
+
 // InitializeFile mocks base method.
 func (m *MockMetadataStorage) InitializeFile(ctx context.Context, id, name string, size int64, bucketID, mimeType string, headers http.Header) *controller.APIError {
 	m.ctrl.T.Helper()
@@ -118,12 +135,11 @@ func (mr *MockMetadataStorageMockRecorder) InsertVirus(ctx, fileID, filename, vi
 }
 
 // ListFiles mocks base method.
-func (m *MockMetadataStorage) ListFiles(ctx context.Context, headers http.Header) ([]controller.FileSummary, *controller.APIError) {
+func (m *MockMetadataStorage) ListFiles(ctx context.Context, headers http.Header) iter.Seq2[controller.FileSummary, *controller.APIError] {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListFiles", ctx, headers)
-	ret0, _ := ret[0].([]controller.FileSummary)
-	ret1, _ := ret[1].(*controller.APIError)
-	return ret0, ret1
+	ret0, _ := ret[0].(iter.Seq2[controller.FileSummary, *controller.APIError])
+	return ret0
 }
 
 // ListFiles indicates an expected call of ListFiles.
@@ -161,6 +177,20 @@ func (mr *MockMetadataStorageMockRecorder) SetIsUploaded(ctx, fileID, isUploaded
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetIsUploaded", reflect.TypeOf((*MockMetadataStorage)(nil).SetIsUploaded), ctx, fileID, isUploaded, headers)
 }
 
+// SetScanStatus mocks base method.
+func (m *MockMetadataStorage) SetScanStatus(ctx context.Context, fileID string, status api.ScanStatus, headers http.Header) *controller.APIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetScanStatus", ctx, fileID, status, headers)
+	ret0, _ := ret[0].(*controller.APIError)
+	return ret0
+}
+
+// SetScanStatus indicates an expected call of SetScanStatus.
+func (mr *MockMetadataStorageMockRecorder) SetScanStatus(ctx, fileID, status, headers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetScanStatus", reflect.TypeOf((*MockMetadataStorage)(nil).SetScanStatus), ctx, fileID, status, headers)
+}
+
 // MockContentStorage is a mock of ContentStorage interface.
 type MockContentStorage struct {
 	ctrl     *gomock.Controller
@@ -200,6 +230,21 @@ func (mr *MockContentStorageMockRecorder) CreatePresignedURL(ctx, filepath, expi
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePresignedURL", reflect.TypeOf((*MockContentStorage)(nil).CreatePresignedURL), ctx, filepath, expire)
 }
 
+// CreatePresignedUpload mocks base method.
+func (m *MockContentStorage) CreatePresignedUpload(ctx context.Context, filepath, contentType string, minSize, maxSize int, expire time.Duration) (*controller.PresignedUpload, *controller.APIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePresignedUpload", ctx, filepath, contentType, minSize, maxSize, expire)
+	ret0, _ := ret[0].(*controller.PresignedUpload)
+	ret1, _ := ret[1].(*controller.APIError)
+	return ret0, ret1
+}
+
+// CreatePresignedUpload indicates an expected call of CreatePresignedUpload.
+func (mr *MockContentStorageMockRecorder) CreatePresignedUpload(ctx, filepath, contentType, minSize, maxSize, expire any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePresignedUpload", reflect.TypeOf((*MockContentStorage)(nil).CreatePresignedUpload), ctx, filepath, contentType, minSize, maxSize, expire)
+}
+
 // DeleteFile mocks base method.
 func (m *MockContentStorage) DeleteFile(ctx context.Context, filepath string) *controller.APIError {
 	m.ctrl.T.Helper()
@@ -244,21 +289,49 @@ func (mr *MockContentStorageMockRecorder) GetFileWithPresignedURL(ctx, filepath,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileWithPresignedURL", reflect.TypeOf((*MockContentStorage)(nil).GetFileWithPresignedURL), ctx, filepath, signature, headers)
 }
 
-// ListFiles mocks base method.
-func (m *MockContentStorage) ListFiles(ctx context.Context) ([]string, *controller.APIError) {
+// HeadFile mocks base method.
+func (m *MockContentStorage) HeadFile(ctx context.Context, filepath string) (*controller.File, *controller.APIError) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListFiles", ctx)
-	ret0, _ := ret[0].([]string)
+	ret := m.ctrl.Call(m, "HeadFile", ctx, filepath)
+	ret0, _ := ret[0].(*controller.File)
 	ret1, _ := ret[1].(*controller.APIError)
 	return ret0, ret1
 }
 
+// HeadFile indicates an expected call of HeadFile.
+func (mr *MockContentStorageMockRecorder) HeadFile(ctx, filepath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeadFile", reflect.TypeOf((*MockContentStorage)(nil).HeadFile), ctx, filepath)
+}
+
+// ListFiles mocks base method.
+func (m *MockContentStorage) ListFiles(ctx context.Context) iter.Seq2[string, *controller.APIError] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFiles", ctx)
+	ret0, _ := ret[0].(iter.Seq2[string, *controller.APIError])
+	return ret0
+}
+
 // ListFiles indicates an expected call of ListFiles.
 func (mr *MockContentStorageMockRecorder) ListFiles(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFiles", reflect.TypeOf((*MockContentStorage)(nil).ListFiles), ctx)
 }
 
+// MoveFile mocks base method.
+func (m *MockContentStorage) MoveFile(ctx context.Context, filepath, newFilepath string) *controller.APIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveFile", ctx, filepath, newFilepath)
+	ret0, _ := ret[0].(*controller.APIError)
+	return ret0
+}
+
+// MoveFile indicates an expected call of MoveFile.
+func (mr *MockContentStorageMockRecorder) MoveFile(ctx, filepath, newFilepath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveFile", reflect.TypeOf((*MockContentStorage)(nil).MoveFile), ctx, filepath, newFilepath)
+}
+
 // PutFile mocks base method.
 func (m *MockContentStorage) PutFile(ctx context.Context, content io.ReadSeeker, filepath, contentType string) (string, *controller.APIError) {
 	m.ctrl.T.Helper()
@@ -274,6 +347,36 @@ func (mr *MockContentStorageMockRecorder) PutFile(ctx, content, filepath, conten
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutFile", reflect.TypeOf((*MockContentStorage)(nil).PutFile), ctx, content, filepath, contentType)
 }
 
+// PutFileIfMatch mocks base method.
+func (m *MockContentStorage) PutFileIfMatch(ctx context.Context, content io.ReadSeeker, filepath, contentType, etag string) (string, *controller.APIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutFileIfMatch", ctx, content, filepath, contentType, etag)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*controller.APIError)
+	return ret0, ret1
+}
+
+// PutFileIfMatch indicates an expected call of PutFileIfMatch.
+func (mr *MockContentStorageMockRecorder) PutFileIfMatch(ctx, content, filepath, contentType, etag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutFileIfMatch", reflect.TypeOf((*MockContentStorage)(nil).PutFileIfMatch), ctx, content, filepath, contentType, etag)
+}
+
+// PutFileStream mocks base method.
+func (m *MockContentStorage) PutFileStream(ctx context.Context, content io.Reader, filepath, contentType string, validate func() *controller.APIError) (string, *controller.APIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutFileStream", ctx, content, filepath, contentType, validate)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*controller.APIError)
+	return ret0, ret1
+}
+
+// PutFileStream indicates an expected call of PutFileStream.
+func (mr *MockContentStorageMockRecorder) PutFileStream(ctx, content, filepath, contentType, validate any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutFileStream", reflect.TypeOf((*MockContentStorage)(nil).PutFileStream), ctx, content, filepath, contentType, validate)
+}
+
 // MockAntivirus is a mock of Antivirus interface.
 type MockAntivirus struct {
 	ctrl     *gomock.Controller
@@ -311,3 +414,146 @@ func (mr *MockAntivirusMockRecorder) ScanReader(ctx, r any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanReader", reflect.TypeOf((*MockAntivirus)(nil).ScanReader), ctx, r)
 }
+
+// MockScanQueue is a mock of ScanQueue interface.
+type MockScanQueue struct {
+	ctrl     *gomock.Controller
+	recorder *MockScanQueueMockRecorder
+	isgomock struct{}
+}
+
+// MockScanQueueMockRecorder is the mock recorder for MockScanQueue.
+type MockScanQueueMockRecorder struct {
+	mock *MockScanQueue
+}
+
+// NewMockScanQueue creates a new mock instance.
+func NewMockScanQueue(ctrl *gomock.Controller) *MockScanQueue {
+	mock := &MockScanQueue{ctrl: ctrl}
+	mock.recorder = &MockScanQueueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScanQueue) EXPECT() *MockScanQueueMockRecorder {
+	return m.recorder
+}
+
+// Enqueue mocks base method.
+func (m *MockScanQueue) Enqueue(ctx context.Context, fileID string) *controller.APIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", ctx, fileID)
+	ret0, _ := ret[0].(*controller.APIError)
+	return ret0
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockScanQueueMockRecorder) Enqueue(ctx, fileID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockScanQueue)(nil).Enqueue), ctx, fileID)
+}
+
+// MockEventPublisher is a mock of EventPublisher interface.
+type MockEventPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventPublisherMockRecorder
+	isgomock struct{}
+}
+
+// MockEventPublisherMockRecorder is the mock recorder for MockEventPublisher.
+type MockEventPublisherMockRecorder struct {
+	mock *MockEventPublisher
+}
+
+// NewMockEventPublisher creates a new mock instance.
+func NewMockEventPublisher(ctrl *gomock.Controller) *MockEventPublisher {
+	mock := &MockEventPublisher{ctrl: ctrl}
+	mock.recorder = &MockEventPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventPublisher) EXPECT() *MockEventPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockEventPublisher) Publish(ctx context.Context, event controller.FileEvent) *controller.APIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, event)
+	ret0, _ := ret[0].(*controller.APIError)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockEventPublisherMockRecorder) Publish(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockEventPublisher)(nil).Publish), ctx, event)
+}
+
+// MockImageCache is a mock of ImageCache interface.
+type MockImageCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockImageCacheMockRecorder
+	isgomock struct{}
+}
+
+// MockImageCacheMockRecorder is the mock recorder for MockImageCache.
+type MockImageCacheMockRecorder struct {
+	mock *MockImageCache
+}
+
+// NewMockImageCache creates a new mock instance.
+func NewMockImageCache(ctrl *gomock.Controller) *MockImageCache {
+	mock := &MockImageCache{ctrl: ctrl}
+	mock.recorder = &MockImageCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImageCache) EXPECT() *MockImageCacheMockRecorder {
+	return m.recorder
+}
+
+// DeleteFile mocks base method.
+func (m *MockImageCache) DeleteFile(ctx context.Context, fileID string) *controller.APIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFile", ctx, fileID)
+	ret0, _ := ret[0].(*controller.APIError)
+	return ret0
+}
+
+// DeleteFile indicates an expected call of DeleteFile.
+func (mr *MockImageCacheMockRecorder) DeleteFile(ctx, fileID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFile", reflect.TypeOf((*MockImageCache)(nil).DeleteFile), ctx, fileID)
+}
+
+// Get mocks base method.
+func (m *MockImageCache) Get(ctx context.Context, fileID, key string) ([]byte, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, fileID, key)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockImageCacheMockRecorder) Get(ctx, fileID, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockImageCache)(nil).Get), ctx, fileID, key)
+}
+
+// Put mocks base method.
+func (m *MockImageCache) Put(ctx context.Context, fileID, key string, content []byte) *controller.APIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, fileID, key, content)
+	ret0, _ := ret[0].(*controller.APIError)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockImageCacheMockRecorder) Put(ctx, fileID, key, content any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockImageCache)(nil).Put), ctx, fileID, key, content)
+}
@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nhost/hasura-storage/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+// cleanupOptions limit what the cleanup operations delete.
+type cleanupOptions struct {
+	dryRun bool
+	// maxDeletions is the number of files to process before stopping, 0 for no limit
+	maxDeletions int
+	// minAge skips files modified more recently, they may still be being uploaded
+	minAge time.Duration
+	// bucketID, if set, skips files in other buckets
+	bucketID string
+}
+
+func newCleanupOptions(dryRun *bool, maxDeletions, minAge *int, bucketID *string) cleanupOptions {
+	opts := cleanupOptions{} //nolint:exhaustruct
+	if dryRun != nil {
+		opts.dryRun = *dryRun
+	}
+
+	if maxDeletions != nil {
+		opts.maxDeletions = *maxDeletions
+	}
+
+	if minAge != nil {
+		opts.minAge = time.Duration(*minAge) * time.Second
+	}
+
+	if bucketID != nil {
+		opts.bucketID = *bucketID
+	}
+
+	return opts
+}
+
+func (o cleanupOptions) limitReached(processed int) bool {
+	return o.maxDeletions > 0 && processed >= o.maxDeletions
+}
+
+func (o cleanupOptions) tooRecent(modified time.Time) bool {
+	return time.Since(modified) < o.minAge
+}
+
+// publicMessage returns the message of an error to report the files that couldn't be
+// cleaned up.
+func publicMessage(apiErr *APIError) *string {
+	msg := apiErr.PublicMessage()
+	return &msg
+}
+
+// sortedCursor walks a sequence sorted by key so it can be merged with another one
+// without loading either of them in memory.
+type sortedCursor[T any] struct {
+	next    func() (T, *APIError, bool)
+	stop    func()
+	key     func(T) string
+	current T
+	started bool
+	done    bool
+}
+
+func newSortedCursor[T any](
+	seq iter.Seq2[T, *APIError], key func(T) string,
+) *sortedCursor[T] {
+	next, stop := iter.Pull2(seq)
+
+	return &sortedCursor[T]{ //nolint:exhaustruct
+		next: next,
+		stop: stop,
+		key:  key,
+	}
+}
+
+func (c *sortedCursor[T]) advance() *APIError {
+	item, apiErr, ok := c.next()
+
+	switch {
+	case !ok:
+		c.done = true
+		return nil
+	case apiErr != nil:
+		c.done = true
+		return apiErr
+	case c.started && c.key(item) < c.key(c.current):
+		c.done = true
+
+		return InternalServerError(fmt.Errorf( //nolint:err113
+			"files aren't listed in order, %s listed after %s", c.key(item), c.key(c.current),
+		))
+	}
+
+	c.started = true
+	c.current = item
+
+	return nil
+}
+
+// seek moves the cursor to the first item with a key greater or equal than key and
+// returns if its key is the one requested.
+func (c *sortedCursor[T]) seek(key string) (bool, *APIError) {
+	if !c.started && !c.done {
+		if apiErr := c.advance(); apiErr != nil {
+			return false, apiErr
+		}
+	}
+
+	for !c.done && c.key(c.current) < key {
+		if apiErr := c.advance(); apiErr != nil {
+			return false, apiErr
+		}
+	}
+
+	return !c.done && c.key(c.current) == key, nil
+}
+
+// rootKeys filters out the keys in the content storage that are inside a folder, files
+// are always stored at the root.
+func rootKeys(keys iter.Seq2[string, *APIError]) iter.Seq2[string, *APIError] {
+	return func(yield func(string, *APIError) bool) {
+		for key, apiErr := range keys {
+			if apiErr != nil {
+				yield("", apiErr)
+				return
+			}
+
+			if strings.Contains(key, "/") {
+				continue
+			}
+
+			if !yield(key, nil) {
+				return
+			}
+		}
+	}
+}
+
+func collect[T any](seq iter.Seq2[T, *APIError]) ([]T, *APIError) {
+	res := make([]T, 0, 10) //nolint:mnd
+	for item, apiErr := range seq {
+		if apiErr != nil {
+			return nil, apiErr
+		}
+
+		res = append(res, item)
+	}
+
+	return slices.Clip(res), nil
+}
+
+// acceptsNDJSON returns if the client asked for the response to be streamed as
+// newline delimited JSON.
+func acceptsNDJSON(ctx context.Context) bool {
+	for _, accept := range middleware.AcceptHeaderFromContext(ctx) {
+		for mediaType := range strings.SplitSeq(accept, ",") {
+			mediaType, _, _ = strings.Cut(mediaType, ";")
+			if strings.TrimSpace(mediaType) == ndjsonContentType {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ndjsonResponse streams items as newline delimited JSON while they are being
+// computed. Errors before the first item are returned as a regular error response,
+// afterwards the status code is already sent so the error is written as the last line.
+type ndjsonResponse[T any] struct {
+	items  iter.Seq2[T, *APIError]
+	logger logrus.FieldLogger
+}
+
+func (r ndjsonResponse[T]) visit(w http.ResponseWriter) error {
+	started := false
+	start := func() {
+		w.Header().Set("Content-Type", ndjsonContentType)
+		w.WriteHeader(http.StatusOK)
+
+		started = true
+	}
+
+	enc := json.NewEncoder(w)
+	for item, apiErr := range r.items {
+		if apiErr != nil {
+			r.logger.WithError(apiErr).Error("problem streaming response")
+
+			if !started {
+				return apiErr.visit(w)
+			}
+
+			return enc.Encode(apiErr.errorResponse()) //nolint:wrapcheck
+		}
+
+		if !started {
+			start()
+		}
+
+		if err := enc.Encode(item); err != nil {
+			return fmt.Errorf("problem writing response: %w", err)
+		}
+	}
+
+	if !started {
+		start()
+	}
+
+	return nil
+}
+
+func (r ndjsonResponse[T]) VisitDeleteBrokenMetadataResponse(w http.ResponseWriter) error {
+	return r.visit(w)
+}
+
+func (r ndjsonResponse[T]) VisitDeleteOrphanedFilesResponse(w http.ResponseWriter) error {
+	return r.visit(w)
+}
+
+func (r ndjsonResponse[T]) VisitListBrokenMetadataResponse(w http.ResponseWriter) error {
+	return r.visit(w)
+}
+
+func (r ndjsonResponse[T]) VisitListFilesNotUploadedResponse(w http.ResponseWriter) error {
+	return r.visit(w)
+}
+
+func (r ndjsonResponse[T]) VisitListOrphanedFilesResponse(w http.ResponseWriter) error {
+	return r.visit(w)
+}
+
+func (r ndjsonResponse[T]) VisitRescanFilesResponse(w http.ResponseWriter) error {
+	return r.visit(w)
+}
@@ -0,0 +1,303 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/metrics"
+	"github.com/nhost/hasura-storage/middleware"
+)
+
+const presignedUploadExpiration = time.Hour
+
+// presignedUploadPrefix is where clients upload the content of presigned uploads. The
+// policy they get can be used until it expires, so it can't point to the path files are
+// served from; the content is moved there when the upload is completed.
+const presignedUploadPrefix = "presigned"
+
+func presignedUploadPath(fileID string) string {
+	return path.Join(presignedUploadPrefix, fileID)
+}
+
+func (ctrl *Controller) CreatePresignedUpload( //nolint:ireturn,funlen
+	ctx context.Context,
+	request api.CreatePresignedUploadRequestObject,
+) (api.CreatePresignedUploadResponseObject, error) {
+	logger := middleware.LoggerFromContext(ctx)
+	sessionHeaders := middleware.SessionHeadersFromContext(ctx)
+
+	bucketID := "default"
+	if request.Body.BucketId != nil {
+		bucketID = *request.Body.BucketId
+	}
+
+	fileID := uuid.New().String()
+	if request.Body.Id != nil {
+		fileID = *request.Body.Id
+	}
+
+	mimeType := "application/octet-stream"
+	if request.Body.MimeType != nil {
+		mimeType = *request.Body.MimeType
+	}
+
+	bucket, apiErr := ctrl.metadataStorage.GetBucketByID(
+		ctx,
+		bucketID,
+		http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
+	)
+	if apiErr != nil {
+		logger.WithError(apiErr).Error("problem getting bucket metadata")
+		return apiErr, nil
+	}
+
+	metrics.BucketToContext(ctx, bucket.ID)
+
+	if !bucket.PresignedURLsEnabled {
+		err := errors.New( //nolint: err113
+			"presigned URLs are not enabled on the bucket",
+		)
+		logger.WithError(err).Error("presigned URLs not enabled for bucket")
+
+		return ForbiddenError(err, err.Error()), nil
+	}
+
+	if apiErr := checkDeclaredMimeType(bucket, request.Body.Name, mimeType); apiErr != nil {
+		logger.WithError(apiErr).Error("file type not accepted by bucket")
+		return apiErr, nil
+	}
+
+	if apiErr := ctrl.metadataStorage.InitializeFile(
+		ctx, fileID, request.Body.Name, 0, bucket.ID, mimeType, sessionHeaders,
+	); apiErr != nil {
+		logger.WithError(apiErr).Error("problem initializing file metadata")
+		return apiErr, nil
+	}
+
+	deleteFile := func() {
+		if _, apiErr := ctrl.metadataStorage.DeleteFileByID(
+			ctx,
+			fileID,
+			http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
+		); apiErr != nil {
+			logger.WithError(apiErr).Error("problem deleting file metadata")
+		}
+	}
+
+	// custom metadata is stored now, completing the upload keeps whatever is there
+	if request.Body.Metadata != nil {
+		if _, apiErr := ctrl.metadataStorage.PopulateMetadata(
+			ctx,
+			fileID, request.Body.Name, 0, bucket.ID, "", false, mimeType, *request.Body.Metadata,
+			http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
+		); apiErr != nil {
+			logger.WithError(apiErr).Error("problem storing file metadata")
+			deleteFile()
+
+			return apiErr, nil
+		}
+	}
+
+	upload, apiErr := ctrl.contentStorage.CreatePresignedUpload(
+		ctx,
+		presignedUploadPath(fileID),
+		mimeType,
+		bucket.MinUploadFile,
+		bucket.MaxUploadFile,
+		presignedUploadExpiration,
+	)
+	if apiErr != nil {
+		logger.WithError(apiErr).Error("problem creating presigned upload")
+		deleteFile()
+
+		return apiErr, nil
+	}
+
+	return api.CreatePresignedUpload201JSONResponse{
+		Id:         fileID,
+		Url:        upload.URL,
+		Fields:     upload.Fields,
+		Expiration: int(presignedUploadExpiration.Seconds()),
+	}, nil
+}
+
+// presignedUploadMimeType checks the content uploaded directly to the content storage
+// against the type the file was declared with and returns the type to store it with.
+func (ctrl *Controller) presignedUploadMimeType(
+	ctx context.Context, fileMetadata api.FileMetadata, bucket BucketMetadata, size int64,
+) (string, *APIError) {
+	var head []byte
+
+	// empty objects can't be requested with a range
+	if size > 0 {
+		downloadRange := fmt.Sprintf("bytes=0-%d", min(size, mimetypeReadLimit)-1)
+
+		file, apiErr := ctrl.contentStorage.GetFile(ctx, fileMetadata.Id, &downloadRange)
+		if apiErr != nil {
+			return "", apiErr.ExtendError("problem getting file content")
+		}
+		defer file.Body.Close()
+
+		b, err := io.ReadAll(io.LimitReader(file.Body, mimetypeReadLimit))
+		if err != nil {
+			return "", InternalServerError(fmt.Errorf(
+				"problem figuring out content type for file %s: %w", fileMetadata.Id, err,
+			))
+		}
+
+		head = b
+	}
+
+	return checkMimeType(bucket, fileMetadata.Name, fileMetadata.MimeType, head)
+}
+
+// checkPresignedUpload moves the content uploaded directly to the content storage in
+// place and makes sure it can be made available, content that can't is deleted. As the
+// client can't write to the final path, what is checked is what gets served. It returns
+// the information of the content and its type.
+func (ctrl *Controller) checkPresignedUpload( //nolint:funlen
+	ctx context.Context,
+	fileMetadata api.FileMetadata,
+	bucket BucketMetadata,
+	expectedEtag *string,
+	sessionHeaders http.Header,
+) (*File, string, *APIError) {
+	// the content was moved already if a previous attempt failed after that
+	if apiErr := ctrl.contentStorage.MoveFile(
+		ctx, presignedUploadPath(fileMetadata.Id), fileMetadata.Id,
+	); apiErr != nil && apiErr != ErrFileNotFound { //nolint:errorlint
+		return nil, "", apiErr.ExtendError("problem moving uploaded content")
+	}
+
+	info, apiErr := ctrl.contentStorage.HeadFile(ctx, fileMetadata.Id)
+	if apiErr == ErrFileNotFound { //nolint:errorlint
+		msg := "file content not found, it needs to be uploaded before completing the upload"
+		return nil, "", BadDataError(errors.New(msg), msg) //nolint:err113
+	}
+
+	if apiErr != nil {
+		return nil, "", apiErr.ExtendError("problem getting file information")
+	}
+
+	if expectedEtag != nil && *expectedEtag != info.Etag {
+		return nil, "", BadDataError(
+			fmt.Errorf( //nolint:err113
+				"etag mismatch, expected %s, got %s", *expectedEtag, info.Etag,
+			),
+			"the uploaded content doesn't match the expected etag",
+		)
+	}
+
+	if apiErr := checkFileSize(
+		fileMetadata.Name, info.ContentLength, bucket.MinUploadFile, bucket.MaxUploadFile,
+	); apiErr != nil {
+		_ = ctrl.contentStorage.DeleteFile(ctx, fileMetadata.Id)
+		return nil, "", apiErr
+	}
+
+	mimeType, apiErr := ctrl.presignedUploadMimeType(
+		ctx, fileMetadata, bucket, info.ContentLength,
+	)
+	if apiErr != nil {
+		if apiErr.StatusCode() != http.StatusInternalServerError {
+			_ = ctrl.contentStorage.DeleteFile(ctx, fileMetadata.Id)
+		}
+
+		return nil, "", apiErr
+	}
+
+	if ctrl.scanAsync() {
+		return info, mimeType, nil
+	}
+
+	file, apiErr := ctrl.contentStorage.GetFile(ctx, fileMetadata.Id, nil)
+	if apiErr != nil {
+		return nil, "", apiErr.ExtendError("problem getting file content")
+	}
+	defer file.Body.Close()
+
+	if apiErr := ctrl.av.ScanReader(
+		ctx, &sequentialReaderAt{r: file.Body, offset: 0},
+	); apiErr != nil {
+		// the content is kept if the scan failed, the client can complete the upload
+		// again once the antivirus is back
+		if apiErr.GetDataString("virus") == "" {
+			return nil, "", InternalServerError(
+				fmt.Errorf("problem scanning file for viruses: %w", apiErr),
+			)
+		}
+
+		_ = ctrl.contentStorage.DeleteFile(ctx, fileMetadata.Id)
+
+		return nil, "", ctrl.reportVirus(
+			ctx, apiErr, fileMetadata.Id, fileMetadata.Name, sessionHeaders,
+		)
+	}
+
+	return info, mimeType, nil
+}
+
+func (ctrl *Controller) CompletePresignedUpload( //nolint:ireturn
+	ctx context.Context,
+	request api.CompletePresignedUploadRequestObject,
+) (api.CompletePresignedUploadResponseObject, error) {
+	logger := middleware.LoggerFromContext(ctx)
+	sessionHeaders := middleware.SessionHeadersFromContext(ctx)
+
+	fileMetadata, bucket, apiErr := ctrl.getFileMetadata(
+		ctx, request.Id, false, sessionHeaders,
+	)
+	if apiErr != nil {
+		logger.WithError(apiErr).Error("problem getting file metadata")
+		return apiErr, nil
+	}
+
+	if fileMetadata.IsUploaded {
+		return api.CompletePresignedUpload200JSONResponse(fileMetadata), nil
+	}
+
+	info, mimeType, apiErr := ctrl.checkPresignedUpload(
+		ctx, fileMetadata, bucket, request.Params.Etag, sessionHeaders,
+	)
+	if apiErr != nil {
+		logger.WithError(apiErr).Errorf("problem checking uploaded file %s", fileMetadata.Id)
+		return apiErr, nil
+	}
+
+	if apiErr := ctrl.enqueueScan(ctx, fileMetadata.Id); apiErr != nil {
+		logger.WithError(apiErr).Errorf("problem scheduling virus scan for %s", fileMetadata.Id)
+		return apiErr, nil
+	}
+
+	var metadata map[string]any
+	if fileMetadata.Metadata != nil {
+		metadata = *fileMetadata.Metadata
+	}
+
+	newMetadata, apiErr := ctrl.metadataStorage.PopulateMetadata(
+		ctx,
+		fileMetadata.Id, fileMetadata.Name, info.ContentLength, bucket.ID, info.Etag, true,
+		mimeType, metadata,
+		http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
+	)
+	if apiErr != nil {
+		logger.WithError(apiErr).Errorf(
+			"problem populating file metadata for file %s", fileMetadata.Id,
+		)
+
+		return apiErr, nil
+	}
+
+	newMetadata.ScanStatus = ctrl.uploadedScanStatus()
+
+	ctrl.publishEvent(ctx, EventFileUploaded, newMetadata, sessionHeaders)
+
+	return api.CompletePresignedUpload200JSONResponse(newMetadata), nil
+}
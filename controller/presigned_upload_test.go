@@ -0,0 +1,336 @@
+package controller_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/controller/mock"
+	"github.com/sirupsen/logrus"
+	gomock "go.uber.org/mock/gomock"
+)
+
+const presignedUploadFileID = "55af1e60-0f28-454e-885e-ea6aab2bb288"
+
+func presignedUploadBucket() controller.BucketMetadata {
+	return controller.BucketMetadata{
+		ID:                   "default",
+		MinUploadFile:        1,
+		MaxUploadFile:        100,
+		PresignedURLsEnabled: true,
+		DownloadExpiration:   30,
+		CreatedAt:            "2021-12-15T13:26:52.082485+00:00",
+		UpdatedAt:            "2021-12-15T13:26:52.082485+00:00",
+		CacheControl:         "max-age=3600",
+	}
+}
+
+func TestCreatePresignedUpload(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	metadataStorage := mock.NewMockMetadataStorage(c)
+	contentStorage := mock.NewMockContentStorage(c)
+
+	metadataStorage.EXPECT().GetBucketByID(
+		gomock.Any(), "default", gomock.Any(),
+	).Return(presignedUploadBucket(), nil)
+
+	metadataStorage.EXPECT().InitializeFile(
+		gomock.Any(),
+		presignedUploadFileID,
+		"my-file.txt",
+		int64(0),
+		"default",
+		"text/plain",
+		gomock.Any(),
+	).Return(nil)
+
+	metadataStorage.EXPECT().PopulateMetadata(
+		gomock.Any(),
+		presignedUploadFileID,
+		"my-file.txt",
+		int64(0),
+		"default",
+		"",
+		false,
+		"text/plain",
+		map[string]any{"some": "metadata"},
+		gomock.Any(),
+	).Return(api.FileMetadata{}, nil) //nolint:exhaustruct
+
+	contentStorage.EXPECT().CreatePresignedUpload(
+		gomock.Any(), "presigned/"+presignedUploadFileID, "text/plain", 1, 100, time.Hour,
+	).Return(&controller.PresignedUpload{
+		URL:    "http://s3/bucket",
+		Fields: map[string]string{"key": "presigned/" + presignedUploadFileID},
+	}, nil)
+
+	ctrl := controller.New(
+		"http://asd",
+		"/v1",
+		"asdasd",
+		metadataStorage,
+		contentStorage,
+		nil,
+		nil,
+		logger,
+	)
+
+	resp, err := ctrl.CreatePresignedUpload(
+		t.Context(),
+		api.CreatePresignedUploadRequestObject{
+			Body: &api.CreatePresignedUploadRequest{
+				BucketId: nil,
+				Id:       ptr(presignedUploadFileID),
+				Metadata: ptr(map[string]any{"some": "metadata"}),
+				MimeType: ptr("text/plain"),
+				Name:     "my-file.txt",
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert(t, api.CreatePresignedUpload201JSONResponse{
+		Id:         presignedUploadFileID,
+		Url:        "http://s3/bucket",
+		Fields:     map[string]string{"key": "presigned/" + presignedUploadFileID},
+		Expiration: 3600,
+	}, resp)
+}
+
+func TestCompletePresignedUpload(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	cases := []struct {
+		name           string
+		contentLength  int64
+		contentFound   bool
+		alreadyMoved   bool
+		scanError      *controller.APIError
+		expectedStatus int
+	}{
+		{
+			name:           "success",
+			contentLength:  12,
+			contentFound:   true,
+			alreadyMoved:   false,
+			scanError:      nil,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "content moved by a previous attempt",
+			contentLength:  12,
+			contentFound:   true,
+			alreadyMoved:   true,
+			scanError:      nil,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "content not uploaded",
+			contentFound:   false,
+			alreadyMoved:   false,
+			scanError:      nil,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "content too big",
+			contentLength:  1000,
+			contentFound:   true,
+			alreadyMoved:   false,
+			scanError:      nil,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:          "virus found",
+			contentLength: 12,
+			contentFound:  true,
+			alreadyMoved:  false,
+			scanError: func() *controller.APIError {
+				apiErr := controller.ForbiddenError(nil, "virus found")
+				apiErr.SetData("virus", "Eicar-Signature")
+
+				return apiErr
+			}(),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "antivirus unavailable",
+			contentLength:  12,
+			contentFound:   true,
+			alreadyMoved:   false,
+			scanError:      controller.InternalServerError(nil),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			metadataStorage := mock.NewMockMetadataStorage(c)
+			contentStorage := mock.NewMockContentStorage(c)
+			av := mock.NewMockAntivirus(c)
+
+			metadataStorage.EXPECT().GetFileByID(
+				gomock.Any(), presignedUploadFileID, gomock.Any(),
+			).Return(api.FileMetadata{ //nolint:exhaustruct
+				Id:         presignedUploadFileID,
+				Name:       "my-file.txt",
+				BucketId:   "default",
+				IsUploaded: false,
+				MimeType:   "text/plain",
+				Metadata:   ptr(map[string]any{"some": "metadata"}),
+			}, nil)
+
+			metadataStorage.EXPECT().GetBucketByID(
+				gomock.Any(), "default", gomock.Any(),
+			).Return(presignedUploadBucket(), nil)
+
+			// the content is checked once the client can't overwrite it anymore
+			var moveErr *controller.APIError
+			if !tc.contentFound || tc.alreadyMoved {
+				moveErr = controller.ErrFileNotFound
+			}
+
+			contentStorage.EXPECT().MoveFile(
+				gomock.Any(), "presigned/"+presignedUploadFileID, presignedUploadFileID,
+			).Return(moveErr)
+
+			switch {
+			case !tc.contentFound:
+				contentStorage.EXPECT().HeadFile(
+					gomock.Any(), presignedUploadFileID,
+				).Return(nil, controller.ErrFileNotFound)
+			case tc.scanError != nil:
+				contentStorage.EXPECT().HeadFile(
+					gomock.Any(), presignedUploadFileID,
+				).Return(&controller.File{ //nolint:exhaustruct
+					ContentLength: tc.contentLength,
+					Etag:          `"some-etag"`,
+				}, nil)
+				contentStorage.EXPECT().GetFile(
+					gomock.Any(), presignedUploadFileID, ptr("bytes=0-11"),
+				).Return(&controller.File{ //nolint:exhaustruct
+					Body: io.NopCloser(strings.NewReader("some content")),
+				}, nil)
+				contentStorage.EXPECT().GetFile(
+					gomock.Any(), presignedUploadFileID, nil,
+				).Return(&controller.File{ //nolint:exhaustruct
+					Body: io.NopCloser(strings.NewReader("some content")),
+				}, nil)
+				av.EXPECT().ScanReader(gomock.Any(), gomock.Any()).Return(tc.scanError)
+
+				// only infected content is deleted, scan failures keep it for a retry
+				if tc.scanError.GetDataString("virus") != "" {
+					contentStorage.EXPECT().DeleteFile(
+						gomock.Any(), presignedUploadFileID,
+					).Return(nil)
+					metadataStorage.EXPECT().InsertVirus(
+						gomock.Any(), presignedUploadFileID, "my-file.txt", "Eicar-Signature",
+						gomock.Any(), gomock.Any(),
+					).Return(nil)
+				}
+			case tc.expectedStatus != http.StatusOK:
+				contentStorage.EXPECT().HeadFile(
+					gomock.Any(), presignedUploadFileID,
+				).Return(&controller.File{ //nolint:exhaustruct
+					ContentLength: tc.contentLength,
+					Etag:          `"some-etag"`,
+				}, nil)
+				contentStorage.EXPECT().DeleteFile(
+					gomock.Any(), presignedUploadFileID,
+				).Return(nil)
+			default:
+				contentStorage.EXPECT().HeadFile(
+					gomock.Any(), presignedUploadFileID,
+				).Return(&controller.File{ //nolint:exhaustruct
+					ContentLength: tc.contentLength,
+					Etag:          `"some-etag"`,
+				}, nil)
+				contentStorage.EXPECT().GetFile(
+					gomock.Any(), presignedUploadFileID, ptr("bytes=0-11"),
+				).Return(&controller.File{ //nolint:exhaustruct
+					Body: io.NopCloser(strings.NewReader("some content")),
+				}, nil)
+				contentStorage.EXPECT().GetFile(
+					gomock.Any(), presignedUploadFileID, nil,
+				).Return(&controller.File{ //nolint:exhaustruct
+					Body: io.NopCloser(strings.NewReader("some content")),
+				}, nil)
+				av.EXPECT().ScanReader(gomock.Any(), gomock.Any()).Return(nil)
+				metadataStorage.EXPECT().PopulateMetadata(
+					gomock.Any(),
+					presignedUploadFileID,
+					"my-file.txt",
+					tc.contentLength,
+					"default",
+					`"some-etag"`,
+					true,
+					"text/plain",
+					map[string]any{"some": "metadata"},
+					gomock.Any(),
+				).Return(api.FileMetadata{ //nolint:exhaustruct
+					Id:         presignedUploadFileID,
+					IsUploaded: true,
+				}, nil)
+			}
+
+			ctrl := controller.New(
+				"http://asd",
+				"/v1",
+				"asdasd",
+				metadataStorage,
+				contentStorage,
+				nil,
+				av,
+				logger,
+			)
+
+			resp, err := ctrl.CompletePresignedUpload(
+				t.Context(),
+				api.CompletePresignedUploadRequestObject{
+					Id:     presignedUploadFileID,
+					Params: api.CompletePresignedUploadParams{Etag: ptr(`"some-etag"`)},
+				},
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.expectedStatus == http.StatusOK {
+				assert(t, api.CompletePresignedUpload200JSONResponse{ //nolint:exhaustruct
+					Id:         presignedUploadFileID,
+					IsUploaded: true,
+					ScanStatus: ptr(api.Clean),
+				}, resp)
+
+				return
+			}
+
+			apiErr, ok := resp.(*controller.APIError)
+			if !ok {
+				t.Fatalf("unexpected response: %#v", resp)
+			}
+
+			assert(t, tc.expectedStatus, apiErr.StatusCode())
+		})
+	}
+}
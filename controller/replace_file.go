@@ -1,9 +1,11 @@
 package controller
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 
 	"github.com/nhost/hasura-storage/api"
 	"github.com/nhost/hasura-storage/middleware"
@@ -84,7 +86,10 @@ func (ctrl *Controller) ReplaceFile( //nolint:funlen,ireturn
 	}
 
 	if apiErr = checkFileSize(
-		file.header, bucketMetadata.MinUploadFile, bucketMetadata.MaxUploadFile,
+		file.header.Filename,
+		file.header.Size,
+		bucketMetadata.MinUploadFile,
+		bucketMetadata.MaxUploadFile,
 	); apiErr != nil {
 		apiErr := fmt.Errorf("problem checking file size %s: %w", file.Name, apiErr)
 		logger.WithError(apiErr).Errorf("problem checking file size %s", file.Name)
@@ -92,15 +97,36 @@ func (ctrl *Controller) ReplaceFile( //nolint:funlen,ireturn
 		return InternalServerError(apiErr), nil
 	}
 
-	fileContent, contentType, apiErr := ctrl.getMultipartFile(file)
+	fileContent, contentType, apiErr := ctrl.getMultipartFile(file, bucketMetadata)
 	if apiErr != nil {
 		logger.WithError(apiErr).Errorf("problem getting multipart file %s", file.Name)
 		return apiErr, nil
 	}
 	defer fileContent.Close()
 
+	size := file.header.Size
+
+	var content interface {
+		io.ReadSeeker
+		io.ReaderAt
+	} = fileContent
+
+	if stripsImageMetadata(bucketMetadata, contentType) {
+		stripped, apiErr := ctrl.stripImageMetadata(
+			ctx, io.NewSectionReader(fileContent, 0, size), file.Name, contentType,
+			bucketMetadata,
+		)
+		if apiErr != nil {
+			logger.WithError(apiErr).Errorf("problem stripping metadata from %s", file.Name)
+			return apiErr, nil
+		}
+
+		content = bytes.NewReader(stripped)
+		size = int64(len(stripped))
+	}
+
 	if apiErr := ctrl.scanAndReportVirus(
-		ctx, fileContent, file.ID, file.Name, sessionHeaders,
+		ctx, content, file.ID, file.Name, sessionHeaders,
 	); apiErr != nil {
 		logger.WithError(apiErr).Errorf("problem scanning file %s for viruses", file.Name)
 		return apiErr, nil
@@ -113,7 +139,14 @@ func (ctrl *Controller) ReplaceFile( //nolint:funlen,ireturn
 		return apiErr, nil
 	}
 
-	etag, apiErr := ctrl.contentStorage.PutFile(ctx, fileContent, file.ID, contentType)
+	if apiErr := ctrl.enqueueScan(ctx, file.ID); apiErr != nil {
+		_ = ctrl.metadataStorage.SetIsUploaded(ctx, file.ID, true, sessionHeaders)
+		logger.WithError(apiErr).Errorf("problem scheduling virus scan for %s", file.Name)
+
+		return apiErr, nil
+	}
+
+	etag, apiErr := ctrl.contentStorage.PutFile(ctx, content, file.ID, contentType)
 	if apiErr != nil {
 		// let's revert the change to isUploaded
 		_ = ctrl.metadataStorage.SetIsUploaded(ctx, file.ID, true, sessionHeaders)
@@ -124,7 +157,7 @@ func (ctrl *Controller) ReplaceFile( //nolint:funlen,ireturn
 
 	newMetadata, apiErr := ctrl.metadataStorage.PopulateMetadata(
 		ctx,
-		file.ID, file.Name, file.header.Size, originalMetadata.BucketId, etag, true, contentType,
+		file.ID, file.Name, size, originalMetadata.BucketId, etag, true, contentType,
 		file.Metadata,
 		sessionHeaders,
 	)
@@ -134,6 +167,11 @@ func (ctrl *Controller) ReplaceFile( //nolint:funlen,ireturn
 	}
 
 	fastly.FileChangedToContext(ctx, request.Id)
+	ctrl.invalidateImageCache(ctx, request.Id)
+
+	newMetadata.ScanStatus = ctrl.uploadedScanStatus()
+
+	ctrl.publishEvent(ctx, EventFileReplaced, newMetadata, sessionHeaders)
 
 	return api.ReplaceFile200JSONResponse(newMetadata), nil
 }
@@ -56,10 +56,16 @@ func TestReplaceFile(t *testing.T) {
 	t.Parallel()
 
 	cases := []struct {
-		name string
+		name  string
+		async bool
 	}{
 		{
-			name: "successful",
+			name:  "successful",
+			async: false,
+		},
+		{
+			name:  "scanned asynchronously",
+			async: true,
 		},
 	}
 
@@ -161,7 +167,14 @@ func TestReplaceFile(t *testing.T) {
 				},
 				nil)
 
-			av.EXPECT().ScanReader(gomock.Any(), gomock.Any()).Return(nil)
+			var scanQueue controller.ScanQueue
+			if tc.async {
+				queue := mock.NewMockScanQueue(c)
+				queue.EXPECT().Enqueue(gomock.Any(), file.md.ID).Return(nil)
+				scanQueue = queue
+			} else {
+				av.EXPECT().ScanReader(gomock.Any(), gomock.Any()).Return(nil)
+			}
 
 			ctrl := controller.New(
 				"http://asd",
@@ -172,6 +185,7 @@ func TestReplaceFile(t *testing.T) {
 				nil,
 				av,
 				logger,
+				controller.WithScanQueue(scanQueue),
 			)
 
 			resp, err := ctrl.ReplaceFile(
@@ -185,6 +199,11 @@ func TestReplaceFile(t *testing.T) {
 				t.Fatalf("unexpected error: %v", err)
 			}
 
+			scanStatus := api.Clean
+			if tc.async {
+				scanStatus = api.Pending
+			}
+
 			assert(t, api.ReplaceFile200JSONResponse{
 				Id:               file.md.ID,
 				Name:             "a_file.txt",
@@ -197,6 +216,7 @@ func TestReplaceFile(t *testing.T) {
 				MimeType:         "text/plain; charset=utf-8",
 				UploadedByUserId: ptr("some-valid-uuid"),
 				Metadata:         ptr(map[string]any{"some": "metadata"}),
+				ScanStatus:       ptr(scanStatus),
 			}, resp,
 				cmpopts.IgnoreFields(api.FileMetadata{}, "Id", "CreatedAt", "UpdatedAt"),
 			)
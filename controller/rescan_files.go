@@ -0,0 +1,275 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"time"
+
+	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/middleware"
+)
+
+const defaultRescanConcurrency = 4
+
+// SignatureReloader is implemented by antiviruses that can be told to reload their virus
+// signatures, i.e. right after they are updated and before rescanning files.
+type SignatureReloader interface {
+	ReloadSignatures(ctx context.Context) *APIError
+}
+
+// rescanOptions select the files to rescan and how.
+type rescanOptions struct {
+	bucketID string
+	// uploadedAfter and uploadedBefore, if set, skip files created outside of
+	// [uploadedAfter, uploadedBefore)
+	uploadedAfter  time.Time
+	uploadedBefore time.Time
+	// after skips files with an id lower or equal, files are listed in order of id
+	after            string
+	concurrency      int
+	reloadSignatures bool
+	dryRun           bool
+}
+
+func newRescanOptions(params api.RescanFilesParams) rescanOptions {
+	opts := rescanOptions{ //nolint:exhaustruct
+		concurrency: defaultRescanConcurrency,
+	}
+
+	if params.BucketId != nil {
+		opts.bucketID = *params.BucketId
+	}
+
+	if params.UploadedAfter != nil {
+		opts.uploadedAfter = *params.UploadedAfter
+	}
+
+	if params.UploadedBefore != nil {
+		opts.uploadedBefore = *params.UploadedBefore
+	}
+
+	if params.After != nil {
+		opts.after = *params.After
+	}
+
+	if params.Concurrency != nil {
+		opts.concurrency = *params.Concurrency
+	}
+
+	if params.ReloadSignatures != nil {
+		opts.reloadSignatures = *params.ReloadSignatures
+	}
+
+	if params.DryRun != nil {
+		opts.dryRun = *params.DryRun
+	}
+
+	return opts
+}
+
+// selects returns if the file has to be rescanned. Files that aren't uploaded yet, are
+// pending to be scanned or are already infected are skipped.
+func (o rescanOptions) selects(file FileSummary) bool {
+	switch {
+	case !file.IsUploaded,
+		file.ScanStatus == string(api.Pending),
+		file.ScanStatus == string(api.Infected),
+		o.bucketID != "" && file.BucketID != o.bucketID,
+		o.after != "" && file.ID <= o.after,
+		!o.uploadedAfter.IsZero() && file.CreatedAt.Before(o.uploadedAfter),
+		!o.uploadedBefore.IsZero() && !file.CreatedAt.Before(o.uploadedBefore):
+		return false
+	default:
+		return true
+	}
+}
+
+func (ctrl *Controller) rescanFile(
+	ctx context.Context, file FileSummary, opts rescanOptions,
+) api.FileRescan {
+	logger := middleware.LoggerFromContext(ctx)
+	adminHeaders := http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}}
+
+	result := api.FileRescan{
+		File:        fileSummary(file),
+		Virus:       nil,
+		Quarantined: false,
+		Error:       nil,
+	}
+
+	virus, apiErr := ctrl.ScanFile(ctx, file.ID)
+	if apiErr != nil {
+		logger.WithError(apiErr).Errorf("problem rescanning file %s", file.ID)
+		result.Error = publicMessage(apiErr)
+
+		return result
+	}
+
+	if opts.dryRun {
+		if virus != "" {
+			result.Virus = &virus
+		}
+
+		return result
+	}
+
+	// the file is scanned already, record the result even if the request is cancelled
+	ctx = context.WithoutCancel(ctx)
+
+	if virus == "" {
+		if file.ScanStatus == string(api.Error) {
+			if apiErr := ctrl.metadataStorage.SetScanStatus(
+				ctx, file.ID, api.Clean, adminHeaders,
+			); apiErr != nil {
+				logger.WithError(apiErr).Errorf("problem flagging file %s as clean", file.ID)
+				result.Error = publicMessage(apiErr)
+			}
+		}
+
+		return result
+	}
+
+	result.Virus = &virus
+
+	logger.Warnf("virus %s found in file %s", virus, file.ID)
+
+	// the file is flagged first so it can't be downloaded even if moving it fails
+	if apiErr := ctrl.metadataStorage.SetScanStatus(
+		ctx, file.ID, api.Infected, adminHeaders,
+	); apiErr != nil {
+		logger.WithError(apiErr).Errorf("problem flagging file %s as infected", file.ID)
+		result.Error = publicMessage(apiErr)
+
+		return result
+	}
+
+	if apiErr := ctrl.QuarantineFile(ctx, file.ID, virus); apiErr != nil {
+		logger.WithError(apiErr).Errorf("problem quarantining file %s", file.ID)
+		result.Error = publicMessage(apiErr)
+
+		return result
+	}
+
+	result.Quarantined = true
+
+	return result
+}
+
+// rescanFiles scans up to opts.concurrency files at the same time, results are yielded
+// in the order files are listed so the last one can be used to resume.
+func (ctrl *Controller) rescanFiles(
+	ctx context.Context, opts rescanOptions,
+) iter.Seq2[api.FileRescan, *APIError] {
+	return func(yield func(api.FileRescan, *APIError) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		workers := make(chan struct{}, opts.concurrency)
+		pending := make(chan chan api.FileRescan, opts.concurrency)
+		listErr := make(chan *APIError, 1)
+
+		go func() {
+			defer close(pending)
+
+			for file, apiErr := range ctrl.metadataStorage.ListFiles(
+				ctx,
+				http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
+			) {
+				if apiErr != nil {
+					listErr <- apiErr
+					return
+				}
+
+				if !opts.selects(file) {
+					continue
+				}
+
+				select {
+				case workers <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+
+				result := make(chan api.FileRescan, 1)
+				go func() {
+					defer func() { <-workers }()
+					result <- ctrl.rescanFile(ctx, file, opts)
+				}()
+
+				select {
+				case pending <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		for result := range pending {
+			if !yield(<-result, nil) {
+				return
+			}
+		}
+
+		select {
+		case apiErr := <-listErr:
+			yield(api.FileRescan{}, apiErr) //nolint:exhaustruct
+		default:
+		}
+	}
+}
+
+func (ctrl *Controller) reloadSignatures(ctx context.Context) *APIError {
+	reloader, ok := ctrl.av.(SignatureReloader)
+	if !ok {
+		return BadDataError(
+			errors.New("antivirus can't reload signatures"), //nolint:err113
+			"the antivirus configured can't reload its signatures",
+		)
+	}
+
+	if apiErr := reloader.ReloadSignatures(ctx); apiErr != nil {
+		return apiErr.ExtendError("problem reloading virus signatures")
+	}
+
+	return nil
+}
+
+func (ctrl *Controller) RescanFiles( //nolint:ireturn
+	ctx context.Context,
+	request api.RescanFilesRequestObject,
+) (api.RescanFilesResponseObject, error) {
+	logger := middleware.LoggerFromContext(ctx)
+
+	opts := newRescanOptions(request.Params)
+
+	if opts.reloadSignatures {
+		if apiErr := ctrl.reloadSignatures(ctx); apiErr != nil {
+			logger.WithError(apiErr).Error("failed to reload virus signatures")
+			return apiErr, nil
+		}
+	}
+
+	if acceptsNDJSON(ctx) {
+		return ndjsonResponse[api.FileRescan]{ctrl.rescanFiles(ctx, opts), logger}, nil
+	}
+
+	results, apiErr := collect(ctrl.rescanFiles(ctx, opts))
+	if apiErr != nil {
+		logger.WithError(apiErr).Error("failed to rescan files")
+		return apiErr, nil
+	}
+
+	files := make([]api.FileSummary, 0)
+	for _, r := range results {
+		if r.Virus != nil {
+			files = append(files, r.File)
+		}
+	}
+
+	return api.RescanFiles200JSONResponse{
+		Infected: &files,
+		Results:  &results,
+	}, nil
+}
@@ -0,0 +1,276 @@
+package controller_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/controller/mock"
+	"github.com/sirupsen/logrus"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestRescanFiles(t *testing.T) { //nolint:funlen,maintidx
+	t.Parallel()
+
+	clean := controller.FileSummary{
+		ID:         "1a6e2c4e-6ab4-4e0d-b1c2-3f27ff5a9a41",
+		Name:       "clean.txt",
+		IsUploaded: true,
+		BucketID:   "default",
+		ScanStatus: string(api.Clean),
+		CreatedAt:  time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+	}
+	failedBefore := controller.FileSummary{
+		ID:         "2f1b9f0e-4b0a-4c55-8a3e-91d4b6f0c2a7",
+		Name:       "failed-before.txt",
+		IsUploaded: true,
+		BucketID:   "default",
+		ScanStatus: string(api.Error),
+		CreatedAt:  time.Date(2024, 7, 10, 0, 0, 0, 0, time.UTC),
+	}
+	infected := controller.FileSummary{
+		ID:         "3c9d7a52-0e8f-4f4c-9d4b-6a1e8f2b7c13",
+		Name:       "infected.txt",
+		IsUploaded: true,
+		BucketID:   "default",
+		ScanStatus: string(api.Clean),
+		CreatedAt:  time.Date(2024, 8, 10, 0, 0, 0, 0, time.UTC),
+	}
+	pending := controller.FileSummary{
+		ID:         "4d0e8b63-1f90-4a5d-8e5c-7b2f903c8d24",
+		Name:       "pending.txt",
+		IsUploaded: true,
+		BucketID:   "default",
+		ScanStatus: string(api.Pending),
+		CreatedAt:  time.Date(2024, 9, 10, 0, 0, 0, 0, time.UTC),
+	}
+	notUploaded := controller.FileSummary{
+		ID:         "5e1f9c74-2a01-4b6e-9f6d-8c3a014d9e35",
+		Name:       "not-uploaded.txt",
+		IsUploaded: false,
+		BucketID:   "default",
+		ScanStatus: string(api.Clean),
+		CreatedAt:  time.Date(2024, 10, 10, 0, 0, 0, 0, time.UTC),
+	}
+	otherBucket := controller.FileSummary{
+		ID:         "6f2a0d85-3b12-4c7f-a07e-9d4b125e0f46",
+		Name:       "other-bucket.txt",
+		IsUploaded: true,
+		BucketID:   "other",
+		ScanStatus: string(api.Clean),
+		CreatedAt:  time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+	}
+
+	summary := func(f controller.FileSummary) api.FileSummary {
+		return api.FileSummary{
+			Id:         f.ID,
+			Name:       f.Name,
+			IsUploaded: f.IsUploaded,
+			BucketId:   f.BucketID,
+		}
+	}
+
+	cases := []struct {
+		name     string
+		params   api.RescanFilesParams
+		scanned  []controller.FileSummary
+		setup    func(metadataStorage *mock.MockMetadataStorage, contentStorage *mock.MockContentStorage)
+		expected api.RescanFiles200JSONResponse
+	}{
+		{
+			name:    "successful",
+			params:  api.RescanFilesParams{}, //nolint:exhaustruct
+			scanned: []controller.FileSummary{clean, failedBefore, infected, otherBucket},
+			setup: func(
+				metadataStorage *mock.MockMetadataStorage, contentStorage *mock.MockContentStorage,
+			) {
+				metadataStorage.EXPECT().SetScanStatus(
+					gomock.Any(), failedBefore.ID, api.Clean, gomock.Any(),
+				).Return(nil)
+
+				metadataStorage.EXPECT().SetScanStatus(
+					gomock.Any(),
+					infected.ID,
+					api.Infected,
+					http.Header{"x-hasura-admin-secret": []string{"asdasd"}},
+				).Return(nil)
+				metadataStorage.EXPECT().GetFileByID(
+					gomock.Any(), infected.ID, gomock.Any(),
+				).Return(api.FileMetadata{ //nolint:exhaustruct
+					Id:   infected.ID,
+					Name: infected.Name,
+				}, nil)
+				contentStorage.EXPECT().MoveFile(
+					gomock.Any(), infected.ID, "quarantine/"+infected.ID,
+				).Return(nil)
+				metadataStorage.EXPECT().InsertVirus(
+					gomock.Any(), infected.ID, infected.Name, "Eicar-Signature",
+					gomock.Any(), gomock.Any(),
+				).Return(nil)
+			},
+			expected: api.RescanFiles200JSONResponse{
+				Infected: &[]api.FileSummary{summary(infected)},
+				Results: &[]api.FileRescan{
+					{File: summary(clean), Virus: nil, Quarantined: false, Error: nil},
+					{File: summary(failedBefore), Virus: nil, Quarantined: false, Error: nil},
+					{
+						File:        summary(infected),
+						Virus:       ptr("Eicar-Signature"),
+						Quarantined: true,
+						Error:       nil,
+					},
+					{File: summary(otherBucket), Virus: nil, Quarantined: false, Error: nil},
+				},
+			},
+		},
+		{
+			name: "dry run in bucket and date range",
+			params: api.RescanFilesParams{ //nolint:exhaustruct
+				BucketId:       ptr("default"),
+				UploadedAfter:  ptr(time.Date(2024, 7, 10, 0, 0, 0, 0, time.UTC)),
+				UploadedBefore: ptr(time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)),
+				DryRun:         ptr(true),
+			},
+			scanned: []controller.FileSummary{failedBefore, infected},
+			setup: func(_ *mock.MockMetadataStorage, _ *mock.MockContentStorage) {
+			},
+			expected: api.RescanFiles200JSONResponse{
+				Infected: &[]api.FileSummary{summary(infected)},
+				Results: &[]api.FileRescan{
+					{File: summary(failedBefore), Virus: nil, Quarantined: false, Error: nil},
+					{
+						File:        summary(infected),
+						Virus:       ptr("Eicar-Signature"),
+						Quarantined: false,
+						Error:       nil,
+					},
+				},
+			},
+		},
+		{
+			name: "resume",
+			params: api.RescanFilesParams{ //nolint:exhaustruct
+				After:       ptr(infected.ID),
+				Concurrency: ptr(1),
+			},
+			scanned: []controller.FileSummary{otherBucket},
+			setup: func(_ *mock.MockMetadataStorage, _ *mock.MockContentStorage) {
+			},
+			expected: api.RescanFiles200JSONResponse{
+				Infected: &[]api.FileSummary{},
+				Results: &[]api.FileRescan{
+					{File: summary(otherBucket), Virus: nil, Quarantined: false, Error: nil},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.ErrorLevel + 1)
+
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			metadataStorage := mock.NewMockMetadataStorage(c)
+			contentStorage := mock.NewMockContentStorage(c)
+			av := mock.NewMockAntivirus(c)
+
+			metadataStorage.EXPECT().ListFiles(gomock.Any(), gomock.Any()).Return(
+				listOf(clean, failedBefore, infected, pending, notUploaded, otherBucket),
+			)
+
+			for _, f := range tc.scanned {
+				contentStorage.EXPECT().GetFile(gomock.Any(), f.ID, nil).DoAndReturn(
+					func(context.Context, string, *string) (*controller.File, *controller.APIError) {
+						return &controller.File{ //nolint:exhaustruct
+							Body: io.NopCloser(strings.NewReader(f.Name)),
+						}, nil
+					},
+				)
+			}
+
+			av.EXPECT().ScanReader(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, r io.ReaderAt) *controller.APIError {
+					b, _ := io.ReadAll(io.NewSectionReader(r, 0, 1<<10)) //nolint:mnd
+					if string(b) != infected.Name {
+						return nil
+					}
+
+					apiErr := controller.ForbiddenError(nil, "virus found")
+					apiErr.SetData("virus", "Eicar-Signature")
+
+					return apiErr
+				},
+			).Times(len(tc.scanned))
+
+			tc.setup(metadataStorage, contentStorage)
+
+			ctrl := controller.New(
+				"http://asd",
+				"/v1",
+				"asdasd",
+				metadataStorage,
+				contentStorage,
+				nil,
+				av,
+				logger,
+			)
+
+			resp, err := ctrl.RescanFiles(
+				t.Context(),
+				api.RescanFilesRequestObject{Params: tc.params},
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			assert(t, tc.expected, resp)
+		})
+	}
+}
+
+func TestRescanFilesReloadSignatures(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	ctrl := controller.New(
+		"http://asd",
+		"/v1",
+		"asdasd",
+		mock.NewMockMetadataStorage(c),
+		mock.NewMockContentStorage(c),
+		nil,
+		mock.NewMockAntivirus(c),
+		logrus.New(),
+	)
+
+	resp, err := ctrl.RescanFiles(
+		t.Context(),
+		api.RescanFilesRequestObject{
+			Params: api.RescanFilesParams{ReloadSignatures: ptr(true)}, //nolint:exhaustruct
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	apiErr, ok := resp.(*controller.APIError)
+	if !ok {
+		t.Fatalf("expected an error, got %T", resp)
+	}
+
+	// the mock antivirus can't reload its signatures
+	assert(t, http.StatusBadRequest, apiErr.StatusCode())
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"path"
+
+	"github.com/nhost/hasura-storage/api"
+)
+
+// quarantinePrefix is where the content of infected files is moved to. Like partial tus
+// uploads it is kept in a folder, files are only served from the root of the storage,
+// and it is never considered orphaned.
+const quarantinePrefix = "quarantine"
+
+func quarantinePath(fileID string) string {
+	return path.Join(quarantinePrefix, fileID)
+}
+
+// scanAsync tells if files are scanned for viruses after being uploaded instead of
+// while they are uploaded.
+func (ctrl *Controller) scanAsync() bool {
+	return ctrl.scanQueue != nil
+}
+
+// enqueueScan schedules the file to be scanned once it is uploaded. It does nothing
+// unless files are scanned asynchronously.
+func (ctrl *Controller) enqueueScan(ctx context.Context, fileID string) *APIError {
+	if !ctrl.scanAsync() {
+		return nil
+	}
+
+	if apiErr := ctrl.scanQueue.Enqueue(ctx, fileID); apiErr != nil {
+		return apiErr.ExtendError("problem scheduling virus scan")
+	}
+
+	return nil
+}
+
+// uploadedScanStatus returns the scan status of a file that was just uploaded. It isn't
+// read from the metadata as users may not be allowed to select it.
+func (ctrl *Controller) uploadedScanStatus() *api.ScanStatus {
+	status := api.Clean
+	if ctrl.scanAsync() {
+		status = api.Pending
+	}
+
+	return &status
+}
+
+// ScanFile scans the content of an uploaded file for viruses and returns the name of
+// the virus found, if any.
+func (ctrl *Controller) ScanFile(ctx context.Context, fileID string) (string, *APIError) {
+	file, apiErr := ctrl.contentStorage.GetFile(ctx, fileID, nil)
+	if apiErr != nil {
+		return "", apiErr.ExtendError("problem getting file content")
+	}
+	defer file.Body.Close()
+
+	if apiErr := ctrl.av.ScanReader(
+		ctx, &sequentialReaderAt{r: file.Body, offset: 0},
+	); apiErr != nil {
+		if virus := apiErr.GetDataString("virus"); virus != "" {
+			return virus, nil
+		}
+
+		return "", apiErr.ExtendError("problem scanning file for viruses")
+	}
+
+	return "", nil
+}
+
+// QuarantineFile moves the content of an infected file to quarantine and records the
+// virus found on behalf of the user that uploaded it.
+func (ctrl *Controller) QuarantineFile(ctx context.Context, fileID, virus string) *APIError {
+	adminHeaders := http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}}
+
+	fileMetadata, apiErr := ctrl.metadataStorage.GetFileByID(ctx, fileID, adminHeaders)
+	if apiErr != nil {
+		return apiErr.ExtendError("problem getting file metadata")
+	}
+
+	if apiErr := ctrl.contentStorage.MoveFile(
+		ctx, fileID, quarantinePath(fileID),
+	); apiErr != nil {
+		return apiErr.ExtendError("problem moving file to quarantine")
+	}
+
+	ctrl.invalidateImageCache(ctx, fileID)
+
+	uploadedBy := http.Header{}
+	if fileMetadata.UploadedByUserId != nil {
+		uploadedBy.Set("X-Hasura-User-Id", *fileMetadata.UploadedByUserId)
+	}
+
+	if apiErr := ctrl.metadataStorage.InsertVirus(
+		ctx, fileID, fileMetadata.Name, virus, GetUserSession(uploadedBy), adminHeaders,
+	); apiErr != nil {
+		return apiErr.ExtendError("problem inserting virus into database")
+	}
+
+	return nil
+}
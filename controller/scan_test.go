@@ -0,0 +1,253 @@
+package controller_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/controller/mock"
+	"github.com/sirupsen/logrus"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestGetFileScanStatus(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name           string
+		scanStatus     api.ScanStatus
+		expectedStatus int
+	}{
+		{
+			name:           "pending",
+			scanStatus:     api.Pending,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "infected",
+			scanStatus:     api.Infected,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "error",
+			scanStatus:     api.Error,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "clean",
+			scanStatus:     api.Clean,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			metadataStorage := mock.NewMockMetadataStorage(c)
+			contentStorage := mock.NewMockContentStorage(c)
+
+			metadataStorage.EXPECT().GetFileByID(
+				gomock.Any(), "55af1e60-0f28-454e-885e-ea6aab2bb288", gomock.Any(),
+			).Return(api.FileMetadata{ //nolint:exhaustruct
+				Id:         "55af1e60-0f28-454e-885e-ea6aab2bb288",
+				Name:       "my-file.txt",
+				Size:       64,
+				BucketId:   "default",
+				Etag:       "\"55af1e60-0f28-454e-885e-ea6aab2bb288\"",
+				CreatedAt:  time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+				UpdatedAt:  time.Date(2021, 12, 27, 9, 58, 11, 0, time.UTC),
+				IsUploaded: true,
+				MimeType:   "text/plain; charset=utf-8",
+			}, nil)
+
+			// users may not be allowed to read the scan status
+			metadataStorage.EXPECT().GetFileScanStatus(
+				gomock.Any(),
+				"55af1e60-0f28-454e-885e-ea6aab2bb288",
+				http.Header{"x-hasura-admin-secret": []string{"asdasd"}},
+			).Return(tc.scanStatus, nil)
+
+			if tc.expectedStatus == http.StatusOK {
+				metadataStorage.EXPECT().GetBucketByID(
+					gomock.Any(), "default", gomock.Any(),
+				).Return(controller.BucketMetadata{ //nolint:exhaustruct
+					ID:            "default",
+					MaxUploadFile: 100,
+					CacheControl:  "max-age=3600",
+				}, nil)
+			}
+
+			ctrl := controller.New(
+				"http://asd",
+				"/v1",
+				"asdasd",
+				metadataStorage,
+				contentStorage,
+				nil,
+				mock.NewMockAntivirus(c),
+				logrus.New(),
+			)
+
+			resp, err := ctrl.GetFileMetadataHeaders(
+				t.Context(),
+				api.GetFileMetadataHeadersRequestObject{ //nolint:exhaustruct
+					Id: "55af1e60-0f28-454e-885e-ea6aab2bb288",
+				},
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			status := http.StatusOK
+			if errResp, ok := resp.(api.GetFileMetadataHeadersdefaultResponse); ok {
+				status = errResp.StatusCode
+			}
+
+			assert(t, tc.expectedStatus, status)
+		})
+	}
+}
+
+func TestScanFile(t *testing.T) {
+	t.Parallel()
+
+	virusFound := controller.ForbiddenError(
+		errors.New("virus found"), "virus found", //nolint:err113
+	)
+	virusFound.SetData("virus", "Eicar-Signature")
+
+	cases := []struct {
+		name           string
+		scanErr        *controller.APIError
+		expectedVirus  string
+		expectedStatus int
+	}{
+		{
+			name:           "clean",
+			scanErr:        nil,
+			expectedVirus:  "",
+			expectedStatus: 0,
+		},
+		{
+			name:           "infected",
+			scanErr:        virusFound,
+			expectedVirus:  "Eicar-Signature",
+			expectedStatus: 0,
+		},
+		{
+			name: "antivirus failed",
+			scanErr: controller.InternalServerError(
+				errors.New("connection refused"), //nolint:err113
+			),
+			expectedVirus:  "",
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			contentStorage := mock.NewMockContentStorage(c)
+			av := mock.NewMockAntivirus(c)
+
+			contentStorage.EXPECT().GetFile(
+				gomock.Any(), "55af1e60-0f28-454e-885e-ea6aab2bb288", nil,
+			).Return(&controller.File{ //nolint:exhaustruct
+				Body: io.NopCloser(strings.NewReader("some content")),
+			}, nil)
+
+			av.EXPECT().ScanReader(gomock.Any(), gomock.Any()).Return(tc.scanErr)
+
+			ctrl := controller.New(
+				"http://asd",
+				"/v1",
+				"asdasd",
+				mock.NewMockMetadataStorage(c),
+				contentStorage,
+				nil,
+				av,
+				logrus.New(),
+			)
+
+			virus, apiErr := ctrl.ScanFile(t.Context(), "55af1e60-0f28-454e-885e-ea6aab2bb288")
+
+			assert(t, tc.expectedVirus, virus)
+			assert(t, tc.expectedStatus, apiErr.StatusCode())
+		})
+	}
+}
+
+func TestQuarantineFile(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	metadataStorage := mock.NewMockMetadataStorage(c)
+	contentStorage := mock.NewMockContentStorage(c)
+	imageCache := mock.NewMockImageCache(c)
+
+	metadataStorage.EXPECT().GetFileByID(
+		gomock.Any(), "55af1e60-0f28-454e-885e-ea6aab2bb288", gomock.Any(),
+	).Return(api.FileMetadata{ //nolint:exhaustruct
+		Id:               "55af1e60-0f28-454e-885e-ea6aab2bb288",
+		Name:             "my-file.txt",
+		IsUploaded:       true,
+		UploadedByUserId: ptr("0f7f0ff0-f945-4597-89e1-3636b16775cd"),
+	}, nil)
+
+	contentStorage.EXPECT().MoveFile(
+		gomock.Any(),
+		"55af1e60-0f28-454e-885e-ea6aab2bb288",
+		"quarantine/55af1e60-0f28-454e-885e-ea6aab2bb288",
+	).Return(nil)
+
+	imageCache.EXPECT().DeleteFile(
+		gomock.Any(), "55af1e60-0f28-454e-885e-ea6aab2bb288",
+	).Return(nil)
+
+	metadataStorage.EXPECT().InsertVirus(
+		gomock.Any(),
+		"55af1e60-0f28-454e-885e-ea6aab2bb288",
+		"my-file.txt",
+		"Eicar-Signature",
+		map[string]any{
+			"access_token_claims": map[string]any{},
+			"hasura_headers": map[string]any{
+				"X-Hasura-User-Id": []string{"0f7f0ff0-f945-4597-89e1-3636b16775cd"},
+			},
+		},
+		http.Header{"x-hasura-admin-secret": []string{"asdasd"}},
+	).Return(nil)
+
+	ctrl := controller.New(
+		"http://asd",
+		"/v1",
+		"asdasd",
+		metadataStorage,
+		contentStorage,
+		nil,
+		nil,
+		logrus.New(),
+		controller.WithImageCache(imageCache),
+	)
+
+	if apiErr := ctrl.QuarantineFile(
+		t.Context(), "55af1e60-0f28-454e-885e-ea6aab2bb288", "Eicar-Signature",
+	); apiErr != nil {
+		t.Fatalf("unexpected error: %v", apiErr)
+	}
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/nhost/hasura-storage/image"
+)
+
+// strippedImageQuality is the quality images are encoded with after stripping their
+// metadata, high enough for the new encoding not to be noticeable.
+const strippedImageQuality = 90
+
+// stripsImageMetadata returns whether files of contentType need their metadata stripped
+// when uploaded to bucket. Formats that can't be encoded are kept as they are.
+func stripsImageMetadata(bucket BucketMetadata, contentType string) bool {
+	if !bucket.StripImageMetadata {
+		return false
+	}
+
+	format, apiErr := mimeTypeToImageType(contentType)
+
+	return apiErr == nil && format.CanExport()
+}
+
+// stripImageMetadata returns the image in content without its EXIF, XMP and IPTC
+// metadata, applying its EXIF orientation so it is still displayed the same way.
+func (ctrl *Controller) stripImageMetadata(
+	ctx context.Context,
+	content io.Reader,
+	filename string,
+	contentType string,
+	bucket BucketMetadata,
+) ([]byte, *APIError) {
+	format, apiErr := mimeTypeToImageType(contentType)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	sized := &sizeLimitReader{
+		r:        content,
+		filename: filename,
+		maxSize:  int64(bucket.MaxUploadFile),
+		size:     0,
+		err:      nil,
+	}
+
+	b, err := io.ReadAll(sized)
+	if sized.err != nil {
+		return nil, sized.err
+	}
+
+	if err != nil {
+		return nil, InternalServerError(
+			fmt.Errorf("problem reading file %s: %w", filename, err),
+		)
+	}
+
+	stripped, apiErr := ctrl.manipulateImage(
+		ctx,
+		io.NopCloser(bytes.NewReader(b)),
+		uint64(len(b)),
+		image.Options{ //nolint:exhaustruct
+			Quality:        strippedImageQuality,
+			OriginalFormat: format,
+			Format:         format,
+			StripMetadata:  true,
+			KeepICCProfile: bucket.KeepICCProfile,
+		},
+	)
+	if apiErr != nil {
+		return nil, apiErr.ExtendError("problem stripping metadata from image " + filename)
+	}
+
+	return stripped, nil
+}
@@ -0,0 +1,563 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nhost/hasura-storage/metrics"
+	"github.com/nhost/hasura-storage/middleware"
+)
+
+const (
+	tusVersion     = "1.0.0"
+	tusExtensions  = "creation,termination,expiration"
+	tusContentType = "application/offset+octet-stream"
+	// PATCH requests are split into chunks of at most this size so a dropped
+	// connection only loses the chunk in flight.
+	tusMaxChunkSize = 16 << 20 // 16 MB
+	// uploads not completed within this time since they were last modified are discarded.
+	tusUploadExpiration = 24 * time.Hour
+	// if the file isn't processed within this time once all its chunks are received,
+	// the request doing it is assumed to have failed and another one can try again.
+	tusCompletionTimeout = 15 * time.Minute
+)
+
+func tusError(ctx *gin.Context, apiErr *APIError) {
+	_ = ctx.Error(fmt.Errorf("problem processing request: %w", apiErr))
+
+	ctx.Header("Tus-Resumable", tusVersion)
+
+	if ctx.Request.Method == http.MethodHead {
+		ctx.Status(apiErr.statusCode)
+		return
+	}
+
+	ctx.JSON(apiErr.statusCode, apiErr.PublicResponse())
+}
+
+func checkTusResumable(ctx *gin.Context) bool {
+	if ctx.GetHeader("Tus-Resumable") != tusVersion {
+		ctx.Header("Tus-Version", tusVersion)
+		tusError(ctx, NewAPIError(
+			http.StatusPreconditionFailed,
+			"unsupported tus version",
+			fmt.Errorf("unsupported tus version: %s", ctx.GetHeader("Tus-Resumable")), //nolint:err113
+			nil,
+		))
+
+		return false
+	}
+
+	return true
+}
+
+// parseUploadMetadata decodes the Upload-Metadata header, a comma separated list of keys
+// and base64 encoded values.
+func parseUploadMetadata(header string) (map[string]string, *APIError) {
+	md := make(map[string]string)
+	if header == "" {
+		return md, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, BadDataError(
+				fmt.Errorf("problem decoding Upload-Metadata key %s: %w", key, err),
+				"invalid Upload-Metadata",
+			)
+		}
+
+		md[key] = string(decoded)
+	}
+
+	return md, nil
+}
+
+func newTusUpload(header http.Header) (*tusUpload, *APIError) {
+	length, err := strconv.ParseInt(header.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return nil, BadDataError(
+			fmt.Errorf("problem parsing Upload-Length: %w", err),
+			"invalid Upload-Length",
+		)
+	}
+
+	md, apiErr := parseUploadMetadata(header.Get("Upload-Metadata"))
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	upload := &tusUpload{
+		ID:        md["id"],
+		BucketID:  md["bucket-id"],
+		Name:      md["filename"],
+		MimeType:  md["filetype"],
+		Metadata:  nil,
+		Length:    length,
+		Offset:    0,
+		ExpiresAt: time.Now().Add(tusUploadExpiration),
+		Chunks:    []tusChunk{},
+		completed: false,
+	}
+
+	if upload.ID == "" {
+		upload.ID = uuid.New().String()
+	}
+
+	if upload.BucketID == "" {
+		upload.BucketID = "default"
+	}
+
+	if upload.Name == "" {
+		upload.Name = upload.ID
+	}
+
+	if upload.MimeType == "" {
+		upload.MimeType = "application/octet-stream"
+	}
+
+	if v, ok := md["metadata"]; ok {
+		if err := json.Unmarshal([]byte(v), &upload.Metadata); err != nil {
+			return nil, WrongMetadataFormatError(err)
+		}
+	}
+
+	return upload, nil
+}
+
+func setTusUploadHeaders(ctx *gin.Context, upload *tusUpload) {
+	ctx.Header("Tus-Resumable", tusVersion)
+	ctx.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	ctx.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+func (ctrl *Controller) TusOptions(ctx *gin.Context) {
+	ctx.Header("Tus-Version", tusVersion)
+	ctx.Header("Tus-Extension", tusExtensions)
+	ctx.Status(http.StatusNoContent)
+}
+
+func (ctrl *Controller) TusCreateUpload(ctx *gin.Context) {
+	if !checkTusResumable(ctx) {
+		return
+	}
+
+	sessionHeaders := middleware.SessionHeadersFromContext(ctx)
+
+	upload, apiErr := newTusUpload(ctx.Request.Header)
+	if apiErr != nil {
+		tusError(ctx, apiErr)
+		return
+	}
+
+	bucket, apiErr := ctrl.metadataStorage.GetBucketByID(
+		ctx,
+		upload.BucketID,
+		http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
+	)
+	if apiErr != nil {
+		tusError(ctx, apiErr)
+		return
+	}
+
+	metrics.BucketToContext(ctx, bucket.ID)
+
+	switch {
+	case upload.Length < int64(bucket.MinUploadFile):
+		tusError(ctx, FileTooSmallError(upload.Name, int(upload.Length), bucket.MinUploadFile))
+		return
+	case upload.Length > int64(bucket.MaxUploadFile):
+		tusError(ctx, FileTooBigError(upload.Name, int(upload.Length), bucket.MaxUploadFile))
+		return
+	}
+
+	if apiErr := checkDeclaredMimeType(bucket, upload.Name, upload.MimeType); apiErr != nil {
+		tusError(ctx, apiErr)
+		return
+	}
+
+	if apiErr := ctrl.metadataStorage.InitializeFile(
+		ctx, upload.ID, upload.Name, upload.Length, upload.BucketID, upload.MimeType,
+		sessionHeaders,
+	); apiErr != nil {
+		tusError(ctx, apiErr)
+		return
+	}
+
+	if apiErr := ctrl.saveTusUpload(ctx, upload); apiErr != nil {
+		tusError(ctx, apiErr)
+		return
+	}
+
+	setTusUploadHeaders(ctx, upload)
+	ctx.Header(
+		"Location",
+		fmt.Sprintf("%s%s/files/tus/%s", ctrl.publicURL, ctrl.apiRootPrefix, upload.ID),
+	)
+	ctx.Status(http.StatusCreated)
+}
+
+// getTusUploadForRequest loads the upload after making sure the session can see the
+// file. For files already uploaded it returns a completed upload without chunks.
+func (ctrl *Controller) getTusUploadForRequest(
+	ctx context.Context, id string, sessionHeaders http.Header,
+) (*tusUpload, *APIError) {
+	file, apiErr := ctrl.metadataStorage.GetFileByID(ctx, id, sessionHeaders)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if file.IsUploaded {
+		return &tusUpload{ //nolint:exhaustruct
+			ID:        file.Id,
+			Length:    file.Size,
+			Offset:    file.Size,
+			completed: true,
+		}, nil
+	}
+
+	upload, apiErr := ctrl.getTusUpload(ctx, id)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if time.Now().After(upload.ExpiresAt) {
+		if apiErr := ctrl.terminateTusUpload(ctx, upload); apiErr != nil {
+			return nil, apiErr
+		}
+
+		return nil, NewAPIError(
+			http.StatusGone,
+			"upload expired",
+			fmt.Errorf("upload %s expired at %s", id, upload.ExpiresAt), //nolint:err113
+			nil,
+		)
+	}
+
+	return upload, nil
+}
+
+func (ctrl *Controller) terminateTusUpload(ctx context.Context, upload *tusUpload) *APIError {
+	if apiErr := ctrl.deleteTusUpload(ctx, upload); apiErr != nil {
+		return apiErr
+	}
+
+	_, apiErr := ctrl.metadataStorage.DeleteFileByID(
+		ctx,
+		upload.ID,
+		http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
+	)
+
+	return apiErr
+}
+
+func (ctrl *Controller) TusGetOffset(ctx *gin.Context) {
+	if !checkTusResumable(ctx) {
+		return
+	}
+
+	upload, apiErr := ctrl.getTusUploadForRequest(
+		ctx, ctx.Param("id"), middleware.SessionHeadersFromContext(ctx),
+	)
+	if apiErr != nil {
+		tusError(ctx, apiErr)
+		return
+	}
+
+	setTusUploadHeaders(ctx, upload)
+	ctx.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Status(http.StatusOK)
+}
+
+// storeTusChunks reads the body in chunks of up to tusMaxChunkSize and stores each of
+// them as soon as it is read so progress is kept even if the connection drops.
+func (ctrl *Controller) storeTusChunks(
+	ctx context.Context, upload *tusUpload, body io.Reader,
+) *APIError {
+	logger := middleware.LoggerFromContext(ctx)
+
+	buf := make([]byte, min(tusMaxChunkSize, upload.Length-upload.Offset))
+
+	for upload.Offset < upload.Length {
+		n, err := io.ReadFull(body, buf[:min(int64(len(buf)), upload.Length-upload.Offset)])
+		if n > 0 {
+			chunk := tusChunk{ID: uuid.NewString(), Offset: upload.Offset, Size: int64(n)}
+
+			if _, apiErr := ctrl.contentStorage.PutFile(
+				ctx,
+				bytes.NewReader(buf[:n]),
+				tusChunkKey(upload.ID, chunk),
+				"application/octet-stream",
+			); apiErr != nil {
+				return apiErr.ExtendError("problem storing chunk")
+			}
+
+			upload.Chunks = append(upload.Chunks, chunk)
+			upload.Offset += int64(n)
+			upload.ExpiresAt = time.Now().Add(tusUploadExpiration)
+
+			if apiErr := ctrl.saveTusUpload(ctx, upload); apiErr != nil {
+				// the chunk isn't part of the stored upload, most likely another request
+				// stored a chunk at the same offset first
+				_ = ctrl.contentStorage.DeleteFile(ctx, tusChunkKey(upload.ID, chunk))
+				return apiErr
+			}
+		}
+
+		switch {
+		case err == nil:
+		case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+			return nil
+		default:
+			// the client will resume from the last chunk we stored
+			logger.WithError(err).Warn("problem reading chunk")
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// claimTusCompletion makes sure only one request processes a finished upload at a time.
+// The claim expires so the client can retry if the request doing it fails.
+func (ctrl *Controller) claimTusCompletion(ctx context.Context, upload *tusUpload) *APIError {
+	if time.Now().Before(upload.CompletingUntil) {
+		return NewAPIError(
+			http.StatusLocked,
+			"upload is being completed by another request",
+			fmt.Errorf("upload %s is being completed", upload.ID), //nolint:err113
+			nil,
+		)
+	}
+
+	upload.CompletingUntil = time.Now().Add(tusCompletionTimeout)
+
+	return ctrl.saveTusUpload(ctx, upload)
+}
+
+// completeTusUpload processes the file the same way as regular uploads do once all its
+// chunks have been received.
+func (ctrl *Controller) completeTusUpload(
+	ctx context.Context, upload *tusUpload, sessionHeaders http.Header,
+) *APIError {
+	bucket, apiErr := ctrl.metadataStorage.GetBucketByID(
+		ctx,
+		upload.BucketID,
+		http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
+	)
+	if apiErr != nil {
+		return apiErr.ExtendError("problem getting bucket metadata")
+	}
+
+	reader := newTusReader(ctx, ctrl.contentStorage, upload)
+	defer reader.Close()
+
+	head := make([]byte, mimetypeReadLimit)
+
+	n, err := io.ReadFull(reader, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return InternalServerError(
+			fmt.Errorf("problem figuring out content type for file %s: %w", upload.Name, err),
+		)
+	}
+
+	mimeType, apiErr := checkMimeType(bucket, upload.Name, upload.MimeType, head[:n])
+	if apiErr != nil {
+		// the content won't change, the upload can't be completed
+		_ = ctrl.terminateTusUpload(ctx, upload)
+		return apiErr
+	}
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return InternalServerError(fmt.Errorf("problem rewinding file: %w", err))
+	}
+
+	if apiErr := ctrl.scanAndReportVirus(
+		ctx, reader, upload.ID, upload.Name, sessionHeaders,
+	); apiErr != nil {
+		_ = ctrl.deleteTusUpload(ctx, upload)
+		return apiErr
+	}
+
+	if apiErr := ctrl.enqueueScan(ctx, upload.ID); apiErr != nil {
+		return apiErr
+	}
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return InternalServerError(fmt.Errorf("problem rewinding file: %w", err))
+	}
+
+	etag, apiErr := ctrl.contentStorage.PutFileStream(ctx, reader, upload.ID, mimeType, nil)
+	if apiErr != nil {
+		return apiErr.ExtendError("problem uploading file to storage")
+	}
+
+	metadata, apiErr := ctrl.metadataStorage.PopulateMetadata(
+		ctx,
+		upload.ID, upload.Name, upload.Length, upload.BucketID, etag, true, mimeType,
+		upload.Metadata,
+		http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
+	)
+	if apiErr != nil {
+		return apiErr.ExtendError("problem populating file metadata for file " + upload.Name)
+	}
+
+	metadata.ScanStatus = ctrl.uploadedScanStatus()
+
+	ctrl.publishEvent(ctx, EventFileUploaded, metadata, sessionHeaders)
+
+	if apiErr := ctrl.deleteTusUpload(ctx, upload); apiErr != nil {
+		middleware.LoggerFromContext(ctx).WithError(apiErr).Warn(
+			"problem cleaning up partial upload",
+		)
+	}
+
+	return nil
+}
+
+func (ctrl *Controller) TusPatchUpload(ctx *gin.Context) {
+	if !checkTusResumable(ctx) {
+		return
+	}
+
+	if ctx.ContentType() != tusContentType {
+		tusError(ctx, NewAPIError(
+			http.StatusUnsupportedMediaType,
+			"Content-Type must be "+tusContentType,
+			fmt.Errorf("wrong content type: %s", ctx.ContentType()), //nolint:err113
+			nil,
+		))
+
+		return
+	}
+
+	id := ctx.Param("id")
+	sessionHeaders := middleware.SessionHeadersFromContext(ctx)
+
+	// we don't want to lose the chunks received so far if the client goes away
+	reqCtx := context.WithoutCancel(ctx.Request.Context())
+
+	upload, apiErr := ctrl.getTusUploadForRequest(reqCtx, id, sessionHeaders)
+	if apiErr != nil {
+		tusError(ctx, apiErr)
+		return
+	}
+
+	offset, err := strconv.ParseInt(ctx.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset != upload.Offset {
+		tusError(ctx, NewAPIError(
+			http.StatusConflict,
+			"Upload-Offset doesn't match the current offset",
+			fmt.Errorf( //nolint:err113
+				"offset mismatch, got %s, expected %d", ctx.GetHeader("Upload-Offset"), upload.Offset,
+			),
+			nil,
+		))
+
+		return
+	}
+
+	if upload.completed {
+		setTusUploadHeaders(ctx, upload)
+		ctx.Status(http.StatusNoContent)
+
+		return
+	}
+
+	if ctx.Request.ContentLength > upload.Length-upload.Offset {
+		tusError(ctx, NewAPIError(
+			http.StatusRequestEntityTooLarge,
+			"chunk exceeds the length of the upload",
+			fmt.Errorf("chunk of %d bytes exceeds upload length", ctx.Request.ContentLength), //nolint:err113,lll
+			nil,
+		))
+
+		return
+	}
+
+	if apiErr := ctrl.storeTusChunks(reqCtx, upload, ctx.Request.Body); apiErr != nil {
+		tusError(ctx, apiErr)
+		return
+	}
+
+	if upload.Offset == upload.Length {
+		if apiErr := ctrl.claimTusCompletion(reqCtx, upload); apiErr != nil {
+			tusError(ctx, apiErr)
+			return
+		}
+
+		if apiErr := ctrl.completeTusUpload(reqCtx, upload, sessionHeaders); apiErr != nil {
+			tusError(ctx, apiErr)
+			return
+		}
+	}
+
+	setTusUploadHeaders(ctx, upload)
+	ctx.Status(http.StatusNoContent)
+}
+
+func (ctrl *Controller) TusTerminateUpload(ctx *gin.Context) {
+	if !checkTusResumable(ctx) {
+		return
+	}
+
+	id := ctx.Param("id")
+
+	upload, apiErr := ctrl.getTusUploadForRequest(
+		ctx, id, middleware.SessionHeadersFromContext(ctx),
+	)
+	if apiErr != nil {
+		tusError(ctx, apiErr)
+		return
+	}
+
+	if upload.completed {
+		tusError(ctx, BadDataError(
+			fmt.Errorf("upload %s is already completed", id), //nolint:err113
+			"upload already completed, delete the file instead",
+		))
+
+		return
+	}
+
+	if _, apiErr := ctrl.metadataStorage.DeleteFileByID(
+		ctx, id, middleware.SessionHeadersFromContext(ctx),
+	); apiErr != nil {
+		tusError(ctx, apiErr)
+		return
+	}
+
+	if apiErr := ctrl.deleteTusUpload(ctx, upload); apiErr != nil {
+		tusError(ctx, apiErr)
+		return
+	}
+
+	ctx.Header("Tus-Resumable", tusVersion)
+	ctx.Status(http.StatusNoContent)
+}
+
+// SetupTusRouter registers the tus endpoints. They are served outside of the OpenAPI
+// router as request validation would buffer the whole body in memory.
+func (ctrl *Controller) SetupTusRouter(router gin.IRouter) {
+	router.Use(middleware.SessionHeaders())
+	router.OPTIONS("", ctrl.TusOptions)
+	router.POST("", ctrl.TusCreateUpload)
+	router.HEAD("/:id", ctrl.TusGetOffset)
+	router.PATCH("/:id", ctrl.TusPatchUpload)
+	router.DELETE("/:id", ctrl.TusTerminateUpload)
+}
@@ -0,0 +1,284 @@
+package controller_test
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/controller/mock"
+	"github.com/nhost/hasura-storage/storage"
+	"github.com/sirupsen/logrus"
+	gomock "go.uber.org/mock/gomock"
+)
+
+const tusFileID = "38288c85-02af-416b-b075-11c4dae9a97b"
+
+func tusRequest(
+	t *testing.T, router http.Handler, method, url, body string, headers map[string]string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequestWithContext(t.Context(), method, url, strings.NewReader(body))
+	req.Header.Set("Tus-Resumable", "1.0.0")
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func getTusRouter(
+	t *testing.T,
+	metadataStorage controller.MetadataStorage,
+	av controller.Antivirus,
+) (*gin.Engine, *storage.Filesystem) {
+	t.Helper()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	contentStorage, err := storage.NewFilesystem(t.TempDir(), []byte("signing-key"), logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctrl := controller.New(
+		"http://asd",
+		"/v1",
+		"asdasd",
+		metadataStorage,
+		contentStorage,
+		nil,
+		av,
+		logger,
+	)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	ctrl.SetupTusRouter(router.Group("/v1/files/tus"))
+
+	return router, contentStorage
+}
+
+func TestTusUpload(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	metadataStorage := mock.NewMockMetadataStorage(c)
+	av := mock.NewMockAntivirus(c)
+
+	router, contentStorage := getTusRouter(t, metadataStorage, av)
+
+	content := "some content that will be uploaded in two parts"
+
+	// once when creating the upload and once when completing it
+	metadataStorage.EXPECT().GetBucketByID(
+		gomock.Any(), "default", gomock.Any(),
+	).Return(controller.BucketMetadata{ //nolint:exhaustruct
+		ID:            "default",
+		MinUploadFile: 0,
+		MaxUploadFile: 100,
+	}, nil).Times(2)
+
+	metadataStorage.EXPECT().InitializeFile(
+		gomock.Any(),
+		tusFileID,
+		"a_file.txt",
+		int64(len(content)),
+		"default",
+		"application/octet-stream",
+		gomock.Any(),
+	).Return(nil)
+
+	metadataStorage.EXPECT().GetFileByID(
+		gomock.Any(), tusFileID, gomock.Any(),
+	).Return(api.FileMetadata{ //nolint:exhaustruct
+		Id:         tusFileID,
+		IsUploaded: false,
+	}, nil).Times(4)
+
+	av.EXPECT().ScanReader(gomock.Any(), ReaderMatcher(content)).Return(nil)
+
+	metadataStorage.EXPECT().PopulateMetadata(
+		gomock.Any(),
+		tusFileID,
+		"a_file.txt",
+		int64(len(content)),
+		"default",
+		gomock.Any(),
+		true,
+		"text/plain; charset=utf-8",
+		map[string]any{"some": "metadata"},
+		gomock.Any(),
+	).Return(api.FileMetadata{}, nil) //nolint:exhaustruct
+
+	b64 := base64.StdEncoding.EncodeToString
+
+	resp := tusRequest(t, router, http.MethodPost, "/v1/files/tus", "", map[string]string{
+		"Upload-Length": "47",
+		"Upload-Metadata": "filename " + b64([]byte("a_file.txt")) +
+			",id " + b64([]byte(tusFileID)) +
+			",metadata " + b64([]byte(`{"some":"metadata"}`)),
+	})
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("unexpected status code creating upload: %d %s", resp.Code, resp.Body)
+	}
+
+	assert(t, "http://asd/v1/files/tus/"+tusFileID, resp.Header().Get("Location"))
+
+	patchHeaders := func(offset string) map[string]string {
+		return map[string]string{
+			"Content-Type":  "application/offset+octet-stream",
+			"Upload-Offset": offset,
+		}
+	}
+
+	resp = tusRequest(
+		t, router, http.MethodPatch, "/v1/files/tus/"+tusFileID, content[:20], patchHeaders("0"),
+	)
+	if resp.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status code patching upload: %d %s", resp.Code, resp.Body)
+	}
+
+	assert(t, "20", resp.Header().Get("Upload-Offset"))
+
+	resp = tusRequest(
+		t, router, http.MethodPatch, "/v1/files/tus/"+tusFileID, content[20:], patchHeaders("10"),
+	)
+	if resp.Code != http.StatusConflict {
+		t.Fatalf("expected a conflict with the wrong offset, got: %d", resp.Code)
+	}
+
+	resp = tusRequest(t, router, http.MethodHead, "/v1/files/tus/"+tusFileID, "", nil)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("unexpected status code getting offset: %d", resp.Code)
+	}
+
+	assert(t, "20", resp.Header().Get("Upload-Offset"))
+	assert(t, "47", resp.Header().Get("Upload-Length"))
+
+	resp = tusRequest(
+		t, router, http.MethodPatch, "/v1/files/tus/"+tusFileID, content[20:], patchHeaders("20"),
+	)
+	if resp.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status code patching upload: %d %s", resp.Code, resp.Body)
+	}
+
+	assert(t, "47", resp.Header().Get("Upload-Offset"))
+
+	file, apiErr := contentStorage.GetFile(t.Context(), tusFileID, nil)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	defer file.Body.Close()
+
+	b, err := io.ReadAll(file.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, content, string(b))
+
+	assert(t, []string{tusFileID}, collect(t, contentStorage.ListFiles(t.Context())))
+}
+
+func TestTusTerminateUpload(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	metadataStorage := mock.NewMockMetadataStorage(c)
+
+	router, contentStorage := getTusRouter(t, metadataStorage, nil)
+
+	metadataStorage.EXPECT().GetBucketByID(
+		gomock.Any(), "default", gomock.Any(),
+	).Return(controller.BucketMetadata{ //nolint:exhaustruct
+		ID:            "default",
+		MaxUploadFile: 100,
+	}, nil)
+	metadataStorage.EXPECT().InitializeFile(
+		gomock.Any(), tusFileID, tusFileID, int64(10), "default", "text/plain", gomock.Any(),
+	).Return(nil)
+	metadataStorage.EXPECT().GetFileByID(
+		gomock.Any(), tusFileID, gomock.Any(),
+	).Return(api.FileMetadata{Id: tusFileID}, nil).Times(2) //nolint:exhaustruct
+	metadataStorage.EXPECT().DeleteFileByID(gomock.Any(), tusFileID, gomock.Any()).Return(
+		api.FileMetadata{}, nil, //nolint:exhaustruct
+	)
+
+	resp := tusRequest(t, router, http.MethodPost, "/v1/files/tus", "", map[string]string{
+		"Upload-Length": "10",
+		"Upload-Metadata": "id " + base64.StdEncoding.EncodeToString([]byte(tusFileID)) +
+			",filetype " + base64.StdEncoding.EncodeToString([]byte("text/plain")),
+	})
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("unexpected status code creating upload: %d %s", resp.Code, resp.Body)
+	}
+
+	resp = tusRequest(
+		t, router, http.MethodPatch, "/v1/files/tus/"+tusFileID, "12345", map[string]string{
+			"Content-Type":  "application/offset+octet-stream",
+			"Upload-Offset": "0",
+		},
+	)
+	if resp.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status code patching upload: %d %s", resp.Code, resp.Body)
+	}
+
+	resp = tusRequest(t, router, http.MethodDelete, "/v1/files/tus/"+tusFileID, "", nil)
+	if resp.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status code terminating upload: %d %s", resp.Code, resp.Body)
+	}
+
+	assert(t, []string{}, collect(t, contentStorage.ListFiles(t.Context())))
+}
+
+func TestTusUploadNotFound(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	metadataStorage := mock.NewMockMetadataStorage(c)
+
+	router, _ := getTusRouter(t, metadataStorage, nil)
+
+	// the file is pending but it wasn't uploaded with tus
+	metadataStorage.EXPECT().GetFileByID(
+		gomock.Any(), tusFileID, gomock.Any(),
+	).Return(api.FileMetadata{Id: tusFileID}, nil) //nolint:exhaustruct
+
+	resp := tusRequest(t, router, http.MethodHead, "/v1/files/tus/"+tusFileID, "", nil)
+	assert(t, http.StatusNotFound, resp.Code)
+}
+
+func TestTusUnsupportedVersion(t *testing.T) {
+	t.Parallel()
+
+	router, _ := getTusRouter(t, nil, nil)
+
+	req := httptest.NewRequestWithContext(
+		t.Context(), http.MethodPost, "/v1/files/tus", http.NoBody,
+	)
+	req.Header.Set("Tus-Resumable", "0.2.2")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert(t, http.StatusPreconditionFailed, w.Code)
+	assert(t, "1.0.0", w.Header().Get("Tus-Version"))
+}
@@ -0,0 +1,293 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// Partial tus uploads are kept in the content storage under this prefix. Each upload
+// gets a folder with an "info" object describing its state and one object per chunk
+// received, named after the offset where the chunk starts. The info object is only
+// replaced if it didn't change since it was read, so requests to different instances
+// of the service can't overwrite each other's progress.
+const tusPrefix = "tus"
+
+type tusChunk struct {
+	// ID tells apart chunks stored at the same offset by concurrent requests.
+	ID     string `json:"id"`
+	Offset int64  `json:"offset"`
+	Size   int64  `json:"size"`
+}
+
+type tusUpload struct {
+	ID        string         `json:"id"`
+	BucketID  string         `json:"bucketId"`
+	Name      string         `json:"name"`
+	MimeType  string         `json:"mimeType"`
+	Metadata  map[string]any `json:"metadata"`
+	Length    int64          `json:"length"`
+	Offset    int64          `json:"offset"`
+	ExpiresAt time.Time      `json:"expiresAt"`
+	Chunks    []tusChunk     `json:"chunks"`
+	// set while a request processes the finished upload, see claimTusCompletion
+	CompletingUntil time.Time `json:"completingUntil"`
+	// set when the file was already processed and the partial data is gone
+	completed bool
+	// etag of the info object when it was read, empty for new uploads
+	etag string
+}
+
+func tusInfoKey(id string) string {
+	return path.Join(tusPrefix, id, "info")
+}
+
+func tusChunkKey(id string, chunk tusChunk) string {
+	return path.Join(tusPrefix, id, fmt.Sprintf("%020d-%s", chunk.Offset, chunk.ID))
+}
+
+// tusUploadIDFromKey returns the upload a key in the content storage belongs to, if any.
+func tusUploadIDFromKey(key string) (string, bool) {
+	parts := strings.Split(key, "/")
+	if len(parts) != 3 || parts[0] != tusPrefix { //nolint:mnd
+		return "", false
+	}
+
+	return parts[1], true
+}
+
+// saveTusUpload stores the upload info unless another request modified it since it was
+// read, in which case it returns a conflict.
+func (ctrl *Controller) saveTusUpload(ctx context.Context, upload *tusUpload) *APIError {
+	b, err := json.Marshal(upload)
+	if err != nil {
+		return InternalServerError(fmt.Errorf("problem encoding upload info: %w", err))
+	}
+
+	etag, apiErr := ctrl.contentStorage.PutFileIfMatch(
+		ctx, bytes.NewReader(b), tusInfoKey(upload.ID), "application/json", upload.etag,
+	)
+	if apiErr == ErrFileModified { //nolint:errorlint
+		return NewAPIError(
+			http.StatusConflict,
+			"upload was modified by another request",
+			fmt.Errorf("upload %s was modified concurrently", upload.ID), //nolint:err113
+			nil,
+		)
+	}
+
+	if apiErr != nil {
+		return apiErr.ExtendError("problem storing upload info")
+	}
+
+	upload.etag = etag
+
+	return nil
+}
+
+func (ctrl *Controller) getTusUpload(ctx context.Context, id string) (*tusUpload, *APIError) {
+	file, apiErr := ctrl.contentStorage.GetFile(ctx, tusInfoKey(id), nil)
+	if apiErr == ErrFileNotFound { //nolint:errorlint
+		// the file wasn't uploaded with tus or the upload was already cleaned up
+		return nil, apiErr
+	}
+
+	if apiErr != nil {
+		return nil, apiErr.ExtendError("problem getting upload info")
+	}
+	defer file.Body.Close()
+
+	var upload tusUpload
+	if err := json.NewDecoder(file.Body).Decode(&upload); err != nil {
+		return nil, InternalServerError(fmt.Errorf("problem decoding upload info: %w", err))
+	}
+
+	upload.etag = file.Etag
+
+	return &upload, nil
+}
+
+// deleteTusUpload removes all the partial data of an upload. It carries on if some
+// object can't be deleted, anything left behind is cleaned up by delete-orphans.
+func (ctrl *Controller) deleteTusUpload(ctx context.Context, upload *tusUpload) *APIError {
+	var errs error
+
+	for _, chunk := range upload.Chunks {
+		if apiErr := ctrl.contentStorage.DeleteFile(
+			ctx, tusChunkKey(upload.ID, chunk),
+		); apiErr != nil {
+			errs = errors.Join(errs, apiErr)
+		}
+	}
+
+	if apiErr := ctrl.contentStorage.DeleteFile(ctx, tusInfoKey(upload.ID)); apiErr != nil {
+		errs = errors.Join(errs, apiErr)
+	}
+
+	if errs != nil {
+		return InternalServerError(fmt.Errorf("problem deleting partial upload: %w", errs))
+	}
+
+	return nil
+}
+
+// tusReader exposes the chunks of a completed upload as a single file without copying
+// them locally. Sequential reads stream each chunk, ReadAt uses range requests.
+type tusReader struct {
+	ctx     context.Context //nolint:containedctx
+	storage ContentStorage
+	upload  *tusUpload
+	offset  int64
+	body    io.ReadCloser
+}
+
+func newTusReader(ctx context.Context, storage ContentStorage, upload *tusUpload) *tusReader {
+	return &tusReader{
+		ctx:     ctx,
+		storage: storage,
+		upload:  upload,
+		offset:  0,
+		body:    nil,
+	}
+}
+
+func (r *tusReader) chunkAt(offset int64) (tusChunk, bool) {
+	for _, chunk := range r.upload.Chunks {
+		if offset >= chunk.Offset && offset < chunk.Offset+chunk.Size {
+			return chunk, true
+		}
+	}
+
+	return tusChunk{}, false
+}
+
+func (r *tusReader) openRange(chunk tusChunk, from, to int64) (io.ReadCloser, error) {
+	downloadRange := fmt.Sprintf("bytes=%d-%d", from-chunk.Offset, to-chunk.Offset)
+
+	file, apiErr := r.storage.GetFile(
+		r.ctx, tusChunkKey(r.upload.ID, chunk), &downloadRange,
+	)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return file.Body, nil
+}
+
+func (r *tusReader) Read(p []byte) (int, error) {
+	for {
+		if r.offset >= r.upload.Length {
+			return 0, io.EOF
+		}
+
+		if r.body == nil {
+			chunk, ok := r.chunkAt(r.offset)
+			if !ok {
+				return 0, fmt.Errorf("missing chunk for offset %d", r.offset) //nolint:err113
+			}
+
+			body, err := r.openRange(chunk, r.offset, chunk.Offset+chunk.Size-1)
+			if err != nil {
+				return 0, err
+			}
+
+			r.body = body
+		}
+
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+
+		if errors.Is(err, io.EOF) {
+			r.body.Close()
+			r.body = nil
+
+			if n == 0 {
+				continue
+			}
+
+			return n, nil
+		}
+
+		return n, err //nolint:wrapcheck
+	}
+}
+
+func (r *tusReader) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = r.offset + offset
+	case io.SeekEnd:
+		abs = r.upload.Length + offset
+	default:
+		return 0, errors.New("invalid whence") //nolint:err113
+	}
+
+	if abs < 0 {
+		return 0, errors.New("negative position") //nolint:err113
+	}
+
+	if abs != r.offset && r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+
+	r.offset = abs
+
+	return abs, nil
+}
+
+func (r *tusReader) ReadAt(p []byte, off int64) (int, error) {
+	read := 0
+
+	for read < len(p) {
+		pos := off + int64(read)
+		if pos >= r.upload.Length {
+			return read, io.EOF
+		}
+
+		chunk, ok := r.chunkAt(pos)
+		if !ok {
+			return read, fmt.Errorf("missing chunk for offset %d", pos) //nolint:err113
+		}
+
+		to := min(chunk.Offset+chunk.Size, pos+int64(len(p)-read)) - 1
+
+		body, err := r.openRange(chunk, pos, to)
+		if err != nil {
+			return read, err
+		}
+
+		n, err := io.ReadFull(body, p[read:read+int(to-pos+1)])
+		body.Close()
+
+		read += n
+
+		if err != nil {
+			return read, err //nolint:wrapcheck
+		}
+	}
+
+	return read, nil
+}
+
+func (r *tusReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+
+	err := r.body.Close()
+	r.body = nil
+
+	return err //nolint:wrapcheck
+}
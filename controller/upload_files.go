@@ -1,15 +1,19 @@
 package controller
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 
-	"github.com/gabriel-vasile/mimetype"
 	"github.com/google/uuid"
 	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/metrics"
 	"github.com/nhost/hasura-storage/middleware"
 )
 
@@ -21,6 +25,9 @@ type UploadFileResponse struct {
 	Error          *ErrorResponse     `json:"error,omitempty"`
 }
 
+// mimetype needs at most this many bytes from the beginning of a file to detect its type.
+const mimetypeReadLimit = 3072
+
 type fileData struct {
 	Name     string         `json:"name"`
 	ID       string         `json:"id"`
@@ -28,22 +35,19 @@ type fileData struct {
 	header   *multipart.FileHeader
 }
 
-type uploadFileRequest struct {
-	bucketID string
-	files    []fileData
-}
-
-func checkFileSize(file *multipart.FileHeader, minSize, maxSize int) *APIError {
-	if minSize > int(file.Size) {
-		return FileTooSmallError(file.Filename, int(file.Size), minSize)
-	} else if int(file.Size) > maxSize {
-		return FileTooBigError(file.Filename, int(file.Size), maxSize)
+func checkFileSize(filename string, size int64, minSize, maxSize int) *APIError {
+	if int64(minSize) > size {
+		return FileTooSmallError(filename, int(size), minSize)
+	} else if size > int64(maxSize) {
+		return FileTooBigError(filename, int(size), maxSize)
 	}
 
 	return nil
 }
 
-func (ctrl *Controller) getMultipartFile(file fileData) (multipart.File, string, *APIError) {
+func (ctrl *Controller) getMultipartFile(
+	file fileData, bucket BucketMetadata,
+) (multipart.File, string, *APIError) {
 	fileContent, err := file.header.Open()
 	if err != nil {
 		return nil, "", InternalServerError(
@@ -51,92 +55,282 @@ func (ctrl *Controller) getMultipartFile(file fileData) (multipart.File, string,
 		)
 	}
 
-	contentType := file.header.Header.Get("Content-Type")
-	if contentType != "" && contentType != "application/octet-stream" {
-		return fileContent, contentType, nil
-	}
+	head := make([]byte, mimetypeReadLimit)
+
+	n, err := fileContent.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		fileContent.Close()
 
-	mt, err := mimetype.DetectReader(fileContent)
-	if err != nil {
 		return nil, "",
 			InternalServerError(
 				fmt.Errorf("problem figuring out content type for file %s: %w", file.Name, err),
 			)
 	}
 
-	return fileContent, mt.String(), nil
+	contentType, apiErr := checkMimeType(
+		bucket, file.Name, file.header.Header.Get("Content-Type"), head[:n],
+	)
+	if apiErr != nil {
+		fileContent.Close()
+		return nil, "", apiErr
+	}
+
+	return fileContent, contentType, nil
+}
+
+func (ctrl *Controller) reportVirus(
+	ctx context.Context,
+	err *APIError,
+	fileID string,
+	filename string,
+	headers http.Header,
+) *APIError {
+	err.SetData("file", filename)
+
+	userSession := GetUserSession(headers)
+
+	if err := ctrl.metadataStorage.InsertVirus(
+		ctx, fileID, filename, err.GetDataString("virus"), userSession,
+		http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
+	); err != nil {
+		err := err.ExtendError("problem inserting virus into database")
+		return err
+	}
+
+	return err
 }
 
 func (ctrl *Controller) scanAndReportVirus(
 	ctx context.Context,
-	fileContent multipart.File,
+	fileContent io.ReaderAt,
 	fileID string,
 	filename string,
 	headers http.Header,
 ) *APIError {
+	if ctrl.scanAsync() {
+		return nil
+	}
+
 	if err := ctrl.av.ScanReader(ctx, fileContent); err != nil {
-		err.SetData("file", filename)
+		return ctrl.reportVirus(ctx, err, fileID, filename, headers)
+	}
 
-		userSession := GetUserSession(headers)
+	return nil
+}
 
-		if err := ctrl.metadataStorage.InsertVirus(
-			ctx, fileID, filename, err.GetDataString("virus"), userSession,
-			http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
-		); err != nil {
-			err := err.ExtendError("problem inserting virus into database")
-			return err
+// sizeLimitReader counts the bytes read and fails as soon as they go over maxSize.
+type sizeLimitReader struct {
+	r        io.Reader
+	filename string
+	maxSize  int64
+	size     int64
+	err      *APIError
+}
+
+func (r *sizeLimitReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.size += int64(n)
+
+	if r.size > r.maxSize {
+		r.err = FileTooBigError(r.filename, int(r.size), int(r.maxSize))
+		return n, r.err
+	}
+
+	return n, err //nolint:wrapcheck
+}
+
+// sequentialReaderAt exposes a stream as an io.ReaderAt for consumers that read it in
+// order, like the antivirus, so they can scan content while it is being uploaded.
+type sequentialReaderAt struct {
+	r      io.Reader
+	offset int64
+}
+
+func (s *sequentialReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off != s.offset {
+		return 0, fmt.Errorf( //nolint:err113
+			"non sequential read at offset %d, expected %d", off, s.offset,
+		)
+	}
+
+	n, err := io.ReadFull(s.r, p)
+	s.offset += int64(n)
+
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return n, io.EOF
+	}
+
+	return n, err //nolint:wrapcheck
+}
+
+// storeFile streams the content to the content storage while the antivirus scans it.
+// The file is only made available once the scan passed and the content is known to
+// have the right size. The returned bool tells if a virus was found.
+func (ctrl *Controller) storeFile(
+	ctx context.Context,
+	file fileData,
+	content *sizeLimitReader,
+	contentType string,
+	minSize int,
+	sessionHeaders http.Header,
+) (string, bool, *APIError) {
+	checkSize := func() *APIError {
+		if content.size < int64(minSize) {
+			return FileTooSmallError(file.Name, int(content.size), minSize)
 		}
 
-		return err
+		return nil
 	}
 
-	return nil
+	if ctrl.scanAsync() {
+		etag, apiErr := ctrl.contentStorage.PutFileStream(
+			ctx, content, file.ID, contentType, checkSize,
+		)
+		if content.err != nil {
+			return "", false, content.err
+		}
+
+		return etag, false, apiErr
+	}
+
+	scanReader, scanWriter := io.Pipe()
+	scanResult := make(chan *APIError, 1)
+
+	go func() {
+		apiErr := ctrl.av.ScanReader(ctx, &sequentialReaderAt{r: scanReader, offset: 0})
+		// the antivirus may stop reading early, we don't want to block the upload
+		_, _ = io.Copy(io.Discard, scanReader)
+		scanResult <- apiErr
+	}()
+
+	virusFound := false
+	scanned := false
+
+	etag, apiErr := ctrl.contentStorage.PutFileStream(
+		ctx, io.TeeReader(content, scanWriter), file.ID, contentType,
+		func() *APIError {
+			scanWriter.Close()
+
+			scanned = true
+			if apiErr := <-scanResult; apiErr != nil {
+				virusFound = true
+				return ctrl.reportVirus(ctx, apiErr, file.ID, file.Name, sessionHeaders)
+			}
+
+			return checkSize()
+		},
+	)
+	if !scanned {
+		// the upload failed before reading everything, stop the antivirus
+		scanWriter.CloseWithError(io.ErrUnexpectedEOF)
+		<-scanResult
+	}
+
+	if content.err != nil {
+		return "", false, content.err
+	}
+
+	return etag, virusFound, apiErr
 }
 
 func (ctrl *Controller) processFile(
 	ctx context.Context,
 	file fileData,
+	content io.Reader,
+	contentType string,
 	bucket BucketMetadata,
 	sessionHeaders http.Header,
 ) (api.FileMetadata, *APIError) {
-	if err := checkFileSize(file.header, bucket.MinUploadFile, bucket.MaxUploadFile); err != nil {
+	// the declared type is never trusted, it has to match the content
+	buffered := bufio.NewReaderSize(content, mimetypeReadLimit)
+
+	head, err := buffered.Peek(mimetypeReadLimit)
+	if err != nil && !errors.Is(err, io.EOF) {
 		return api.FileMetadata{}, InternalServerError(
-			fmt.Errorf("problem checking file size %s: %w", file.Name, err),
+			fmt.Errorf("problem figuring out content type for file %s: %w", file.Name, err),
 		)
 	}
 
-	fileContent, contentType, err := ctrl.getMultipartFile(file)
-	if err != nil {
-		return api.FileMetadata{}, err
+	contentType, apiErr := checkMimeType(bucket, file.Name, contentType, head)
+	if apiErr != nil {
+		return api.FileMetadata{}, apiErr
 	}
-	defer fileContent.Close()
 
-	if err := ctrl.metadataStorage.InitializeFile(
-		ctx, file.ID, file.Name, file.header.Size, bucket.ID, contentType, sessionHeaders,
-	); err != nil {
-		return api.FileMetadata{}, err
+	var (
+		body io.Reader = buffered
+		// the size is only known in advance if the content had to be processed
+		size int64
+	)
+
+	if stripsImageMetadata(bucket, contentType) {
+		stripped, apiErr := ctrl.stripImageMetadata(
+			ctx, buffered, file.Name, contentType, bucket,
+		)
+		if apiErr != nil {
+			return api.FileMetadata{}, apiErr
+		}
+
+		body = bytes.NewReader(stripped)
+		size = int64(len(stripped))
 	}
 
-	if err := ctrl.scanAndReportVirus(
-		ctx, fileContent, file.ID, file.Name, sessionHeaders,
-	); err != nil {
-		return api.FileMetadata{}, err
+	if apiErr := ctrl.metadataStorage.InitializeFile(
+		ctx, file.ID, file.Name, size, bucket.ID, contentType, sessionHeaders,
+	); apiErr != nil {
+		return api.FileMetadata{}, apiErr
 	}
 
-	etag, apiErr := ctrl.contentStorage.PutFile(ctx, fileContent, file.ID, contentType)
-	if apiErr != nil {
-		_ = ctrl.metadataStorage.DeleteFileByID(
+	if apiErr := ctrl.enqueueScan(ctx, file.ID); apiErr != nil {
+		_, _ = ctrl.metadataStorage.DeleteFileByID(
 			ctx,
 			file.ID,
 			http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
 		)
 
+		return api.FileMetadata{}, apiErr
+	}
+
+	sized := &sizeLimitReader{
+		r:        body,
+		filename: file.Name,
+		maxSize:  int64(bucket.MaxUploadFile),
+		size:     0,
+		err:      nil,
+	}
+
+	etag, virusFound, apiErr := ctrl.storeFile(
+		ctx, file, sized, contentType, bucket.MinUploadFile, sessionHeaders,
+	)
+	if apiErr != nil {
+		if !virusFound {
+			_, _ = ctrl.metadataStorage.DeleteFileByID(
+				ctx,
+				file.ID,
+				http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
+			)
+		}
+
 		return api.FileMetadata{}, apiErr.ExtendError("problem uploading file to storage")
 	}
 
+	fileMetadata := file.Metadata
+	if ctrl.imageInfoOnUpload {
+		// the upload succeeded so it isn't worth failing it because of the info
+		withInfo, apiErr := ctrl.uploadedImageInfo(
+			ctx, file.ID, etag, contentType, file.Metadata,
+		)
+		if apiErr != nil {
+			middleware.LoggerFromContext(ctx).WithError(apiErr).Warnf(
+				"problem getting image info for file %s", file.Name,
+			)
+		} else {
+			fileMetadata = withInfo
+		}
+	}
+
 	metadata, apiErr := ctrl.metadataStorage.PopulateMetadata(
 		ctx,
-		file.ID, file.Name, file.header.Size, bucket.ID, etag, true, contentType, file.Metadata,
+		file.ID, file.Name, sized.size, bucket.ID, etag, true, contentType, fileMetadata,
 		http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
 	)
 	if apiErr != nil {
@@ -145,104 +339,204 @@ func (ctrl *Controller) processFile(
 		)
 	}
 
+	metadata.ScanStatus = ctrl.uploadedScanStatus()
+
+	ctrl.publishEvent(ctx, EventFileUploaded, metadata, sessionHeaders)
+
 	return metadata, nil
 }
 
-func (ctrl *Controller) upload(
-	ctx context.Context,
-	request uploadFileRequest,
-	sessionHeaders http.Header,
-) ([]api.FileMetadata, *APIError) {
-	bucket, err := ctrl.metadataStorage.GetBucketByID(
-		ctx,
-		request.bucketID,
-		http.Header{"x-hasura-admin-secret": []string{ctrl.hasuraAdminSecret}},
-	)
+// formUpload processes the files of an upload form while it is being read. Each file
+// is streamed straight to the content storage with the bucket-id and metadata[] sent
+// before it, or the defaults if they weren't.
+type formUpload struct {
+	ctrl           *Controller
+	sessionHeaders http.Header
+	bucketID       string
+	bucketSent     bool
+	bucket         *BucketMetadata
+	metadata       []fileData
+	files          int
+	processed      []api.FileMetadata
+}
+
+func readFormValue(part *multipart.Part) ([]byte, *APIError) {
+	b, err := io.ReadAll(io.LimitReader(part, maxFormMemory+1))
 	if err != nil {
-		return nil, err
+		return nil, InternalServerError(fmt.Errorf("problem reading multipart form: %w", err))
 	}
 
-	filesMetadata := make([]api.FileMetadata, 0, len(request.files))
+	if len(b) > maxFormMemory {
+		return nil, BadDataError(
+			fmt.Errorf("form value %s is too big", part.FormName()), //nolint:err113
+			fmt.Sprintf("form value %s is too big", part.FormName()),
+		)
+	}
 
-	for _, file := range request.files {
-		metadata, err := ctrl.processFile(ctx, file, bucket, sessionHeaders)
-		if err != nil {
-			return filesMetadata, err
-		}
+	return b, nil
+}
 
-		filesMetadata = append(filesMetadata, metadata)
+func (u *formUpload) getBucket(ctx context.Context) (BucketMetadata, *APIError) {
+	if u.bucket != nil {
+		return *u.bucket, nil
 	}
 
-	return filesMetadata, nil
-}
+	bucket, apiErr := u.ctrl.metadataStorage.GetBucketByID(
+		ctx,
+		u.bucketID,
+		http.Header{"x-hasura-admin-secret": []string{u.ctrl.hasuraAdminSecret}},
+	)
+	if apiErr != nil {
+		return BucketMetadata{}, apiErr
+	}
 
-func fileDataFromFormValue(
-	md map[string][]string,
-	fileHedaer *multipart.FileHeader,
-	i int,
-) (fileData, *APIError) {
-	formValue := []byte("{}")
+	metrics.BucketToContext(ctx, bucket.ID)
 
-	userSpecified, ok := md["metadata[]"]
-	if ok {
-		formValue = []byte(userSpecified[i])
+	u.bucket = &bucket
+
+	return bucket, nil
+}
+
+// fileData returns the details of the file at idx using the defaults for anything
+// not specified in its metadata[].
+func (u *formUpload) fileData(idx int, filename string) fileData {
+	var file fileData
+	if idx < len(u.metadata) {
+		file = u.metadata[idx]
 	}
 
-	var data fileData
-	if err := json.Unmarshal(formValue, &data); err != nil {
-		return fileData{}, WrongMetadataFormatError(err)
+	if file.Name == "" {
+		file.Name = filename
 	}
 
-	data.header = fileHedaer
+	if file.ID == "" {
+		file.ID = uuid.New().String()
+	}
 
-	return data, nil
+	return file
 }
 
-func getBucketIDFromFormValue(md map[string][]string) string {
-	bucketID, ok := md["bucket-id"]
-	if ok {
-		return bucketID[0]
+func (u *formUpload) process(
+	ctx context.Context, idx int, filename string, content io.Reader, contentType string,
+) *APIError {
+	bucket, apiErr := u.getBucket(ctx)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	metadata, apiErr := u.ctrl.processFile(
+		ctx, u.fileData(idx, filename), content, contentType, bucket, u.sessionHeaders,
+	)
+	if apiErr != nil {
+		return apiErr
 	}
 
-	return "default"
+	u.processed = append(u.processed, metadata)
+
+	return nil
 }
 
-func parseUploadRequest(form *multipart.Form) (uploadFileRequest, *APIError) {
-	files, ok := form.File["file[]"]
-	if !ok {
-		return uploadFileRequest{}, ErrMultipartFormFileNotFound
-	}
+func (u *formUpload) readPart(ctx context.Context, part *multipart.Part) *APIError {
+	switch part.FormName() {
+	case "bucket-id":
+		b, apiErr := readFormValue(part)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		if u.bucketSent {
+			return nil
+		}
+
+		if u.files > 0 {
+			// the files sent so far were already stored in the default bucket
+			return BadDataError(
+				errors.New("bucket-id sent after file[]"), //nolint:err113
+				"bucket-id must be sent before the files",
+			)
+		}
+
+		u.bucketID = string(b)
+		u.bucketSent = true
 
-	md, ok := form.Value["metadata[]"]
-	if ok {
-		if len(md) != len(files) {
-			return uploadFileRequest{}, ErrMetadataLength
+		return nil
+	case "metadata[]":
+		b, apiErr := readFormValue(part)
+		if apiErr != nil {
+			return apiErr
 		}
+
+		var data fileData
+		if err := json.Unmarshal(b, &data); err != nil {
+			return WrongMetadataFormatError(err)
+		}
+
+		if len(u.metadata) < u.files {
+			// the file it belongs to was already stored with the defaults
+			return BadDataError(
+				fmt.Errorf("metadata[] for file %d sent after it", len(u.metadata)), //nolint:err113
+				"metadata[] must be sent before its file[]",
+			)
+		}
+
+		u.metadata = append(u.metadata, data)
+
+		return nil
+	case "file[]":
+		idx := u.files
+		u.files++
+
+		return u.process(ctx, idx, part.FileName(), part, part.Header.Get("Content-Type"))
+	}
+
+	return nil
+}
+
+func (ctrl *Controller) upload(
+	ctx context.Context,
+	reader *multipart.Reader,
+	sessionHeaders http.Header,
+) ([]api.FileMetadata, *APIError) {
+	u := &formUpload{
+		ctrl:           ctrl,
+		sessionHeaders: sessionHeaders,
+		bucketID:       "default",
+		bucketSent:     false,
+		bucket:         nil,
+		metadata:       nil,
+		files:          0,
+		processed:      make([]api.FileMetadata, 0),
 	}
 
-	processedFiles := make([]fileData, len(files))
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
 
-	for idx, fileHeader := range files {
-		fileReq, err := fileDataFromFormValue(form.Value, fileHeader, idx)
 		if err != nil {
-			return uploadFileRequest{}, err
+			return u.processed, InternalServerError(
+				fmt.Errorf("problem reading multipart form: %w", err),
+			)
 		}
 
-		if fileReq.Name == "" {
-			fileReq.Name = fileHeader.Filename
-		}
+		apiErr := u.readPart(ctx, part)
+		part.Close()
 
-		if fileReq.ID == "" {
-			fileReq.ID = uuid.New().String()
+		if apiErr != nil {
+			return u.processed, apiErr
 		}
+	}
 
-		processedFiles[idx] = fileReq
+	if u.files == 0 {
+		return u.processed, ErrMultipartFormFileNotFound
 	}
 
-	return uploadFileRequest{
-		bucketID: getBucketIDFromFormValue(form.Value),
-		files:    processedFiles,
-	}, nil
+	if len(u.metadata) > 0 && len(u.metadata) != u.files {
+		return u.processed, ErrMetadataLength
+	}
+
+	return u.processed, nil
 }
 
 func (ctrl *Controller) UploadFiles( //nolint:ireturn
@@ -251,19 +545,7 @@ func (ctrl *Controller) UploadFiles( //nolint:ireturn
 	logger := middleware.LoggerFromContext(ctx)
 	sessionHeaders := middleware.SessionHeadersFromContext(ctx)
 
-	form, err := request.Body.ReadForm(maxFormMemory)
-	if err != nil {
-		logger.WithError(err).Error("problem reading multipart form")
-		return InternalServerError(err), nil
-	}
-
-	uploadFilesRequest, apiErr := parseUploadRequest(form)
-	if apiErr != nil {
-		logger.WithError(apiErr).Error("problem parsing upload request")
-		return apiErr, nil
-	}
-
-	fm, apiErr := ctrl.upload(ctx, uploadFilesRequest, sessionHeaders)
+	fm, apiErr := ctrl.upload(ctx, request.Body, sessionHeaders)
 	if apiErr != nil {
 		logger.WithError(apiErr).Error("problem uploading files")
 
@@ -2,10 +2,12 @@ package controller_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
 	"net/textproto"
 	"strings"
 	"testing"
@@ -41,7 +43,9 @@ type fakeFile struct {
 	md          fakeFileMetadata
 }
 
-func createMultiForm(t *testing.T, files ...fakeFile) *multipart.Reader {
+// createMultiForm sends the metadata of each file before it unless metadataFirst is
+// false, in which case it goes after the file and is too late to be used.
+func createMultiForm(t *testing.T, metadataFirst bool, files ...fakeFile) *multipart.Reader {
 	t.Helper()
 
 	body := &bytes.Buffer{}
@@ -60,7 +64,23 @@ func createMultiForm(t *testing.T, files ...fakeFile) *multipart.Reader {
 		t.Fatal(err)
 	}
 
+	writeMetadata := func(file fakeFile) {
+		formWriter, err := writer.CreateFormField("metadata[]")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = io.Copy(formWriter, strings.NewReader(file.md.encode()))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
 	for _, file := range files {
+		if metadataFirst {
+			writeMetadata(file)
+		}
+
 		h := make(textproto.MIMEHeader)
 		h.Set("Content-Disposition",
 			fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
@@ -77,14 +97,8 @@ func createMultiForm(t *testing.T, files ...fakeFile) *multipart.Reader {
 			t.Fatal(err)
 		}
 
-		formWriter, err = writer.CreateFormField("metadata[]")
-		if err != nil {
-			t.Fatal(err)
-		}
-
-		_, err = io.Copy(formWriter, strings.NewReader(file.md.encode()))
-		if err != nil {
-			t.Fatal(err)
+		if !metadataFirst {
+			writeMetadata(file)
 		}
 	}
 
@@ -94,7 +108,31 @@ func createMultiForm(t *testing.T, files ...fakeFile) *multipart.Reader {
 	return multipart.NewReader(bytes.NewReader(body.Bytes()), boundary)
 }
 
-func TestUploadFile(t *testing.T) {
+// putFileStream mimics a content storage reading the whole stream before validating it.
+func putFileStream(
+	t *testing.T, expected string,
+) func(context.Context, io.Reader, string, string, func() *controller.APIError) (string, *controller.APIError) {
+	t.Helper()
+
+	return func(
+		_ context.Context, content io.Reader, _, _ string, validate func() *controller.APIError,
+	) (string, *controller.APIError) {
+		b, err := io.ReadAll(content)
+		if err != nil {
+			return "", controller.InternalServerError(err)
+		}
+
+		assert(t, expected, string(b))
+
+		if apiErr := validate(); apiErr != nil {
+			return "", apiErr
+		}
+
+		return "some-etag", nil
+	}
+}
+
+func TestUploadFile(t *testing.T) { //nolint:maintidx
 	t.Parallel()
 
 	cases := []struct {
@@ -165,20 +203,19 @@ func TestUploadFile(t *testing.T) {
 					gomock.Any(),
 					file.md.ID,
 					file.md.Name,
-					int64(len(file.contents)),
+					int64(0),
 					"blah",
 					"text/plain; charset=utf-8",
 					gomock.Any(),
 				).Return(nil)
 
-				contentStorage.EXPECT().PutFile(
+				contentStorage.EXPECT().PutFileStream(
+					gomock.Any(),
 					gomock.Any(),
-					ReaderMatcher(
-						file.contents,
-					),
 					file.md.ID,
 					"text/plain; charset=utf-8",
-				).Return("some-etag", nil)
+					gomock.Any(),
+				).DoAndReturn(putFileStream(t, file.contents))
 
 				metadataStorage.EXPECT().PopulateMetadata(
 					gomock.Any(),
@@ -215,20 +252,19 @@ func TestUploadFile(t *testing.T) {
 					gomock.Any(),
 					file.md.ID,
 					file.md.Name,
-					int64(len(file.contents)),
+					int64(0),
 					"blah",
 					"text/markdown",
 					gomock.Any(),
 				).Return(nil)
 
-				contentStorage.EXPECT().PutFile(
+				contentStorage.EXPECT().PutFileStream(
+					gomock.Any(),
 					gomock.Any(),
-					ReaderMatcher(
-						file.contents,
-					),
 					file.md.ID,
 					"text/markdown",
-				).Return("some-etag", nil)
+					gomock.Any(),
+				).DoAndReturn(putFileStream(t, file.contents))
 
 				metadataStorage.EXPECT().PopulateMetadata(
 					gomock.Any(),
@@ -275,7 +311,7 @@ func TestUploadFile(t *testing.T) {
 			resp, err := ctrl.UploadFiles(
 				t.Context(),
 				api.UploadFilesRequestObject{
-					Body: createMultiForm(t, files...),
+					Body: createMultiForm(t, true, files...),
 				},
 			)
 			if err != nil {
@@ -296,6 +332,7 @@ func TestUploadFile(t *testing.T) {
 						MimeType:         "text/plain; charset=utf-8",
 						UploadedByUserId: ptr("some-valid-uuid"),
 						Metadata:         ptr(map[string]any{}),
+						ScanStatus:       ptr(api.Clean),
 					},
 					{
 						Id:               "d041c7c5-10e7-410e-a599-799409b5",
@@ -309,6 +346,7 @@ func TestUploadFile(t *testing.T) {
 						MimeType:         "text/markdown",
 						UploadedByUserId: ptr("some-valid-uuid"),
 						Metadata:         ptr(map[string]any{"some": "metadata"}),
+						ScanStatus:       ptr(api.Clean),
 					},
 				},
 			}, resp,
@@ -317,3 +355,425 @@ func TestUploadFile(t *testing.T) {
 		})
 	}
 }
+
+func TestUploadFileDefaults(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	formWriter, err := writer.CreateFormFile("file[]", "a_file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := io.WriteString(formWriter, "some content"); err != nil {
+		t.Fatal(err)
+	}
+
+	writer.Close()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	metadataStorage := mock.NewMockMetadataStorage(c)
+	contentStorage := mock.NewMockContentStorage(c)
+	av := mock.NewMockAntivirus(c)
+
+	metadataStorage.EXPECT().GetBucketByID(
+		gomock.Any(), "default", gomock.Any(),
+	).Return(controller.BucketMetadata{ //nolint:exhaustruct
+		ID:            "default",
+		MinUploadFile: 0,
+		MaxUploadFile: 100,
+	}, nil)
+
+	metadataStorage.EXPECT().InitializeFile(
+		gomock.Any(), gomock.Any(), "a_file.txt", int64(0), "default",
+		"text/plain; charset=utf-8", gomock.Any(),
+	).Return(nil)
+
+	contentStorage.EXPECT().PutFileStream(
+		gomock.Any(), gomock.Any(), gomock.Any(), "text/plain; charset=utf-8", gomock.Any(),
+	).DoAndReturn(putFileStream(t, "some content"))
+
+	av.EXPECT().ScanReader(gomock.Any(), gomock.Any()).Return(nil)
+
+	metadataStorage.EXPECT().PopulateMetadata(
+		gomock.Any(),
+		gomock.Any(),
+		"a_file.txt",
+		int64(12),
+		"default",
+		"some-etag",
+		true,
+		"text/plain; charset=utf-8",
+		nil,
+		gomock.Any(),
+	).Return(api.FileMetadata{Name: "a_file.txt"}, nil) //nolint:exhaustruct
+
+	ctrl := controller.New(
+		"http://asd",
+		"/v1",
+		"asdasd",
+		metadataStorage,
+		contentStorage,
+		nil,
+		av,
+		logger,
+	)
+
+	resp, err := ctrl.UploadFiles(
+		t.Context(),
+		api.UploadFilesRequestObject{
+			Body: multipart.NewReader(body, writer.Boundary()),
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, api.UploadFiles201JSONResponse{
+		ProcessedFiles: []api.FileMetadata{
+			{Name: "a_file.txt", ScanStatus: ptr(api.Clean)}, //nolint:exhaustruct
+		},
+	}, resp)
+}
+
+func TestUploadFileMetadataAfterFile(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	file := fakeFile{
+		contents:    "some content",
+		contentType: "text/plain",
+		md: fakeFileMetadata{
+			Name:     "a_file.txt",
+			ID:       uuid.New().String(),
+			Metadata: map[string]any{"some": "metadata"},
+		},
+	}
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	metadataStorage := mock.NewMockMetadataStorage(c)
+	contentStorage := mock.NewMockContentStorage(c)
+	av := mock.NewMockAntivirus(c)
+
+	metadataStorage.EXPECT().GetBucketByID(
+		gomock.Any(), "blah", gomock.Any(),
+	).Return(controller.BucketMetadata{ //nolint:exhaustruct
+		ID:            "blah",
+		MinUploadFile: 0,
+		MaxUploadFile: 100,
+	}, nil)
+
+	// the file is stored before its metadata is received so it gets the defaults
+	metadataStorage.EXPECT().InitializeFile(
+		gomock.Any(), gomock.Not(file.md.ID), file.md.Name, int64(0), "blah", "text/plain",
+		gomock.Any(),
+	).Return(nil)
+
+	contentStorage.EXPECT().PutFileStream(
+		gomock.Any(), gomock.Any(), gomock.Any(), "text/plain", gomock.Any(),
+	).DoAndReturn(putFileStream(t, file.contents))
+
+	av.EXPECT().ScanReader(gomock.Any(), gomock.Any()).Return(nil)
+
+	metadataStorage.EXPECT().PopulateMetadata(
+		gomock.Any(),
+		gomock.Not(file.md.ID),
+		file.md.Name,
+		int64(len(file.contents)),
+		"blah",
+		"some-etag",
+		true,
+		"text/plain",
+		nil,
+		gomock.Any(),
+	).Return(api.FileMetadata{Name: file.md.Name}, nil) //nolint:exhaustruct
+
+	ctrl := controller.New(
+		"http://asd",
+		"/v1",
+		"asdasd",
+		metadataStorage,
+		contentStorage,
+		nil,
+		av,
+		logger,
+	)
+
+	resp, err := ctrl.UploadFiles(
+		t.Context(),
+		api.UploadFilesRequestObject{
+			Body: createMultiForm(t, false, file),
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errResp, ok := resp.(api.UploadFilesdefaultJSONResponse)
+	if !ok {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+
+	assert(t, http.StatusBadRequest, errResp.StatusCode)
+	assert(t, "metadata[] must be sent before its file[]", errResp.Body.Error.Message)
+	assert(t, 1, len(*errResp.Body.ProcessedFiles))
+}
+
+func TestUploadFileTooBig(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	file := fakeFile{
+		contents:    "this is more than ten bytes",
+		contentType: "text/plain",
+		md: fakeFileMetadata{
+			Name:     "a_file.txt",
+			ID:       uuid.New().String(),
+			Metadata: map[string]any{},
+		},
+	}
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	metadataStorage := mock.NewMockMetadataStorage(c)
+	contentStorage := mock.NewMockContentStorage(c)
+	av := mock.NewMockAntivirus(c)
+
+	metadataStorage.EXPECT().GetBucketByID(
+		gomock.Any(), "blah", gomock.Any(),
+	).Return(controller.BucketMetadata{ //nolint:exhaustruct
+		ID:            "blah",
+		MinUploadFile: 0,
+		MaxUploadFile: 10,
+	}, nil)
+
+	metadataStorage.EXPECT().InitializeFile(
+		gomock.Any(), file.md.ID, file.md.Name, int64(0), "blah", "text/plain", gomock.Any(),
+	).Return(nil)
+
+	contentStorage.EXPECT().PutFileStream(
+		gomock.Any(), gomock.Any(), file.md.ID, "text/plain", gomock.Any(),
+	).DoAndReturn(func(
+		_ context.Context, content io.Reader, _, _ string, _ func() *controller.APIError,
+	) (string, *controller.APIError) {
+		_, err := io.ReadAll(content)
+		return "", controller.InternalServerError(err)
+	})
+
+	av.EXPECT().ScanReader(gomock.Any(), gomock.Any()).Return(nil)
+
+	metadataStorage.EXPECT().DeleteFileByID(gomock.Any(), file.md.ID, gomock.Any()).Return(
+		api.FileMetadata{}, nil, //nolint:exhaustruct
+	)
+
+	ctrl := controller.New(
+		"http://asd",
+		"/v1",
+		"asdasd",
+		metadataStorage,
+		contentStorage,
+		nil,
+		av,
+		logger,
+	)
+
+	resp, err := ctrl.UploadFiles(
+		t.Context(),
+		api.UploadFilesRequestObject{
+			Body: createMultiForm(t, true, file),
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errResp, ok := resp.(api.UploadFilesdefaultJSONResponse)
+	if !ok {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+
+	assert(t, http.StatusBadRequest, errResp.StatusCode)
+	assert(t, "file too big", errResp.Body.Error.Message)
+}
+
+func TestUploadFileMimeType(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	html := "<html><body>hello</body></html>"
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01"
+
+	cases := []struct {
+		name             string
+		filename         string
+		contents         string
+		contentType      string
+		allowed          []string
+		denied           []string
+		expectedMimeType string
+		expectedStatus   int
+		expectedMessage  string
+	}{
+		{
+			name:             "declared type",
+			filename:         "image.png",
+			contents:         png,
+			contentType:      "image/png",
+			allowed:          []string{"image/*"},
+			denied:           nil,
+			expectedMimeType: "image/png",
+			expectedStatus:   http.StatusForbidden,
+			expectedMessage:  "initialized",
+		},
+		{
+			name:             "detected type",
+			filename:         "image.png",
+			contents:         png,
+			contentType:      "application/octet-stream",
+			allowed:          nil,
+			denied:           nil,
+			expectedMimeType: "image/png",
+			expectedStatus:   http.StatusForbidden,
+			expectedMessage:  "initialized",
+		},
+		{
+			name:             "more generic declared type",
+			filename:         "page",
+			contents:         html,
+			contentType:      "text/plain",
+			allowed:          nil,
+			denied:           nil,
+			expectedMimeType: "text/plain",
+			expectedStatus:   http.StatusForbidden,
+			expectedMessage:  "initialized",
+		},
+		{
+			name:             "declared type doesn't match content",
+			filename:         "page.png",
+			contents:         html,
+			contentType:      "image/png",
+			allowed:          nil,
+			denied:           nil,
+			expectedMimeType: "",
+			expectedStatus:   http.StatusBadRequest,
+			expectedMessage:  "file content doesn't match its type",
+		},
+		{
+			name:             "extension doesn't match content",
+			filename:         "image.png",
+			contents:         html,
+			contentType:      "application/octet-stream",
+			allowed:          nil,
+			denied:           nil,
+			expectedMimeType: "",
+			expectedStatus:   http.StatusBadRequest,
+			expectedMessage:  "file content doesn't match its type",
+		},
+		{
+			name:             "not allowed",
+			filename:         "page.html",
+			contents:         html,
+			contentType:      "text/html",
+			allowed:          []string{"image/*", "application/pdf"},
+			denied:           nil,
+			expectedMimeType: "",
+			expectedStatus:   http.StatusUnsupportedMediaType,
+			expectedMessage:  "file type not allowed",
+		},
+		{
+			name:             "detected type denied",
+			filename:         "page",
+			contents:         html,
+			contentType:      "text/plain",
+			allowed:          nil,
+			denied:           []string{"text/html"},
+			expectedMimeType: "",
+			expectedStatus:   http.StatusUnsupportedMediaType,
+			expectedMessage:  "file type not allowed",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			metadataStorage := mock.NewMockMetadataStorage(c)
+
+			file := fakeFile{
+				contents:    tc.contents,
+				contentType: tc.contentType,
+				md: fakeFileMetadata{
+					Name:     tc.filename,
+					ID:       uuid.New().String(),
+					Metadata: map[string]any{},
+				},
+			}
+
+			metadataStorage.EXPECT().GetBucketByID(
+				gomock.Any(), "blah", gomock.Any(),
+			).Return(controller.BucketMetadata{ //nolint:exhaustruct
+				ID:               "blah",
+				MinUploadFile:    0,
+				MaxUploadFile:    100,
+				AllowedMimeTypes: tc.allowed,
+				DeniedMimeTypes:  tc.denied,
+			}, nil)
+
+			// the upload stops here, the type it gets is all we need
+			if tc.expectedMimeType != "" {
+				metadataStorage.EXPECT().InitializeFile(
+					gomock.Any(), file.md.ID, tc.filename, int64(0), "blah",
+					tc.expectedMimeType, gomock.Any(),
+				).Return(controller.ForbiddenError(nil, "initialized"))
+			}
+
+			ctrl := controller.New(
+				"http://asd",
+				"/v1",
+				"asdasd",
+				metadataStorage,
+				mock.NewMockContentStorage(c),
+				nil,
+				mock.NewMockAntivirus(c),
+				logger,
+			)
+
+			resp, err := ctrl.UploadFiles(
+				t.Context(),
+				api.UploadFilesRequestObject{
+					Body: createMultiForm(t, true, file),
+				},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			errResp, ok := resp.(api.UploadFilesdefaultJSONResponse)
+			if !ok {
+				t.Fatalf("unexpected response: %#v", resp)
+			}
+
+			assert(t, tc.expectedStatus, errResp.StatusCode)
+			assert(t, tc.expectedMessage, errResp.Body.Error.Message)
+		})
+	}
+}
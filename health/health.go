@@ -0,0 +1,122 @@
+// Package health checks whether the dependencies of the service are reachable.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// Check returns an error if the dependency isn't ready.
+type Check func(ctx context.Context) error
+
+type CheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Readiness runs the checks of the dependencies, caching the result so frequent probes
+// don't overload them.
+type Readiness struct {
+	checks   map[string]Check
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	mu        sync.Mutex
+	report    Report
+	checkedAt time.Time
+}
+
+// NewReadiness returns a Readiness running checks, each of them with the given timeout,
+// at most once every cacheTTL.
+func NewReadiness(checks map[string]Check, timeout, cacheTTL time.Duration) *Readiness {
+	return &Readiness{ //nolint:exhaustruct
+		checks:   checks,
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+	}
+}
+
+func (r *Readiness) run(ctx context.Context) Report {
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]CheckResult, len(r.checks)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for name, check := range r.checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, r.timeout)
+			defer cancel()
+
+			result := CheckResult{Status: StatusOK, Error: ""}
+			if err := check(ctx); err != nil {
+				result = CheckResult{Status: StatusError, Error: err.Error()}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusError
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+// Check returns the status of the dependencies. Concurrent calls wait for the checks in
+// progress instead of starting new ones.
+func (r *Readiness) Check(ctx context.Context) Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.checkedAt.IsZero() && time.Since(r.checkedAt) < r.cacheTTL {
+		return r.report
+	}
+
+	// the result is shared with other requests so it shouldn't depend on this one
+	r.report = r.run(context.WithoutCancel(ctx))
+	r.checkedAt = time.Now()
+
+	return r.report
+}
+
+// Handler responds with the report, with status 503 if any dependency isn't ready.
+func (r *Readiness) Handler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		report := r.Check(ctx)
+
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+
+		ctx.JSON(status, report)
+	}
+}
@@ -0,0 +1,139 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-cmp/cmp"
+	"github.com/nhost/hasura-storage/health"
+)
+
+func TestReadiness(t *testing.T) {
+	t.Parallel()
+
+	ok := func(context.Context) error { return nil }
+	failing := func(context.Context) error { return errors.New("connection refused") } //nolint:err113
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	cases := []struct {
+		name           string
+		checks         map[string]health.Check
+		expectedStatus int
+		expected       health.Report
+	}{
+		{
+			name: "all ok",
+			checks: map[string]health.Check{
+				"hasura": ok,
+				"s3":     ok,
+			},
+			expectedStatus: http.StatusOK,
+			expected: health.Report{
+				Status: health.StatusOK,
+				Checks: map[string]health.CheckResult{
+					"hasura": {Status: health.StatusOK, Error: ""},
+					"s3":     {Status: health.StatusOK, Error: ""},
+				},
+			},
+		},
+		{
+			name: "failing",
+			checks: map[string]health.Check{
+				"hasura": ok,
+				"s3":     failing,
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expected: health.Report{
+				Status: health.StatusError,
+				Checks: map[string]health.CheckResult{
+					"hasura": {Status: health.StatusOK, Error: ""},
+					"s3":     {Status: health.StatusError, Error: "connection refused"},
+				},
+			},
+		},
+		{
+			name: "timeout",
+			checks: map[string]health.Check{
+				"clamd": slow,
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expected: health.Report{
+				Status: health.StatusError,
+				Checks: map[string]health.CheckResult{
+					"clamd": {Status: health.StatusError, Error: "context deadline exceeded"},
+				},
+			},
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			readiness := health.NewReadiness(tc.checks, 10*time.Millisecond, time.Minute)
+
+			router := gin.New()
+			router.GET("/readyz", readiness.Handler())
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, rec.Code)
+			}
+
+			var got health.Report
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestReadinessCache(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	readiness := health.NewReadiness(
+		map[string]health.Check{
+			"s3": func(context.Context) error {
+				calls.Add(1)
+				return nil
+			},
+		},
+		time.Second,
+		50*time.Millisecond,
+	)
+
+	readiness.Check(context.Background())
+	readiness.Check(context.Background())
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected the result to be cached, got %d calls", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	readiness.Check(context.Background())
+
+	if n := calls.Load(); n != 2 { //nolint:mnd
+		t.Errorf("expected the checks to run again after the TTL, got %d calls", n)
+	}
+}
@@ -0,0 +1,224 @@
+package icap_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nhost/hasura-storage/icap"
+)
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		addr      string
+		config    icap.Config
+		expectErr bool
+	}{
+		{name: "icap", addr: "icap://localhost:1344/avscan", expectErr: false},
+		{name: "default port", addr: "icap://localhost/avscan", expectErr: false},
+		{
+			name:      "reqmod",
+			addr:      "icap://localhost/avscan",
+			config:    icap.Config{Method: "reqmod"}, //nolint:exhaustruct
+			expectErr: false,
+		},
+		{name: "tcp", addr: "tcp://localhost:1344", expectErr: true},
+		{
+			name:      "unknown method",
+			addr:      "icap://localhost/avscan",
+			config:    icap.Config{Method: "OPTIONS"}, //nolint:exhaustruct
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := icap.NewClient(tc.addr, tc.config)
+			if (err != nil) != tc.expectErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeICAP(t, 1024, true, 0)
+
+	client, err := icap.NewClient(server.addr, icap.Config{}) //nolint:exhaustruct
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	opts, err := client.Options(t.Context())
+	if err != nil {
+		t.Fatalf("failed to get options: %v", err)
+	}
+
+	expected := icap.Options{
+		Methods: []string{"RESPMOD", "REQMOD"},
+		Preview: 1024,
+		ISTag:   "fake-1",
+	}
+	if diff := cmp.Diff(expected, opts); diff != "" {
+		t.Errorf("unexpected options (-want +got):\n%s", diff)
+	}
+}
+
+func TestClientScan(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		content       string
+		expectedError error
+	}{
+		{
+			name:          "clean",
+			content:       "hello world",
+			expectedError: nil,
+		},
+		{
+			name:          "clean and larger than the preview",
+			content:       strings.Repeat("hello world ", 10),
+			expectedError: nil,
+		},
+		{
+			name:          "infection found",
+			content:       "hello EICAR world",
+			expectedError: &icap.VirusFoundError{Name: "Eicar-Test-Signature"},
+		},
+		{
+			name:          "infection found after the preview",
+			content:       strings.Repeat("a", 100) + "EICAR",
+			expectedError: &icap.VirusFoundError{Name: "Eicar-Test-Signature"},
+		},
+		{
+			name:          "violations found",
+			content:       "VIOLATION",
+			expectedError: &icap.VirusFoundError{Name: "Trojan.Fake, Worm.Fake"},
+		},
+		{
+			name:          "blocked",
+			content:       "BLOCK",
+			expectedError: &icap.VirusFoundError{Name: icap.UnknownThreat},
+		},
+		{
+			name:          "error",
+			content:       "ERROR",
+			expectedError: &icap.ResponseError{Response: "ICAP/1.0 500 Server Error"},
+		},
+	}
+
+	servers := []struct {
+		name     string
+		preview  int
+		allow204 bool
+		config   icap.Config
+	}{
+		{
+			name:     "respmod with preview",
+			preview:  16,
+			allow204: true,
+			config:   icap.Config{ChunkSize: 8}, //nolint:exhaustruct
+		},
+		{
+			name:     "reqmod without preview",
+			preview:  -1,
+			allow204: true,
+			config:   icap.Config{Method: icap.MethodReqMod, ChunkSize: 8}, //nolint:exhaustruct
+		},
+		{
+			name:     "preview disabled",
+			preview:  16,
+			allow204: true,
+			config:   icap.Config{Preview: -1}, //nolint:exhaustruct
+		},
+		{
+			name:     "without 204",
+			preview:  0,
+			allow204: false,
+			config:   icap.Config{}, //nolint:exhaustruct
+		},
+	}
+
+	for _, s := range servers {
+		server := newFakeICAP(t, s.preview, s.allow204, 0)
+
+		client, err := icap.NewClient(server.addr, s.config)
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		for _, tc := range cases {
+			t.Run(s.name+"/"+tc.name, func(t *testing.T) {
+				err := client.Scan(t.Context(), strings.NewReader(tc.content))
+				if diff := cmp.Diff(tc.expectedError, err); diff != "" {
+					t.Errorf("unexpected error (-want +got):\n%s", diff)
+				}
+
+				server.mu.Lock()
+				received := server.received
+				server.mu.Unlock()
+
+				// the rest of infected files isn't needed once the preview is enough
+				if !strings.Contains(tc.content, "EICAR") && received != tc.content {
+					t.Errorf("expected the service to get %q, got %q", tc.content, received)
+				}
+			})
+		}
+	}
+}
+
+func TestClientScanPreview(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeICAP(t, 8, true, 0)
+
+	client, err := icap.NewClient(server.addr, icap.Config{}) //nolint:exhaustruct
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	for _, content := range []string{"abc", "abcdefgh", "abcdefghi", "EICAR and more"} {
+		_ = client.Scan(t.Context(), strings.NewReader(content))
+	}
+
+	// the preview size is requested once, the rest of the file only when needed
+	expected := []string{
+		"OPTIONS", "RESPMOD", "RESPMOD", "RESPMOD", "RESPMOD+continue", "RESPMOD",
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if diff := cmp.Diff(expected, server.requests); diff != "" {
+		t.Errorf("unexpected requests (-want +got):\n%s", diff)
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeICAP(t, -1, true, time.Second)
+
+	client, err := icap.NewClient(
+		server.addr, icap.Config{Preview: -1, Timeout: 50 * time.Millisecond}, //nolint:exhaustruct
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	err = client.Scan(t.Context(), strings.NewReader("clean"))
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package icap
+
+type VirusFoundError struct {
+	Name string
+}
+
+func (e *VirusFoundError) Error() string {
+	return "virus found: " + e.Name
+}
+
+// ResponseError is returned when the service replies with an error or an unexpected
+// response.
+type ResponseError struct {
+	Response string
+}
+
+func (e *ResponseError) Error() string {
+	return "unexpected response from icap service: " + e.Response
+}
@@ -0,0 +1,260 @@
+package icap_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeICAP implements enough of ICAP to test the client. Files containing "EICAR" are
+// reported with X-Infection-Found, "VIOLATION" with X-Violations-Found, "BLOCK" with a
+// 403 response and "ERROR" make it fail. Anything else is clean.
+type fakeICAP struct {
+	addr string
+	// preview is the preview size advertised, -1 for none.
+	preview int
+	// allow204 replies with the file instead of 204 when false, like services that don't
+	// support it.
+	allow204 bool
+	// delay is added before every reply.
+	delay time.Duration
+
+	listener net.Listener
+
+	mu sync.Mutex
+	// requests are the methods of the requests received, with "+continue" appended when
+	// the rest of the file after the preview was requested.
+	requests []string
+	// received is the content of the last file received.
+	received string
+	wg       sync.WaitGroup
+}
+
+func newFakeICAP(t *testing.T, preview int, allow204 bool, delay time.Duration) *fakeICAP {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	f := &fakeICAP{
+		addr:     "icap://" + listener.Addr().String() + "/avscan",
+		preview:  preview,
+		allow204: allow204,
+		delay:    delay,
+		listener: listener,
+		mu:       sync.Mutex{},
+		requests: nil,
+		received: "",
+		wg:       sync.WaitGroup{},
+	}
+
+	f.wg.Add(1)
+
+	go f.serve()
+
+	t.Cleanup(f.close)
+
+	return f
+}
+
+func (f *fakeICAP) serve() {
+	defer f.wg.Done()
+
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		f.wg.Add(1)
+
+		go func() {
+			defer f.wg.Done()
+			defer conn.Close()
+
+			_ = conn.SetDeadline(time.Now().Add(10 * time.Second)) //nolint:mnd
+
+			if err := f.handle(conn); err != nil {
+				fmt.Fprintf(conn, "ICAP/1.0 400 Bad Request\r\n\r\n")
+			}
+		}()
+	}
+}
+
+func (f *fakeICAP) close() {
+	f.listener.Close()
+	f.wg.Wait()
+}
+
+func (f *fakeICAP) record(request string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, request)
+}
+
+func (f *fakeICAP) handle(conn net.Conn) error {
+	r := bufio.NewReader(conn)
+
+	method, header, err := readRequest(r)
+	if err != nil {
+		return err
+	}
+
+	f.record(method)
+
+	if method == "OPTIONS" {
+		time.Sleep(f.delay)
+
+		preview := ""
+		if f.preview >= 0 {
+			preview = "Preview: " + strconv.Itoa(f.preview) + "\r\n"
+		}
+
+		fmt.Fprintf(conn, "ICAP/1.0 200 OK\r\nMethods: RESPMOD, REQMOD\r\n"+
+			"ISTag: \"fake-1\"\r\n%sAllow: 204\r\nEncapsulated: null-body=0\r\n\r\n", preview)
+
+		return nil
+	}
+
+	if method != "RESPMOD" && method != "REQMOD" {
+		return errors.New("unknown method") //nolint:err113
+	}
+
+	// the encapsulated HTTP headers go before the body, at the offset of *-body
+	_, offset, _ := strings.Cut(header["Encapsulated"], "-body=")
+
+	size, err := strconv.Atoi(offset)
+	if err != nil {
+		return fmt.Errorf("bad Encapsulated header: %w", err)
+	}
+
+	if _, err := io.CopyN(io.Discard, r, int64(size)); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	var content bytes.Buffer
+
+	ieof, err := readChunks(r, &content)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := header["Preview"]; ok && !ieof && !verdictInPreview(content.String()) {
+		fmt.Fprint(conn, "ICAP/1.0 100 Continue\r\n\r\n")
+		f.record(method + "+continue")
+
+		if _, err := readChunks(r, &content); err != nil {
+			return err
+		}
+	}
+
+	f.mu.Lock()
+	f.received = content.String()
+	f.mu.Unlock()
+
+	time.Sleep(f.delay)
+
+	_, err = io.WriteString(conn, f.reply(content.String(), header["Allow"] == "204"))
+
+	return err //nolint:wrapcheck
+}
+
+// verdictInPreview returns if the preview is enough to tell the file is infected.
+func verdictInPreview(content string) bool {
+	return strings.Contains(content, "EICAR")
+}
+
+func (f *fakeICAP) reply(content string, allow204 bool) string {
+	switch {
+	case strings.Contains(content, "EICAR"):
+		return "ICAP/1.0 200 OK\r\n" +
+			"X-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Test-Signature;\r\n" +
+			"Encapsulated: res-hdr=0, res-body=45\r\n\r\n" +
+			"HTTP/1.1 403 Forbidden\r\nContent-Length: 0\r\n\r\n0\r\n\r\n"
+	case strings.Contains(content, "VIOLATION"):
+		return "ICAP/1.0 200 OK\r\n" +
+			"X-Violations-Found: 2\r\n" +
+			"\tfile\r\n\tTrojan.Fake\r\n\t1\r\n\t0\r\n" +
+			"\tfile\r\n\tWorm.Fake\r\n\t2\r\n\t0\r\n" +
+			"Encapsulated: null-body=0\r\n\r\n"
+	case strings.Contains(content, "BLOCK"):
+		return "ICAP/1.0 200 OK\r\n" +
+			"Encapsulated: res-hdr=0, res-body=45\r\n\r\n" +
+			"HTTP/1.1 403 Forbidden\r\nContent-Length: 0\r\n\r\n0\r\n\r\n"
+	case strings.Contains(content, "ERROR"):
+		return "ICAP/1.0 500 Server Error\r\n\r\n"
+	case f.allow204 && allow204:
+		return "ICAP/1.0 204 No Content\r\n\r\n"
+	}
+
+	return "ICAP/1.0 200 OK\r\n" +
+		"Encapsulated: res-hdr=0, res-body=38\r\n\r\n" +
+		"HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n0\r\n\r\n"
+}
+
+func readRequest(r *bufio.Reader) (string, map[string]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", nil, err //nolint:wrapcheck
+	}
+
+	method, _, _ := strings.Cut(line, " ")
+	header := map[string]string{}
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", nil, err //nolint:wrapcheck
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			return method, header, nil
+		}
+
+		key, value, _ := strings.Cut(line, ":")
+		header[key] = strings.TrimSpace(value)
+	}
+}
+
+// readChunks reads a chunked body until the last chunk and returns if it was flagged
+// with ieof.
+func readChunks(r *bufio.Reader, w io.Writer) (bool, error) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return false, err //nolint:wrapcheck
+		}
+
+		size, ext, _ := strings.Cut(strings.TrimRight(line, "\r\n"), ";")
+
+		n, err := strconv.ParseInt(size, 16, 64)
+		if err != nil {
+			return false, fmt.Errorf("bad chunk size: %w", err)
+		}
+
+		if n == 0 {
+			_, err := r.ReadString('\n')
+			return strings.TrimSpace(ext) == "ieof", err //nolint:wrapcheck
+		}
+
+		if _, err := io.CopyN(w, r, n); err != nil {
+			return false, err //nolint:wrapcheck
+		}
+
+		if _, err := r.ReadString('\n'); err != nil {
+			return false, err //nolint:wrapcheck
+		}
+	}
+}
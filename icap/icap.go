@@ -0,0 +1,262 @@
+// Package icap implements a client to scan files with an antivirus exposed through
+// ICAP (RFC 3507).
+package icap
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MethodRespMod = "RESPMOD"
+	MethodReqMod  = "REQMOD"
+
+	defaultPort        = "1344"
+	defaultChunkSize   = 64 << 10
+	defaultDialTimeout = 10 * time.Second
+	defaultTimeout     = time.Minute
+)
+
+// Config tunes the client. Zero values use the defaults.
+type Config struct {
+	// Method is how files are sent to the service, RESPMOD (default) as if they were
+	// downloaded or REQMOD as if they were uploaded.
+	Method string
+	// Preview is the number of bytes sent before the service decides if it needs the rest
+	// of the file. 0 uses the preview size the service advertises, a negative value
+	// sends the whole file straight away.
+	Preview int
+	// ChunkSize is the size of the chunks files are streamed in, 64 KiB by default.
+	ChunkSize int
+	// DialTimeout is how long connecting to the service can take, 10s by default.
+	DialTimeout time.Duration
+	// Timeout is how long a request can take, including streaming the file and reading
+	// the reply, 1m by default.
+	Timeout time.Duration
+}
+
+type Client struct {
+	addr   string
+	uri    string
+	config Config
+
+	mu sync.Mutex
+	// preview is the preview size advertised by the service, nil until it is known.
+	preview *int
+}
+
+// NewClient returns a client for the ICAP service at addr, i.e.
+// icap://scanner:1344/avscan. The port defaults to 1344.
+func NewClient(addr string, config Config) (*Client, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse addr: %w", err)
+	}
+
+	if u.Scheme != "icap" {
+		return nil, fmt.Errorf("invalid scheme: %s", u.Scheme) //nolint:err113
+	}
+
+	if u.Port() == "" {
+		u.Host = net.JoinHostPort(u.Hostname(), defaultPort)
+	}
+
+	config.Method = strings.ToUpper(config.Method)
+
+	switch config.Method {
+	case "":
+		config.Method = MethodRespMod
+	case MethodRespMod, MethodReqMod:
+	default:
+		return nil, fmt.Errorf("invalid method: %s", config.Method) //nolint:err113
+	}
+
+	if config.ChunkSize <= 0 {
+		config.ChunkSize = defaultChunkSize
+	}
+
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = defaultDialTimeout
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+
+	return &Client{
+		addr:    u.Host,
+		uri:     u.String(),
+		config:  config,
+		mu:      sync.Mutex{},
+		preview: nil,
+	}, nil
+}
+
+// conn is a connection to the service. A new one is opened for every request, requests
+// are sent with "Connection: close".
+type conn struct {
+	net.Conn
+
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+func (c *Client) Dial(ctx context.Context) (net.Conn, error) {
+	dialer := net.Dialer{ //nolint:exhaustruct
+		Timeout: c.config.DialTimeout,
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial: %w", err)
+	}
+
+	return conn, nil
+}
+
+// do calls f on a new connection with the deadline of the request set, interrupting it
+// if ctx is done.
+func (c *Client) do(ctx context.Context, f func(conn *conn) error) error {
+	nc, err := c.Dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer nc.Close()
+
+	deadline := time.Now().Add(c.config.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	if err := nc.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = nc.SetDeadline(time.Unix(1, 0))
+	})
+	defer stop()
+
+	if err := f(&conn{
+		Conn: nc,
+		r:    bufio.NewReader(nc),
+		w:    bufio.NewWriterSize(nc, c.config.ChunkSize),
+	}); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w: %w", context.Cause(ctx), err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// writeRequest writes the request line and the ICAP headers, followed by the
+// encapsulated HTTP headers if any.
+func (c *Client) writeRequest(
+	w *bufio.Writer, method string, header []string, encapsulated string,
+) error {
+	fmt.Fprintf(w, "%s %s ICAP/1.0\r\n", method, c.uri)
+	fmt.Fprintf(w, "Host: %s\r\n", c.addr)
+	fmt.Fprint(w, "Connection: close\r\n")
+
+	for _, h := range header {
+		fmt.Fprintf(w, "%s\r\n", h)
+	}
+
+	fmt.Fprint(w, "\r\n")
+	fmt.Fprint(w, encapsulated)
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write request: %w", err)
+	}
+
+	return nil
+}
+
+// response is the status and headers of a reply. Headers are indexed by their canonical
+// name and keep their continuation lines, which some of them use to report a list.
+type response struct {
+	status     int
+	statusLine string
+	header     map[string][]string
+}
+
+// get returns the first line of the header.
+func (r *response) get(key string) string {
+	if lines := r.header[textproto.CanonicalMIMEHeaderKey(key)]; len(lines) > 0 {
+		return lines[0]
+	}
+
+	return ""
+}
+
+func readResponse(r *bufio.Reader) (*response, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	proto, status, _ := strings.Cut(line, " ")
+	code, _, _ := strings.Cut(status, " ")
+
+	statusCode, err := strconv.Atoi(code)
+	if !strings.HasPrefix(proto, "ICAP/") || err != nil {
+		return nil, &ResponseError{Response: line}
+	}
+
+	resp := &response{
+		status:     statusCode,
+		statusLine: line,
+		header:     map[string][]string{},
+	}
+
+	last := ""
+
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response headers: %w", err)
+		}
+
+		if line == "" {
+			return resp, nil
+		}
+
+		if (line[0] == ' ' || line[0] == '\t') && last != "" {
+			resp.header[last] = append(resp.header[last], strings.TrimSpace(line))
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, &ResponseError{Response: line}
+		}
+
+		last = textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(key))
+		resp.header[last] = append(resp.header[last], strings.TrimSpace(value))
+	}
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		if errors.Is(err, bufio.ErrBufferFull) {
+			return "", &ResponseError{Response: "line too long"}
+		}
+
+		return "", err //nolint:wrapcheck
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
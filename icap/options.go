@@ -0,0 +1,100 @@
+package icap
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type Options struct {
+	Methods []string
+	// Preview is the number of bytes the service wants to get before the rest of a file,
+	// -1 if it doesn't support previews.
+	Preview int
+	ISTag   string
+}
+
+func (c *Client) Options(ctx context.Context) (Options, error) {
+	var opts Options
+
+	err := c.do(ctx, func(conn *conn) error {
+		if err := c.writeRequest(
+			conn.w, "OPTIONS", []string{"Encapsulated: null-body=0"}, "",
+		); err != nil {
+			return err
+		}
+
+		resp, err := readResponse(conn.r)
+		if err != nil {
+			return err
+		}
+
+		if resp.status != http.StatusOK {
+			return &ResponseError{Response: resp.statusLine}
+		}
+
+		opts = parseOptions(resp)
+
+		return nil
+	})
+
+	return opts, err
+}
+
+func parseOptions(resp *response) Options {
+	opts := Options{
+		Methods: nil,
+		Preview: -1,
+		ISTag:   strings.Trim(resp.get("ISTag"), `"`),
+	}
+
+	for method := range strings.SplitSeq(resp.get("Methods"), ",") {
+		if method = strings.TrimSpace(method); method != "" {
+			opts.Methods = append(opts.Methods, method)
+		}
+	}
+
+	if preview, err := strconv.Atoi(resp.get("Preview")); err == nil && preview >= 0 {
+		opts.Preview = preview
+	}
+
+	return opts
+}
+
+// Ping checks the service is up by requesting its options.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Options(ctx)
+	return err
+}
+
+// previewSize returns the number of bytes to send as preview, -1 to send the whole file
+// straight away. Unless it is configured, the size the service advertises is used.
+func (c *Client) previewSize(ctx context.Context) (int, error) {
+	switch {
+	case c.config.Preview < 0:
+		return -1, nil
+	case c.config.Preview > 0:
+		return c.config.Preview, nil
+	}
+
+	c.mu.Lock()
+	preview := c.preview
+	c.mu.Unlock()
+
+	if preview != nil {
+		return *preview, nil
+	}
+
+	opts, err := c.Options(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get preview size: %w", err)
+	}
+
+	c.mu.Lock()
+	c.preview = &opts.Preview
+	c.mu.Unlock()
+
+	return opts.Preview, nil
+}
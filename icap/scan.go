@@ -0,0 +1,238 @@
+package icap
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nhost/hasura-storage/telemetry"
+)
+
+const (
+	statusContinue  = 100
+	statusNoContent = 204
+
+	// UnknownThreat is the name of the virus when the service blocks the file without
+	// telling what it found.
+	UnknownThreat = "unknown"
+)
+
+// Scan sends the content of r to the service and returns a VirusFoundError if it finds a
+// virus.
+func (c *Client) Scan(ctx context.Context, r io.ReaderAt) (err error) {
+	ctx, span := telemetry.Start(ctx, "icap.Scan")
+	defer func() { telemetry.End(span, err) }()
+
+	preview, err := c.previewSize(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.do(ctx, func(conn *conn) error {
+		return c.scan(conn, r, preview)
+	})
+}
+
+// encapsulated returns the HTTP message the file is sent in and the value of the
+// Encapsulated header that describes it.
+func (c *Client) encapsulated() (string, string) {
+	if c.config.Method == MethodReqMod {
+		req := "POST /file HTTP/1.1\r\n" +
+			"Host: hasura-storage\r\n" +
+			"Content-Type: application/octet-stream\r\n" +
+			"Transfer-Encoding: chunked\r\n\r\n"
+
+		return req, fmt.Sprintf("req-hdr=0, req-body=%d", len(req))
+	}
+
+	req := "GET /file HTTP/1.1\r\n" +
+		"Host: hasura-storage\r\n\r\n"
+	res := "HTTP/1.1 200 OK\r\n" +
+		"Content-Type: application/octet-stream\r\n" +
+		"Transfer-Encoding: chunked\r\n\r\n"
+
+	return req + res, fmt.Sprintf(
+		"req-hdr=0, res-hdr=%d, res-body=%d", len(req), len(req)+len(res),
+	)
+}
+
+func writeChunk(w *bufio.Writer, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "%x\r\n", len(data))
+	_, _ = w.Write(data)
+	fmt.Fprint(w, "\r\n")
+}
+
+func (c *Client) scan(conn *conn, r io.ReaderAt, preview int) error {
+	message, encapsulated := c.encapsulated()
+
+	header := []string{"Allow: 204", "Encapsulated: " + encapsulated}
+	if preview >= 0 {
+		header = append(header, "Preview: "+strconv.Itoa(preview))
+	}
+
+	if err := c.writeRequest(conn.w, c.config.Method, header, message); err != nil {
+		return err
+	}
+
+	var sent int64
+
+	if preview >= 0 {
+		n, done, err := c.sendPreview(conn, r, preview)
+		if done || err != nil {
+			return err
+		}
+
+		sent = n
+	}
+
+	return c.sendRest(conn, r, sent)
+}
+
+// sendPreview sends the first bytes of the file and returns how many were read and if
+// the service replied without needing the rest.
+func (c *Client) sendPreview(conn *conn, r io.ReaderAt, preview int) (int64, bool, error) {
+	// one more byte tells if the whole file fits in the preview
+	buf := make([]byte, preview+1)
+
+	n, err := r.ReadAt(buf, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, true, fmt.Errorf("failed to read preview: %w", err)
+	}
+
+	if n <= preview {
+		writeChunk(conn.w, buf[:n])
+		fmt.Fprint(conn.w, "0; ieof\r\n\r\n")
+	} else {
+		writeChunk(conn.w, buf[:preview])
+		fmt.Fprint(conn.w, "0\r\n\r\n")
+	}
+
+	if err := conn.w.Flush(); err != nil {
+		return 0, true, fmt.Errorf("failed to send preview: %w", err)
+	}
+
+	resp, err := readResponse(conn.r)
+	if err != nil {
+		return 0, true, err
+	}
+
+	if resp.status == statusContinue && n > preview {
+		// the byte read past the preview goes first, files are read sequentially
+		writeChunk(conn.w, buf[preview:n])
+		return int64(n), false, nil
+	}
+
+	return 0, true, c.verdict(conn, resp)
+}
+
+func (c *Client) sendRest(conn *conn, r io.ReaderAt, sent int64) error {
+	buf := make([]byte, c.config.ChunkSize)
+
+	for {
+		nr, err := r.ReadAt(buf, sent)
+
+		if nr > 0 {
+			writeChunk(conn.w, buf[:nr])
+			sent += int64(nr)
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read chunk: %w", err)
+		}
+	}
+
+	fmt.Fprint(conn.w, "0\r\n\r\n")
+
+	if err := conn.w.Flush(); err != nil {
+		// the service may reply before reading the whole file
+		if resp, rerr := readResponse(conn.r); rerr == nil {
+			return c.verdict(conn, resp)
+		}
+
+		return fmt.Errorf("failed to send file: %w", err)
+	}
+
+	resp, err := readResponse(conn.r)
+	if err != nil {
+		return err
+	}
+
+	return c.verdict(conn, resp)
+}
+
+// verdict returns the error corresponding to the reply to a scan, nil if the file is
+// clean. Besides the headers reporting the threats found, a service blocks a file by
+// replying with an HTTP error or, with REQMOD, with a response instead of the request.
+func (c *Client) verdict(conn *conn, resp *response) error {
+	switch resp.status {
+	case statusNoContent:
+		return nil
+	case http.StatusOK:
+	default:
+		return &ResponseError{Response: resp.statusLine}
+	}
+
+	if name := threat(resp); name != "" {
+		return &VirusFoundError{Name: name}
+	}
+
+	encapsulated := resp.get("Encapsulated")
+	if !strings.HasPrefix(encapsulated, "res-hdr=") {
+		return nil
+	}
+
+	line, err := readLine(conn.r)
+	if err != nil {
+		return fmt.Errorf("failed to read encapsulated response: %w", err)
+	}
+
+	_, status, _ := strings.Cut(line, " ")
+	code, _, _ := strings.Cut(status, " ")
+
+	statusCode, err := strconv.Atoi(code)
+	if err != nil {
+		return &ResponseError{Response: line}
+	}
+
+	if c.config.Method == MethodReqMod || statusCode >= http.StatusBadRequest {
+		return &VirusFoundError{Name: UnknownThreat}
+	}
+
+	return nil
+}
+
+// threat returns the names of the threats reported by the service, if any.
+func threat(resp *response) string {
+	// X-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Test-Signature;
+	for field := range strings.SplitSeq(resp.get("X-Infection-Found"), ";") {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(field), "Threat="); ok && name != "" {
+			return name
+		}
+	}
+
+	// X-Violations-Found: <count> followed by the filename, threat, id and disposition
+	// of each of them in continuation lines
+	if lines := resp.header["X-Violations-Found"]; len(lines) > 2 { //nolint:mnd
+		names := make([]string, 0, len(lines)/4) //nolint:mnd
+		for i := 2; i < len(lines); i += 4 {
+			names = append(names, lines[i])
+		}
+
+		return strings.Join(names, ", ")
+	}
+
+	return resp.get("X-Virus-ID")
+}
@@ -0,0 +1,115 @@
+package image
+
+import (
+	"bytes"
+)
+
+// AnimationProbeSize is how much of the beginning of an image IsAnimated needs to tell
+// whether most images are animated.
+const AnimationProbeSize = 1 << 20
+
+const (
+	gifHeaderSize          = 13
+	gifImageDescriptorSize = 10
+	gifColorTableFlag      = 0x80
+	gifColorTableSizeMask  = 0x07
+	gifExtension           = 0x21
+	gifImageDescriptor     = 0x2c
+	gifTrailer             = 0x3b
+
+	webpFlagsOffset    = 20
+	webpAnimationFlag  = 0x02
+	webpExtendedHeader = "VP8X"
+)
+
+// gifColorTableSize returns the size in bytes of the color table described by flags.
+func gifColorTableSize(flags byte) int {
+	if flags&gifColorTableFlag == 0 {
+		return 0
+	}
+
+	return 3 << (flags&gifColorTableSizeMask + 1) //nolint:mnd
+}
+
+// skipGIFSubBlocks returns the position after the data sub-blocks starting at pos, or
+// -1 if they don't end within b.
+func skipGIFSubBlocks(b []byte, pos int) int {
+	for pos < len(b) {
+		size := int(b[pos])
+		pos++
+
+		if size == 0 {
+			return pos
+		}
+
+		pos += size
+	}
+
+	return -1
+}
+
+// isAnimatedGIF counts the frames of the GIF starting with head.
+func isAnimatedGIF(head []byte) bool {
+	if len(head) < gifHeaderSize {
+		return true
+	}
+
+	frames := 0
+	pos := gifHeaderSize + gifColorTableSize(head[10])
+
+	for pos >= 0 && pos < len(head) {
+		switch head[pos] {
+		case gifExtension:
+			pos = skipGIFSubBlocks(head, pos+2) //nolint:mnd
+		case gifImageDescriptor:
+			frames++
+			if frames > 1 {
+				return true
+			}
+
+			if pos+gifImageDescriptorSize > len(head) {
+				return true
+			}
+
+			flags := head[pos+gifImageDescriptorSize-1]
+			pos += gifImageDescriptorSize + gifColorTableSize(flags)
+			// the LZW minimum code size precedes the image data
+			pos = skipGIFSubBlocks(head, pos+1)
+		case gifTrailer:
+			return false
+		default:
+			// libvips will fail to load it anyway
+			return false
+		}
+	}
+
+	return true
+}
+
+// isAnimatedWEBP checks the animation flag of the extended header, simple WebP images
+// can't be animated.
+func isAnimatedWEBP(head []byte) bool {
+	if len(head) <= webpFlagsOffset {
+		return true
+	}
+
+	if !bytes.Equal(head[12:16], []byte(webpExtendedHeader)) {
+		return false
+	}
+
+	return head[webpFlagsOffset]&webpAnimationFlag != 0
+}
+
+// IsAnimated returns whether the image of the given format starting with head has more
+// than one frame. head should be the first AnimationProbeSize bytes of the image, or all
+// of it if it is smaller; if that isn't enough to tell, the image is assumed animated.
+func IsAnimated(format ImageType, head []byte) bool {
+	switch format { //nolint:exhaustive
+	case ImageTypeGIF:
+		return isAnimatedGIF(head)
+	case ImageTypeWEBP:
+		return isAnimatedWEBP(head)
+	}
+
+	return false
+}
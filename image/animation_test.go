@@ -0,0 +1,104 @@
+package image_test
+
+import (
+	"bytes"
+	goimage "image"
+	"image/color"
+	"image/gif"
+	"os"
+	"testing"
+
+	"github.com/nhost/hasura-storage/image"
+)
+
+func encodeGIF(t *testing.T, frames int) []byte {
+	t.Helper()
+
+	palette := color.Palette{color.White, color.Black}
+
+	anim := &gif.GIF{} //nolint:exhaustruct
+	for i := range frames {
+		frame := goimage.NewPaletted(goimage.Rect(0, 0, 40, 20), palette)
+		frame.SetColorIndex(i, i, 1)
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, 10) //nolint:mnd
+	}
+
+	buf := &bytes.Buffer{}
+	if err := gif.EncodeAll(buf, anim); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestIsAnimated(t *testing.T) {
+	t.Parallel()
+
+	webp, err := os.ReadFile("testdata/nhost.webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	animatedWEBP := bytes.Clone(webp)
+	animatedWEBP[20] |= 0x02
+
+	png, err := os.ReadFile("testdata/nhost.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		format   image.ImageType
+		head     []byte
+		expected bool
+	}{
+		{
+			name:     "static gif",
+			format:   image.ImageTypeGIF,
+			head:     encodeGIF(t, 1),
+			expected: false,
+		},
+		{
+			name:     "animated gif",
+			format:   image.ImageTypeGIF,
+			head:     encodeGIF(t, 3),
+			expected: true,
+		},
+		{
+			name:     "gif cut before the end of the first frame",
+			format:   image.ImageTypeGIF,
+			head:     encodeGIF(t, 1)[:30],
+			expected: true,
+		},
+		{
+			name:     "static webp",
+			format:   image.ImageTypeWEBP,
+			head:     webp,
+			expected: false,
+		},
+		{
+			name:     "animated webp",
+			format:   image.ImageTypeWEBP,
+			head:     animatedWEBP,
+			expected: true,
+		},
+		{
+			name:     "png",
+			format:   image.ImageTypePNG,
+			head:     png,
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := image.IsAnimated(tc.format, tc.head); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package image
+
+import (
+	goimage "image"
+	"math"
+	"strings"
+)
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// BlurHash components, 4x3 is the recommended size for most images.
+const (
+	blurHashXComponents = 4
+	blurHashYComponents = 3
+)
+
+func encodeBase83(sb *strings.Builder, value, length int) {
+	for i := 1; i <= length; i++ {
+		digit := (value / int(math.Pow(83, float64(length-i)))) % 83 //nolint:mnd
+		sb.WriteByte(base83Chars[digit])
+	}
+}
+
+func sRGBToLinear(value uint8) float64 {
+	v := float64(value) / math.MaxUint8
+	if v <= 0.04045 { //nolint:mnd
+		return v / 12.92 //nolint:mnd
+	}
+
+	return math.Pow((v+0.055)/1.055, 2.4) //nolint:mnd
+}
+
+func linearToSRGB(value float64) int {
+	v := max(0, min(1, value))
+	if v <= 0.0031308 { //nolint:mnd
+		return int(v*12.92*math.MaxUint8 + 0.5) //nolint:mnd
+	}
+
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*math.MaxUint8 + 0.5) //nolint:mnd
+}
+
+func signPow(value, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(value), exp), value)
+}
+
+// blurHashFactor is the contribution of the cosine basis function (i, j) to the image.
+func blurHashFactor(pixels [][3]float64, width, height, i, j int) [3]float64 {
+	var factor [3]float64
+
+	for y := range height {
+		for x := range width {
+			basis := math.Cos(math.Pi*float64(i)*float64(x)/float64(width)) *
+				math.Cos(math.Pi*float64(j)*float64(y)/float64(height))
+
+			for c := range 3 {
+				factor[c] += basis * pixels[y*width+x][c]
+			}
+		}
+	}
+
+	normalisation := 2.0
+	if i == 0 && j == 0 {
+		normalisation = 1
+	}
+
+	scale := normalisation / float64(width*height)
+	for c := range 3 {
+		factor[c] *= scale
+	}
+
+	return factor
+}
+
+// encodeBlurHash returns the BlurHash (https://blurha.sh) of img. Transparent pixels are
+// expected to be flattened beforehand as the alpha channel is ignored.
+func encodeBlurHash(img goimage.Image) string {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	pixels := make([][3]float64, 0, width*height)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := rgba(img, x, y)
+			pixels = append(pixels, [3]float64{
+				sRGBToLinear(c.R), sRGBToLinear(c.G), sRGBToLinear(c.B),
+			})
+		}
+	}
+
+	factors := make([][3]float64, 0, blurHashXComponents*blurHashYComponents)
+	for j := range blurHashYComponents {
+		for i := range blurHashXComponents {
+			factors = append(factors, blurHashFactor(pixels, width, height, i, j))
+		}
+	}
+
+	dc, ac := factors[0], factors[1:]
+
+	var sb strings.Builder
+
+	encodeBase83(&sb, (blurHashXComponents-1)+(blurHashYComponents-1)*9, 1) //nolint:mnd
+
+	maximumValue := 0.0
+	for _, f := range ac {
+		for _, v := range f {
+			maximumValue = max(maximumValue, math.Abs(v))
+		}
+	}
+
+	quantisedMaximumValue := int(max(0, min(82, math.Floor(maximumValue*166-0.5)))) //nolint:mnd
+	maximumValue = float64(quantisedMaximumValue+1) / 166                           //nolint:mnd
+	encodeBase83(&sb, quantisedMaximumValue, 1)
+
+	encodeBase83(
+		&sb,
+		linearToSRGB(dc[0])<<16+linearToSRGB(dc[1])<<8+linearToSRGB(dc[2]), //nolint:mnd
+		4, //nolint:mnd
+	)
+
+	for _, f := range ac {
+		var q [3]int
+		for c, v := range f {
+			q[c] = int(max(0, min(18, math.Floor(signPow(v/maximumValue, 0.5)*9+9.5)))) //nolint:mnd
+		}
+
+		encodeBase83(&sb, q[0]*19*19+q[1]*19+q[2], 2) //nolint:mnd
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,76 @@
+package image //nolint:testpackage
+
+import (
+	goimage "image"
+	"image/color"
+	"testing"
+)
+
+func newTestImage(width, height int, f func(x, y int) color.NRGBA) goimage.Image {
+	img := goimage.NewNRGBA(goimage.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.SetNRGBA(x, y, f(x, y))
+		}
+	}
+
+	return img
+}
+
+func TestEncodeBlurHash(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		img      goimage.Image
+		expected string
+	}{
+		{
+			name: "gradient",
+			img: newTestImage(8, 6, func(x, y int) color.NRGBA { //nolint:mnd
+				return color.NRGBA{
+					R: uint8(255 * x / 7), //nolint:gosec,mnd
+					G: uint8(255 * y / 5), //nolint:gosec,mnd
+					B: 128,                //nolint:mnd
+					A: 255,                //nolint:mnd
+				}
+			}),
+			expected: "LyI5er3AfQxtz4NKfQnSeXf7fQf7",
+		},
+		{
+			name: "solid",
+			img: newTestImage(4, 4, func(int, int) color.NRGBA { //nolint:mnd
+				return color.NRGBA{R: 255, G: 0, B: 0, A: 255} //nolint:mnd
+			}),
+			expected: "L~TI:j|cfQ|c|c$5fQ$5fQfQfQfQ",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := encodeBlurHash(tc.img); got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestDominantColor(t *testing.T) {
+	t.Parallel()
+
+	// two thirds of the image is (almost) blue
+	img := newTestImage(3, 2, func(x, y int) color.NRGBA { //nolint:mnd
+		if x == 0 {
+			return color.NRGBA{R: 255, G: 0, B: 0, A: 255} //nolint:mnd
+		}
+
+		return color.NRGBA{R: 0, G: 0, B: uint8(250 + x + y), A: 255} //nolint:gosec,mnd
+	})
+
+	expected := Color{R: 0, G: 0, B: 252, A: 255}
+	if got := dominantColor(img); got != expected {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -2,17 +2,16 @@ package image
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math"
-	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/davidbyttow/govips/v2/vips"
-)
-
-const (
-	maxWorkers = 3
+	"github.com/nhost/hasura-storage/metrics"
 )
 
 type ImageType int //nolint: revive
@@ -24,8 +23,103 @@ const (
 	ImageTypePNG
 	ImageTypeWEBP
 	ImageTypeAVIF
+	ImageTypeGIF
+	ImageTypeTIFF
+	ImageTypeJXL
+	// ImageTypeHEIF and ImageTypeSVG can only be read, they need to be converted to
+	// another format.
+	ImageTypeHEIF
+	ImageTypeSVG
+)
+
+// CanExport returns whether images can be encoded in this format.
+func (t ImageType) CanExport() bool {
+	return t != ImageTypeHEIF && t != ImageTypeSVG
+}
+
+// SupportsAnimation returns whether the format can store animations. Whether an image
+// is animated is told by IsAnimated.
+func (t ImageType) SupportsAnimation() bool {
+	return t == ImageTypeGIF || t == ImageTypeWEBP
+}
+
+var (
+	// ErrInvalidOptions is returned when the options can't be applied to the image.
+	ErrInvalidOptions = errors.New("invalid image options")
+	// ErrTooLarge is returned when the image exceeds the limits of the transformer.
+	ErrTooLarge = errors.New("image too large")
+	// ErrBusy is returned when no worker becomes free within the queue timeout.
+	ErrBusy = errors.New("too many images being transformed")
+	// ErrTimeout is returned when the image takes too long to transform.
+	ErrTimeout = errors.New("image transformation timed out")
 )
 
+// Fit is how the image is resized when both the width and height are given.
+type Fit int
+
+const (
+	// FitCover resizes the image to cover both dimensions, cropping what doesn't fit.
+	FitCover Fit = iota
+	// FitContain resizes the image to fit within both dimensions and pads it with the
+	// background color.
+	FitContain
+	// FitFill stretches the image to both dimensions, ignoring its aspect ratio.
+	FitFill
+	// FitInside resizes the image to fit within both dimensions.
+	FitInside
+)
+
+// Gravity is the part of the image kept when it is cropped to cover both dimensions.
+type Gravity int
+
+const (
+	GravityCenter Gravity = iota
+	// GravityAttention keeps the most salient features of the image.
+	GravityAttention
+	// GravityEntropy keeps the area with most detail.
+	GravityEntropy
+)
+
+func (g Gravity) interesting() vips.Interesting {
+	switch g {
+	case GravityAttention:
+		return vips.InterestingAttention
+	case GravityEntropy:
+		return vips.InterestingEntropy
+	case GravityCenter:
+	}
+
+	return vips.InterestingCentre
+}
+
+type Flip int
+
+const (
+	FlipNone Flip = iota
+	FlipHorizontal
+	FlipVertical
+	FlipBoth
+)
+
+// Rect is an area of the image, in pixels.
+type Rect struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+func (r Rect) IsEmpty() bool {
+	return r.Width == 0 || r.Height == 0
+}
+
+type Color struct {
+	R uint8
+	G uint8
+	B uint8
+	A uint8
+}
+
 type Options struct {
 	Height         int
 	Width          int
@@ -33,11 +127,63 @@ type Options struct {
 	Quality        int
 	OriginalFormat ImageType
 	Format         ImageType
+	// Crop is applied to the original image, before resizing it.
+	Crop    Rect
+	Fit     Fit
+	Gravity Gravity
+	// Rotate is the clockwise rotation in degrees, one of 0, 90, 180 or 270.
+	Rotate    int
+	Flip      Flip
+	Sharpen   float32
+	Grayscale bool
+	// Background pads the image when Fit is FitContain.
+	Background Color
+	// DPR multiplies Width and Height, 1 if 0.
+	DPR float32
+	// StripMetadata removes the EXIF, XMP and IPTC metadata of the image. Its EXIF
+	// orientation is applied first, before any other transformation.
+	StripMetadata bool
+	// KeepICCProfile keeps the ICC profile when stripping the metadata, otherwise the
+	// image is converted to sRGB.
+	KeepICCProfile bool
 }
 
 func (o Options) IsEmpty() bool {
 	return o.Height == 0 && o.Width == 0 && o.Blur == 0 && o.Quality == 0 &&
-		o.OriginalFormat == o.Format
+		o.OriginalFormat == o.Format && o.Crop.IsEmpty() && o.Rotate == 0 &&
+		o.Flip == FlipNone && o.Sharpen == 0 && !o.Grayscale && !o.StripMetadata
+}
+
+// Normalized returns options producing the same image with the device pixel ratio
+// applied to the dimensions and the fields that don't affect the result zeroed, so
+// equivalent options can be compared.
+func (o Options) Normalized() Options {
+	dpr := float64(o.DPR)
+	if dpr == 0 {
+		dpr = 1
+	}
+
+	o.Width = int(math.Round(float64(o.Width) * dpr))
+	o.Height = int(math.Round(float64(o.Height) * dpr))
+	o.DPR = 0
+
+	if o.Width == 0 || o.Height == 0 {
+		o.Fit = FitCover
+	}
+
+	if o.Fit != FitCover || (o.Width == 0 && o.Height == 0) {
+		o.Gravity = GravityCenter
+	}
+
+	if o.Fit != FitContain {
+		o.Background = Color{} //nolint:exhaustruct
+	}
+
+	if !o.StripMetadata {
+		o.KeepICCProfile = false
+	}
+
+	return o
 }
 
 func (o Options) FormatChanged() bool {
@@ -54,6 +200,13 @@ func (o Options) FormatMimeType() string {
 		return "image/webp"
 	case ImageTypeAVIF:
 		return "image/avif"
+	case ImageTypeGIF:
+		return "image/gif"
+	case ImageTypeTIFF:
+		return "image/tiff"
+	case ImageTypeJXL:
+		return "image/jxl"
+	case ImageTypeHEIF, ImageTypeSVG:
 	}
 
 	return ""
@@ -69,34 +222,58 @@ func (o Options) FileExtension() string {
 		return "webp"
 	case ImageTypeAVIF:
 		return "avif"
+	case ImageTypeGIF:
+		return "gif"
+	case ImageTypeTIFF:
+		return "tiff"
+	case ImageTypeJXL:
+		return "jxl"
+	case ImageTypeHEIF, ImageTypeSVG:
 	}
 
 	return ""
 }
 
+// Config limits the resources used to transform images. Zero values mean no limit.
+type Config struct {
+	// Workers is the number of images transformed at the same time, at least 1.
+	Workers int
+	// QueueTimeout is how long a transformation waits for a free worker.
+	QueueTimeout time.Duration
+	// Timeout is how long a transformation can take once it has a worker.
+	Timeout time.Duration
+	// MaxInputSize is the maximum size in bytes of the original image.
+	MaxInputSize uint64
+	// MaxPixels is the maximum number of pixels of the original image, counting all the
+	// frames of animations, to protect against decompression bombs.
+	MaxPixels int
+	// MaxDimension is the maximum width or height of the original image.
+	MaxDimension int
+}
+
 type Transformer struct {
+	config  Config
 	workers chan struct{}
-	pool    sync.Pool
 }
 
-func NewTransformer() *Transformer {
+func NewTransformer(config Config) *Transformer {
 	if atomic.CompareAndSwapInt32(&initialized, 0, 1) {
 		vips.LoggingSettings(nil, vips.LogLevelWarning)
 		vips.Startup(nil)
 	}
 
-	workers := make(chan struct{}, maxWorkers)
-	for range maxWorkers {
+	config.Workers = max(config.Workers, 1)
+
+	workers := make(chan struct{}, config.Workers)
+	for range config.Workers {
 		workers <- struct{}{}
 	}
 
+	metrics.SetImageTransformerWorkers(config.Workers)
+
 	return &Transformer{
+		config:  config,
 		workers: workers,
-		pool: sync.Pool{
-			New: func() any {
-				return new(bytes.Buffer)
-			},
-		},
 	}
 }
 
@@ -104,6 +281,21 @@ func (t *Transformer) Shutdown() {
 	vips.Shutdown()
 }
 
+// Ping checks libvips is initialized by creating a tiny image.
+func (t *Transformer) Ping(_ context.Context) error {
+	if atomic.LoadInt32(&initialized) == 0 {
+		return errors.New("libvips is not initialized") //nolint:err113
+	}
+
+	image, err := vips.Black(1, 1)
+	if err != nil {
+		return fmt.Errorf("problem creating image: %w", err)
+	}
+	defer image.Close()
+
+	return nil
+}
+
 func export(image *vips.ImageRef, opts Options) ([]byte, error) {
 	var (
 		b   []byte
@@ -127,27 +319,277 @@ func export(image *vips.ImageRef, opts Options) ([]byte, error) {
 		ep.Quality = opts.Quality
 		ep.Effort = 0
 		b, _, err = image.ExportAvif(ep)
+	case ImageTypeGIF:
+		ep := vips.NewGifExportParams()
+		if opts.Quality > 0 {
+			ep.Quality = opts.Quality
+		}
+		b, _, err = image.ExportGIF(ep)
+	case ImageTypeTIFF:
+		ep := vips.NewTiffExportParams()
+		if opts.Quality > 0 {
+			ep.Quality = opts.Quality
+		}
+		b, _, err = image.ExportTiff(ep)
+	case ImageTypeJXL:
+		ep := vips.NewJxlExportParams()
+		if opts.Quality > 0 {
+			ep.Quality = opts.Quality
+		}
+		b, _, err = image.ExportJxl(ep)
+	case ImageTypeHEIF, ImageTypeSVG:
+		err = fmt.Errorf("%w: can't encode images in this format", ErrInvalidOptions)
 	}
 
 	return b, err //nolint: wrapcheck
 }
 
-func processImage(image *vips.ImageRef, opts Options) error {
-	if opts.Width > 0 || opts.Height > 0 {
-		width := opts.Width
-		height := opts.Height
+// defaults of vips_sharpen for the flat/jaggy threshold and the slope for jaggy areas.
+const (
+	sharpenX1 = 2
+	sharpenM2 = 3
+)
+
+func crop(image *vips.ImageRef, rect Rect) error {
+	width := min(rect.Width, image.Width()-rect.X)
+	height := min(rect.Height, image.Height()-rect.Y)
+
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("%w: crop area is outside of the image", ErrInvalidOptions)
+	}
+
+	if err := image.ExtractArea(rect.X, rect.Y, width, height); err != nil {
+		return fmt.Errorf("failed to crop: %w", err)
+	}
+
+	return nil
+}
+
+func orientate(image *vips.ImageRef, opts Options) error {
+	angles := map[int]vips.Angle{
+		0:   vips.Angle0,
+		90:  vips.Angle90,
+		180: vips.Angle180,
+		270: vips.Angle270,
+	}
+
+	angle, ok := angles[opts.Rotate]
+	if !ok {
+		return fmt.Errorf("%w: can't rotate %d degrees", ErrInvalidOptions, opts.Rotate)
+	}
+
+	flip := opts.Flip
+	if angle == vips.Angle180 && animated(image) {
+		// rotating 180 degrees is the same as flipping both ways (the Flip values are bit
+		// flags), which keeps the frames in order
+		angle = vips.Angle0
+		flip ^= FlipBoth
+	}
+
+	if angle != vips.Angle0 {
+		if err := image.Rotate(angle); err != nil {
+			return fmt.Errorf("failed to rotate: %w", err)
+		}
+	}
+
+	if flip == FlipHorizontal || flip == FlipBoth {
+		if err := image.Flip(vips.DirectionHorizontal); err != nil {
+			return fmt.Errorf("failed to flip: %w", err)
+		}
+	}
 
-		if width == 0 {
-			width = int((float64(height) / float64(image.Height())) * float64(image.Width()))
+	if flip == FlipVertical || flip == FlipBoth {
+		if err := flipVertical(image); err != nil {
+			return fmt.Errorf("failed to flip: %w", err)
 		}
+	}
+
+	return nil
+}
+
+// animated returns whether more than one frame of image was loaded. Pages can't be used
+// for this, it is the number of frames in the file even if only the first one is loaded.
+func animated(image *vips.ImageRef) bool {
+	return image.Height() > image.PageHeight()
+}
 
-		if height == 0 {
-			height = int((float64(width) / float64(image.Width())) * float64(image.Height()))
+// flipVertical flips the image upside down. The frames of animated images are stacked
+// vertically, so they are flipped with rotations that keep them in order.
+func flipVertical(image *vips.ImageRef) error {
+	if !animated(image) {
+		return image.Flip(vips.DirectionVertical) //nolint:wrapcheck
+	}
+
+	if err := image.Rotate(vips.Angle90); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if err := image.Flip(vips.DirectionHorizontal); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return image.Rotate(vips.Angle270) //nolint:wrapcheck
+}
+
+func contain(image *vips.ImageRef, width, height int, background Color) error {
+	if err := image.ThumbnailWithSize(
+		width, height, vips.InterestingNone, vips.SizeBoth,
+	); err != nil {
+		return fmt.Errorf("failed to thumbnail: %w", err)
+	}
+
+	if background.A < math.MaxUint8 && !image.HasAlpha() {
+		if err := image.AddAlpha(); err != nil {
+			return fmt.Errorf("failed to add alpha channel: %w", err)
 		}
+	}
+
+	if err := image.EmbedBackgroundRGBA(
+		(width-image.Width())/2,   //nolint:mnd
+		(height-image.Height())/2, //nolint:mnd
+		width,
+		height,
+		&vips.ColorRGBA{R: background.R, G: background.G, B: background.B, A: background.A},
+	); err != nil {
+		return fmt.Errorf("failed to pad: %w", err)
+	}
+
+	return nil
+}
+
+// resizeAnimated resizes the frames of an animated image to fit within width and height,
+// keeping their aspect ratio as cropping or padding each frame isn't supported.
+func resizeAnimated(image *vips.ImageRef, width, height int) error {
+	hScale := float64(width) / float64(image.Width())
+	vScale := float64(height) / float64(image.PageHeight())
+
+	var scale float64
+
+	switch {
+	case width == 0:
+		scale = vScale
+	case height == 0:
+		scale = hScale
+	default:
+		scale = min(hScale, vScale)
+	}
+
+	if err := image.Resize(scale, vips.KernelAuto); err != nil {
+		return fmt.Errorf("failed to resize: %w", err)
+	}
 
-		if err := image.Thumbnail(width, height, vips.InterestingCentre); err != nil {
+	return nil
+}
+
+func resize(image *vips.ImageRef, opts Options) error {
+	dpr := float64(opts.DPR)
+	if dpr == 0 {
+		dpr = 1
+	}
+
+	width := int(math.Round(float64(opts.Width) * dpr))
+	height := int(math.Round(float64(opts.Height) * dpr))
+
+	if width == 0 && height == 0 {
+		return nil
+	}
+
+	if animated(image) {
+		return resizeAnimated(image, width, height)
+	}
+
+	switch {
+	case width == 0:
+		width = int((float64(height) / float64(image.Height())) * float64(image.Width()))
+	case height == 0:
+		height = int((float64(width) / float64(image.Width())) * float64(image.Height()))
+	case opts.Fit == FitContain:
+		return contain(image, width, height, opts.Background)
+	case opts.Fit == FitFill:
+		if err := image.ResizeWithVScale(
+			float64(width)/float64(image.Width()),
+			float64(height)/float64(image.Height()),
+			vips.KernelAuto,
+		); err != nil {
+			return fmt.Errorf("failed to resize: %w", err)
+		}
+
+		return nil
+	case opts.Fit == FitInside:
+		if err := image.ThumbnailWithSize(
+			width, height, vips.InterestingNone, vips.SizeBoth,
+		); err != nil {
 			return fmt.Errorf("failed to thumbnail: %w", err)
 		}
+
+		return nil
+	}
+
+	if err := image.Thumbnail(width, height, opts.Gravity.interesting()); err != nil {
+		return fmt.Errorf("failed to thumbnail: %w", err)
+	}
+
+	return nil
+}
+
+// strip removes the EXIF, XMP and IPTC metadata of the image, rotating it first as its
+// EXIF orientation says so it is still displayed the same way.
+func strip(image *vips.ImageRef, keepICCProfile bool) error {
+	if err := image.AutoRotate(); err != nil {
+		return fmt.Errorf("failed to auto rotate: %w", err)
+	}
+
+	if err := image.RemoveMetadata(); err != nil {
+		return fmt.Errorf("failed to remove metadata: %w", err)
+	}
+
+	if keepICCProfile || !image.HasICCProfile() {
+		return nil
+	}
+
+	// images without a profile are assumed to be sRGB
+	if err := image.TransformICCProfile(vips.SRGBIEC6196621ICCProfilePath); err != nil {
+		return fmt.Errorf("failed to convert to sRGB: %w", err)
+	}
+
+	if err := image.RemoveICCProfile(); err != nil {
+		return fmt.Errorf("failed to remove ICC profile: %w", err)
+	}
+
+	return nil
+}
+
+func processImage(image *vips.ImageRef, opts Options) error { //nolint:cyclop
+	if opts.StripMetadata {
+		if err := strip(image, opts.KeepICCProfile); err != nil {
+			return err
+		}
+	}
+
+	if !opts.Crop.IsEmpty() {
+		if err := crop(image, opts.Crop); err != nil {
+			return err
+		}
+	}
+
+	if err := orientate(image, opts); err != nil {
+		return err
+	}
+
+	if err := resize(image, opts); err != nil {
+		return err
+	}
+
+	if opts.Sharpen > 0 {
+		if err := image.Sharpen(float64(opts.Sharpen), sharpenX1, sharpenM2); err != nil {
+			return fmt.Errorf("failed to sharpen: %w", err)
+		}
+	}
+
+	if opts.Grayscale {
+		if err := image.ToColorSpace(vips.InterpretationBW); err != nil {
+			return fmt.Errorf("failed to convert to grayscale: %w", err)
+		}
 	}
 
 	if opts.Blur > 0 {
@@ -159,50 +601,213 @@ func processImage(image *vips.ImageRef, opts Options) error {
 	return nil
 }
 
-func (t *Transformer) Run(
-	orig io.Reader,
-	length uint64,
-	modified io.Writer,
-	opts Options,
-) error {
+// acquire waits for a free worker, the returned function releases it.
+func (t *Transformer) acquire(ctx context.Context) (func(), error) {
 	// this is to avoid processing too many images at the same time in order to save memory
-	<-t.workers
+	waiting := metrics.ImageTransformerWaiting()
+	defer waiting()
 
-	defer func() { t.workers <- struct{}{} }()
+	var timeout <-chan time.Time
 
-	buf, _ := t.pool.Get().(*bytes.Buffer)
-	defer t.pool.Put(buf)
-	defer buf.Reset()
+	if t.config.QueueTimeout > 0 {
+		timer := time.NewTimer(t.config.QueueTimeout)
+		defer timer.Stop()
 
-	if length > math.MaxUint32 {
-		panic("length is too big")
+		timeout = timer.C
 	}
 
-	if l := int(length); buf.Len() < l {
-		buf.Grow(l)
+	select {
+	case <-t.workers:
+	case <-timeout:
+		return nil, fmt.Errorf("%w: no worker free after %s", ErrBusy, t.config.QueueTimeout)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("gave up waiting for a worker: %w", context.Cause(ctx))
 	}
 
-	_, err := io.Copy(buf, orig)
+	busy := metrics.ImageTransformerBusy()
+
+	return func() {
+		busy()
+		t.workers <- struct{}{}
+	}, nil
+}
+
+// read returns the content of orig, checking it isn't larger than allowed.
+func (t *Transformer) read(orig io.Reader, length uint64) ([]byte, error) {
+	maxSize := t.config.MaxInputSize
+	if maxSize == 0 || maxSize > math.MaxUint32 {
+		maxSize = math.MaxUint32
+	}
+
+	if length > maxSize {
+		return nil, fmt.Errorf("%w: %d bytes, the maximum is %d", ErrTooLarge, length, maxSize)
+	}
+
+	// length might be wrong so we still need to limit what we read
+	buf := bytes.NewBuffer(make([]byte, 0, length))
+	if _, err := io.Copy(buf, io.LimitReader(orig, int64(maxSize)+1)); err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	if uint64(buf.Len()) > maxSize {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, maxSize)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// load decodes the headers of b, checking the image isn't larger than allowed before
+// its pixels are decoded.
+func (t *Transformer) load(b []byte, params *vips.ImportParams) (*vips.ImageRef, error) {
+	image, err := vips.LoadImageFromBuffer(b, params)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to load image: %w", err)
 	}
 
-	image, err := vips.NewImageFromBuffer(buf.Bytes())
+	width, height := image.Width(), image.PageHeight()
+
+	switch {
+	case t.config.MaxDimension > 0 && max(width, height) > t.config.MaxDimension:
+		image.Close()
+
+		return nil, fmt.Errorf(
+			"%w: %dx%d, the maximum dimension is %d",
+			ErrTooLarge, width, height, t.config.MaxDimension,
+		)
+	case t.config.MaxPixels > 0 && width*image.Height() > t.config.MaxPixels:
+		image.Close()
+
+		return nil, fmt.Errorf(
+			"%w: %d pixels, the maximum is %d",
+			ErrTooLarge, width*image.Height(), t.config.MaxPixels,
+		)
+	}
+
+	return image, nil
+}
+
+// loadFrames loads b with its first frame and, if allFrames is set and it has more, loads
+// it again with all of them so static images aren't decoded as animations.
+func (t *Transformer) loadFrames(b []byte, allFrames bool) (*vips.ImageRef, error) {
+	image, err := t.load(b, nil)
+	if err != nil || !allFrames || image.Pages() <= 1 {
+		return image, err
+	}
+
+	image.Close()
+
+	params := vips.NewImportParams()
+	params.NumPages.Set(-1)
+
+	return t.load(b, params)
+}
+
+type result[T any] struct {
+	value T
+	err   error
+}
+
+// withImage calls f with b loaded, releasing the worker afterwards. Only the first frame
+// of animations is loaded unless allFrames is set. It gives up when ctx is done or the
+// timeout expires but, as libvips can't be interrupted, f keeps running in the
+// background holding its worker until it returns.
+func withImage[T any](
+	ctx context.Context,
+	t *Transformer,
+	release func(),
+	b []byte,
+	allFrames bool,
+	f func(image *vips.ImageRef) (T, error),
+) (T, error) {
+	var zero T
+
+	if t.config.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, t.config.Timeout)
+		defer cancel()
+	}
+
+	done := make(chan result[T], 1)
+
+	go func() {
+		defer release()
+		defer func() {
+			// the recovery middleware doesn't cover this goroutine
+			if r := recover(); r != nil {
+				err := fmt.Errorf("panic transforming image: %v", r) //nolint:err113
+				done <- result[T]{value: zero, err: err}
+			}
+		}()
+
+		image, err := t.loadFrames(b, allFrames)
+		if err != nil {
+			done <- result[T]{value: zero, err: err}
+			return
+		}
+		defer image.Close()
+
+		v, err := f(image)
+		done <- result[T]{value: v, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return zero, fmt.Errorf("%w: %w", ErrTimeout, context.Cause(ctx))
+		}
+
+		return zero, fmt.Errorf("gave up transforming image: %w", context.Cause(ctx))
+	}
+}
+
+func (t *Transformer) Run(
+	ctx context.Context,
+	orig io.Reader,
+	length uint64,
+	modified io.Writer,
+	opts Options,
+) (err error) {
+	// the input is read before taking a worker so slow downloads don't hold one
+	b, err := t.read(orig, length)
 	if err != nil {
-		return fmt.Errorf("failed to load image: %w", err)
+		return err
 	}
-	defer image.Close()
 
-	if err := processImage(image, opts); err != nil {
+	release, err := t.acquire(ctx)
+	if err != nil {
 		return err
 	}
 
-	b, err := export(image, opts)
+	start := time.Now()
+	defer func() {
+		metrics.ObserveImageTransformation(opts.FileExtension(), time.Since(start), err)
+	}()
+
+	process := func(image *vips.ImageRef) ([]byte, error) {
+		if err := processImage(image, opts); err != nil {
+			return nil, err
+		}
+
+		b, err := export(image, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export: %w", err)
+		}
+
+		return b, nil
+	}
+
+	// all the frames are only needed if the animation is kept
+	allFrames := opts.OriginalFormat.SupportsAnimation() && opts.Format.SupportsAnimation()
+
+	transformed, err := withImage(ctx, t, release, b, allFrames, process)
 	if err != nil {
-		return fmt.Errorf("failed to export: %w", err)
+		return err
 	}
 
-	if _, err = modified.Write(b); err != nil {
+	if _, err = modified.Write(transformed); err != nil {
 		return fmt.Errorf("failed to write: %w", err)
 	}
 
@@ -1,11 +1,15 @@
 package image_test
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"image/gif"
 	"io"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/nhost/hasura-storage/image"
@@ -78,7 +82,7 @@ func TestManipulate(t *testing.T) {
 		},
 	}
 
-	transformer := image.NewTransformer()
+	transformer := image.NewTransformer(image.Config{Workers: 3})
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -93,8 +97,8 @@ func TestManipulate(t *testing.T) {
 			hasher := sha256.New()
 			// f, _ := os.OpenFile("/tmp/nhost-test."+tc.name, os.O_WRONLY|os.O_CREATE, 0o644)
 			// defer f.Close()
-			// if err := transformer.Run(orig, tc.size, f, tc.options); err != nil {
-			if err := transformer.Run(orig, tc.size, hasher, tc.options); err != nil {
+			// if err := transformer.Run(t.Context(), orig, tc.size, f, tc.options); err != nil {
+			if err := transformer.Run(t.Context(), orig, tc.size, hasher, tc.options); err != nil {
 				t.Fatal(err)
 			}
 
@@ -106,8 +110,161 @@ func TestManipulate(t *testing.T) {
 	}
 }
 
+func TestManipulateInvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		options image.Options
+	}{
+		{
+			name: "crop outside of the image",
+			options: image.Options{
+				Crop:   image.Rect{X: 5000, Y: 0, Width: 10, Height: 10},
+				Format: image.ImageTypeJPEG,
+			},
+		},
+		{
+			name:    "rotation",
+			options: image.Options{Rotate: 45, Format: image.ImageTypeJPEG},
+		},
+	}
+
+	transformer := image.NewTransformer(image.Config{Workers: 3})
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			orig, err := os.Open("testdata/nhost.jpg")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer orig.Close()
+
+			err = transformer.Run(t.Context(), orig, 33399, io.Discard, tc.options)
+			if !errors.Is(err, image.ErrInvalidOptions) {
+				t.Errorf("expected ErrInvalidOptions, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStripMetadata(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name           string
+		keepICCProfile bool
+		expected       map[string]bool
+	}{
+		{
+			name:           "keep icc profile",
+			keepICCProfile: true,
+			expected: map[string]bool{
+				"Exif":                         false,
+				"http://ns.adobe.com/xap/1.0/": false,
+				"ICC_PROFILE":                  true,
+			},
+		},
+		{
+			name:           "remove icc profile",
+			keepICCProfile: false,
+			expected: map[string]bool{
+				"Exif":                         false,
+				"http://ns.adobe.com/xap/1.0/": false,
+				"ICC_PROFILE":                  false,
+			},
+		},
+	}
+
+	transformer := image.NewTransformer(image.Config{Workers: 3})
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			orig, err := os.Open("testdata/nhost.jpg")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer orig.Close()
+
+			buf := &bytes.Buffer{}
+			if err := transformer.Run(t.Context(), orig, 33399, buf, image.Options{
+				Format:         image.ImageTypeJPEG,
+				StripMetadata:  true,
+				KeepICCProfile: tc.keepICCProfile,
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string]bool, len(tc.expected))
+			for marker := range tc.expected {
+				got[marker] = bytes.Contains(buf.Bytes(), []byte(marker))
+			}
+
+			if !cmp.Equal(got, tc.expected) {
+				t.Error(cmp.Diff(got, tc.expected))
+			}
+		})
+	}
+}
+
+func TestInfo(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		filename string
+		size     uint64
+	}{
+		{name: "jpg", filename: "testdata/nhost.jpg", size: 33399},
+		{name: "png", filename: "testdata/nhost.png", size: 68307},
+	}
+
+	transformer := image.NewTransformer(image.Config{Workers: 3})
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			orig, err := os.Open(tc.filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer orig.Close()
+
+			info, err := transformer.Info(t.Context(), orig, tc.size)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the placeholder depends on the version of libvips so we only check its size
+			if len(info.BlurHash) != 28 { //nolint:mnd
+				t.Errorf("unexpected blurhash: %s", info.BlurHash)
+			}
+
+			info.BlurHash = ""
+			info.DominantColor = image.Color{} //nolint:exhaustruct
+
+			expected := image.Info{
+				Width:         678,
+				Height:        258,
+				Orientation:   1,
+				ColorSpace:    "srgb",
+				DominantColor: image.Color{}, //nolint:exhaustruct
+				BlurHash:      "",
+			}
+			if !cmp.Equal(info, expected) {
+				t.Error(cmp.Diff(info, expected))
+			}
+		})
+	}
+}
+
 func BenchmarkManipulate(b *testing.B) {
-	transformer := image.NewTransformer()
+	transformer := image.NewTransformer(image.Config{Workers: 3})
 
 	orig, err := os.Open("testdata/nhost.jpg")
 	if err != nil {
@@ -119,6 +276,7 @@ func BenchmarkManipulate(b *testing.B) {
 		_, _ = orig.Seek(0, 0)
 
 		if err := transformer.Run(
+			b.Context(),
 			orig,
 			33399,
 			io.Discard,
@@ -128,3 +286,184 @@ func BenchmarkManipulate(b *testing.B) {
 		}
 	}
 }
+
+func TestManipulateFormats(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		format image.ImageType
+		magic  []byte
+	}{
+		{name: "gif", format: image.ImageTypeGIF, magic: []byte("GIF89a")},
+		{name: "tiff", format: image.ImageTypeTIFF, magic: []byte("II*\x00")},
+		{name: "jxl", format: image.ImageTypeJXL, magic: []byte("\xff\x0a")},
+	}
+
+	transformer := image.NewTransformer(image.Config{Workers: 3})
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			orig, err := os.Open("testdata/nhost.png")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer orig.Close()
+
+			buf := &bytes.Buffer{}
+			if err := transformer.Run(t.Context(), orig, 68307, buf, image.Options{
+				Width:          300,
+				OriginalFormat: image.ImageTypePNG,
+				Format:         tc.format,
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.HasPrefix(buf.Bytes(), tc.magic) {
+				t.Errorf("expected a %s image, got %x", tc.name, buf.Bytes()[:8])
+			}
+		})
+	}
+}
+
+func TestManipulateAnimated(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name           string
+		frames         int
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{
+			// animations keep their aspect ratio and all their frames
+			name:           "animated",
+			frames:         3,
+			expectedWidth:  20,
+			expectedHeight: 10,
+		},
+		{
+			name:           "static",
+			frames:         1,
+			expectedWidth:  20,
+			expectedHeight: 20,
+		},
+	}
+
+	transformer := image.NewTransformer(image.Config{Workers: 1})
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			orig := encodeGIF(t, tc.frames)
+
+			buf := &bytes.Buffer{}
+			if err := transformer.Run(
+				t.Context(), bytes.NewReader(orig), uint64(len(orig)), buf, image.Options{
+					Width:          20,
+					Height:         20,
+					Rotate:         180,
+					OriginalFormat: image.ImageTypeGIF,
+					Format:         image.ImageTypeGIF,
+				},
+			); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := gif.DecodeAll(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got.Image) != tc.frames || got.Config.Width != tc.expectedWidth ||
+				got.Config.Height != tc.expectedHeight {
+				t.Errorf(
+					"expected %d frames of %dx%d, got %d of %dx%d",
+					tc.frames, tc.expectedWidth, tc.expectedHeight,
+					len(got.Image), got.Config.Width, got.Config.Height,
+				)
+			}
+		})
+	}
+}
+
+func TestLimits(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		config image.Config
+	}{
+		{name: "input size", config: image.Config{Workers: 1, MaxInputSize: 1024}},
+		{name: "dimension", config: image.Config{Workers: 1, MaxDimension: 600}},
+		{name: "pixels", config: image.Config{Workers: 1, MaxPixels: 100_000}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			orig, err := os.Open("testdata/nhost.jpg")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer orig.Close()
+
+			err = image.NewTransformer(tc.config).Run(
+				t.Context(), orig, 33399, io.Discard, image.Options{Format: image.ImageTypePNG},
+			)
+			if !errors.Is(err, image.ErrTooLarge) {
+				t.Errorf("expected %v, got %v", image.ErrTooLarge, err)
+			}
+		})
+	}
+}
+
+type blockingReader struct {
+	unblock chan struct{}
+}
+
+func (r blockingReader) Read(_ []byte) (int, error) {
+	<-r.unblock
+	return 0, io.EOF
+}
+
+func TestSlowInputDoesNotHoldWorker(t *testing.T) {
+	t.Parallel()
+
+	transformer := image.NewTransformer(
+		image.Config{Workers: 1, QueueTimeout: 10 * time.Millisecond},
+	)
+
+	// the first transformation is still waiting for its input
+	blocked := blockingReader{unblock: make(chan struct{})}
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_ = transformer.Run(
+			t.Context(), blocked, 1, io.Discard, image.Options{Format: image.ImageTypePNG},
+		)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	orig, err := os.Open("testdata/nhost.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer orig.Close()
+
+	if err := transformer.Run(
+		t.Context(), orig, 33399, io.Discard, image.Options{Format: image.ImageTypePNG},
+	); err != nil {
+		t.Errorf("expected the only worker to be free, got %v", err)
+	}
+
+	close(blocked.unblock)
+	<-done
+}
@@ -0,0 +1,179 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	goimage "image"
+	"image/color"
+	"image/png"
+	"io"
+
+	"github.com/davidbyttow/govips/v2/vips"
+)
+
+// the placeholder and dominant color are computed on a thumbnail of at most this size.
+const infoThumbnailSize = 32
+
+// Info describes an image so clients can reserve its space and show a placeholder while
+// it loads.
+type Info struct {
+	// Width and Height are the dimensions of the image as displayed, after applying the
+	// EXIF orientation.
+	Width  int
+	Height int
+	// Orientation is the EXIF orientation, 1 if the image doesn't have one.
+	Orientation   int
+	ColorSpace    string
+	DominantColor Color
+	BlurHash      string
+}
+
+func colorSpace(interpretation vips.Interpretation) string {
+	switch interpretation { //nolint:exhaustive
+	case vips.InterpretationSRGB:
+		return "srgb"
+	case vips.InterpretationRGB:
+		return "rgb"
+	case vips.InterpretationRGB16:
+		return "rgb16"
+	case vips.InterpretationScRGB:
+		return "scrgb"
+	case vips.InterpretationBW:
+		return "b-w"
+	case vips.InterpretationGrey16:
+		return "grey16"
+	case vips.InterpretationCMYK:
+		return "cmyk"
+	case vips.InterpretationLAB, vips.InterpretationLABQ, vips.InterpretationLABS:
+		return "lab"
+	case vips.InterpretationXYZ:
+		return "xyz"
+	case vips.InterpretationHSV:
+		return "hsv"
+	}
+
+	return "multiband"
+}
+
+func rgba(img goimage.Image, x, y int) color.NRGBA {
+	return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA) //nolint:forcetypeassert
+}
+
+// dominantColor returns the average of the most common colors of img, grouping similar
+// ones together.
+func dominantColor(img goimage.Image) Color {
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+
+	buckets := make(map[int]*bucket)
+
+	var dominant *bucket
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := rgba(img, x, y)
+
+			key := int(c.R>>4)<<8 | int(c.G>>4)<<4 | int(c.B>>4) //nolint:mnd
+			if _, ok := buckets[key]; !ok {
+				buckets[key] = &bucket{} //nolint:exhaustruct
+			}
+
+			b := buckets[key]
+			b.count++
+			b.r += int(c.R)
+			b.g += int(c.G)
+			b.b += int(c.B)
+
+			if dominant == nil || b.count > dominant.count {
+				dominant = b
+			}
+		}
+	}
+
+	if dominant == nil {
+		return Color{} //nolint:exhaustruct
+	}
+
+	return Color{
+		R: uint8(dominant.r / dominant.count), //nolint:gosec
+		G: uint8(dominant.g / dominant.count), //nolint:gosec
+		B: uint8(dominant.b / dominant.count), //nolint:gosec
+		A: 255,                                //nolint:mnd
+	}
+}
+
+// thumbnail returns a small sRGB version of image, rotated as displayed and with
+// transparent areas flattened onto white.
+func thumbnail(image *vips.ImageRef) (goimage.Image, error) {
+	// thumbnailing also applies the EXIF orientation
+	if err := image.Thumbnail(
+		infoThumbnailSize, infoThumbnailSize, vips.InterestingNone,
+	); err != nil {
+		return nil, fmt.Errorf("failed to thumbnail: %w", err)
+	}
+
+	if err := image.ToColorSpace(vips.InterpretationSRGB); err != nil {
+		return nil, fmt.Errorf("failed to convert to sRGB: %w", err)
+	}
+
+	if image.HasAlpha() {
+		if err := image.Flatten(&vips.Color{R: 255, G: 255, B: 255}); err != nil { //nolint:mnd
+			return nil, fmt.Errorf("failed to flatten: %w", err)
+		}
+	}
+
+	b, _, err := image.ExportPng(vips.NewPngExportParams())
+	if err != nil {
+		return nil, fmt.Errorf("failed to export: %w", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode thumbnail: %w", err)
+	}
+
+	return img, nil
+}
+
+// Info returns the dimensions, color information and a BlurHash placeholder of orig.
+func (t *Transformer) Info(ctx context.Context, orig io.Reader, length uint64) (Info, error) {
+	b, err := t.read(orig, length)
+	if err != nil {
+		return Info{}, err //nolint:exhaustruct
+	}
+
+	release, err := t.acquire(ctx)
+	if err != nil {
+		return Info{}, err //nolint:exhaustruct
+	}
+
+	return withImage(ctx, t, release, b, false, func(image *vips.ImageRef) (Info, error) {
+		var info Info
+
+		info.Width = image.Width()
+		info.Height = image.Height()
+		info.Orientation = image.Orientation()
+		info.ColorSpace = colorSpace(image.Interpretation())
+
+		switch info.Orientation {
+		case 0:
+			info.Orientation = 1
+		case 5, 6, 7, 8: //nolint:mnd
+			info.Width, info.Height = info.Height, info.Width
+		}
+
+		img, err := thumbnail(image)
+		if err != nil {
+			return Info{}, err //nolint:exhaustruct
+		}
+
+		info.DominantColor = dominantColor(img)
+		info.BlurHash = encodeBlurHash(img)
+
+		return info, nil
+	})
+}
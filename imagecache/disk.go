@@ -0,0 +1,293 @@
+// Package imagecache stores transformed images in a local folder so they don't need to
+// be transformed again, evicting the least recently used ones when the folder grows over
+// its maximum size.
+package imagecache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/sirupsen/logrus"
+)
+
+const tmpPrefix = ".tmp-"
+
+type entry struct {
+	fileID  string
+	key     string
+	size    int64
+	modTime time.Time
+}
+
+// Disk keeps the transformed images of each file in a folder named after the file's id.
+type Disk struct {
+	root    string
+	maxSize int64
+	logger  *logrus.Logger
+
+	mu      sync.Mutex
+	size    int64
+	lru     *list.List // most recently used at the front
+	entries map[string]map[string]*list.Element
+}
+
+// NewDisk returns a cache storing up to maxSize bytes in root. Images cached by previous
+// runs are kept, the most recently used first.
+func NewDisk(root string, maxSize int64, logger *logrus.Logger) (*Disk, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil { //nolint:mnd
+		return nil, fmt.Errorf("problem creating cache folder: %w", err)
+	}
+
+	d := &Disk{
+		root:    root,
+		maxSize: maxSize,
+		logger:  logger,
+		mu:      sync.Mutex{},
+		size:    0,
+		lru:     list.New(),
+		entries: make(map[string]map[string]*list.Element),
+	}
+
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *Disk) load() error {
+	var entries []entry
+
+	if err := filepath.WalkDir(d.root, func(path string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if de.IsDir() {
+			return nil
+		}
+
+		if strings.HasPrefix(de.Name(), tmpPrefix) {
+			return os.Remove(path) //nolint:wrapcheck
+		}
+
+		rel, err := filepath.Rel(d.root, path)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		fileID, key, ok := strings.Cut(rel, string(filepath.Separator))
+		if !ok || strings.ContainsRune(key, filepath.Separator) {
+			return nil
+		}
+
+		info, err := de.Info()
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		entries = append(entries, entry{
+			fileID:  fileID,
+			key:     key,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("problem loading cache folder: %w", err)
+	}
+
+	slices.SortFunc(entries, func(a, b entry) int {
+		return a.modTime.Compare(b.modTime)
+	})
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, e := range entries {
+		d.add(e)
+	}
+
+	d.evict()
+
+	return nil
+}
+
+// path returns where a cached image is stored, making sure it is inside the root folder.
+func (d *Disk) path(fileID, key string) (string, error) {
+	for _, s := range []string{fileID, key} {
+		if !filepath.IsLocal(s) || strings.ContainsAny(s, `/\`) ||
+			strings.HasPrefix(s, tmpPrefix) {
+			return "", fmt.Errorf("invalid cache key: %q", s) //nolint:err113
+		}
+	}
+
+	return filepath.Join(d.root, fileID, key), nil
+}
+
+// add needs to be called with the lock held.
+func (d *Disk) add(e entry) {
+	if _, ok := d.entries[e.fileID]; !ok {
+		d.entries[e.fileID] = make(map[string]*list.Element)
+	}
+
+	if el, ok := d.entries[e.fileID][e.key]; ok {
+		d.size -= el.Value.(entry).size //nolint:forcetypeassert
+		d.lru.Remove(el)
+	}
+
+	d.entries[e.fileID][e.key] = d.lru.PushFront(e)
+	d.size += e.size
+}
+
+// remove needs to be called with the lock held.
+func (d *Disk) remove(el *list.Element) {
+	e := el.Value.(entry) //nolint:forcetypeassert
+
+	d.lru.Remove(el)
+	d.size -= e.size
+
+	delete(d.entries[e.fileID], e.key)
+
+	if len(d.entries[e.fileID]) == 0 {
+		delete(d.entries, e.fileID)
+	}
+}
+
+// evict removes the least recently used images until the cache fits in its maximum
+// size. It needs to be called with the lock held.
+func (d *Disk) evict() {
+	for d.size > d.maxSize && d.lru.Len() > 0 {
+		el := d.lru.Back()
+		e := el.Value.(entry) //nolint:forcetypeassert
+
+		d.remove(el)
+
+		path, _ := d.path(e.fileID, e.key)
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			d.logger.WithError(err).Errorf("problem evicting %s from the image cache", path)
+		}
+
+		if len(d.entries[e.fileID]) == 0 {
+			_ = os.Remove(filepath.Dir(path))
+		}
+	}
+}
+
+func (d *Disk) Get(_ context.Context, fileID, key string) ([]byte, bool) {
+	path, err := d.path(fileID, key)
+	if err != nil {
+		return nil, false
+	}
+
+	d.mu.Lock()
+	el, ok := d.entries[fileID][key]
+	if ok {
+		d.lru.MoveToFront(el)
+	}
+	d.mu.Unlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		d.logger.WithError(err).Errorf("problem reading %s from the image cache", path)
+
+		d.mu.Lock()
+		if el, ok := d.entries[fileID][key]; ok {
+			d.remove(el)
+		}
+		d.mu.Unlock()
+
+		return nil, false
+	}
+
+	// the modification time keeps the order in which images were used across restarts
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return b, true
+}
+
+func (d *Disk) Put(_ context.Context, fileID, key string, content []byte) *controller.APIError {
+	path, err := d.path(fileID, key)
+	if err != nil {
+		return controller.InternalServerError(err)
+	}
+
+	// written to a temporary file first so readers never see a partial image
+	f, err := os.CreateTemp(d.root, tmpPrefix)
+	if err != nil {
+		return controller.InternalServerError(fmt.Errorf("problem creating file: %w", err))
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return controller.InternalServerError(fmt.Errorf("problem writing file: %w", err))
+	}
+
+	if err := f.Close(); err != nil {
+		return controller.InternalServerError(fmt.Errorf("problem writing file: %w", err))
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// created with the lock held as evict removes empty folders
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil { //nolint:mnd
+		return controller.InternalServerError(
+			fmt.Errorf("problem creating cache folder: %w", err),
+		)
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return controller.InternalServerError(fmt.Errorf("problem moving file: %w", err))
+	}
+
+	d.add(entry{
+		fileID:  fileID,
+		key:     key,
+		size:    int64(len(content)),
+		modTime: time.Now(),
+	})
+	d.evict()
+
+	return nil
+}
+
+// DeleteFile removes all the images cached for a file.
+func (d *Disk) DeleteFile(_ context.Context, fileID string) *controller.APIError {
+	path, err := d.path(fileID, "_")
+	if err != nil {
+		return controller.InternalServerError(err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, el := range d.entries[fileID] {
+		d.remove(el)
+	}
+
+	if err := os.RemoveAll(filepath.Dir(path)); err != nil {
+		return controller.InternalServerError(
+			fmt.Errorf("problem deleting cached images: %w", err),
+		)
+	}
+
+	return nil
+}
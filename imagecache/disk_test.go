@@ -0,0 +1,184 @@
+package imagecache_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nhost/hasura-storage/imagecache"
+	"github.com/sirupsen/logrus"
+)
+
+func newDisk(t *testing.T, root string, maxSize int64) *imagecache.Disk {
+	t.Helper()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	d, err := imagecache.NewDisk(root, maxSize, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return d
+}
+
+func assertCached(t *testing.T, d *imagecache.Disk, fileID, key, expected string) {
+	t.Helper()
+
+	b, ok := d.Get(t.Context(), fileID, key)
+
+	switch {
+	case expected == "" && ok:
+		t.Errorf("expected %s/%s not to be cached, got %q", fileID, key, b)
+	case expected != "" && !ok:
+		t.Errorf("expected %s/%s to be cached", fileID, key)
+	case string(b) != expected:
+		t.Errorf("expected %q, got %q", expected, b)
+	}
+}
+
+func TestDiskPutGet(t *testing.T) {
+	t.Parallel()
+
+	d := newDisk(t, t.TempDir(), 1024) //nolint:mnd
+
+	assertCached(t, d, "file1", "key1", "")
+
+	if apiErr := d.Put(t.Context(), "file1", "key1", []byte("image1")); apiErr != nil {
+		t.Fatalf("unexpected error: %v", apiErr)
+	}
+
+	assertCached(t, d, "file1", "key1", "image1")
+	assertCached(t, d, "file1", "key2", "")
+	assertCached(t, d, "file2", "key1", "")
+}
+
+func TestDiskEviction(t *testing.T) {
+	t.Parallel()
+
+	d := newDisk(t, t.TempDir(), 10) //nolint:mnd
+
+	for _, key := range []string{"key1", "key2"} {
+		if apiErr := d.Put(t.Context(), "file1", key, []byte("12345")); apiErr != nil {
+			t.Fatalf("unexpected error: %v", apiErr)
+		}
+	}
+
+	// key1 becomes the most recently used one
+	assertCached(t, d, "file1", "key1", "12345")
+
+	if apiErr := d.Put(t.Context(), "file2", "key3", []byte("12345")); apiErr != nil {
+		t.Fatalf("unexpected error: %v", apiErr)
+	}
+
+	assertCached(t, d, "file1", "key1", "12345")
+	assertCached(t, d, "file1", "key2", "")
+	assertCached(t, d, "file2", "key3", "12345")
+}
+
+func TestDiskDeleteFile(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	d := newDisk(t, root, 1024) //nolint:mnd
+
+	for _, fileID := range []string{"file1", "file2"} {
+		if apiErr := d.Put(t.Context(), fileID, "key1", []byte(fileID)); apiErr != nil {
+			t.Fatalf("unexpected error: %v", apiErr)
+		}
+	}
+
+	if apiErr := d.DeleteFile(t.Context(), "file1"); apiErr != nil {
+		t.Fatalf("unexpected error: %v", apiErr)
+	}
+
+	assertCached(t, d, "file1", "key1", "")
+	assertCached(t, d, "file2", "key1", "file2")
+
+	if _, err := os.Stat(filepath.Join(root, "file1")); !os.IsNotExist(err) {
+		t.Errorf("expected the folder of file1 to be removed, got %v", err)
+	}
+
+	// deleting a file that was never cached isn't an error
+	if apiErr := d.DeleteFile(t.Context(), "file3"); apiErr != nil {
+		t.Errorf("unexpected error: %v", apiErr)
+	}
+}
+
+func TestDiskReload(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	d := newDisk(t, root, 1024) //nolint:mnd
+
+	for _, key := range []string{"key1", "key2"} {
+		if apiErr := d.Put(t.Context(), "file1", key, []byte("12345")); apiErr != nil {
+			t.Fatalf("unexpected error: %v", apiErr)
+		}
+	}
+
+	// key2 is the least recently used one on the next run
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(root, "file1", "key2"), old, old); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// left behind by an interrupted write
+	if err := os.WriteFile(filepath.Join(root, ".tmp-123"), []byte("x"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	d = newDisk(t, root, 5) //nolint:mnd
+
+	assertCached(t, d, "file1", "key1", "12345")
+	assertCached(t, d, "file1", "key2", "")
+
+	if _, err := os.Stat(filepath.Join(root, ".tmp-123")); !os.IsNotExist(err) {
+		t.Errorf("expected temporary files to be removed, got %v", err)
+	}
+}
+
+func TestDiskInvalidKeys(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	d := newDisk(t, filepath.Join(root, "cache"), 1024) //nolint:mnd
+
+	cases := []struct {
+		name   string
+		fileID string
+		key    string
+	}{
+		{name: "parent folder", fileID: "..", key: "key1"},
+		{name: "path in file id", fileID: "../file1", key: "key1"},
+		{name: "path in key", fileID: "file1", key: "../../key1"},
+		{name: "temporary file", fileID: "file1", key: ".tmp-key1"},
+		{name: "empty", fileID: "", key: "key1"},
+	}
+
+	// runs after the subtests
+	t.Cleanup(func() {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(entries) != 1 {
+			t.Errorf("expected nothing written outside the cache folder, got %v", entries)
+		}
+	})
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if apiErr := d.Put(t.Context(), tc.fileID, tc.key, []byte("x")); apiErr == nil {
+				t.Error("expected an error")
+			}
+
+			assertCached(t, d, tc.fileID, tc.key, "")
+		})
+	}
+}
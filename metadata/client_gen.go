@@ -99,10 +99,12 @@ func (t *FileMetadataFragment) GetMetadata() map[string]any {
 }
 
 type FileMetadataSummaryFragment struct {
-	ID         string  "json:\"id\" graphql:\"id\""
-	Name       *string "json:\"name,omitempty\" graphql:\"name\""
-	BucketID   string  "json:\"bucketId\" graphql:\"bucketId\""
-	IsUploaded *bool   "json:\"isUploaded,omitempty\" graphql:\"isUploaded\""
+	ID         string    "json:\"id\" graphql:\"id\""
+	Name       *string   "json:\"name,omitempty\" graphql:\"name\""
+	BucketID   string    "json:\"bucketId\" graphql:\"bucketId\""
+	IsUploaded *bool     "json:\"isUploaded,omitempty\" graphql:\"isUploaded\""
+	ScanStatus string    "json:\"scanStatus\" graphql:\"scanStatus\""
+	CreatedAt  time.Time "json:\"createdAt\" graphql:\"createdAt\""
 }
 
 func (t *FileMetadataSummaryFragment) GetID() string {
@@ -129,16 +131,34 @@ func (t *FileMetadataSummaryFragment) GetIsUploaded() *bool {
 	}
 	return t.IsUploaded
 }
+func (t *FileMetadataSummaryFragment) GetScanStatus() string {
+	if t == nil {
+		t = &FileMetadataSummaryFragment{}
+	}
+	return t.ScanStatus
+}
+func (t *FileMetadataSummaryFragment) GetCreatedAt() *time.Time {
+	if t == nil {
+		t = &FileMetadataSummaryFragment{}
+	}
+	return &t.CreatedAt
+}
 
 type BucketMetadataFragment struct {
-	ID                   string    "json:\"id\" graphql:\"id\""
-	MinUploadFileSize    int64     "json:\"minUploadFileSize\" graphql:\"minUploadFileSize\""
-	MaxUploadFileSize    int64     "json:\"maxUploadFileSize\" graphql:\"maxUploadFileSize\""
-	PresignedUrlsEnabled bool      "json:\"presignedUrlsEnabled\" graphql:\"presignedUrlsEnabled\""
-	DownloadExpiration   int64     "json:\"downloadExpiration\" graphql:\"downloadExpiration\""
-	CreatedAt            time.Time "json:\"createdAt\" graphql:\"createdAt\""
-	UpdatedAt            time.Time "json:\"updatedAt\" graphql:\"updatedAt\""
-	CacheControl         *string   "json:\"cacheControl,omitempty\" graphql:\"cacheControl\""
+	ID                   string                            "json:\"id\" graphql:\"id\""
+	MinUploadFileSize    int64                             "json:\"minUploadFileSize\" graphql:\"minUploadFileSize\""
+	MaxUploadFileSize    int64                             "json:\"maxUploadFileSize\" graphql:\"maxUploadFileSize\""
+	PresignedUrlsEnabled bool                              "json:\"presignedUrlsEnabled\" graphql:\"presignedUrlsEnabled\""
+	DownloadExpiration   int64                             "json:\"downloadExpiration\" graphql:\"downloadExpiration\""
+	CreatedAt            time.Time                         "json:\"createdAt\" graphql:\"createdAt\""
+	UpdatedAt            time.Time                         "json:\"updatedAt\" graphql:\"updatedAt\""
+	CacheControl         *string                           "json:\"cacheControl,omitempty\" graphql:\"cacheControl\""
+	PresetsOnly          bool                              "json:\"presetsOnly\" graphql:\"presetsOnly\""
+	StripImageMetadata   bool                              "json:\"stripImageMetadata\" graphql:\"stripImageMetadata\""
+	KeepIccProfile       bool                              "json:\"keepIccProfile\" graphql:\"keepIccProfile\""
+	AllowedMimeTypes     []string                          "json:\"allowedMimeTypes\" graphql:\"allowedMimeTypes\""
+	DeniedMimeTypes      []string                          "json:\"deniedMimeTypes\" graphql:\"deniedMimeTypes\""
+	Presets              []*BucketMetadataFragment_Presets "json:\"presets\" graphql:\"presets\""
 }
 
 func (t *BucketMetadataFragment) GetID() string {
@@ -189,25 +209,79 @@ func (t *BucketMetadataFragment) GetCacheControl() *string {
 	}
 	return t.CacheControl
 }
+func (t *BucketMetadataFragment) GetPresetsOnly() bool {
+	if t == nil {
+		t = &BucketMetadataFragment{}
+	}
+	return t.PresetsOnly
+}
+func (t *BucketMetadataFragment) GetStripImageMetadata() bool {
+	if t == nil {
+		t = &BucketMetadataFragment{}
+	}
+	return t.StripImageMetadata
+}
+func (t *BucketMetadataFragment) GetKeepIccProfile() bool {
+	if t == nil {
+		t = &BucketMetadataFragment{}
+	}
+	return t.KeepIccProfile
+}
+func (t *BucketMetadataFragment) GetAllowedMimeTypes() []string {
+	if t == nil {
+		t = &BucketMetadataFragment{}
+	}
+	return t.AllowedMimeTypes
+}
+func (t *BucketMetadataFragment) GetDeniedMimeTypes() []string {
+	if t == nil {
+		t = &BucketMetadataFragment{}
+	}
+	return t.DeniedMimeTypes
+}
+func (t *BucketMetadataFragment) GetPresets() []*BucketMetadataFragment_Presets {
+	if t == nil {
+		t = &BucketMetadataFragment{}
+	}
+	return t.Presets
+}
 
-type InsertFile_InsertFile struct {
-	ID string "json:\"id\" graphql:\"id\""
+type BucketMetadataFragment_Presets struct {
+	Name    string         "json:\"name\" graphql:\"name\""
+	Options map[string]any "json:\"options\" graphql:\"options\""
 }
 
-func (t *InsertFile_InsertFile) GetID() string {
+func (t *BucketMetadataFragment_Presets) GetName() string {
 	if t == nil {
-		t = &InsertFile_InsertFile{}
+		t = &BucketMetadataFragment_Presets{}
 	}
-	return t.ID
+	return t.Name
+}
+func (t *BucketMetadataFragment_Presets) GetOptions() map[string]any {
+	if t == nil {
+		t = &BucketMetadataFragment_Presets{}
+	}
+	return t.Options
+}
+
+type GetFileScanStatus_File struct {
+	ScanStatus string "json:\"scanStatus\" graphql:\"scanStatus\""
+}
+
+func (t *GetFileScanStatus_File) GetScanStatus() string {
+	if t == nil {
+		t = &GetFileScanStatus_File{}
+	}
+	return t.ScanStatus
 }
 
-type DeleteFile_DeleteFile struct {
+type InsertFile_InsertFile struct {
 	ID string "json:\"id\" graphql:\"id\""
 }
 
-func (t *DeleteFile_DeleteFile) GetID() string {
+func (t *InsertFile_InsertFile) GetID() string {
 	if t == nil {
-		t = &DeleteFile_DeleteFile{}
+		t = &InsertFile_InsertFile{}
 	}
 	return t.ID
 }
@@ -245,6 +319,17 @@ func (t *GetFile) GetFile() *FileMetadataFragment {
 	return t.File
 }
 
+type GetFileScanStatus struct {
+	File *GetFileScanStatus_File "json:\"file,omitempty\" graphql:\"file\""
+}
+
+func (t *GetFileScanStatus) GetFile() *GetFileScanStatus_File {
+	if t == nil {
+		t = &GetFileScanStatus{}
+	}
+	return t.File
+}
+
 type ListFilesSummary struct {
 	Files []*FileMetadataSummaryFragment "json:\"files\" graphql:\"files\""
 }
@@ -279,10 +364,10 @@ func (t *UpdateFile) GetUpdateFile() *FileMetadataFragment {
 }
 
 type DeleteFile struct {
-	DeleteFile *DeleteFile_DeleteFile "json:\"deleteFile,omitempty\" graphql:\"deleteFile\""
+	DeleteFile *FileMetadataFragment "json:\"deleteFile,omitempty\" graphql:\"deleteFile\""
 }
 
-func (t *DeleteFile) GetDeleteFile() *DeleteFile_DeleteFile {
+func (t *DeleteFile) GetDeleteFile() *FileMetadataFragment {
 	if t == nil {
 		t = &DeleteFile{}
 	}
@@ -314,6 +399,15 @@ fragment BucketMetadataFragment on buckets {
 	createdAt
 	updatedAt
 	cacheControl
+	presetsOnly
+	stripImageMetadata
+	keepIccProfile
+	allowedMimeTypes
+	deniedMimeTypes
+	presets {
+		name
+		options
+	}
 }
 `
 
@@ -371,8 +465,32 @@ func (c *Client) GetFile(ctx context.Context, id string, interceptors ...clientv
 	return &res, nil
 }
 
-const ListFilesSummaryDocument = `query ListFilesSummary {
-	files {
+const GetFileScanStatusDocument = `query GetFileScanStatus ($id: uuid!) {
+	file(id: $id) {
+		scanStatus
+	}
+}
+`
+
+func (c *Client) GetFileScanStatus(ctx context.Context, id string, interceptors ...clientv2.RequestInterceptor) (*GetFileScanStatus, error) {
+	vars := map[string]any{
+		"id": id,
+	}
+
+	var res GetFileScanStatus
+	if err := c.Client.Post(ctx, "GetFileScanStatus", GetFileScanStatusDocument, &res, vars, interceptors...); err != nil {
+		if c.Client.ParseDataWhenErrors {
+			return &res, err
+		}
+
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+const ListFilesSummaryDocument = `query ListFilesSummary ($where: files_bool_exp!, $limit: Int!) {
+	files(where: $where, order_by: {id:asc}, limit: $limit) {
 		... FileMetadataSummaryFragment
 	}
 }
@@ -381,11 +499,16 @@ fragment FileMetadataSummaryFragment on files {
 	name
 	bucketId
 	isUploaded
+	scanStatus
+	createdAt
 }
 `
 
-func (c *Client) ListFilesSummary(ctx context.Context, interceptors ...clientv2.RequestInterceptor) (*ListFilesSummary, error) {
-	vars := map[string]any{}
+func (c *Client) ListFilesSummary(ctx context.Context, where FilesBoolExp, limit int64, interceptors ...clientv2.RequestInterceptor) (*ListFilesSummary, error) {
+	vars := map[string]any{
+		"where": where,
+		"limit": limit,
+	}
 
 	var res ListFilesSummary
 	if err := c.Client.Post(ctx, "ListFilesSummary", ListFilesSummaryDocument, &res, vars, interceptors...); err != nil {
@@ -463,9 +586,22 @@ func (c *Client) UpdateFile(ctx context.Context, id string, set FilesSetInput, i
 
 const DeleteFileDocument = `mutation DeleteFile ($id: uuid!) {
 	deleteFile(id: $id) {
-		id
+		... FileMetadataFragment
 	}
 }
+fragment FileMetadataFragment on files {
+	id
+	name
+	size
+	bucketId
+	etag
+	createdAt
+	updatedAt
+	isUploaded
+	mimeType
+	uploadedByUserId
+	metadata
+}
 `
 
 func (c *Client) DeleteFile(ctx context.Context, id string, interceptors ...clientv2.RequestInterceptor) (*DeleteFile, error) {
@@ -510,11 +646,12 @@ func (c *Client) InsertVirus(ctx context.Context, object VirusInsertInput, inter
 }
 
 var DocumentOperationNames = map[string]string{
-	GetBucketDocument:        "GetBucket",
-	GetFileDocument:          "GetFile",
-	ListFilesSummaryDocument: "ListFilesSummary",
-	InsertFileDocument:       "InsertFile",
-	UpdateFileDocument:       "UpdateFile",
-	DeleteFileDocument:       "DeleteFile",
-	InsertVirusDocument:      "InsertVirus",
+	GetBucketDocument:         "GetBucket",
+	GetFileDocument:           "GetFile",
+	GetFileScanStatusDocument: "GetFileScanStatus",
+	ListFilesSummaryDocument:  "ListFilesSummary",
+	InsertFileDocument:        "InsertFile",
+	UpdateFileDocument:        "UpdateFile",
+	DeleteFileDocument:        "DeleteFile",
+	InsertVirusDocument:       "InsertVirus",
 }
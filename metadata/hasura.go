@@ -2,13 +2,20 @@ package metadata
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"iter"
 	"net/http"
 	"time"
 
 	"github.com/Yamashou/gqlgenc/clientv2"
 	"github.com/nhost/hasura-storage/api"
 	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 func ptr[T any](x T) *T {
@@ -42,6 +49,8 @@ func (md *FileMetadataSummaryFragment) ToControllerType() controller.FileSummary
 		Name:       *md.GetName(),
 		BucketID:   md.GetBucketID(),
 		IsUploaded: *md.GetIsUploaded(),
+		ScanStatus: md.GetScanStatus(),
+		CreatedAt:  *md.GetCreatedAt(),
 	}
 }
 
@@ -55,6 +64,12 @@ func (md *BucketMetadataFragment) ToControllerType() controller.BucketMetadata {
 		CreatedAt:            md.GetCreatedAt().Format(time.RFC3339),
 		UpdatedAt:            md.GetUpdatedAt().Format(time.RFC3339),
 		CacheControl:         *md.GetCacheControl(),
+		PresetsOnly:          md.GetPresetsOnly(),
+		Presets:              nil,
+		StripImageMetadata:   md.GetStripImageMetadata(),
+		KeepICCProfile:       md.GetKeepIccProfile(),
+		AllowedMimeTypes:     md.GetAllowedMimeTypes(),
+		DeniedMimeTypes:      md.GetDeniedMimeTypes(),
 	}
 }
 
@@ -71,6 +86,7 @@ func (md *FileMetadataFragment) ToControllerType() api.FileMetadata {
 		MimeType:         *md.GetMimeType(),
 		Metadata:         ptr(md.GetMetadata()),
 		UploadedByUserId: md.GetUploadedByUserID(),
+		ScanStatus:       nil,
 	}
 }
 
@@ -92,6 +108,35 @@ func WithHeaders(header http.Header) clientv2.RequestInterceptor {
 	}
 }
 
+// WithTracing creates a span for each GraphQL call and propagates the trace to hasura.
+func WithTracing() clientv2.RequestInterceptor {
+	return func(
+		ctx context.Context,
+		req *http.Request,
+		gqlInfo *clientv2.GQLRequestInfo,
+		res any,
+		next clientv2.RequestInterceptorFunc,
+	) error {
+		operation := gqlInfo.Request.OperationName
+
+		ctx, span := telemetry.Start(
+			ctx,
+			"metadata.Hasura."+operation,
+			attribute.String("graphql.operation.name", operation),
+		)
+
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+		err := next(ctx, req, gqlInfo, res)
+		telemetry.End(span, err)
+
+		return err
+	}
+}
+
+// listFilesPageSize is the number of files ListFiles fetches at a time.
+const listFilesPageSize = 1000
+
 type Hasura struct {
 	cl *Client
 }
@@ -102,10 +147,26 @@ func NewHasura(endpoint string) *Hasura {
 			&http.Client{}, //nolint:exhaustruct
 			endpoint,
 			&clientv2.Options{}, //nolint:exhaustruct
+			WithTracing(),
 		),
 	}
 }
 
+// Ping checks hasura's GraphQL API is reachable.
+func (h *Hasura) Ping(ctx context.Context, headers http.Header) error {
+	var resp struct {
+		Typename string `json:"__typename"`
+	}
+
+	if err := h.cl.Client.Post(
+		ctx, "Ping", "query Ping { __typename }", &resp, nil, WithHeaders(headers),
+	); err != nil {
+		return fmt.Errorf("problem querying hasura: %w", err)
+	}
+
+	return nil
+}
+
 func (h *Hasura) GetBucketByID(
 	ctx context.Context,
 	bucketID string,
@@ -125,7 +186,24 @@ func (h *Hasura) GetBucketByID(
 		return controller.BucketMetadata{}, controller.ErrBucketNotFound
 	}
 
-	return resp.Bucket.ToControllerType(), nil
+	bucket := resp.Bucket.ToControllerType()
+
+	presets := make(map[string]json.RawMessage, len(resp.Bucket.GetPresets()))
+	for _, preset := range resp.Bucket.GetPresets() {
+		if presets[preset.GetName()], err = json.Marshal(preset.GetOptions()); err != nil {
+			return controller.BucketMetadata{}, controller.InternalServerError(
+				fmt.Errorf("problem getting bucket metadata: %w", err),
+			)
+		}
+	}
+
+	if bucket.Presets, err = parsePresets(presets); err != nil {
+		return controller.BucketMetadata{}, controller.InternalServerError(
+			fmt.Errorf("problem getting bucket metadata: %w", err),
+		)
+	}
+
+	return bucket, nil
 }
 
 func (h *Hasura) InitializeFile(
@@ -206,6 +284,30 @@ func (h *Hasura) GetFileByID(
 	return resp.File.ToControllerType(), nil
 }
 
+// GetFileScanStatus is queried apart from the rest of the metadata as the roles of the
+// users downloading files may not be allowed to select it.
+func (h *Hasura) GetFileScanStatus(
+	ctx context.Context,
+	fileID string,
+	headers http.Header,
+) (api.ScanStatus, *controller.APIError) {
+	resp, err := h.cl.GetFileScanStatus(
+		ctx,
+		fileID,
+		WithHeaders(headers),
+	)
+	if err != nil {
+		aerr := parseGraphqlError(err)
+		return "", aerr.ExtendError("problem getting file scan status")
+	}
+
+	if resp.File == nil {
+		return "", controller.ErrFileNotFound
+	}
+
+	return api.ScanStatus(resp.File.GetScanStatus()), nil
+}
+
 func (h *Hasura) SetIsUploaded(
 	ctx context.Context, fileID string, isUploaded bool, headers http.Header,
 ) *controller.APIError {
@@ -229,47 +331,88 @@ func (h *Hasura) SetIsUploaded(
 	return nil
 }
 
-func (h *Hasura) DeleteFileByID(
-	ctx context.Context,
-	fileID string,
-	headers http.Header,
+func (h *Hasura) SetScanStatus(
+	ctx context.Context, fileID string, status api.ScanStatus, headers http.Header,
 ) *controller.APIError {
-	resp, err := h.cl.DeleteFile(
+	resp, err := h.cl.UpdateFile(
 		ctx,
 		fileID,
+		FilesSetInput{ //nolint:exhaustruct
+			ScanStatus: ptr(string(status)),
+		},
 		WithHeaders(headers),
 	)
 	if err != nil {
 		aerr := parseGraphqlError(err)
-		return aerr.ExtendError("problem deleting file")
+		return aerr.ExtendError("problem setting file scan status")
 	}
 
-	if resp.DeleteFile == nil || resp.DeleteFile.ID == "" {
+	if resp.UpdateFile == nil || resp.UpdateFile.ID == "" {
 		return controller.ErrFileNotFound
 	}
 
 	return nil
 }
 
-func (h *Hasura) ListFiles(
+func (h *Hasura) DeleteFileByID(
 	ctx context.Context,
+	fileID string,
 	headers http.Header,
-) ([]controller.FileSummary, *controller.APIError) {
-	resp, err := h.cl.ListFilesSummary(
+) (api.FileMetadata, *controller.APIError) {
+	resp, err := h.cl.DeleteFile(
 		ctx,
+		fileID,
 		WithHeaders(headers),
 	)
 	if err != nil {
 		aerr := parseGraphqlError(err)
-		return nil, aerr.ExtendError("problem listing files")
+		return api.FileMetadata{}, aerr.ExtendError("problem deleting file")
 	}
 
-	files := make([]controller.FileSummary, len(resp.Files))
-	for i, f := range resp.Files {
-		files[i] = f.ToControllerType()
+	if resp.DeleteFile == nil || resp.DeleteFile.ID == "" {
+		return api.FileMetadata{}, controller.ErrFileNotFound
 	}
 
-	return files, nil
+	return resp.DeleteFile.ToControllerType(), nil
+}
+
+func (h *Hasura) ListFiles(
+	ctx context.Context,
+	headers http.Header,
+) iter.Seq2[controller.FileSummary, *controller.APIError] {
+	return func(yield func(controller.FileSummary, *controller.APIError) bool) {
+		where := FilesBoolExp{} //nolint:exhaustruct
+		for {
+			resp, err := h.cl.ListFilesSummary(
+				ctx,
+				where,
+				listFilesPageSize,
+				WithHeaders(headers),
+			)
+			if err != nil {
+				aerr := parseGraphqlError(err)
+				yield(controller.FileSummary{}, aerr.ExtendError("problem listing files"))
+
+				return
+			}
+
+			for _, f := range resp.Files {
+				if !yield(f.ToControllerType(), nil) {
+					return
+				}
+			}
+
+			if len(resp.Files) < listFilesPageSize {
+				return
+			}
+
+			where = FilesBoolExp{ //nolint:exhaustruct
+				ID: &UUIDComparisonExp{ //nolint:exhaustruct
+					Gt: ptr(resp.Files[len(resp.Files)-1].ID),
+				},
+			}
+		}
+	}
 }
 
 func (h *Hasura) InsertVirus(
@@ -7,6 +7,8 @@ import (
 	"context"
 	"net/http"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -56,6 +58,9 @@ func TestGetBucketByID(t *testing.T) {
 				CreatedAt:            "",
 				UpdatedAt:            "",
 				CacheControl:         "max-age=3600",
+				KeepICCProfile:       true,
+				AllowedMimeTypes:     []string{},
+				DeniedMimeTypes:      []string{},
 			},
 		},
 		{
@@ -219,6 +224,7 @@ func TestPopulateMetadata(t *testing.T) {
 				MimeType:         "text",
 				UploadedByUserId: nil,
 				Metadata:         ptr[map[string]any](nil),
+				ScanStatus:       nil,
 			},
 		},
 		{
@@ -331,6 +337,7 @@ func TestGetFileByID(t *testing.T) {
 				MimeType:         "text",
 				UploadedByUserId: nil,
 				Metadata:         ptr[map[string]any](nil),
+				ScanStatus:       nil,
 			},
 		},
 		{
@@ -393,6 +400,71 @@ func TestGetFileByID(t *testing.T) {
 	}
 }
 
+func TestGetFileScanStatus(t *testing.T) {
+	t.Parallel()
+
+	hasura := metadata.NewHasura(hasuraURL)
+
+	fileID := uuid.New().String()
+	if err := hasura.InitializeFile(context.Background(), fileID, "name", 123, "default", "mimetype", getAuthHeader()); err != nil {
+		panic(err)
+	}
+
+	if err := hasura.SetScanStatus(context.Background(), fileID, api.Pending, getAuthHeader()); err != nil {
+		panic(err)
+	}
+
+	cases := []struct {
+		name                   string
+		fileID                 string
+		expectedStatusCode     int
+		expectedPublicResponse *controller.ErrorResponse
+		expected               api.ScanStatus
+	}{
+		{
+			name:                   "success",
+			fileID:                 fileID,
+			expectedStatusCode:     0,
+			expectedPublicResponse: &controller.ErrorResponse{},
+			expected:               api.Pending,
+		},
+		{
+			name:               "not found",
+			fileID:             uuid.New().String(),
+			expectedStatusCode: http.StatusNotFound,
+			expectedPublicResponse: &controller.ErrorResponse{
+				Message: "file not found",
+			},
+			expected: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := hasura.GetFileScanStatus(context.Background(), tc.fileID, getAuthHeader())
+			if tc.expectedStatusCode != err.StatusCode() {
+				t.Errorf(
+					"wrong status code, expected %d, got %d",
+					tc.expectedStatusCode,
+					err.StatusCode(),
+				)
+			}
+
+			if err != nil {
+				if !cmp.Equal(err.PublicResponse(), tc.expectedPublicResponse) {
+					t.Error(cmp.Diff(err.PublicResponse(), tc.expectedPublicResponse))
+				}
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
 func TestSetIsUploaded(t *testing.T) {
 	t.Parallel()
 
@@ -529,7 +601,7 @@ func TestDeleteFileByID(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			err := hasura.DeleteFileByID(context.Background(), tc.fileID, tc.headers)
+			_, err := hasura.DeleteFileByID(context.Background(), tc.fileID, tc.headers)
 			if tc.expectedStatusCode != err.StatusCode() {
 				t.Errorf(
 					"wrong status code, expected %d, got %d",
@@ -591,7 +663,19 @@ func TestListFiles(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			got, err := hasura.ListFiles(context.Background(), tc.headers)
+			var (
+				got []controller.FileSummary
+				err *controller.APIError
+			)
+			for f, apiErr := range hasura.ListFiles(context.Background(), tc.headers) {
+				if apiErr != nil {
+					err = apiErr
+					break
+				}
+
+				got = append(got, f)
+			}
+
 			if tc.expectedStatusCode != err.StatusCode() {
 				t.Errorf(
 					"wrong status code, expected %d, got %d",
@@ -609,6 +693,12 @@ func TestListFiles(t *testing.T) {
 					t.Error("we got an empty list")
 				}
 
+				if !slices.IsSortedFunc(got, func(a, b controller.FileSummary) int {
+					return strings.Compare(a.ID, b.ID)
+				}) {
+					t.Error("files aren't sorted by id")
+				}
+
 				found1 := false
 				found2 := false
 				for _, f := range got {
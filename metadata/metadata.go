@@ -1 +1,32 @@
 package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nhost/hasura-storage/controller"
+)
+
+// parsePresets parses the options of the image presets of a bucket, keyed by name.
+func parsePresets(presets map[string]json.RawMessage) (map[string]controller.ImagePreset, error) {
+	if len(presets) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	parsed := make(map[string]controller.ImagePreset, len(presets))
+
+	for name, options := range presets {
+		dec := json.NewDecoder(bytes.NewReader(options))
+		dec.DisallowUnknownFields()
+
+		var preset controller.ImagePreset
+		if err := dec.Decode(&preset); err != nil {
+			return nil, fmt.Errorf("problem parsing options of preset %s: %w", name, err)
+		}
+
+		parsed[name] = preset
+	}
+
+	return parsed, nil
+}
@@ -569,6 +569,7 @@ type FilesSetInput struct {
 	Metadata         map[string]any `json:"metadata,omitempty"`
 	MimeType         *string        `json:"mimeType,omitempty"`
 	Name             *string        `json:"name,omitempty"`
+	ScanStatus       *string        `json:"scanStatus,omitempty"`
 	Size             *int64         `json:"size,omitempty"`
 	UpdatedAt        *time.Time     `json:"updatedAt,omitempty"`
 	UploadedByUserID *string        `json:"uploadedByUserId,omitempty"`
@@ -0,0 +1,110 @@
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v2"
+)
+
+type Operation string
+
+const (
+	OperationSelect Operation = "select"
+	OperationInsert Operation = "insert"
+	OperationUpdate Operation = "update"
+	OperationDelete Operation = "delete"
+)
+
+// FileRecord contains the attributes of a file an Authorizer can base its decision on.
+type FileRecord struct {
+	ID               string
+	BucketID         string
+	UploadedByUserID *string
+}
+
+// Authorizer is the hook used by Postgres to enforce permissions on files. It is
+// never called for admin sessions.
+type Authorizer interface {
+	Authorize(session Session, op Operation, file FileRecord) bool
+}
+
+// AuthorizerFunc allows using a plain function as an Authorizer.
+type AuthorizerFunc func(session Session, op Operation, file FileRecord) bool
+
+func (f AuthorizerFunc) Authorize(session Session, op Operation, file FileRecord) bool {
+	return f(session, op, file)
+}
+
+// Rule grants an operation. All conditions set must be met.
+type Rule struct {
+	// Buckets limits the rule to files in these buckets, any bucket if empty
+	Buckets []string `yaml:"buckets"`
+	// Owner limits the rule to files uploaded by the user in the session
+	Owner bool `yaml:"owner"`
+}
+
+func (r Rule) allows(session Session, file FileRecord) bool {
+	if len(r.Buckets) > 0 && !slices.Contains(r.Buckets, file.BucketID) {
+		return false
+	}
+
+	if r.Owner {
+		userID := session.UserID()
+		if userID == nil || file.UploadedByUserID == nil || *userID != *file.UploadedByUserID {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Policy is an Authorizer that maps roles to the operations they can perform.
+// Anything not explicitly granted is denied. For instance:
+//
+//	user:
+//	  select: {}
+//	  insert:
+//	    buckets: [default]
+//	  update:
+//	    owner: true
+//	  delete:
+//	    owner: true
+//	public:
+//	  select:
+//	    buckets: [public]
+type Policy map[string]map[Operation]Rule
+
+func LoadPolicy(path string) (Policy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("problem reading policy file: %w", err)
+	}
+
+	var policy Policy
+	if err := yaml.UnmarshalStrict(b, &policy); err != nil {
+		return nil, fmt.Errorf("problem parsing policy file: %w", err)
+	}
+
+	for role, rules := range policy {
+		for op := range rules {
+			switch op {
+			case OperationSelect, OperationInsert, OperationUpdate, OperationDelete:
+			default:
+				return nil, fmt.Errorf("unknown operation %s for role %s", op, role) //nolint:err113
+			}
+		}
+	}
+
+	return policy, nil
+}
+
+func (p Policy) Authorize(session Session, op Operation, file FileRecord) bool {
+	rule, ok := p[session.Role][op]
+	if !ok {
+		return false
+	}
+
+	return rule.allows(session, file)
+}
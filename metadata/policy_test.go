@@ -0,0 +1,147 @@
+package metadata_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nhost/hasura-storage/metadata"
+)
+
+const samplePolicy = `
+user:
+  select: {}
+  insert:
+    buckets: [default]
+  delete:
+    owner: true
+public:
+  select:
+    buckets: [public]
+`
+
+func TestPolicyAuthorize(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(samplePolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := metadata.LoadPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner, someoneElse := "user-1", "user-2"
+
+	user := metadata.Session{
+		Role:      "user",
+		Variables: map[string]any{"x-hasura-user-id": "user-1"},
+	}
+	public := metadata.Session{
+		Role:      "public",
+		Variables: map[string]any{},
+	}
+
+	cases := []struct {
+		name     string
+		session  metadata.Session
+		op       metadata.Operation
+		file     metadata.FileRecord
+		expected bool
+	}{
+		{
+			name:     "user can select any file",
+			session:  user,
+			op:       metadata.OperationSelect,
+			file:     metadata.FileRecord{ID: "1", BucketID: "private", UploadedByUserID: nil},
+			expected: true,
+		},
+		{
+			name:     "user can insert in default bucket",
+			session:  user,
+			op:       metadata.OperationInsert,
+			file:     metadata.FileRecord{ID: "1", BucketID: "default", UploadedByUserID: nil},
+			expected: true,
+		},
+		{
+			name:     "user can't insert in other buckets",
+			session:  user,
+			op:       metadata.OperationInsert,
+			file:     metadata.FileRecord{ID: "1", BucketID: "public", UploadedByUserID: nil},
+			expected: false,
+		},
+		{
+			name:    "user can delete own files",
+			session: user,
+			op:      metadata.OperationDelete,
+			file: metadata.FileRecord{
+				ID: "1", BucketID: "default", UploadedByUserID: &owner,
+			},
+			expected: true,
+		},
+		{
+			name:    "user can't delete someone else's files",
+			session: user,
+			op:      metadata.OperationDelete,
+			file: metadata.FileRecord{
+				ID: "1", BucketID: "default", UploadedByUserID: &someoneElse,
+			},
+			expected: false,
+		},
+		{
+			name:    "user can't update",
+			session: user,
+			op:      metadata.OperationUpdate,
+			file: metadata.FileRecord{
+				ID: "1", BucketID: "default", UploadedByUserID: &owner,
+			},
+			expected: false,
+		},
+		{
+			name:     "public can select public bucket",
+			session:  public,
+			op:       metadata.OperationSelect,
+			file:     metadata.FileRecord{ID: "1", BucketID: "public", UploadedByUserID: nil},
+			expected: true,
+		},
+		{
+			name:     "public can't select default bucket",
+			session:  public,
+			op:       metadata.OperationSelect,
+			file:     metadata.FileRecord{ID: "1", BucketID: "default", UploadedByUserID: nil},
+			expected: false,
+		},
+		{
+			name:     "unknown role",
+			session:  metadata.Session{Role: "other", Variables: map[string]any{}},
+			op:       metadata.OperationSelect,
+			file:     metadata.FileRecord{ID: "1", BucketID: "public", UploadedByUserID: nil},
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := policy.Authorize(tc.session, tc.op, tc.file); got != tc.expected {
+				t.Errorf("expected %t but got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestLoadPolicyUnknownOperation(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte("user:\n  upsert: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := metadata.LoadPolicy(path); err == nil {
+		t.Error("expected an error")
+	}
+}
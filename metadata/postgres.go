@@ -0,0 +1,622 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/controller"
+)
+
+const fileColumns = `id, created_at, updated_at, bucket_id, name, size, mime_type, etag,
+	is_uploaded, uploaded_by_user_id, metadata, scan_status`
+
+func parsePostgresError(err error) *controller.APIError {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "42501": // insufficient_privilege
+			return controller.ForbiddenError(pgErr, "you are not authorized")
+		case strings.HasPrefix(pgErr.Code, "22"), strings.HasPrefix(pgErr.Code, "23"):
+			// data exceptions and integrity constraint violations
+			return controller.BadDataError(err, pgErr.Message)
+		}
+	}
+
+	return controller.InternalServerError(err)
+}
+
+type fileRow struct {
+	ID               string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	BucketID         string
+	Name             *string
+	Size             *int64
+	MimeType         *string
+	Etag             *string
+	IsUploaded       *bool
+	UploadedByUserID *string
+	Metadata         map[string]any
+	ScanStatus       string
+}
+
+func scanFile(row pgx.Row) (fileRow, error) {
+	var f fileRow
+	err := row.Scan(
+		&f.ID,
+		&f.CreatedAt,
+		&f.UpdatedAt,
+		&f.BucketID,
+		&f.Name,
+		&f.Size,
+		&f.MimeType,
+		&f.Etag,
+		&f.IsUploaded,
+		&f.UploadedByUserID,
+		&f.Metadata,
+		&f.ScanStatus,
+	)
+
+	return f, err //nolint:wrapcheck
+}
+
+func deref[T any](x *T) T {
+	if x == nil {
+		var zero T
+		return zero
+	}
+
+	return *x
+}
+
+func (f fileRow) record() FileRecord {
+	return FileRecord{
+		ID:               f.ID,
+		BucketID:         f.BucketID,
+		UploadedByUserID: f.UploadedByUserID,
+	}
+}
+
+func (f fileRow) ToControllerType() api.FileMetadata {
+	return api.FileMetadata{
+		Id:               f.ID,
+		Name:             deref(f.Name),
+		Size:             deref(f.Size),
+		BucketId:         f.BucketID,
+		Etag:             deref(f.Etag),
+		CreatedAt:        f.CreatedAt,
+		UpdatedAt:        f.UpdatedAt,
+		IsUploaded:       deref(f.IsUploaded),
+		MimeType:         deref(f.MimeType),
+		Metadata:         ptr(f.Metadata),
+		UploadedByUserId: f.UploadedByUserID,
+		ScanStatus:       ptr(api.ScanStatus(f.ScanStatus)),
+	}
+}
+
+// Postgres implements controller.MetadataStorage by querying the storage schema directly
+// instead of going through hasura. As hasura's permissions don't apply, every operation
+// on a file is checked with the Authorizer unless the session is an admin one.
+type Postgres struct {
+	pool       *pgxpool.Pool
+	sessions   *SessionResolver
+	authorizer Authorizer
+}
+
+func NewPostgres(
+	ctx context.Context,
+	connString string,
+	sessions *SessionResolver,
+	authorizer Authorizer,
+) (*Postgres, error) {
+	pool, err := pgxpool.New(ctx, connString)
+	if err != nil {
+		return nil, fmt.Errorf("problem creating postgres pool: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("problem connecting to postgres: %w", err)
+	}
+
+	return &Postgres{
+		pool:       pool,
+		sessions:   sessions,
+		authorizer: authorizer,
+	}, nil
+}
+
+func (p *Postgres) Close() {
+	p.pool.Close()
+}
+
+func (p *Postgres) Ping(ctx context.Context) error {
+	if err := p.pool.Ping(ctx); err != nil {
+		return fmt.Errorf("problem pinging postgres: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) authorize(session Session, op Operation, file FileRecord) bool {
+	return session.IsAdmin() || p.authorizer.Authorize(session, op, file)
+}
+
+func (p *Postgres) GetBucketByID(
+	ctx context.Context,
+	bucketID string,
+	headers http.Header,
+) (controller.BucketMetadata, *controller.APIError) {
+	if _, apiErr := p.sessions.Resolve(headers); apiErr != nil {
+		return controller.BucketMetadata{}, apiErr.ExtendError("problem getting bucket metadata")
+	}
+
+	var (
+		bucket               controller.BucketMetadata
+		createdAt, updatedAt time.Time
+		cacheControl         *string
+		presets              map[string]json.RawMessage
+	)
+
+	err := p.pool.QueryRow(
+		ctx,
+		`SELECT id, min_upload_file_size, max_upload_file_size, presigned_urls_enabled,
+			download_expiration, created_at, updated_at, cache_control, presets_only,
+			strip_image_metadata, keep_icc_profile, allowed_mime_types, denied_mime_types,
+			(SELECT json_object_agg(name, options) FROM storage.bucket_presets
+				WHERE bucket_id = buckets.id)
+		FROM storage.buckets WHERE id = $1`,
+		bucketID,
+	).Scan(
+		&bucket.ID,
+		&bucket.MinUploadFile,
+		&bucket.MaxUploadFile,
+		&bucket.PresignedURLsEnabled,
+		&bucket.DownloadExpiration,
+		&createdAt,
+		&updatedAt,
+		&cacheControl,
+		&bucket.PresetsOnly,
+		&bucket.StripImageMetadata,
+		&bucket.KeepICCProfile,
+		&bucket.AllowedMimeTypes,
+		&bucket.DeniedMimeTypes,
+		&presets,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return controller.BucketMetadata{}, controller.ErrBucketNotFound
+	}
+
+	if err != nil {
+		aerr := parsePostgresError(err)
+		return controller.BucketMetadata{}, aerr.ExtendError("problem getting bucket metadata")
+	}
+
+	bucket.CreatedAt = createdAt.Format(time.RFC3339)
+	bucket.UpdatedAt = updatedAt.Format(time.RFC3339)
+	bucket.CacheControl = deref(cacheControl)
+
+	if bucket.Presets, err = parsePresets(presets); err != nil {
+		return controller.BucketMetadata{}, controller.InternalServerError(
+			fmt.Errorf("problem getting bucket metadata: %w", err),
+		)
+	}
+
+	return bucket, nil
+}
+
+func (p *Postgres) InitializeFile(
+	ctx context.Context,
+	fileID, name string, size int64, bucketID, mimeType string,
+	headers http.Header,
+) *controller.APIError {
+	session, apiErr := p.sessions.Resolve(headers)
+	if apiErr != nil {
+		return apiErr.ExtendError("problem initializing file metadata")
+	}
+
+	userID := session.UserID()
+	if !p.authorize(session, OperationInsert, FileRecord{
+		ID:               fileID,
+		BucketID:         bucketID,
+		UploadedByUserID: userID,
+	}) {
+		return controller.ForbiddenError(
+			fmt.Errorf("role %s can't insert files in bucket %s", session.Role, bucketID), //nolint:err113
+			"you are not authorized",
+		)
+	}
+
+	if _, err := p.pool.Exec(
+		ctx,
+		`INSERT INTO storage.files (id, bucket_id, name, size, mime_type, uploaded_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		fileID, bucketID, name, size, mimeType, userID,
+	); err != nil {
+		aerr := parsePostgresError(err)
+		return aerr.ExtendError("problem initializing file metadata")
+	}
+
+	return nil
+}
+
+// updateFile locks the file, checks the session can update it and runs update within the
+// same transaction. update receives the current state of the file and returns the new one.
+func (p *Postgres) updateFile(
+	ctx context.Context,
+	fileID string,
+	headers http.Header,
+	update func(tx pgx.Tx, current fileRow) (fileRow, error),
+) (fileRow, *controller.APIError) {
+	session, apiErr := p.sessions.Resolve(headers)
+	if apiErr != nil {
+		return fileRow{}, apiErr
+	}
+
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return fileRow{}, controller.InternalServerError(err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	current, err := scanFile(tx.QueryRow(
+		ctx,
+		"SELECT "+fileColumns+" FROM storage.files WHERE id = $1 FOR UPDATE",
+		fileID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fileRow{}, controller.ErrFileNotFound
+	}
+
+	if err != nil {
+		return fileRow{}, parsePostgresError(err)
+	}
+
+	if !p.authorize(session, OperationSelect, current.record()) {
+		return fileRow{}, controller.ErrFileNotFound
+	}
+
+	if !p.authorize(session, OperationUpdate, current.record()) {
+		return fileRow{}, controller.ForbiddenError(
+			fmt.Errorf("role %s can't update file %s", session.Role, fileID), //nolint:err113
+			"you are not authorized",
+		)
+	}
+
+	updated, err := update(tx, current)
+	if err != nil {
+		return fileRow{}, parsePostgresError(err)
+	}
+
+	// same as hasura's post-update check, the file must still be updatable after the change
+	if !p.authorize(session, OperationUpdate, updated.record()) {
+		return fileRow{}, controller.ForbiddenError(
+			fmt.Errorf("role %s can't move file %s to bucket %s", //nolint:err113
+				session.Role, fileID, updated.BucketID),
+			"you are not authorized",
+		)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fileRow{}, parsePostgresError(err)
+	}
+
+	return updated, nil
+}
+
+func (p *Postgres) PopulateMetadata(
+	ctx context.Context,
+	fileID, name string, size int64, bucketID, etag string, isUploaded bool, mimeType string,
+	metadata map[string]any,
+	headers http.Header,
+) (api.FileMetadata, *controller.APIError) {
+	// a nil map would be stored as JSON null instead of keeping the current metadata
+	var md any
+	if metadata != nil {
+		md = metadata
+	}
+
+	updated, apiErr := p.updateFile(
+		ctx,
+		fileID,
+		headers,
+		func(tx pgx.Tx, _ fileRow) (fileRow, error) {
+			return scanFile(tx.QueryRow(
+				ctx,
+				`UPDATE storage.files
+				SET bucket_id = $2, etag = $3, is_uploaded = $4,
+					metadata = COALESCE($5, metadata), mime_type = $6, name = $7, size = $8
+				WHERE id = $1
+				RETURNING `+fileColumns,
+				fileID, bucketID, etag, isUploaded, md, mimeType, name, size,
+			))
+		},
+	)
+	if apiErr != nil {
+		if apiErr == controller.ErrFileNotFound { //nolint:errorlint
+			return api.FileMetadata{}, apiErr
+		}
+
+		return api.FileMetadata{}, apiErr.ExtendError("problem populating file metadata")
+	}
+
+	return updated.ToControllerType(), nil
+}
+
+func (p *Postgres) GetFileByID(
+	ctx context.Context,
+	fileID string,
+	headers http.Header,
+) (api.FileMetadata, *controller.APIError) {
+	session, apiErr := p.sessions.Resolve(headers)
+	if apiErr != nil {
+		return api.FileMetadata{}, apiErr.ExtendError("problem getting file metadata")
+	}
+
+	file, err := scanFile(p.pool.QueryRow(
+		ctx,
+		"SELECT "+fileColumns+" FROM storage.files WHERE id = $1",
+		fileID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return api.FileMetadata{}, controller.ErrFileNotFound
+	}
+
+	if err != nil {
+		aerr := parsePostgresError(err)
+		return api.FileMetadata{}, aerr.ExtendError("problem getting file metadata")
+	}
+
+	if !p.authorize(session, OperationSelect, file.record()) {
+		return api.FileMetadata{}, controller.ErrFileNotFound
+	}
+
+	return file.ToControllerType(), nil
+}
+
+func (p *Postgres) GetFileScanStatus(
+	ctx context.Context,
+	fileID string,
+	headers http.Header,
+) (api.ScanStatus, *controller.APIError) {
+	file, apiErr := p.GetFileByID(ctx, fileID, headers)
+	if apiErr != nil {
+		return "", apiErr
+	}
+
+	return *file.ScanStatus, nil
+}
+
+func (p *Postgres) SetIsUploaded(
+	ctx context.Context, fileID string, isUploaded bool, headers http.Header,
+) *controller.APIError {
+	_, apiErr := p.updateFile(
+		ctx,
+		fileID,
+		headers,
+		func(tx pgx.Tx, _ fileRow) (fileRow, error) {
+			return scanFile(tx.QueryRow(
+				ctx,
+				"UPDATE storage.files SET is_uploaded = $2 WHERE id = $1 RETURNING "+fileColumns,
+				fileID, isUploaded,
+			))
+		},
+	)
+	if apiErr != nil {
+		if apiErr == controller.ErrFileNotFound { //nolint:errorlint
+			return apiErr
+		}
+
+		return apiErr.ExtendError("problem setting file as uploaded")
+	}
+
+	return nil
+}
+
+func (p *Postgres) SetScanStatus(
+	ctx context.Context, fileID string, status api.ScanStatus, headers http.Header,
+) *controller.APIError {
+	_, apiErr := p.updateFile(
+		ctx,
+		fileID,
+		headers,
+		func(tx pgx.Tx, _ fileRow) (fileRow, error) {
+			return scanFile(tx.QueryRow(
+				ctx,
+				"UPDATE storage.files SET scan_status = $2 WHERE id = $1 RETURNING "+fileColumns,
+				fileID, string(status),
+			))
+		},
+	)
+	if apiErr != nil {
+		if apiErr == controller.ErrFileNotFound { //nolint:errorlint
+			return apiErr
+		}
+
+		return apiErr.ExtendError("problem setting file scan status")
+	}
+
+	return nil
+}
+
+func (p *Postgres) DeleteFileByID(
+	ctx context.Context,
+	fileID string,
+	headers http.Header,
+) (api.FileMetadata, *controller.APIError) {
+	session, apiErr := p.sessions.Resolve(headers)
+	if apiErr != nil {
+		return api.FileMetadata{}, apiErr.ExtendError("problem deleting file")
+	}
+
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return api.FileMetadata{}, controller.InternalServerError(err).ExtendError(
+			"problem deleting file",
+		)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	file, err := scanFile(tx.QueryRow(
+		ctx,
+		"DELETE FROM storage.files WHERE id = $1 RETURNING "+fileColumns,
+		fileID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return api.FileMetadata{}, controller.ErrFileNotFound
+	}
+
+	if err != nil {
+		aerr := parsePostgresError(err)
+		return api.FileMetadata{}, aerr.ExtendError("problem deleting file")
+	}
+
+	if !p.authorize(session, OperationDelete, file.record()) {
+		// rolling back the transaction undoes the delete
+		return api.FileMetadata{}, controller.ErrFileNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		aerr := parsePostgresError(err)
+		return api.FileMetadata{}, aerr.ExtendError("problem deleting file")
+	}
+
+	return file.ToControllerType(), nil
+}
+
+// listFilesPage returns up to listFilesPageSize files with an id greater than after, all
+// of them if after is empty.
+func (p *Postgres) listFilesPage(
+	ctx context.Context, after string,
+) ([]controller.FileSummary, []*string, *controller.APIError) {
+	query := `SELECT id, name, bucket_id, is_uploaded, scan_status, uploaded_by_user_id,
+		created_at FROM storage.files ORDER BY id LIMIT $1`
+	args := []any{listFilesPageSize}
+
+	if after != "" {
+		query = `SELECT id, name, bucket_id, is_uploaded, scan_status, uploaded_by_user_id,
+			created_at FROM storage.files WHERE id > $2 ORDER BY id LIMIT $1`
+		args = append(args, after)
+	}
+
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, nil, parsePostgresError(err)
+	}
+	defer rows.Close()
+
+	files := make([]controller.FileSummary, 0, listFilesPageSize)
+	uploadedBy := make([]*string, 0, listFilesPageSize)
+
+	for rows.Next() {
+		var (
+			file       controller.FileSummary
+			name       *string
+			isUploaded *bool
+			userID     *string
+		)
+
+		if err := rows.Scan(
+			&file.ID, &name, &file.BucketID, &isUploaded, &file.ScanStatus, &userID,
+			&file.CreatedAt,
+		); err != nil {
+			return nil, nil, parsePostgresError(err)
+		}
+
+		file.Name = deref(name)
+		file.IsUploaded = deref(isUploaded)
+		files = append(files, file)
+		uploadedBy = append(uploadedBy, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, parsePostgresError(err)
+	}
+
+	return files, uploadedBy, nil
+}
+
+func (p *Postgres) ListFiles(
+	ctx context.Context,
+	headers http.Header,
+) iter.Seq2[controller.FileSummary, *controller.APIError] {
+	return func(yield func(controller.FileSummary, *controller.APIError) bool) {
+		session, apiErr := p.sessions.Resolve(headers)
+		if apiErr != nil {
+			yield(controller.FileSummary{}, apiErr.ExtendError("problem listing files"))
+			return
+		}
+
+		after := ""
+		for {
+			// the whole page is read before yielding so the connection isn't held
+			// while the caller processes the files
+			files, uploadedBy, apiErr := p.listFilesPage(ctx, after)
+			if apiErr != nil {
+				yield(controller.FileSummary{}, apiErr.ExtendError("problem listing files"))
+				return
+			}
+
+			for i, file := range files {
+				if !p.authorize(session, OperationSelect, FileRecord{
+					ID:               file.ID,
+					BucketID:         file.BucketID,
+					UploadedByUserID: uploadedBy[i],
+				}) {
+					continue
+				}
+
+				if !yield(file, nil) {
+					return
+				}
+			}
+
+			if len(files) < listFilesPageSize {
+				return
+			}
+
+			after = files[len(files)-1].ID
+		}
+	}
+}
+
+func (p *Postgres) InsertVirus(
+	ctx context.Context,
+	fileID, filename, virus string,
+	userSession map[string]any,
+	headers http.Header,
+) *controller.APIError {
+	session, apiErr := p.sessions.Resolve(headers)
+	if apiErr != nil {
+		return apiErr.ExtendError("problem inserting virus")
+	}
+
+	if !session.IsAdmin() {
+		return controller.ForbiddenError(
+			fmt.Errorf("role %s can't insert viruses", session.Role), //nolint:err113
+			"you are not authorized",
+		)
+	}
+
+	if _, err := p.pool.Exec(
+		ctx,
+		`INSERT INTO storage.virus (file_id, filename, virus, user_session)
+		VALUES ($1, $2, $3, $4)`,
+		fileID, filename, virus, userSession,
+	); err != nil {
+		aerr := parsePostgresError(err)
+		return aerr.ExtendError("problem inserting virus")
+	}
+
+	return nil
+}
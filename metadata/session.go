@@ -0,0 +1,305 @@
+package metadata
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/nhost/hasura-storage/controller"
+)
+
+const (
+	roleAdmin              = "admin"
+	defaultClaimsNamespace = "https://hasura.io/jwt/claims"
+
+	headerAdminSecret   = "x-hasura-admin-secret"
+	headerRole          = "x-hasura-role"
+	headerAuthorization = "authorization"
+
+	claimUserID       = "x-hasura-user-id"
+	claimDefaultRole  = "x-hasura-default-role"
+	claimAllowedRoles = "x-hasura-allowed-roles"
+)
+
+// Session is the identity a metadata operation is performed on behalf of. It mirrors
+// what hasura would derive from the same request: the admin secret or the claims in the
+// JWT plus the x-hasura-role header.
+type Session struct {
+	Role string
+	// Variables holds the session variables (x-hasura-*) keyed in lower case
+	Variables map[string]any
+}
+
+func (s Session) IsAdmin() bool {
+	return s.Role == roleAdmin
+}
+
+// UserID returns the x-hasura-user-id session variable or nil if it isn't set.
+func (s Session) UserID() *string {
+	v, ok := s.Variables[claimUserID].(string)
+	if !ok || v == "" {
+		return nil
+	}
+
+	return &v
+}
+
+// JWTSecret follows the format of hasura's HASURA_GRAPHQL_JWT_SECRET.
+type JWTSecret struct {
+	Type            string `json:"type"`
+	Key             string `json:"key"`
+	ClaimsNamespace string `json:"claims_namespace"` //nolint:tagliatelle
+	Issuer          string `json:"issuer"`
+	Audience        string `json:"audience"`
+}
+
+// SessionResolver authenticates requests and builds the Session for them.
+type SessionResolver struct {
+	adminSecret      string
+	unauthorizedRole string
+	claimsNamespace  string
+	key              any
+	parser           *jwt.Parser
+}
+
+func parseJWTKey(secret JWTSecret) (any, error) {
+	switch {
+	case strings.HasPrefix(secret.Type, "HS"):
+		return []byte(secret.Key), nil
+	case strings.HasPrefix(secret.Type, "RS"), strings.HasPrefix(secret.Type, "PS"):
+		key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(secret.Key))
+		if err != nil {
+			return nil, fmt.Errorf("problem parsing RSA public key: %w", err)
+		}
+
+		return key, nil
+	case strings.HasPrefix(secret.Type, "ES"):
+		key, err := jwt.ParseECPublicKeyFromPEM([]byte(secret.Key))
+		if err != nil {
+			return nil, fmt.Errorf("problem parsing EC public key: %w", err)
+		}
+
+		return key, nil
+	case secret.Type == "Ed25519":
+		key, err := jwt.ParseEdPublicKeyFromPEM([]byte(secret.Key))
+		if err != nil {
+			return nil, fmt.Errorf("problem parsing Ed25519 public key: %w", err)
+		}
+
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported jwt secret type: %s", secret.Type) //nolint:err113
+	}
+}
+
+// signingMethod returns the alg tokens signed with the secret carry in their header.
+// Hasura names Ed25519 keys after the curve while tokens use the "EdDSA" alg.
+func signingMethod(secret JWTSecret) string {
+	if secret.Type == "Ed25519" {
+		return jwt.SigningMethodEdDSA.Alg()
+	}
+
+	return secret.Type
+}
+
+// NewSessionResolver returns a SessionResolver. jwtSecret is the JSON-encoded JWTSecret
+// and can be empty to only accept the admin secret and unauthenticated requests.
+// unauthorizedRole is the role given to requests without credentials; if empty those
+// requests are rejected.
+func NewSessionResolver(
+	adminSecret, jwtSecret, unauthorizedRole string,
+) (*SessionResolver, error) {
+	resolver := &SessionResolver{
+		adminSecret:      adminSecret,
+		unauthorizedRole: unauthorizedRole,
+		claimsNamespace:  defaultClaimsNamespace,
+		key:              nil,
+		parser:           nil,
+	}
+
+	if jwtSecret == "" {
+		return resolver, nil
+	}
+
+	var secret JWTSecret
+	if err := json.Unmarshal([]byte(jwtSecret), &secret); err != nil {
+		return nil, fmt.Errorf("problem parsing jwt secret: %w", err)
+	}
+
+	key, err := parseJWTKey(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []jwt.ParserOption{jwt.WithValidMethods([]string{signingMethod(secret)})}
+	if secret.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(secret.Issuer))
+	}
+
+	if secret.Audience != "" {
+		opts = append(opts, jwt.WithAudience(secret.Audience))
+	}
+
+	if secret.ClaimsNamespace != "" {
+		resolver.claimsNamespace = secret.ClaimsNamespace
+	}
+
+	resolver.key = key
+	resolver.parser = jwt.NewParser(opts...)
+
+	return resolver, nil
+}
+
+// getHeader is a case-insensitive lookup as callers within this service don't always
+// canonicalize header names.
+func getHeader(headers http.Header, key string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, key) && len(v) > 0 {
+			return v[0]
+		}
+	}
+
+	return ""
+}
+
+func hasuraHeaders(headers http.Header) map[string]any {
+	variables := make(map[string]any)
+
+	for k, v := range headers {
+		k = strings.ToLower(k)
+		if strings.HasPrefix(k, "x-hasura-") && k != headerAdminSecret && len(v) > 0 {
+			variables[k] = v[0]
+		}
+	}
+
+	return variables
+}
+
+func (r *SessionResolver) adminSession(headers http.Header) Session {
+	variables := hasuraHeaders(headers)
+
+	role, ok := variables[headerRole].(string)
+	if !ok || role == "" {
+		role = roleAdmin
+	}
+
+	return Session{
+		Role:      role,
+		Variables: variables,
+	}
+}
+
+func (r *SessionResolver) verifyToken(token string) (map[string]any, error) {
+	if r.parser == nil {
+		return nil, errors.New("jwt authentication is not configured") //nolint:err113
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := r.parser.ParseWithClaims(
+		token, claims, func(*jwt.Token) (any, error) { return r.key, nil },
+	); err != nil {
+		return nil, fmt.Errorf("problem verifying jwt: %w", err)
+	}
+
+	namespaced, ok := claims[r.claimsNamespace].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("jwt is missing claims under %s", r.claimsNamespace) //nolint:err113
+	}
+
+	variables := make(map[string]any, len(namespaced))
+	for k, v := range namespaced {
+		variables[strings.ToLower(k)] = v
+	}
+
+	return variables, nil
+}
+
+func allowedRoles(variables map[string]any) []string {
+	raw, ok := variables[claimAllowedRoles].([]any)
+	if !ok {
+		return nil
+	}
+
+	roles := make([]string, 0, len(raw))
+	for _, r := range raw {
+		if s, ok := r.(string); ok {
+			roles = append(roles, s)
+		}
+	}
+
+	return roles
+}
+
+func (r *SessionResolver) jwtSession(token string, headers http.Header) (Session, error) {
+	variables, err := r.verifyToken(token)
+	if err != nil {
+		return Session{}, err
+	}
+
+	role, _ := variables[claimDefaultRole].(string)
+	if requested := getHeader(headers, headerRole); requested != "" {
+		if !slices.Contains(allowedRoles(variables), requested) {
+			return Session{}, fmt.Errorf("role %s is not allowed", requested) //nolint:err113
+		}
+
+		role = requested
+	}
+
+	if role == "" {
+		return Session{}, errors.New("jwt doesn't specify a default role") //nolint:err113
+	}
+
+	return Session{
+		Role:      role,
+		Variables: variables,
+	}, nil
+}
+
+// Resolve returns the Session for the given request headers.
+func (r *SessionResolver) Resolve(headers http.Header) (Session, *controller.APIError) {
+	if secret := getHeader(headers, headerAdminSecret); secret != "" {
+		if r.adminSecret == "" ||
+			subtle.ConstantTimeCompare([]byte(secret), []byte(r.adminSecret)) != 1 {
+			return Session{}, controller.ForbiddenError(
+				errors.New("invalid x-hasura-admin-secret"), //nolint:err113
+				"you are not authorized",
+			)
+		}
+
+		return r.adminSession(headers), nil
+	}
+
+	if auth := getHeader(headers, headerAuthorization); auth != "" {
+		token, ok := strings.CutPrefix(auth, "Bearer ")
+		if !ok {
+			return Session{}, controller.ForbiddenError(
+				errors.New("malformed authorization header"), //nolint:err113
+				"you are not authorized",
+			)
+		}
+
+		session, err := r.jwtSession(token, headers)
+		if err != nil {
+			return Session{}, controller.ForbiddenError(err, "you are not authorized")
+		}
+
+		return session, nil
+	}
+
+	if r.unauthorizedRole == "" {
+		return Session{}, controller.ForbiddenError(
+			errors.New("missing credentials and no unauthorized role configured"), //nolint:err113
+			"you are not authorized",
+		)
+	}
+
+	return Session{
+		Role:      r.unauthorizedRole,
+		Variables: map[string]any{},
+	}, nil
+}
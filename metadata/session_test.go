@@ -0,0 +1,202 @@
+package metadata_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/go-cmp/cmp"
+	"github.com/nhost/hasura-storage/metadata"
+)
+
+const (
+	jwtKey    = "5152fa850c02dc222631cca898ed1485821a70912a6e3649c49076912daa3b62182ba013315915d64f40cddfbb8b58eb5bd11ba225336a6af45bbae07ca873f3" //nolint:lll,gosec
+	jwtSecret = `{"type":"HS256","key":"` + jwtKey + `"}`
+)
+
+func getToken(t *testing.T, key string, claims map[string]any) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"exp":                          time.Now().Add(time.Hour).Unix(),
+		"https://hasura.io/jwt/claims": claims,
+	})
+
+	signed, err := token.SignedString([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func TestSessionResolverResolveEd25519(t *testing.T) {
+	t.Parallel()
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	secret, err := json.Marshal(metadata.JWTSecret{Type: "Ed25519", Key: string(key)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolver, err := metadata.NewSessionResolver("secret", string(secret), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := map[string]any{
+		"x-hasura-user-id":       "ab5ba9b1-d2ec-4a3b-8d55-c6cd2e1e1a1e",
+		"x-hasura-default-role":  "user",
+		"x-hasura-allowed-roles": []any{"user"},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+		"exp":                          time.Now().Add(time.Hour).Unix(),
+		"https://hasura.io/jwt/claims": claims,
+	}).SignedString(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, apiErr := resolver.Resolve(http.Header{"Authorization": {"Bearer " + token}})
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+
+	if diff := cmp.Diff(metadata.Session{Role: "user", Variables: claims}, got); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestSessionResolverResolve(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	userClaims := map[string]any{
+		"x-hasura-user-id":       "ab5ba9b1-d2ec-4a3b-8d55-c6cd2e1e1a1e",
+		"x-hasura-default-role":  "user",
+		"x-hasura-allowed-roles": []any{"user", "me"},
+	}
+
+	cases := []struct {
+		name               string
+		headers            http.Header
+		expected           metadata.Session
+		expectedStatusCode int
+	}{
+		{
+			name:    "admin secret",
+			headers: http.Header{"x-hasura-admin-secret": {"secret"}},
+			expected: metadata.Session{
+				Role:      "admin",
+				Variables: map[string]any{},
+			},
+		},
+		{
+			name: "admin secret with role",
+			headers: http.Header{
+				"X-Hasura-Admin-Secret": {"secret"},
+				"X-Hasura-Role":         {"user"},
+				"X-Hasura-User-Id":      {"some-user"},
+			},
+			expected: metadata.Session{
+				Role: "user",
+				Variables: map[string]any{
+					"x-hasura-role":    "user",
+					"x-hasura-user-id": "some-user",
+				},
+			},
+		},
+		{
+			name:               "wrong admin secret",
+			headers:            http.Header{"X-Hasura-Admin-Secret": {"wrong"}},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name: "jwt",
+			headers: http.Header{
+				"Authorization": {"Bearer " + getToken(t, jwtKey, userClaims)},
+			},
+			expected: metadata.Session{
+				Role:      "user",
+				Variables: userClaims,
+			},
+		},
+		{
+			name: "jwt with allowed role",
+			headers: http.Header{
+				"Authorization": {"Bearer " + getToken(t, jwtKey, userClaims)},
+				"X-Hasura-Role": {"me"},
+			},
+			expected: metadata.Session{
+				Role:      "me",
+				Variables: userClaims,
+			},
+		},
+		{
+			name: "jwt with role not allowed",
+			headers: http.Header{
+				"Authorization": {"Bearer " + getToken(t, jwtKey, userClaims)},
+				"X-Hasura-Role": {"admin"},
+			},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name: "jwt with wrong signature",
+			headers: http.Header{
+				"Authorization": {"Bearer " + getToken(t, "another key", userClaims)},
+			},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:    "unauthenticated",
+			headers: http.Header{},
+			expected: metadata.Session{
+				Role:      "public",
+				Variables: map[string]any{},
+			},
+		},
+	}
+
+	resolver, err := metadata.NewSessionResolver("secret", jwtSecret, "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, apiErr := resolver.Resolve(tc.headers)
+
+			statusCode := 0
+			if apiErr != nil {
+				statusCode = apiErr.StatusCode()
+			}
+
+			if statusCode != tc.expectedStatusCode {
+				t.Errorf("expected status code %d but got %d", tc.expectedStatusCode, statusCode)
+			}
+
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
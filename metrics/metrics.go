@@ -0,0 +1,207 @@
+// Package metrics contains the prometheus collectors exposed in /metrics.
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "hasura_storage"
+
+const (
+	VerdictClean    = "clean"
+	VerdictInfected = "infected"
+	VerdictError    = "error"
+)
+
+//nolint:gochecknoglobals
+var (
+	Registry = prometheus.NewRegistry()
+
+	httpRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{ //nolint:exhaustruct
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests handled.",
+		},
+		[]string{"operation", "bucket", "status"},
+	)
+	httpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{ //nolint:exhaustruct
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to handle HTTP requests.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300},
+		},
+		[]string{"operation", "bucket", "status"},
+	)
+	uploadedBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{ //nolint:exhaustruct
+			Namespace: namespace,
+			Name:      "uploaded_bytes_total",
+			Help:      "Bytes received in request bodies.",
+		},
+		[]string{"operation", "bucket"},
+	)
+	downloadedBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{ //nolint:exhaustruct
+			Namespace: namespace,
+			Name:      "downloaded_bytes_total",
+			Help:      "Bytes sent in response bodies.",
+		},
+		[]string{"operation", "bucket"},
+	)
+
+	imageTransformationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{ //nolint:exhaustruct
+			Namespace: namespace,
+			Name:      "image_transformation_duration_seconds",
+			Help:      "Time taken to transform images, without waiting for a worker.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"format", "status"},
+	)
+	imageTransformerWorkers = prometheus.NewGauge(
+		prometheus.GaugeOpts{ //nolint:exhaustruct
+			Namespace: namespace,
+			Name:      "image_transformer_workers",
+			Help:      "Size of the image transformer worker pool.",
+		},
+	)
+	imageTransformerBusyWorkers = prometheus.NewGauge(
+		prometheus.GaugeOpts{ //nolint:exhaustruct
+			Namespace: namespace,
+			Name:      "image_transformer_busy_workers",
+			Help:      "Image transformer workers currently transforming an image.",
+		},
+	)
+	imageTransformerWaiting = prometheus.NewGauge(
+		prometheus.GaugeOpts{ //nolint:exhaustruct
+			Namespace: namespace,
+			Name:      "image_transformer_waiting",
+			Help:      "Image transformations waiting for a free worker.",
+		},
+	)
+
+	antivirusScanDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{ //nolint:exhaustruct
+			Namespace: namespace,
+			Name:      "antivirus_scan_duration_seconds",
+			Help:      "Time taken to scan files with the antivirus, labeled by verdict.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"verdict"},
+	)
+
+	s3RequestsInFlight = prometheus.NewGauge(
+		prometheus.GaugeOpts{ //nolint:exhaustruct
+			Namespace: namespace,
+			Name:      "s3_requests_in_flight",
+			Help:      "Requests to S3 currently in progress.",
+		},
+	)
+)
+
+func init() { //nolint:gochecknoinits
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}), //nolint:exhaustruct
+		httpRequests,
+		httpRequestDuration,
+		uploadedBytes,
+		downloadedBytes,
+		imageTransformationDuration,
+		imageTransformerWorkers,
+		imageTransformerBusyWorkers,
+		imageTransformerWaiting,
+		antivirusScanDuration,
+		s3RequestsInFlight,
+	)
+}
+
+// Handler serves the metrics in the prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}) //nolint:exhaustruct
+}
+
+func ObserveImageTransformation(format string, duration time.Duration, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+
+	imageTransformationDuration.WithLabelValues(format, status).Observe(duration.Seconds())
+}
+
+func SetImageTransformerWorkers(n int) {
+	imageTransformerWorkers.Set(float64(n))
+}
+
+// ImageTransformerWaiting tracks a transformation waiting for a worker, the returned
+// function is called once it gets one.
+func ImageTransformerWaiting() func() {
+	imageTransformerWaiting.Inc()
+	return imageTransformerWaiting.Dec
+}
+
+// ImageTransformerBusy tracks a worker transforming an image, the returned function is
+// called once it is done.
+func ImageTransformerBusy() func() {
+	imageTransformerBusyWorkers.Inc()
+	return imageTransformerBusyWorkers.Dec
+}
+
+func ObserveAntivirusScan(verdict string, duration time.Duration) {
+	antivirusScanDuration.WithLabelValues(verdict).Observe(duration.Seconds())
+}
+
+// HTTPClient is the interface of the clients used by the AWS SDK.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type s3Client struct {
+	client HTTPClient
+}
+
+// inFlightBody keeps the request in flight until the response body is closed, so
+// downloads streamed from S3 are tracked until they finish.
+type inFlightBody struct {
+	io.ReadCloser
+
+	once sync.Once
+}
+
+func (b *inFlightBody) Close() error {
+	b.once.Do(s3RequestsInFlight.Dec)
+	return b.ReadCloser.Close() //nolint:wrapcheck
+}
+
+func (c s3Client) Do(req *http.Request) (*http.Response, error) {
+	s3RequestsInFlight.Inc()
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		s3RequestsInFlight.Dec()
+		return nil, err //nolint:wrapcheck
+	}
+
+	resp.Body = &inFlightBody{ReadCloser: resp.Body} //nolint:exhaustruct
+
+	return resp, nil
+}
+
+// InstrumentS3Client tracks the requests in flight made by an S3 client.
+func InstrumentS3Client(client HTTPClient) HTTPClient { //nolint:ireturn
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return s3Client{client: client}
+}
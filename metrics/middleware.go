@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+const bucketContextKey = "metrics.bucket"
+
+// BucketToContext sets the bucket the request operates on so the metrics of the
+// request are labeled with it.
+func BucketToContext(ctx context.Context, bucketID string) {
+	ginCtx, ok := ctx.(*gin.Context)
+	if !ok {
+		return
+	}
+
+	ginCtx.Set(bucketContextKey, bucketID)
+}
+
+// Operations maps the routes, as gin reports them, to the operation ids in the
+// OpenAPI spec.
+type Operations map[string]string
+
+func operationKey(method, route string) string {
+	return method + " " + route
+}
+
+func OperationsFromSpec(doc *openapi3.T, apiRootPrefix string) Operations {
+	ops := Operations{}
+
+	for path, item := range doc.Paths.Map() {
+		// gin uses :param instead of {param}
+		route := apiRootPrefix + strings.NewReplacer("{", ":", "}", "").Replace(path)
+		for method, op := range item.Operations() {
+			ops[operationKey(method, route)] = op.OperationID
+		}
+	}
+
+	return ops
+}
+
+// operation returns the operation id of the route or, for routes not in the spec, the
+// route itself so the cardinality stays bounded.
+func (o Operations) operation(ctx *gin.Context) string {
+	route := ctx.FullPath()
+	if route == "" {
+		return "not_found"
+	}
+
+	if op, ok := o[operationKey(ctx.Request.Method, route)]; ok {
+		return op
+	}
+
+	return route
+}
+
+type countingReader struct {
+	io.ReadCloser
+
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+
+	return n, err //nolint:wrapcheck
+}
+
+// Middleware records the metrics of each request.
+func Middleware(operations Operations) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		var body *countingReader
+		if ctx.Request.Body != nil && ctx.Request.Body != http.NoBody {
+			body = &countingReader{ReadCloser: ctx.Request.Body, n: 0}
+			ctx.Request.Body = body
+		}
+
+		ctx.Next()
+
+		op := operations.operation(ctx)
+		bucket := ctx.GetString(bucketContextKey)
+		status := strconv.Itoa(ctx.Writer.Status())
+
+		httpRequests.WithLabelValues(op, bucket, status).Inc()
+		httpRequestDuration.WithLabelValues(op, bucket, status).Observe(
+			time.Since(start).Seconds(),
+		)
+
+		if body != nil {
+			uploadedBytes.WithLabelValues(op, bucket).Add(float64(body.n))
+		}
+
+		if size := ctx.Writer.Size(); size > 0 {
+			downloadedBytes.WithLabelValues(op, bucket).Add(float64(size))
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	doc, err := openapi3.NewLoader().LoadFromData(controller.OpenAPISchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(metrics.Middleware(metrics.OperationsFromSpec(doc, "/v1")))
+	router.PUT("/v1/files/:id", func(ctx *gin.Context) {
+		metrics.BucketToContext(ctx, "some-bucket")
+
+		_, _ = io.Copy(io.Discard, ctx.Request.Body)
+		ctx.String(http.StatusOK, "hello")
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/files/some-id", strings.NewReader("content"))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	expected := `
+# HELP hasura_storage_uploaded_bytes_total Bytes received in request bodies.
+# TYPE hasura_storage_uploaded_bytes_total counter
+hasura_storage_uploaded_bytes_total{bucket="some-bucket",operation="replaceFile"} 7
+# HELP hasura_storage_downloaded_bytes_total Bytes sent in response bodies.
+# TYPE hasura_storage_downloaded_bytes_total counter
+hasura_storage_downloaded_bytes_total{bucket="some-bucket",operation="replaceFile"} 5
+# HELP hasura_storage_http_requests_total Number of HTTP requests handled.
+# TYPE hasura_storage_http_requests_total counter
+hasura_storage_http_requests_total{bucket="some-bucket",operation="replaceFile",status="200"} 1
+`
+
+	if err := testutil.GatherAndCompare(
+		metrics.Registry,
+		strings.NewReader(expected),
+		"hasura_storage_uploaded_bytes_total",
+		"hasura_storage_downloaded_bytes_total",
+		"hasura_storage_http_requests_total",
+	); err != nil {
+		t.Error(err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gin-gonic/gin"
 	ginmiddleware "github.com/oapi-codegen/gin-middleware"
 )
 
@@ -42,6 +43,15 @@ func AcceptHeaderFromContext(ctx context.Context) []string {
 	return headers.Values("Accept")
 }
 
+// SessionHeaders makes the request headers available to SessionHeadersFromContext in
+// routes that aren't validated against the OpenAPI schema.
+func SessionHeaders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(HeadersContextKey, c.Request.Header)
+		c.Next()
+	}
+}
+
 func AuthenticationFunc(adminSecret string) openapi3filter.AuthenticationFunc {
 	return func(ctx context.Context,
 		input *openapi3filter.AuthenticationInput,
@@ -6,6 +6,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 type loggerCtxKey struct{}
@@ -35,6 +38,23 @@ func Logger(logger logrus.FieldLogger) gin.HandlerFunc {
 		startTime := time.Now()
 
 		trace := TraceFromHTTPHeaders(ctx.Request.Header)
+		// with OpenTelemetry the request has its own span, whose parent is the caller's
+		if sc := oteltrace.SpanContextFromContext(ctx.Request.Context()); sc.IsValid() {
+			parent := oteltrace.SpanContextFromContext(
+				otel.GetTextMapPropagator().Extract(
+					context.Background(), propagation.HeaderCarrier(ctx.Request.Header),
+				),
+			)
+
+			trace = Trace{
+				TraceID:      sc.TraceID().String(),
+				ParentSpanID: "",
+				SpanID:       sc.SpanID().String(),
+			}
+			if parent.IsValid() && parent.SpanID() != sc.SpanID() {
+				trace.ParentSpanID = parent.SpanID().String()
+			}
+		}
 
 		clientIP := ctx.ClientIP()
 		reqMethod := ctx.Request.Method
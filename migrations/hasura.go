@@ -185,6 +185,11 @@ func ApplyHasuraMetadata(url, hasuraSecret, hasuraDBName string) error { //nolin
 					"max_upload_file_size":   "maxUploadFileSize",
 					"cache_control":          "cacheControl",
 					"presigned_urls_enabled": "presignedUrlsEnabled",
+					"presets_only":           "presetsOnly",
+					"strip_image_metadata":   "stripImageMetadata",
+					"keep_icc_profile":       "keepIccProfile",
+					"allowed_mime_types":     "allowedMimeTypes",
+					"denied_mime_types":      "deniedMimeTypes",
 				},
 			},
 		},
@@ -227,6 +232,7 @@ func ApplyHasuraMetadata(url, hasuraSecret, hasuraDBName string) error { //nolin
 					"is_uploaded":         "isUploaded",
 					"uploaded_by_user_id": "uploadedByUserId",
 					"metadata":            "metadata",
+					"scan_status":         "scanStatus",
 				},
 			},
 		},
@@ -274,6 +280,42 @@ func ApplyHasuraMetadata(url, hasuraSecret, hasuraDBName string) error { //nolin
 		return fmt.Errorf("problem adding metadata for the virus table: %w", err)
 	}
 
+	bucketPresetsTable := TrackTable{
+		Type: "pg_track_table",
+		Args: PgTrackTableArgs{
+			Source: hasuraDBName,
+			Table: Table{
+				Schema: "storage",
+				Name:   "bucket_presets",
+			},
+			Configuration: Configuration{
+				CustomName: "bucketPresets",
+				CustomRootFields: CustomRootFields{
+					Select:          "bucketPresets",
+					SelectByPk:      "bucketPreset",
+					SelectAggregate: "bucketPresetsAggregate",
+					Insert:          "insertBucketPresets",
+					InsertOne:       "insertBucketPreset",
+					Update:          "updateBucketPresets",
+					UpdateByPk:      "updateBucketPreset",
+					Delete:          "deleteBucketPresets",
+					DeleteByPk:      "deleteBucketPreset",
+				},
+				CustomColumnNames: map[string]string{
+					"bucket_id":  "bucketId",
+					"name":       "name",
+					"options":    "options",
+					"created_at": "createdAt",
+					"updated_at": "updatedAt",
+				},
+			},
+		},
+	}
+
+	if err := postMetadata(url, hasuraSecret, bucketPresetsTable); err != nil {
+		return fmt.Errorf("problem adding metadata for the bucket presets table: %w", err)
+	}
+
 	objRelationshipBuckets := CreateObjectRelationship{
 		Type: "pg_create_object_relationship",
 		Args: CreateObjectRelationshipArgs{
@@ -318,6 +360,31 @@ func ApplyHasuraMetadata(url, hasuraSecret, hasuraDBName string) error { //nolin
 		return fmt.Errorf("problem creating array relationships: %w", err)
 	}
 
+	arrRelationshipPresets := CreateArrayRelationship{
+		Type: "pg_create_array_relationship",
+		Args: CreateArrayRelationshipArgs{
+			Table: Table{
+				Schema: "storage",
+				Name:   "buckets",
+			},
+			Name:   "presets",
+			Source: hasuraDBName,
+			Using: CreateArrayRelationshipUsing{
+				ForeignKeyConstraintOn: ForeignKeyConstraintOn{
+					Table: Table{
+						Schema: "storage",
+						Name:   "bucket_presets",
+					},
+					Columns: []string{"bucket_id"},
+				},
+			},
+		},
+	}
+
+	if err := postMetadata(url, hasuraSecret, arrRelationshipPresets); err != nil {
+		return fmt.Errorf("problem creating array relationship for bucket presets: %w", err)
+	}
+
 	objRelationshipVirusFile := CreateObjectRelationship{
 		Type: "pg_create_object_relationship",
 		Args: CreateObjectRelationshipArgs{
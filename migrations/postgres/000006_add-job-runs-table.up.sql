@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS storage.job_runs (
+  id uuid DEFAULT public.gen_random_uuid () NOT NULL PRIMARY KEY,
+  job text NOT NULL,
+  started_at timestamp with time zone DEFAULT now() NOT NULL,
+  ended_at timestamp with time zone,
+  items_affected int,
+  errors jsonb,
+  error text
+);
+
+CREATE INDEX IF NOT EXISTS job_runs_job_started_at_idx ON storage.job_runs (job, started_at DESC);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS storage.webhook_outbox (
+  id uuid DEFAULT public.gen_random_uuid () NOT NULL PRIMARY KEY,
+  webhook text NOT NULL,
+  event_type text NOT NULL,
+  payload jsonb NOT NULL,
+  attempts int DEFAULT 0 NOT NULL,
+  next_attempt_at timestamp with time zone DEFAULT now() NOT NULL,
+  last_error text,
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  delivered_at timestamp with time zone,
+  failed_at timestamp with time zone
+);
+
+CREATE INDEX IF NOT EXISTS webhook_outbox_pending_idx ON storage.webhook_outbox (next_attempt_at)
+WHERE delivered_at IS NULL AND failed_at IS NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS storage.bucket_presets;
+
+ALTER TABLE storage.buckets DROP COLUMN IF EXISTS presets_only;
@@ -0,0 +1,17 @@
+ALTER TABLE storage.buckets
+  ADD COLUMN IF NOT EXISTS presets_only boolean DEFAULT false NOT NULL;
+
+CREATE TABLE IF NOT EXISTS storage.bucket_presets (
+  bucket_id text NOT NULL REFERENCES storage.buckets(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  name text NOT NULL,
+  options jsonb NOT NULL,
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  updated_at timestamp with time zone DEFAULT now() NOT NULL,
+  PRIMARY KEY (bucket_id, name)
+);
+
+DROP TRIGGER IF EXISTS set_storage_bucket_presets_updated_at ON storage.bucket_presets;
+CREATE TRIGGER set_storage_bucket_presets_updated_at
+  BEFORE UPDATE ON storage.bucket_presets
+  FOR EACH ROW
+  EXECUTE FUNCTION storage.set_current_timestamp_updated_at ();
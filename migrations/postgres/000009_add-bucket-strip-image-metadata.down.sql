@@ -0,0 +1,3 @@
+ALTER TABLE storage.buckets
+  DROP COLUMN IF EXISTS strip_image_metadata,
+  DROP COLUMN IF EXISTS keep_icc_profile;
@@ -0,0 +1,3 @@
+ALTER TABLE storage.buckets
+  ADD COLUMN IF NOT EXISTS strip_image_metadata boolean DEFAULT false NOT NULL,
+  ADD COLUMN IF NOT EXISTS keep_icc_profile boolean DEFAULT true NOT NULL;
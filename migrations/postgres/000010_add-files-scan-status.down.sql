@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS storage.scan_queue;
+
+ALTER TABLE storage.files DROP COLUMN IF EXISTS scan_status;
@@ -0,0 +1,14 @@
+ALTER TABLE storage.files
+  ADD COLUMN IF NOT EXISTS scan_status text DEFAULT 'clean' NOT NULL
+  CONSTRAINT files_scan_status_check CHECK (scan_status IN ('pending', 'clean', 'infected', 'error'));
+
+CREATE TABLE IF NOT EXISTS storage.scan_queue (
+  id uuid DEFAULT public.gen_random_uuid () NOT NULL PRIMARY KEY,
+  file_id uuid NOT NULL UNIQUE REFERENCES storage.files(id) ON DELETE CASCADE,
+  attempts int DEFAULT 0 NOT NULL,
+  next_attempt_at timestamp with time zone DEFAULT now() NOT NULL,
+  last_error text,
+  created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS scan_queue_next_attempt_at_idx ON storage.scan_queue (next_attempt_at);
@@ -0,0 +1,3 @@
+ALTER TABLE storage.buckets
+  DROP COLUMN IF EXISTS allowed_mime_types,
+  DROP COLUMN IF EXISTS denied_mime_types;
@@ -0,0 +1,3 @@
+ALTER TABLE storage.buckets
+  ADD COLUMN IF NOT EXISTS allowed_mime_types text[] DEFAULT '{}' NOT NULL,
+  ADD COLUMN IF NOT EXISTS denied_mime_types text[] DEFAULT '{}' NOT NULL;
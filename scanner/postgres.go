@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nhost/hasura-storage/api"
+)
+
+// Postgres implements Store with the storage.scan_queue table. Jobs are claimed with
+// SKIP LOCKED so any number of replicas can scan files. The id of a job changes every
+// time its file is enqueued, so results of scans that are no longer relevant are
+// discarded.
+type Postgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgres(ctx context.Context, connString string) (*Postgres, error) {
+	pool, err := pgxpool.New(ctx, connString)
+	if err != nil {
+		return nil, fmt.Errorf("problem creating postgres pool: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("problem connecting to postgres: %w", err)
+	}
+
+	return &Postgres{pool: pool}, nil
+}
+
+func (p *Postgres) Close() {
+	p.pool.Close()
+}
+
+func (p *Postgres) Enqueue(ctx context.Context, fileID string) error {
+	batch := &pgx.Batch{} //nolint:exhaustruct
+	batch.Queue(
+		`INSERT INTO storage.scan_queue (file_id) VALUES ($1)
+		ON CONFLICT (file_id) DO UPDATE SET id = public.gen_random_uuid(), attempts = 0,
+			next_attempt_at = now(), last_error = NULL`,
+		fileID,
+	)
+	batch.Queue(
+		`UPDATE storage.files SET scan_status = $2 WHERE id = $1`,
+		fileID, string(api.Pending),
+	)
+
+	// a batch runs in an implicit transaction, the file is only pending if it is queued
+	if err := p.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("problem enqueueing file to scan: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) Claim(ctx context.Context, limit int, lease time.Duration) ([]Job, error) {
+	rows, err := p.pool.Query(
+		ctx,
+		`UPDATE storage.scan_queue
+		SET attempts = attempts + 1, next_attempt_at = now() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT q.id FROM storage.scan_queue q
+			JOIN storage.files f ON f.id = q.file_id
+			WHERE f.is_uploaded AND q.next_attempt_at <= now()
+			ORDER BY q.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF q SKIP LOCKED
+		)
+		RETURNING id, file_id, attempts`,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("problem claiming files to scan: %w", err)
+	}
+
+	jobs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Job, error) {
+		var j Job
+		err := row.Scan(&j.ID, &j.FileID, &j.Attempts)
+
+		return j, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, fmt.Errorf("problem reading files to scan: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (p *Postgres) Finish(ctx context.Context, id string, status api.ScanStatus) (bool, error) {
+	tag, err := p.pool.Exec(
+		ctx,
+		`WITH job AS (DELETE FROM storage.scan_queue WHERE id = $1 RETURNING file_id)
+		UPDATE storage.files SET scan_status = $2 WHERE id = (SELECT file_id FROM job)`,
+		id, string(status),
+	)
+	if err != nil {
+		return false, fmt.Errorf("problem recording scan status: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (p *Postgres) Retry(ctx context.Context, id string, after time.Duration, lastErr string) error {
+	if _, err := p.pool.Exec(
+		ctx,
+		`UPDATE storage.scan_queue
+		SET next_attempt_at = now() + make_interval(secs => $2), last_error = $3
+		WHERE id = $1`,
+		id, after.Seconds(), lastErr,
+	); err != nil {
+		return fmt.Errorf("problem scheduling scan retry: %w", err)
+	}
+
+	return nil
+}
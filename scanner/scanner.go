@@ -0,0 +1,190 @@
+package scanner
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pollInterval = 5 * time.Second
+	scanTimeout  = 10 * time.Minute
+	// jobs are claimed for this long, if the replica goes away before finishing them
+	// another one picks them up afterwards
+	claimLease = 2 * scanTimeout
+
+	maxAttempts = 5
+	minBackoff  = 30 * time.Second
+	maxBackoff  = time.Hour
+)
+
+// Job is a file pending to be scanned for viruses.
+type Job struct {
+	ID       string
+	FileID   string
+	Attempts int
+}
+
+// Store is the queue of files pending to be scanned.
+type Store interface {
+	// Enqueue flags the file as pending to be scanned. Enqueueing a file that is already
+	// queued schedules it again from scratch.
+	Enqueue(ctx context.Context, fileID string) error
+	// Claim returns up to limit jobs due whose file is uploaded, they aren't returned
+	// again by other calls until the lease expires. Claiming a job counts as an attempt.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Job, error)
+	// Finish records the scan status of the file and removes the job. It returns false,
+	// discarding the status, if the file was enqueued again after the job was claimed.
+	Finish(ctx context.Context, id string, status api.ScanStatus) (bool, error)
+	Retry(ctx context.Context, id string, after time.Duration, lastErr string) error
+}
+
+// Files scans and quarantines files, it is implemented by controller.Controller.
+type Files interface {
+	ScanFile(ctx context.Context, fileID string) (string, *controller.APIError)
+	QuarantineFile(ctx context.Context, fileID, virus string) *controller.APIError
+}
+
+// backoff returns how long to wait before the next attempt, doubling after each one.
+func backoff(attempts int) time.Duration {
+	d := minBackoff
+	for range attempts - 1 {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	return d
+}
+
+// Scanner implements controller.ScanQueue. Files are scanned in the background once
+// they are uploaded by a pool of workers, infected files are moved to quarantine. Files
+// that can't be scanned are retried with an exponential backoff before giving up.
+type Scanner struct {
+	store   Store
+	workers int
+	logger  logrus.FieldLogger
+}
+
+func New(store Store, workers int, logger logrus.FieldLogger) *Scanner {
+	return &Scanner{
+		store:   store,
+		workers: max(workers, 1),
+		logger:  logger,
+	}
+}
+
+func (s *Scanner) Enqueue(ctx context.Context, fileID string) *controller.APIError {
+	if err := s.store.Enqueue(ctx, fileID); err != nil {
+		return controller.InternalServerError(err)
+	}
+
+	return nil
+}
+
+// Start scans the pending files in the background until the context is cancelled, the
+// returned function waits until the scans in progress finish.
+func (s *Scanner) Start(ctx context.Context, files Files) func() {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			s.ScanPending(ctx, files)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() { <-done }
+}
+
+// ScanPending scans the files that are due until there are none left, one per worker
+// at a time.
+func (s *Scanner) ScanPending(ctx context.Context, files Files) {
+	for ctx.Err() == nil {
+		jobs, err := s.store.Claim(ctx, s.workers, claimLease)
+		if err != nil {
+			s.logger.WithError(err).Error("problem claiming files to scan")
+			return
+		}
+
+		wg := sync.WaitGroup{}
+		for _, job := range jobs {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+				s.scan(ctx, files, job)
+			}()
+		}
+
+		wg.Wait()
+
+		if len(jobs) < s.workers {
+			return
+		}
+	}
+}
+
+func (s *Scanner) scan(ctx context.Context, files Files, job Job) {
+	logger := s.logger.WithFields(logrus.Fields{
+		"file":    job.FileID,
+		"job":     job.ID,
+		"attempt": job.Attempts,
+	})
+
+	// the outcome is recorded even if we are shutting down
+	storeCtx := context.WithoutCancel(ctx)
+
+	scanCtx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+
+	virus, scanErr := files.ScanFile(scanCtx, job.FileID)
+
+	var err error
+
+	switch {
+	case scanErr != nil && job.Attempts >= maxAttempts:
+		logger.WithError(scanErr).Error("problem scanning file, giving up")
+
+		_, err = s.store.Finish(storeCtx, job.ID, api.Error)
+	case scanErr != nil:
+		logger.WithError(scanErr).Warn("problem scanning file, retrying later")
+
+		err = s.store.Retry(storeCtx, job.ID, backoff(job.Attempts), scanErr.Error())
+	case virus != "":
+		logger.WithField("virus", virus).Warn("virus found, moving file to quarantine")
+
+		// the file is flagged first so it can't be downloaded even if moving it fails
+		var current bool
+
+		current, err = s.store.Finish(storeCtx, job.ID, api.Infected)
+		if err == nil && current {
+			if apiErr := files.QuarantineFile(storeCtx, job.FileID, virus); apiErr != nil {
+				logger.WithError(apiErr).Error("problem moving file to quarantine")
+			}
+		}
+	default:
+		logger.Debug("file is clean")
+
+		_, err = s.store.Finish(storeCtx, job.ID, api.Clean)
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("problem recording scan result")
+	}
+}
@@ -0,0 +1,176 @@
+package scanner_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/scanner"
+	"github.com/sirupsen/logrus"
+)
+
+type fakeStore struct {
+	mu       sync.Mutex
+	pending  []scanner.Job
+	stale    map[string]bool
+	finished map[string]api.ScanStatus
+	retried  []string
+}
+
+func newFakeStore(jobs ...scanner.Job) *fakeStore {
+	return &fakeStore{
+		mu:       sync.Mutex{},
+		pending:  jobs,
+		stale:    map[string]bool{},
+		finished: map[string]api.ScanStatus{},
+		retried:  nil,
+	}
+}
+
+func (s *fakeStore) Enqueue(_ context.Context, fileID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = append(s.pending, scanner.Job{ID: "job-" + fileID, FileID: fileID, Attempts: 0})
+
+	return nil
+}
+
+func (s *fakeStore) Claim(_ context.Context, limit int, _ time.Duration) ([]scanner.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := min(limit, len(s.pending))
+	claimed := s.pending[:n]
+	s.pending = s.pending[n:]
+
+	for i := range claimed {
+		claimed[i].Attempts++
+	}
+
+	return claimed, nil
+}
+
+func (s *fakeStore) Finish(_ context.Context, id string, status api.ScanStatus) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stale[id] {
+		return false, nil
+	}
+
+	s.finished[id] = status
+
+	return true, nil
+}
+
+func (s *fakeStore) Retry(_ context.Context, id string, _ time.Duration, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.retried = append(s.retried, id)
+
+	return nil
+}
+
+type fakeFiles struct {
+	mu          sync.Mutex
+	viruses     map[string]string
+	broken      map[string]bool
+	quarantined []string
+}
+
+func (f *fakeFiles) ScanFile(_ context.Context, fileID string) (string, *controller.APIError) {
+	if f.broken[fileID] {
+		return "", controller.InternalServerError(errors.New("clamd is down")) //nolint:err113
+	}
+
+	return f.viruses[fileID], nil
+}
+
+func (f *fakeFiles) QuarantineFile(_ context.Context, fileID, _ string) *controller.APIError {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.quarantined = append(f.quarantined, fileID)
+
+	return nil
+}
+
+func TestEnqueue(t *testing.T) {
+	t.Parallel()
+
+	store := newFakeStore()
+	s := scanner.New(store, 1, logrus.New())
+
+	if apiErr := s.Enqueue(t.Context(), "some-file"); apiErr != nil {
+		t.Fatalf("unexpected error: %v", apiErr)
+	}
+
+	if diff := cmp.Diff(
+		[]scanner.Job{{ID: "job-some-file", FileID: "some-file", Attempts: 0}},
+		store.pending,
+	); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestScanPending(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel + 1)
+
+	store := newFakeStore(
+		scanner.Job{ID: "clean", FileID: "clean-file", Attempts: 0},
+		scanner.Job{ID: "infected", FileID: "infected-file", Attempts: 0},
+		scanner.Job{ID: "replaced", FileID: "replaced-file", Attempts: 0},
+		scanner.Job{ID: "broken", FileID: "broken-file", Attempts: 0},
+		scanner.Job{ID: "given-up", FileID: "given-up-file", Attempts: 4},
+	)
+	store.stale["replaced"] = true
+
+	files := &fakeFiles{
+		mu: sync.Mutex{},
+		viruses: map[string]string{
+			"infected-file": "Eicar-Signature",
+			"replaced-file": "Eicar-Signature",
+		},
+		broken:      map[string]bool{"broken-file": true, "given-up-file": true},
+		quarantined: nil,
+	}
+
+	scanner.New(store, 2, logger).ScanPending(t.Context(), files)
+
+	if len(store.pending) != 0 {
+		t.Errorf("expected all the jobs to be claimed, %d left", len(store.pending))
+	}
+
+	if diff := cmp.Diff(
+		map[string]api.ScanStatus{
+			"clean":    api.Clean,
+			"infected": api.Infected,
+			"given-up": api.Error,
+		},
+		store.finished,
+	); diff != "" {
+		t.Error(diff)
+	}
+
+	if diff := cmp.Diff([]string{"broken"}, store.retried); diff != "" {
+		t.Error(diff)
+	}
+
+	slices.Sort(files.quarantined)
+
+	// the replaced file is scanned again with its new content, it isn't quarantined
+	if diff := cmp.Diff([]string{"infected-file"}, files.quarantined); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockPrefix namespaces the advisory locks so they don't clash with locks taken by
+// other applications sharing the database.
+const lockPrefix = "hasura-storage."
+
+// Postgres implements Store using advisory locks to elect the replica that runs a job
+// and keeps the history in storage.job_runs.
+type Postgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgres(ctx context.Context, connString string) (*Postgres, error) {
+	pool, err := pgxpool.New(ctx, connString)
+	if err != nil {
+		return nil, fmt.Errorf("problem creating postgres pool: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("problem connecting to postgres: %w", err)
+	}
+
+	return &Postgres{pool: pool}, nil
+}
+
+func (p *Postgres) Close() {
+	p.pool.Close()
+}
+
+// Lock takes a session level advisory lock, the connection is kept out of the pool
+// until the lock is released so it isn't released early by another query.
+func (p *Postgres) Lock(ctx context.Context, job string) (func(), bool, error) {
+	conn, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("problem acquiring connection: %w", err)
+	}
+
+	var ok bool
+	if err := conn.QueryRow(
+		ctx, "SELECT pg_try_advisory_lock(hashtext($1))", lockPrefix+job,
+	).Scan(&ok); err != nil {
+		conn.Release()
+		return nil, false, fmt.Errorf("problem taking advisory lock: %w", err)
+	}
+
+	if !ok {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		defer conn.Release()
+
+		if _, err := conn.Exec(
+			context.WithoutCancel(ctx), "SELECT pg_advisory_unlock(hashtext($1))", lockPrefix+job,
+		); err != nil {
+			// closing the connection is the only way left to release the lock
+			_ = conn.Conn().Close(context.WithoutCancel(ctx))
+		}
+	}
+
+	return unlock, true, nil
+}
+
+func (p *Postgres) LastRun(ctx context.Context, job string) (time.Time, error) {
+	var startedAt time.Time
+
+	err := p.pool.QueryRow(
+		ctx,
+		"SELECT started_at FROM storage.job_runs WHERE job = $1 ORDER BY started_at DESC LIMIT 1",
+		job,
+	).Scan(&startedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, nil
+	}
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("problem querying last run: %w", err)
+	}
+
+	return startedAt, nil
+}
+
+func (p *Postgres) StartRun(ctx context.Context, job string) (Run, error) {
+	run := Run{Job: job} //nolint:exhaustruct
+
+	if err := p.pool.QueryRow(
+		ctx,
+		"INSERT INTO storage.job_runs (job) VALUES ($1) RETURNING id, started_at",
+		job,
+	).Scan(&run.ID, &run.StartedAt); err != nil {
+		return Run{}, fmt.Errorf("problem inserting job run: %w", err)
+	}
+
+	return run, nil
+}
+
+func (p *Postgres) EndRun(ctx context.Context, run Run) error {
+	itemErrors := run.Errors
+	if itemErrors == nil {
+		itemErrors = []string{}
+	}
+
+	b, err := json.Marshal(itemErrors)
+	if err != nil {
+		return fmt.Errorf("problem marshalling errors: %w", err)
+	}
+
+	var runErr *string
+	if run.Error != "" {
+		runErr = &run.Error
+	}
+
+	if _, err := p.pool.Exec(
+		ctx,
+		`UPDATE storage.job_runs
+		SET ended_at = $2, items_affected = $3, errors = $4, error = $5
+		WHERE id = $1`,
+		run.ID, run.EndedAt, run.ItemsAffected, b, runErr,
+	); err != nil {
+		return fmt.Errorf("problem updating job run: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxCheckInterval is how often, at most, replicas check if a job is due. Jobs run
+// once per interval across all replicas, checking more often than the interval makes
+// sure a job still runs on time if the replica that ran it last goes away.
+const maxCheckInterval = time.Minute
+
+// Job is a task that runs periodically in one replica at a time.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	// Run does the job and returns how many items it affected and the problems found
+	// with individual items; err is for problems that stopped the job.
+	Run func(ctx context.Context) (itemsAffected int, itemErrors []string, err error)
+}
+
+// Run is the record of a job run kept in the history.
+type Run struct {
+	ID            string
+	Job           string
+	StartedAt     time.Time
+	EndedAt       *time.Time
+	ItemsAffected int
+	Errors        []string
+	Error         string
+}
+
+// Store elects which replica runs a job and keeps the history of the runs.
+type Store interface {
+	// Lock tries to take the lock of a job, ok is false if another replica holds it.
+	// Unlock must be called when the job is done.
+	Lock(ctx context.Context, job string) (unlock func(), ok bool, err error)
+	// LastRun returns when the job last started, zero if it never ran.
+	LastRun(ctx context.Context, job string) (time.Time, error)
+	StartRun(ctx context.Context, job string) (Run, error)
+	EndRun(ctx context.Context, run Run) error
+}
+
+type Scheduler struct {
+	store  Store
+	jobs   []Job
+	logger logrus.FieldLogger
+	now    func() time.Time
+}
+
+func New(store Store, jobs []Job, logger logrus.FieldLogger) *Scheduler {
+	return &Scheduler{
+		store:  store,
+		jobs:   jobs,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Start runs the jobs in the background until the context is cancelled, the returned
+// function waits until the jobs in progress finish.
+func (s *Scheduler) Start(ctx context.Context) func() {
+	wg := sync.WaitGroup{}
+
+	for _, job := range s.jobs {
+		s.logger.WithFields(logrus.Fields{
+			"job":      job.Name,
+			"interval": job.Interval.String(),
+		}).Info("scheduling job")
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}()
+	}
+
+	return wg.Wait
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(min(job.Interval, maxCheckInterval))
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunIfDue(ctx, job); err != nil {
+			s.logger.WithError(err).WithField("job", job.Name).Error("problem running job")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunIfDue runs the job if no other replica is running it and it didn't run within its
+// interval, it returns if the job ran.
+func (s *Scheduler) RunIfDue(ctx context.Context, job Job) (bool, error) {
+	unlock, ok, err := s.store.Lock(ctx, job.Name)
+	if err != nil {
+		return false, fmt.Errorf("problem taking lock: %w", err)
+	}
+
+	if !ok {
+		return false, nil
+	}
+	defer unlock()
+
+	lastRun, err := s.store.LastRun(ctx, job.Name)
+	if err != nil {
+		return false, fmt.Errorf("problem getting last run: %w", err)
+	}
+
+	if !lastRun.IsZero() && s.now().Sub(lastRun) < job.Interval {
+		return false, nil
+	}
+
+	run, err := s.store.StartRun(ctx, job.Name)
+	if err != nil {
+		return false, fmt.Errorf("problem recording job start: %w", err)
+	}
+
+	logger := s.logger.WithFields(logrus.Fields{"job": job.Name, "run": run.ID})
+	logger.Info("running job")
+
+	itemsAffected, itemErrors, jobErr := job.Run(ctx)
+
+	endedAt := s.now()
+	run.EndedAt = &endedAt
+	run.ItemsAffected = itemsAffected
+	run.Errors = itemErrors
+
+	logger = logger.WithFields(logrus.Fields{
+		"items_affected": itemsAffected,
+		"errors":         len(itemErrors),
+		"duration":       endedAt.Sub(run.StartedAt).String(),
+	})
+
+	if jobErr != nil {
+		run.Error = jobErr.Error()
+		logger.WithError(jobErr).Error("job failed")
+	} else {
+		logger.Info("job finished")
+	}
+
+	// the job is recorded even if the context was cancelled while it was running
+	if err := s.store.EndRun(context.WithoutCancel(ctx), run); err != nil {
+		return true, fmt.Errorf("problem recording job end: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,158 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/nhost/hasura-storage/scheduler"
+	"github.com/sirupsen/logrus"
+)
+
+type fakeStore struct {
+	lockedElsewhere bool
+	lastRun         time.Time
+	locked          bool
+	runs            []scheduler.Run
+}
+
+func (s *fakeStore) Lock(_ context.Context, _ string) (func(), bool, error) {
+	if s.lockedElsewhere {
+		return nil, false, nil
+	}
+
+	s.locked = true
+
+	return func() { s.locked = false }, true, nil
+}
+
+func (s *fakeStore) LastRun(_ context.Context, _ string) (time.Time, error) {
+	return s.lastRun, nil
+}
+
+func (s *fakeStore) StartRun(_ context.Context, job string) (scheduler.Run, error) {
+	return scheduler.Run{ //nolint:exhaustruct
+		ID:        "some-run",
+		Job:       job,
+		StartedAt: time.Now(),
+	}, nil
+}
+
+func (s *fakeStore) EndRun(_ context.Context, run scheduler.Run) error {
+	s.runs = append(s.runs, run)
+	return nil
+}
+
+func TestRunIfDue(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	cases := []struct {
+		name         string
+		store        *fakeStore
+		run          func(ctx context.Context) (int, []string, error)
+		expectedRan  bool
+		expectedRuns []scheduler.Run
+	}{
+		{
+			name:  "never ran",
+			store: &fakeStore{}, //nolint:exhaustruct
+			run: func(_ context.Context) (int, []string, error) {
+				return 3, []string{"problem with some-file"}, nil
+			},
+			expectedRan: true,
+			expectedRuns: []scheduler.Run{
+				{ //nolint:exhaustruct
+					ID:            "some-run",
+					Job:           "some-job",
+					ItemsAffected: 3,
+					Errors:        []string{"problem with some-file"},
+				},
+			},
+		},
+		{
+			name: "ran long ago",
+			store: &fakeStore{ //nolint:exhaustruct
+				lastRun: time.Now().Add(-2 * time.Hour),
+			},
+			run: func(_ context.Context) (int, []string, error) {
+				return 0, nil, errors.New("some error") //nolint:err113
+			},
+			expectedRan: true,
+			expectedRuns: []scheduler.Run{
+				{ //nolint:exhaustruct
+					ID:    "some-run",
+					Job:   "some-job",
+					Error: "some error",
+				},
+			},
+		},
+		{
+			name: "ran recently",
+			store: &fakeStore{ //nolint:exhaustruct
+				lastRun: time.Now().Add(-time.Minute),
+			},
+			run: func(_ context.Context) (int, []string, error) {
+				t.Error("job shouldn't run")
+				return 0, nil, nil
+			},
+			expectedRan:  false,
+			expectedRuns: nil,
+		},
+		{
+			name: "locked by another replica",
+			store: &fakeStore{ //nolint:exhaustruct
+				lockedElsewhere: true,
+			},
+			run: func(_ context.Context) (int, []string, error) {
+				t.Error("job shouldn't run")
+				return 0, nil, nil
+			},
+			expectedRan:  false,
+			expectedRuns: nil,
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			job := scheduler.Job{
+				Name:     "some-job",
+				Interval: time.Hour,
+				Run:      tc.run,
+			}
+
+			s := scheduler.New(tc.store, []scheduler.Job{job}, logger)
+
+			ran, err := s.RunIfDue(t.Context(), job)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if ran != tc.expectedRan {
+				t.Errorf("expected ran to be %t, got %t", tc.expectedRan, ran)
+			}
+
+			if tc.store.locked {
+				t.Error("lock wasn't released")
+			}
+
+			opts := cmpopts.IgnoreFields(scheduler.Run{}, "StartedAt", "EndedAt") //nolint:exhaustruct
+			if diff := cmp.Diff(tc.expectedRuns, tc.store.runs, opts); diff != "" {
+				t.Error(diff)
+			}
+
+			for _, run := range tc.store.runs {
+				if run.EndedAt == nil {
+					t.Error("run end wasn't recorded")
+				}
+			}
+		})
+	}
+}
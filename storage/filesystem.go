@@ -0,0 +1,644 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5" //nolint:gosec
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// files' metadata (content type, etag) is stored in this folder inside the root folder.
+	filesystemMetadataFolder = ".metadata"
+	filesystemTmpPrefix      = ".tmp-"
+	filesystemSignAlgorithm  = "NHOST-HMAC-SHA256"
+	filesystemCredential     = "filesystem"
+	amzDateFormat            = "20060102T150405Z"
+)
+
+type filesystemMetadata struct {
+	ContentType string `json:"contentType"`
+	Etag        string `json:"etag"`
+}
+
+// Filesystem stores files in a folder of the local filesystem. Presigned URLs
+// are signed with an HMAC key and verified locally.
+type Filesystem struct {
+	rootFolder string
+	signingKey []byte
+	logger     *logrus.Logger
+	// conditionalWrites serializes PutFileIfMatch so the etag can't change between
+	// checking and replacing the file.
+	conditionalWrites sync.Mutex
+}
+
+func NewFilesystem(
+	rootFolder string,
+	signingKey []byte,
+	logger *logrus.Logger,
+) (*Filesystem, error) {
+	if len(signingKey) == 0 {
+		return nil, errors.New("a signing key is required to sign presigned URLs") //nolint:err113
+	}
+
+	if err := os.MkdirAll(
+		filepath.Join(rootFolder, filesystemMetadataFolder), 0o750, //nolint:mnd
+	); err != nil {
+		return nil, fmt.Errorf("problem creating root folder: %w", err)
+	}
+
+	return &Filesystem{
+		rootFolder:        rootFolder,
+		signingKey:        signingKey,
+		logger:            logger,
+		conditionalWrites: sync.Mutex{},
+	}, nil
+}
+
+func (f *Filesystem) paths(filePath string) (string, string, *controller.APIError) {
+	if !filepath.IsLocal(filePath) ||
+		strings.HasPrefix(filePath, filesystemMetadataFolder) ||
+		strings.HasPrefix(filepath.Base(filePath), filesystemTmpPrefix) {
+		return "", "", controller.BadDataError(
+			fmt.Errorf("invalid file path: %s", filePath), //nolint:err113
+			"invalid file path",
+		)
+	}
+
+	return filepath.Join(f.rootFolder, filePath),
+		filepath.Join(f.rootFolder, filesystemMetadataFolder, filePath+".json"),
+		nil
+}
+
+// writeFileAtomically writes content to a temporary file and moves it into place. If
+// validate is set it is called once all the content is written, the file is discarded
+// if it returns an error.
+func writeFileAtomically(path string, content io.Reader, validate func() error) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil { //nolint:mnd
+		return 0, fmt.Errorf("problem creating folder: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filesystemTmpPrefix)
+	if err != nil {
+		return 0, fmt.Errorf("problem creating temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	n, err := io.Copy(tmp, content)
+	if err != nil {
+		return 0, fmt.Errorf("problem writing file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("problem closing file: %w", err)
+	}
+
+	if validate != nil {
+		if err := validate(); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("problem moving file into place: %w", err)
+	}
+
+	return n, nil
+}
+
+func (f *Filesystem) putFile(
+	content io.Reader,
+	filePath string,
+	contentType string,
+	validate func() *controller.APIError,
+) (string, *controller.APIError) {
+	path, metadataPath, apiErr := f.paths(filePath)
+	if apiErr != nil {
+		return "", apiErr
+	}
+
+	var validateErr *controller.APIError
+
+	hash := md5.New() //nolint:gosec
+	if _, err := writeFileAtomically(path, io.TeeReader(content, hash), func() error {
+		if validate == nil {
+			return nil
+		}
+
+		validateErr = validate()
+		if validateErr != nil {
+			return validateErr
+		}
+
+		return nil
+	}); err != nil {
+		if validateErr != nil {
+			return "", validateErr
+		}
+
+		return "", controller.InternalServerError(err)
+	}
+
+	metadata := filesystemMetadata{
+		ContentType: contentType,
+		Etag:        `"` + hex.EncodeToString(hash.Sum(nil)) + `"`,
+	}
+
+	b, err := json.Marshal(metadata)
+	if err != nil {
+		return "", controller.InternalServerError(
+			fmt.Errorf("problem marshalling file metadata: %w", err),
+		)
+	}
+
+	if _, err := writeFileAtomically(
+		metadataPath, strings.NewReader(string(b)), nil,
+	); err != nil {
+		return "", controller.InternalServerError(err)
+	}
+
+	return metadata.Etag, nil
+}
+
+func (f *Filesystem) PutFile(
+	_ context.Context,
+	content io.ReadSeeker,
+	filePath string,
+	contentType string,
+) (string, *controller.APIError) {
+	// let's make sure we are in the beginning of the content
+	if _, err := content.Seek(0, 0); err != nil {
+		return "", controller.InternalServerError(
+			fmt.Errorf("problem going to the beginning of the content: %w", err),
+		)
+	}
+
+	return f.putFile(content, filePath, contentType, nil)
+}
+
+func (f *Filesystem) PutFileIfMatch(
+	ctx context.Context,
+	content io.ReadSeeker,
+	filePath string,
+	contentType string,
+	etag string,
+) (string, *controller.APIError) {
+	_, metadataPath, apiErr := f.paths(filePath)
+	if apiErr != nil {
+		return "", apiErr
+	}
+
+	f.conditionalWrites.Lock()
+	defer f.conditionalWrites.Unlock()
+
+	metadata, err := f.getMetadata(metadataPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if etag != "" {
+			return "", controller.ErrFileModified
+		}
+	case err != nil:
+		return "", controller.InternalServerError(err)
+	case metadata.Etag != etag:
+		return "", controller.ErrFileModified
+	}
+
+	return f.PutFile(ctx, content, filePath, contentType)
+}
+
+func (f *Filesystem) PutFileStream(
+	_ context.Context,
+	content io.Reader,
+	filePath string,
+	contentType string,
+	validate func() *controller.APIError,
+) (string, *controller.APIError) {
+	return f.putFile(content, filePath, contentType, validate)
+}
+
+func (f *Filesystem) getMetadata(metadataPath string) (filesystemMetadata, error) {
+	b, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return filesystemMetadata{}, fmt.Errorf("problem reading file metadata: %w", err)
+	}
+
+	var metadata filesystemMetadata
+	if err := json.Unmarshal(b, &metadata); err != nil {
+		return filesystemMetadata{}, fmt.Errorf("problem parsing file metadata: %w", err)
+	}
+
+	return metadata, nil
+}
+
+// parseRange parses a Range header of the form bytes=start-end. Like S3, only
+// a single range is supported.
+func parseRange(downloadRange string, size int64) (int64, int64, *controller.APIError) {
+	invalidRange := controller.NewAPIError(
+		http.StatusRequestedRangeNotSatisfiable,
+		"The requested range is not satisfiable",
+		fmt.Errorf("invalid range %s for size %d", downloadRange, size), //nolint:err113
+		nil,
+	)
+
+	spec, ok := strings.CutPrefix(downloadRange, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, 0, invalidRange
+	}
+
+	startStr, endStr, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, 0, invalidRange
+	}
+
+	var (
+		start, end, n int64
+		err           error
+	)
+
+	switch {
+	case startStr == "":
+		// suffix range, last N bytes
+		n, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, invalidRange
+		}
+
+		start = max(size-n, 0)
+		end = size - 1
+	default:
+		start, err = strconv.ParseInt(startStr, 10, 64)
+		if err != nil {
+			return 0, 0, invalidRange
+		}
+
+		end = size - 1
+		if endStr != "" {
+			end, err = strconv.ParseInt(endStr, 10, 64)
+			if err != nil {
+				return 0, 0, invalidRange
+			}
+
+			end = min(end, size-1)
+		}
+	}
+
+	if start < 0 || start >= size || start > end {
+		return 0, 0, invalidRange
+	}
+
+	return start, end, nil
+}
+
+type sectionReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func (f *Filesystem) GetFile(
+	_ context.Context,
+	filePath string,
+	downloadRange *string,
+) (*controller.File, *controller.APIError) {
+	path, metadataPath, apiErr := f.paths(filePath)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, controller.ErrFileNotFound
+	}
+
+	if err != nil {
+		return nil, controller.InternalServerError(fmt.Errorf("problem opening file: %w", err))
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, controller.InternalServerError(fmt.Errorf("problem reading file info: %w", err))
+	}
+
+	metadata, err := f.getMetadata(metadataPath)
+	if err != nil {
+		file.Close()
+		return nil, controller.InternalServerError(err)
+	}
+
+	if downloadRange == nil {
+		return &controller.File{
+			ContentType:   metadata.ContentType,
+			ContentLength: stat.Size(),
+			Etag:          metadata.Etag,
+			LastModified:  stat.ModTime(),
+			StatusCode:    http.StatusOK,
+			Body:          file,
+			ExtraHeaders:  make(http.Header),
+		}, nil
+	}
+
+	start, end, apiErr := parseRange(*downloadRange, stat.Size())
+	if apiErr != nil {
+		file.Close()
+		return nil, apiErr
+	}
+
+	return &controller.File{
+		ContentType:   metadata.ContentType,
+		ContentLength: end - start + 1,
+		Etag:          metadata.Etag,
+		LastModified:  stat.ModTime(),
+		StatusCode:    http.StatusPartialContent,
+		Body: sectionReadCloser{
+			Reader: io.NewSectionReader(file, start, end-start+1),
+			Closer: file,
+		},
+		ExtraHeaders: http.Header{
+			"Accept-Ranges": []string{"bytes"},
+			"Content-Range": []string{fmt.Sprintf("bytes %d-%d/%d", start, end, stat.Size())},
+		},
+	}, nil
+}
+
+func (f *Filesystem) sign(filePath, date, expires string) string {
+	mac := hmac.New(sha256.New, f.signingKey)
+	mac.Write([]byte(filePath + "\n" + date + "\n" + expires))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CreatePresignedURL returns a query string that mimics the shape of S3's
+// presigned URLs so the same endpoint can serve both backends.
+func (f *Filesystem) CreatePresignedURL(
+	_ context.Context,
+	filePath string,
+	expire time.Duration,
+) (string, *controller.APIError) {
+	if _, _, apiErr := f.paths(filePath); apiErr != nil {
+		return "", apiErr
+	}
+
+	date := time.Now().UTC().Format(amzDateFormat)
+	expires := strconv.Itoa(int(expire.Seconds()))
+
+	values := url.Values{}
+	values.Set("X-Amz-Algorithm", filesystemSignAlgorithm)
+	values.Set("X-Amz-Credential", filesystemCredential)
+	values.Set("X-Amz-Date", date)
+	values.Set("X-Amz-Expires", expires)
+	values.Set("X-Amz-SignedHeaders", "host")
+	values.Set("X-Amz-Checksum-Mode", "ENABLED")
+	values.Set("x-id", "GetObject")
+	values.Set("X-Amz-Signature", f.sign(filePath, date, expires))
+
+	return values.Encode(), nil
+}
+
+func (f *Filesystem) verifySignature(filePath, signature string) *controller.APIError {
+	values, err := url.ParseQuery(signature)
+	if err != nil {
+		return controller.BadDataError(err, "problem parsing signature")
+	}
+
+	date := values.Get("X-Amz-Date")
+	expires := values.Get("X-Amz-Expires")
+
+	expected := f.sign(filePath, date, expires)
+	if values.Get("X-Amz-Algorithm") != filesystemSignAlgorithm ||
+		!hmac.Equal([]byte(expected), []byte(values.Get("X-Amz-Signature"))) {
+		return controller.ForbiddenError(
+			errors.New("signature does not match"), //nolint:err113
+			"The request signature we calculated does not match the signature you provided.",
+		)
+	}
+
+	signedAt, err := time.Parse(amzDateFormat, date)
+	if err != nil {
+		return controller.BadDataError(err, "problem parsing X-Amz-Date")
+	}
+
+	expiresIn, err := strconv.Atoi(expires)
+	if err != nil {
+		return controller.BadDataError(err, "problem parsing X-Amz-Expires")
+	}
+
+	if time.Since(signedAt) > time.Duration(expiresIn)*time.Second {
+		return controller.ForbiddenError(
+			errors.New("signature expired"), //nolint:err113
+			"Request has expired",
+		)
+	}
+
+	return nil
+}
+
+func (f *Filesystem) GetFileWithPresignedURL(
+	ctx context.Context, filePath, signature string, headers http.Header,
+) (*controller.File, *controller.APIError) {
+	if apiErr := f.verifySignature(filePath, signature); apiErr != nil {
+		return nil, apiErr
+	}
+
+	var downloadRange *string
+	if r := headers.Get("Range"); r != "" {
+		downloadRange = &r
+	}
+
+	file, apiErr := f.GetFile(ctx, filePath, downloadRange)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if ifNoneMatch := headers.Get("If-None-Match"); ifNoneMatch != "" &&
+		ifNoneMatch == file.Etag {
+		file.Body.Close()
+
+		return &controller.File{
+			ContentType:   "",
+			ContentLength: 0,
+			Etag:          file.Etag,
+			LastModified:  file.LastModified,
+			StatusCode:    http.StatusNotModified,
+			Body:          io.NopCloser(strings.NewReader("")),
+			ExtraHeaders:  make(http.Header),
+		}, nil
+	}
+
+	if file.StatusCode == http.StatusOK {
+		file.ExtraHeaders.Set("Accept-Ranges", "bytes")
+	}
+
+	return file, nil
+}
+
+// CreatePresignedUpload isn't supported, there is nothing to upload to other than this
+// service so files need to be uploaded through it.
+func (f *Filesystem) CreatePresignedUpload(
+	_ context.Context,
+	_, _ string,
+	_, _ int,
+	_ time.Duration,
+) (*controller.PresignedUpload, *controller.APIError) {
+	err := errors.New( //nolint:err113
+		"presigned uploads are not supported by the filesystem storage",
+	)
+
+	return nil, controller.NewAPIError(http.StatusNotImplemented, err.Error(), err, nil)
+}
+
+func (f *Filesystem) HeadFile(
+	_ context.Context,
+	filePath string,
+) (*controller.File, *controller.APIError) {
+	path, metadataPath, apiErr := f.paths(filePath)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	stat, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, controller.ErrFileNotFound
+	}
+
+	if err != nil {
+		return nil, controller.InternalServerError(fmt.Errorf("problem reading file info: %w", err))
+	}
+
+	metadata, err := f.getMetadata(metadataPath)
+	if err != nil {
+		return nil, controller.InternalServerError(err)
+	}
+
+	return &controller.File{
+		ContentType:   metadata.ContentType,
+		ContentLength: stat.Size(),
+		Etag:          metadata.Etag,
+		LastModified:  stat.ModTime(),
+		StatusCode:    http.StatusOK,
+		Body:          http.NoBody,
+		ExtraHeaders:  make(http.Header),
+	}, nil
+}
+
+func (f *Filesystem) DeleteFile(_ context.Context, filePath string) *controller.APIError {
+	path, metadataPath, apiErr := f.paths(filePath)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	// like S3, deleting a file that doesn't exist is not an error
+	for _, p := range []string{path, metadataPath} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return controller.InternalServerError(fmt.Errorf("problem deleting file: %w", err))
+		}
+	}
+
+	return nil
+}
+
+func (f *Filesystem) MoveFile(
+	_ context.Context,
+	filePath, newFilePath string,
+) *controller.APIError {
+	path, metadataPath, apiErr := f.paths(filePath)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	newPath, newMetadataPath, apiErr := f.paths(newFilePath)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if err := os.MkdirAll(filepath.Dir(newPath), 0o750); err != nil { //nolint:mnd
+		return controller.InternalServerError(fmt.Errorf("problem creating folder: %w", err))
+	}
+
+	if err := os.Rename(path, newPath); errors.Is(err, fs.ErrNotExist) {
+		return controller.ErrFileNotFound
+	} else if err != nil {
+		return controller.InternalServerError(fmt.Errorf("problem moving file: %w", err))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(newMetadataPath), 0o750); err != nil { //nolint:mnd
+		return controller.InternalServerError(fmt.Errorf("problem creating folder: %w", err))
+	}
+
+	if err := os.Rename(metadataPath, newMetadataPath); err != nil &&
+		!errors.Is(err, fs.ErrNotExist) {
+		return controller.InternalServerError(
+			fmt.Errorf("problem moving file metadata: %w", err),
+		)
+	}
+
+	return nil
+}
+
+func (f *Filesystem) ListFiles(_ context.Context) iter.Seq2[string, *controller.APIError] {
+	return func(yield func(string, *controller.APIError) bool) {
+		err := filepath.WalkDir(f.rootFolder, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				if path == filepath.Join(f.rootFolder, filesystemMetadataFolder) {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			if strings.HasPrefix(d.Name(), filesystemTmpPrefix) {
+				return nil
+			}
+
+			rel, err := filepath.Rel(f.rootFolder, path)
+			if err != nil {
+				return err //nolint:wrapcheck
+			}
+
+			if !yield(filepath.ToSlash(rel), nil) {
+				return filepath.SkipAll
+			}
+
+			return nil
+		})
+		if err != nil {
+			yield("", controller.InternalServerError(fmt.Errorf("problem listing files: %w", err)))
+		}
+	}
+}
+
+// Ping checks the root folder is still accessible.
+func (f *Filesystem) Ping(_ context.Context) error {
+	info, err := os.Stat(filepath.Join(f.rootFolder, filesystemMetadataFolder))
+	if err != nil {
+		return fmt.Errorf("problem accessing root folder: %w", err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a folder", f.rootFolder) //nolint:err113
+	}
+
+	return nil
+}
@@ -0,0 +1,478 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/storage"
+	"github.com/sirupsen/logrus"
+)
+
+const sampleEtag = `"8ba761284b556cd234f73ec0b75fa054"`
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func listFiles(t *testing.T, seq iter.Seq2[string, *controller.APIError]) []string {
+	t.Helper()
+
+	files := make([]string, 0)
+	for f, apiErr := range seq {
+		if apiErr != nil {
+			t.Fatal(apiErr)
+		}
+
+		files = append(files, f)
+	}
+
+	return files
+}
+
+func getFilesystem(t *testing.T) *storage.Filesystem {
+	t.Helper()
+
+	fs, err := storage.NewFilesystem(t.TempDir(), []byte("signing-key"), logrus.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	etag, apiErr := fs.PutFile(
+		context.Background(), strings.NewReader("this is a sample\n"), "sample.txt", "text",
+	)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+
+	if etag != sampleEtag {
+		t.Fatalf("unexpected etag: %s", etag)
+	}
+
+	return fs
+}
+
+func TestFilesystemGetFile(t *testing.T) {
+	t.Parallel()
+
+	fs := getFilesystem(t)
+
+	cases := []struct {
+		name               string
+		filepath           string
+		downloadRange      *string
+		expected           *controller.File
+		expectedContent    string
+		expectedStatusCode int
+	}{
+		{
+			name:     "success",
+			filepath: "sample.txt",
+			expected: &controller.File{
+				ContentType:   "text",
+				ContentLength: 17,
+				Etag:          sampleEtag,
+				StatusCode:    http.StatusOK,
+				ExtraHeaders:  http.Header{},
+			},
+			expectedContent:    "this is a sample\n",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "range",
+			filepath:      "sample.txt",
+			downloadRange: ptr("bytes=5-6"),
+			expected: &controller.File{
+				ContentType:   "text",
+				ContentLength: 2,
+				Etag:          sampleEtag,
+				StatusCode:    http.StatusPartialContent,
+				ExtraHeaders: http.Header{
+					"Accept-Ranges": {"bytes"},
+					"Content-Range": {"bytes 5-6/17"},
+				},
+			},
+			expectedContent:    "is",
+			expectedStatusCode: http.StatusPartialContent,
+		},
+		{
+			name:          "suffix range",
+			filepath:      "sample.txt",
+			downloadRange: ptr("bytes=-7"),
+			expected: &controller.File{
+				ContentType:   "text",
+				ContentLength: 7,
+				Etag:          sampleEtag,
+				StatusCode:    http.StatusPartialContent,
+				ExtraHeaders: http.Header{
+					"Accept-Ranges": {"bytes"},
+					"Content-Range": {"bytes 10-16/17"},
+				},
+			},
+			expectedContent:    "sample\n",
+			expectedStatusCode: http.StatusPartialContent,
+		},
+		{
+			name:               "range not satisfiable",
+			filepath:           "sample.txt",
+			downloadRange:      ptr("bytes=100-"),
+			expectedStatusCode: http.StatusRequestedRangeNotSatisfiable,
+		},
+		{
+			name:               "file not found",
+			filepath:           "qwenmzxcxzcsadsad",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "path traversal",
+			filepath:           "../sample.txt",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, apiErr := fs.GetFile(context.Background(), tc.filepath, tc.downloadRange)
+
+			opts := cmp.Options{
+				cmpopts.IgnoreFields(controller.File{}, "Body", "LastModified"),
+			}
+			if !cmp.Equal(got, tc.expected, opts) {
+				t.Error(cmp.Diff(got, tc.expected, opts))
+			}
+
+			statusCode := 0
+			if got != nil {
+				statusCode = got.StatusCode
+				defer got.Body.Close()
+			}
+
+			if apiErr != nil {
+				statusCode = apiErr.StatusCode()
+			}
+
+			if statusCode != tc.expectedStatusCode {
+				t.Errorf("expected status code %d but got %d", tc.expectedStatusCode, statusCode)
+			}
+
+			if tc.expectedContent != "" {
+				b, err := io.ReadAll(got.Body)
+				if err != nil {
+					t.Error(err)
+				}
+
+				if !cmp.Equal(string(b), tc.expectedContent) {
+					t.Error(cmp.Diff(string(b), tc.expectedContent))
+				}
+			}
+		})
+	}
+}
+
+func TestFilesystemDeleteAndListFiles(t *testing.T) {
+	t.Parallel()
+
+	fs := getFilesystem(t)
+
+	if _, apiErr := fs.PutFile(
+		context.Background(), strings.NewReader("other"), "other.txt", "text",
+	); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+
+	got := listFiles(t, fs.ListFiles(context.Background()))
+	if diff := cmp.Diff([]string{"other.txt", "sample.txt"}, got); diff != "" {
+		t.Error(diff)
+	}
+
+	if apiErr := fs.DeleteFile(context.Background(), "sample.txt"); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+
+	if apiErr := fs.DeleteFile(context.Background(), "sample.txt"); apiErr != nil {
+		t.Errorf("deleting a missing file shouldn't fail: %v", apiErr)
+	}
+
+	got = listFiles(t, fs.ListFiles(context.Background()))
+	if diff := cmp.Diff([]string{"other.txt"}, got); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestFilesystemMoveFile(t *testing.T) {
+	t.Parallel()
+
+	fs := getFilesystem(t)
+
+	if apiErr := fs.MoveFile(
+		context.Background(), "sample.txt", "folder/sample.txt",
+	); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+
+	if _, apiErr := fs.GetFile(context.Background(), "sample.txt", nil); apiErr == nil ||
+		apiErr.StatusCode() != http.StatusNotFound {
+		t.Errorf("expected the original file to be gone, got %v", apiErr)
+	}
+
+	f, apiErr := fs.HeadFile(context.Background(), "folder/sample.txt")
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+
+	if f.ContentType != "text" {
+		t.Errorf("expected the metadata to be moved, got content type %q", f.ContentType)
+	}
+
+	if apiErr := fs.MoveFile(
+		context.Background(), "sample.txt", "folder/sample.txt",
+	); apiErr == nil || apiErr.StatusCode() != http.StatusNotFound {
+		t.Errorf("expected moving a missing file to fail, got %v", apiErr)
+	}
+}
+
+func TestFilesystemPutFileStream(t *testing.T) {
+	t.Parallel()
+
+	fs := getFilesystem(t)
+
+	etag, apiErr := fs.PutFileStream(
+		context.Background(),
+		strings.NewReader("this is a sample\n"),
+		"streamed.txt",
+		"text",
+		func() *controller.APIError { return nil },
+	)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+
+	if etag != sampleEtag {
+		t.Errorf("unexpected etag: %s", etag)
+	}
+
+	rejected := controller.ForbiddenError(errors.New("rejected"), "rejected") //nolint:err113
+
+	if _, apiErr := fs.PutFileStream(
+		context.Background(),
+		strings.NewReader("not wanted"),
+		"rejected.txt",
+		"text",
+		func() *controller.APIError { return rejected },
+	); apiErr != rejected { //nolint:errorlint
+		t.Errorf("expected the validation error, got: %v", apiErr)
+	}
+
+	got := listFiles(t, fs.ListFiles(context.Background()))
+	if diff := cmp.Diff([]string{"sample.txt", "streamed.txt"}, got); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestFilesystemPutFileIfMatch(t *testing.T) {
+	t.Parallel()
+
+	fs := getFilesystem(t)
+
+	cases := []struct {
+		name     string
+		filepath string
+		etag     string
+		expected *controller.APIError
+	}{
+		{
+			name:     "new file",
+			filepath: "new.txt",
+			etag:     "",
+			expected: nil,
+		},
+		{
+			name:     "new file already exists",
+			filepath: "sample.txt",
+			etag:     "",
+			expected: controller.ErrFileModified,
+		},
+		{
+			name:     "etag matches",
+			filepath: "sample.txt",
+			etag:     sampleEtag,
+			expected: nil,
+		},
+		{
+			name:     "etag changed",
+			filepath: "sample.txt",
+			etag:     sampleEtag,
+			expected: controller.ErrFileModified,
+		},
+		{
+			name:     "file doesn't exist",
+			filepath: "missing.txt",
+			etag:     sampleEtag,
+			expected: controller.ErrFileModified,
+		},
+	}
+
+	// cases run in order as each one modifies the files for the next
+	for _, tc := range cases {
+		_, apiErr := fs.PutFileIfMatch(
+			context.Background(), strings.NewReader("new content"), tc.filepath, "text", tc.etag,
+		)
+		if apiErr != tc.expected { //nolint:errorlint
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, apiErr)
+		}
+	}
+}
+
+func TestFilesystemGetFilePresignedURL(t *testing.T) {
+	t.Parallel()
+
+	fs := getFilesystem(t)
+
+	cases := []struct {
+		name               string
+		filepath           string
+		signedFilepath     string
+		expire             time.Duration
+		tamper             func(url.Values)
+		requestHeaders     http.Header
+		expectedContent    string
+		expectedErr        *controller.ErrorResponse
+		expectedStatusCode int
+	}{
+		{
+			name:               "success",
+			filepath:           "sample.txt",
+			signedFilepath:     "sample.txt",
+			expire:             time.Minute,
+			expectedContent:    "this is a sample\n",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "range",
+			filepath:       "sample.txt",
+			signedFilepath: "sample.txt",
+			expire:         time.Minute,
+			requestHeaders: http.Header{
+				"Range": {"bytes=0-3"},
+			},
+			expectedContent:    "this",
+			expectedStatusCode: http.StatusPartialContent,
+		},
+		{
+			name:           "not modified",
+			filepath:       "sample.txt",
+			signedFilepath: "sample.txt",
+			expire:         time.Minute,
+			requestHeaders: http.Header{
+				"If-None-Match": {sampleEtag},
+			},
+			expectedStatusCode: http.StatusNotModified,
+		},
+		{
+			name:           "signed for a different file",
+			filepath:       "sample.txt",
+			signedFilepath: "another.txt",
+			expire:         time.Minute,
+			expectedErr: &controller.ErrorResponse{
+				Message: "The request signature we calculated does not match the signature you provided.",
+			},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "tampered expiration",
+			filepath:       "sample.txt",
+			signedFilepath: "sample.txt",
+			expire:         time.Minute,
+			tamper: func(v url.Values) {
+				v.Set("X-Amz-Expires", "604800")
+			},
+			expectedErr: &controller.ErrorResponse{
+				Message: "The request signature we calculated does not match the signature you provided.",
+			},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "expired",
+			filepath:       "sample.txt",
+			signedFilepath: "sample.txt",
+			expire:         -time.Minute,
+			expectedErr: &controller.ErrorResponse{
+				Message: "Request has expired",
+			},
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			signature, apiErr := fs.CreatePresignedURL(
+				context.Background(), tc.signedFilepath, tc.expire,
+			)
+			if apiErr != nil {
+				t.Fatal(apiErr)
+			}
+
+			if tc.tamper != nil {
+				values, err := url.ParseQuery(signature)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				tc.tamper(values)
+				signature = values.Encode()
+			}
+
+			if tc.requestHeaders == nil {
+				tc.requestHeaders = http.Header{}
+			}
+
+			got, apiErr := fs.GetFileWithPresignedURL(
+				context.Background(), tc.filepath, signature, tc.requestHeaders,
+			)
+
+			statusCode := 0
+			if got != nil {
+				statusCode = got.StatusCode
+				defer got.Body.Close()
+			}
+
+			var publicResponse *controller.ErrorResponse
+			if apiErr != nil {
+				statusCode = apiErr.StatusCode()
+				publicResponse = apiErr.PublicResponse()
+			}
+
+			if statusCode != tc.expectedStatusCode {
+				t.Errorf("expected status code %d but got %d", tc.expectedStatusCode, statusCode)
+			}
+
+			if !cmp.Equal(publicResponse, tc.expectedErr) {
+				t.Error(cmp.Diff(publicResponse, tc.expectedErr))
+			}
+
+			if tc.expectedContent != "" {
+				b, err := io.ReadAll(got.Body)
+				if err != nil {
+					t.Error(err)
+				}
+
+				if !cmp.Equal(string(b), tc.expectedContent) {
+					t.Error(cmp.Diff(string(b), tc.expectedContent))
+				}
+			}
+		})
+	}
+}
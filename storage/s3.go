@@ -1,21 +1,30 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/telemetry"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func deptr[T any](p *T) T { //nolint:ireturn
@@ -58,27 +67,55 @@ func parseS3Error(resp *http.Response) *controller.APIError {
 	)
 }
 
+const (
+	// S3 rejects multipart uploads with parts smaller than this, except for the last one.
+	s3MinPartSize = 5 << 20
+	// s3MaxParts is the maximum number of parts of a multipart upload.
+	s3MaxParts = 10000
+	// s3MaxCopySize is the largest object CopyObject can copy, larger ones are copied in
+	// parts of at least s3CopyPartSize.
+	s3MaxCopySize  = 5 << 30
+	s3CopyPartSize = 512 << 20
+)
+
 type S3 struct {
-	client     *s3.Client
-	bucket     *string
-	rootFolder string
-	url        string
-	logger     *logrus.Logger
+	client      *s3.Client
+	bucket      *string
+	rootFolder  string
+	url         string
+	partSize    int64
+	concurrency int
+	logger      *logrus.Logger
+}
+
+// endSpan ends the span of a call, marking it as failed if it returned a server error.
+func endSpan(span trace.Span, apiErr *controller.APIError) {
+	if apiErr != nil && apiErr.StatusCode() >= http.StatusInternalServerError {
+		telemetry.RecordError(span, apiErr)
+	}
+
+	span.End()
 }
 
+// NewS3 returns a content storage backed by an S3 bucket. partSize and concurrency
+// control how streamed uploads are split and how many parts are sent at the same time.
 func NewS3(
 	client *s3.Client,
 	bucket string,
 	rootFolder string,
 	url string,
+	partSize int64,
+	concurrency int,
 	logger *logrus.Logger,
 ) *S3 {
 	return &S3{
-		client:     client,
-		bucket:     aws.String(bucket),
-		rootFolder: rootFolder,
-		url:        url,
-		logger:     logger,
+		client:      client,
+		bucket:      aws.String(bucket),
+		rootFolder:  rootFolder,
+		url:         url,
+		partSize:    max(partSize, s3MinPartSize),
+		concurrency: max(concurrency, 1),
+		logger:      logger,
 	}
 }
 
@@ -87,6 +124,42 @@ func (s *S3) PutFile(
 	content io.ReadSeeker,
 	filepath string,
 	contentType string,
+) (etag string, apiErr *controller.APIError) {
+	ctx, span := telemetry.Start(
+		ctx, "storage.S3.PutFile", attribute.String("storage.path", filepath),
+	)
+	defer func() { endSpan(span, apiErr) }()
+
+	return s.putObject(ctx, content, filepath, contentType, nil, nil)
+}
+
+// PutFileIfMatch relies on S3 conditional writes. Both a failed precondition and a
+// concurrent conditional write to the same key mean someone else modified the file.
+func (s *S3) PutFileIfMatch(
+	ctx context.Context,
+	content io.ReadSeeker,
+	filepath string,
+	contentType string,
+	etag string,
+) (newEtag string, apiErr *controller.APIError) {
+	ctx, span := telemetry.Start(
+		ctx, "storage.S3.PutFileIfMatch", attribute.String("storage.path", filepath),
+	)
+	defer func() { endSpan(span, apiErr) }()
+
+	if etag == "" {
+		return s.putObject(ctx, content, filepath, contentType, nil, aws.String("*"))
+	}
+
+	return s.putObject(ctx, content, filepath, contentType, aws.String(etag), nil)
+}
+
+func (s *S3) putObject(
+	ctx context.Context,
+	content io.ReadSeeker,
+	filepath string,
+	contentType string,
+	ifMatch, ifNoneMatch *string,
 ) (string, *controller.APIError) {
 	key, err := url.JoinPath(s.rootFolder, filepath)
 	if err != nil {
@@ -106,8 +179,21 @@ func (s *S3) PutFile(
 			Bucket:      s.bucket,
 			Key:         aws.String(key),
 			ContentType: aws.String(contentType),
+			IfMatch:     ifMatch,
+			IfNoneMatch: ifNoneMatch,
 		},
 	)
+
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		switch respErr.HTTPStatusCode() {
+		case http.StatusPreconditionFailed, http.StatusConflict, http.StatusNotFound:
+			if ifMatch != nil || ifNoneMatch != nil {
+				return "", controller.ErrFileModified
+			}
+		}
+	}
+
 	if err != nil {
 		return "", controller.InternalServerError(fmt.Errorf("problem putting object: %w", err))
 	}
@@ -115,11 +201,217 @@ func (s *S3) PutFile(
 	return *object.ETag, nil
 }
 
+// readPart reads up to size bytes from content, the returned bool is true if the
+// content ended.
+func readPart(content io.Reader, size int64) ([]byte, bool, error) {
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, content, size); err != nil {
+		if errors.Is(err, io.EOF) {
+			return buf.Bytes(), true, nil
+		}
+
+		return nil, false, fmt.Errorf("problem reading content: %w", err)
+	}
+
+	return buf.Bytes(), false, nil
+}
+
+// PutFileStream uploads content as it is read using a multipart upload so it never
+// needs to be fully buffered. Content smaller than a part is sent in one request.
+func (s *S3) PutFileStream(
+	ctx context.Context,
+	content io.Reader,
+	filepath string,
+	contentType string,
+	validate func() *controller.APIError,
+) (etag string, apiErr *controller.APIError) {
+	ctx, span := telemetry.Start(
+		ctx, "storage.S3.PutFileStream", attribute.String("storage.path", filepath),
+	)
+	defer func() { endSpan(span, apiErr) }()
+
+	key, err := url.JoinPath(s.rootFolder, filepath)
+	if err != nil {
+		return "", controller.InternalServerError(fmt.Errorf("problem joining path: %w", err))
+	}
+
+	part, last, err := readPart(content, s.partSize)
+	if err != nil {
+		return "", controller.InternalServerError(err)
+	}
+
+	if last {
+		if validate != nil {
+			if apiErr := validate(); apiErr != nil {
+				return "", apiErr
+			}
+		}
+
+		return s.PutFile(ctx, bytes.NewReader(part), filepath, contentType)
+	}
+
+	upload, err := s.client.CreateMultipartUpload(ctx,
+		&s3.CreateMultipartUploadInput{ //nolint:exhaustruct
+			Bucket:      s.bucket,
+			Key:         aws.String(key),
+			ContentType: aws.String(contentType),
+		},
+	)
+	if err != nil {
+		return "", controller.InternalServerError(
+			fmt.Errorf("problem creating multipart upload: %w", err),
+		)
+	}
+
+	etag, apiErr = s.uploadParts(ctx, key, upload.UploadId, part, content, validate)
+	if apiErr != nil {
+		// the request may be gone already but we still want to free the parts
+		if _, err := s.client.AbortMultipartUpload(context.WithoutCancel(ctx),
+			&s3.AbortMultipartUploadInput{ //nolint:exhaustruct
+				Bucket:   s.bucket,
+				Key:      aws.String(key),
+				UploadId: upload.UploadId,
+			},
+		); err != nil {
+			s.logger.WithError(err).Errorf("problem aborting multipart upload for %s", key)
+		}
+
+		return "", apiErr
+	}
+
+	return etag, nil
+}
+
+// uploadParts reads the rest of the content part by part, uploading up to s.concurrency
+// parts at the same time, and completes the upload once validate succeeds.
+func (s *S3) uploadParts( //nolint:funlen
+	ctx context.Context,
+	key string,
+	uploadID *string,
+	part []byte,
+	content io.Reader,
+	validate func() *controller.APIError,
+) (string, *controller.APIError) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		uploadErr error
+		completed []types.CompletedPart
+	)
+
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if uploadErr == nil {
+			uploadErr = err
+			cancel()
+		}
+	}
+
+	inFlight := make(chan struct{}, s.concurrency)
+
+	uploadPart := func(number int32, body []byte) {
+		inFlight <- struct{}{}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+
+			out, err := s.client.UploadPart(ctx,
+				&s3.UploadPartInput{ //nolint:exhaustruct
+					Bucket:        s.bucket,
+					Key:           aws.String(key),
+					UploadId:      uploadID,
+					PartNumber:    aws.Int32(number),
+					Body:          bytes.NewReader(body),
+					ContentLength: aws.Int64(int64(len(body))),
+				},
+			)
+			if err != nil {
+				fail(fmt.Errorf("problem uploading part %d: %w", number, err))
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			completed = append(completed, types.CompletedPart{ //nolint:exhaustruct
+				ETag:       out.ETag,
+				PartNumber: aws.Int32(number),
+			})
+		}()
+	}
+
+	last := false
+	for number := int32(1); ; number++ {
+		uploadPart(number, part)
+
+		if last {
+			break
+		}
+
+		var err error
+
+		part, last, err = readPart(content, s.partSize)
+		if err != nil {
+			fail(err)
+			break
+		}
+
+		if len(part) == 0 || ctx.Err() != nil {
+			break
+		}
+	}
+
+	wg.Wait()
+
+	if uploadErr != nil {
+		return "", controller.InternalServerError(uploadErr)
+	}
+
+	if validate != nil {
+		if apiErr := validate(); apiErr != nil {
+			return "", apiErr
+		}
+	}
+
+	slices.SortFunc(completed, func(a, b types.CompletedPart) int {
+		return int(*a.PartNumber - *b.PartNumber)
+	})
+
+	out, err := s.client.CompleteMultipartUpload(ctx,
+		&s3.CompleteMultipartUploadInput{ //nolint:exhaustruct
+			Bucket:          s.bucket,
+			Key:             aws.String(key),
+			UploadId:        uploadID,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+		},
+	)
+	if err != nil {
+		return "", controller.InternalServerError(
+			fmt.Errorf("problem completing multipart upload: %w", err),
+		)
+	}
+
+	return *out.ETag, nil
+}
+
 func (s *S3) GetFile(
 	ctx context.Context,
 	filepath string,
 	downloadRange *string,
-) (*controller.File, *controller.APIError) {
+) (file *controller.File, apiErr *controller.APIError) {
+	ctx, span := telemetry.Start(
+		ctx, "storage.S3.GetFile", attribute.String("storage.path", filepath),
+	)
+	defer func() { endSpan(span, apiErr) }()
+
 	key, err := url.JoinPath(s.rootFolder, filepath)
 	if err != nil {
 		return nil, controller.InternalServerError(fmt.Errorf("problem joining path: %w", err))
@@ -136,6 +428,10 @@ func (s *S3) GetFile(
 			Range: downloadRange,
 		},
 	)
+	if noSuchKey := new(types.NoSuchKey); errors.As(err, &noSuchKey) {
+		return nil, controller.ErrFileNotFound
+	}
+
 	if err != nil {
 		return nil, controller.InternalServerError(fmt.Errorf("problem getting object: %w", err))
 	}
@@ -155,6 +451,7 @@ func (s *S3) GetFile(
 		ContentType:   *object.ContentType,
 		ContentLength: deptr(object.ContentLength),
 		Etag:          *object.ETag,
+		LastModified:  deptr(object.LastModified),
 		StatusCode:    status,
 		Body:          object.Body,
 		ExtraHeaders:  respHeaders,
@@ -165,7 +462,12 @@ func (s *S3) CreatePresignedURL(
 	ctx context.Context,
 	filepath string,
 	expire time.Duration,
-) (string, *controller.APIError) {
+) (signature string, apiErr *controller.APIError) {
+	ctx, span := telemetry.Start(
+		ctx, "storage.S3.CreatePresignedURL", attribute.String("storage.path", filepath),
+	)
+	defer func() { endSpan(span, apiErr) }()
+
 	key, err := url.JoinPath(s.rootFolder, filepath)
 	if err != nil {
 		return "", controller.InternalServerError(fmt.Errorf("problem joining path: %w", err))
@@ -200,7 +502,12 @@ func (s *S3) CreatePresignedURL(
 
 func (s *S3) GetFileWithPresignedURL(
 	ctx context.Context, filepath, signature string, headers http.Header,
-) (*controller.File, *controller.APIError) {
+) (file *controller.File, apiErr *controller.APIError) {
+	ctx, span := telemetry.Start(
+		ctx, "storage.S3.GetFileWithPresignedURL", attribute.String("storage.path", filepath),
+	)
+	defer func() { endSpan(span, apiErr) }()
+
 	if s.rootFolder != "" {
 		filepath = s.rootFolder + "/" + filepath
 	}
@@ -229,6 +536,9 @@ func (s *S3) GetFileWithPresignedURL(
 
 	respHeaders := make(http.Header)
 
+	// not all S3-compatible services send it, in that case it is left empty
+	lastModified, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+
 	var length int64
 
 	switch resp.StatusCode {
@@ -252,13 +562,105 @@ func (s *S3) GetFileWithPresignedURL(
 		ContentType:   resp.Header.Get("Content-Type"),
 		ContentLength: length,
 		Etag:          resp.Header.Get("Etag"),
+		LastModified:  lastModified,
 		StatusCode:    resp.StatusCode,
 		Body:          resp.Body,
 		ExtraHeaders:  respHeaders,
 	}, nil
 }
 
-func (s *S3) DeleteFile(ctx context.Context, filepath string) *controller.APIError {
+// CreatePresignedUpload returns a presigned POST policy; S3 enforces the content type
+// and size limits when the form is submitted.
+func (s *S3) CreatePresignedUpload(
+	ctx context.Context,
+	filepath string,
+	contentType string,
+	minSize, maxSize int,
+	expire time.Duration,
+) (upload *controller.PresignedUpload, apiErr *controller.APIError) {
+	ctx, span := telemetry.Start(
+		ctx, "storage.S3.CreatePresignedUpload", attribute.String("storage.path", filepath),
+	)
+	defer func() { endSpan(span, apiErr) }()
+
+	key, err := url.JoinPath(s.rootFolder, filepath)
+	if err != nil {
+		return nil, controller.InternalServerError(fmt.Errorf("problem joining path: %w", err))
+	}
+
+	presignClient := s3.NewPresignClient(s.client)
+
+	request, err := presignClient.PresignPostObject(ctx,
+		&s3.PutObjectInput{ //nolint:exhaustruct
+			Bucket: s.bucket,
+			Key:    aws.String(key),
+		},
+		func(po *s3.PresignPostOptions) {
+			po.Expires = expire
+			po.Conditions = []any{
+				[]any{"content-length-range", minSize, maxSize},
+				map[string]string{"Content-Type": contentType},
+			}
+		},
+	)
+	if err != nil {
+		return nil, controller.InternalServerError(
+			fmt.Errorf("problem generating presigned upload: %w", err),
+		)
+	}
+
+	request.Values["Content-Type"] = contentType
+
+	return &controller.PresignedUpload{
+		URL:    request.URL,
+		Fields: request.Values,
+	}, nil
+}
+
+func (s *S3) HeadFile(
+	ctx context.Context, filepath string,
+) (file *controller.File, apiErr *controller.APIError) {
+	ctx, span := telemetry.Start(
+		ctx, "storage.S3.HeadFile", attribute.String("storage.path", filepath),
+	)
+	defer func() { endSpan(span, apiErr) }()
+
+	key, err := url.JoinPath(s.rootFolder, filepath)
+	if err != nil {
+		return nil, controller.InternalServerError(fmt.Errorf("problem joining path: %w", err))
+	}
+
+	object, err := s.client.HeadObject(ctx,
+		&s3.HeadObjectInput{ //nolint:exhaustruct
+			Bucket: s.bucket,
+			Key:    aws.String(key),
+		},
+	)
+	if err != nil {
+		if notFound := new(types.NotFound); errors.As(err, &notFound) {
+			return nil, controller.ErrFileNotFound
+		}
+
+		return nil, controller.InternalServerError(fmt.Errorf("problem getting object: %w", err))
+	}
+
+	return &controller.File{
+		ContentType:   deptr(object.ContentType),
+		ContentLength: deptr(object.ContentLength),
+		Etag:          deptr(object.ETag),
+		LastModified:  deptr(object.LastModified),
+		StatusCode:    http.StatusOK,
+		Body:          http.NoBody,
+		ExtraHeaders:  make(http.Header),
+	}, nil
+}
+
+func (s *S3) DeleteFile(ctx context.Context, filepath string) (apiErr *controller.APIError) {
+	ctx, span := telemetry.Start(
+		ctx, "storage.S3.DeleteFile", attribute.String("storage.path", filepath),
+	)
+	defer func() { endSpan(span, apiErr) }()
+
 	key, err := url.JoinPath(s.rootFolder, filepath)
 	if err != nil {
 		return controller.InternalServerError(fmt.Errorf("problem joining path: %w", err))
@@ -275,22 +677,197 @@ func (s *S3) DeleteFile(ctx context.Context, filepath string) *controller.APIErr
 	return nil
 }
 
-func (s *S3) ListFiles(ctx context.Context) ([]string, *controller.APIError) {
-	objects, err := s.client.ListObjects(ctx,
-		&s3.ListObjectsInput{ //nolint:exhaustruct
-			Bucket: s.bucket,
-			Prefix: aws.String(s.rootFolder + "/"),
-		})
+// copySource returns the CopySource of key, which needs to be URL-encoded.
+func (s *S3) copySource(key string) *string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return aws.String(*s.bucket + "/" + strings.Join(segments, "/"))
+}
+
+// copyParts copies objects too large for CopyObject with a multipart upload.
+func (s *S3) copyParts(
+	ctx context.Context, key, newKey string, size int64, contentType *string,
+) *controller.APIError {
+	upload, err := s.client.CreateMultipartUpload(ctx,
+		&s3.CreateMultipartUploadInput{ //nolint:exhaustruct
+			Bucket:      s.bucket,
+			Key:         aws.String(newKey),
+			ContentType: contentType,
+		},
+	)
 	if err != nil {
-		return nil, controller.InternalServerError(
-			fmt.Errorf("problem listing objects in s3: %w", err),
+		return controller.InternalServerError(
+			fmt.Errorf("problem creating multipart upload: %w", err),
+		)
+	}
+
+	abort := func() {
+		if _, err := s.client.AbortMultipartUpload(context.WithoutCancel(ctx),
+			&s3.AbortMultipartUploadInput{ //nolint:exhaustruct
+				Bucket:   s.bucket,
+				Key:      aws.String(newKey),
+				UploadId: upload.UploadId,
+			},
+		); err != nil {
+			s.logger.WithError(err).Errorf("problem aborting multipart upload for %s", newKey)
+		}
+	}
+
+	// parts need to be large enough for the object to fit in the maximum number of parts
+	partSize := max(s3CopyPartSize, (size+s3MaxParts-1)/s3MaxParts)
+	completed := make([]types.CompletedPart, 0, (size+partSize-1)/partSize)
+
+	var number int32
+	for start := int64(0); start < size; start += partSize {
+		number++
+
+		out, err := s.client.UploadPartCopy(ctx,
+			&s3.UploadPartCopyInput{ //nolint:exhaustruct
+				Bucket:     s.bucket,
+				Key:        aws.String(newKey),
+				UploadId:   upload.UploadId,
+				PartNumber: aws.Int32(number),
+				CopySource: s.copySource(key),
+				CopySourceRange: aws.String(
+					fmt.Sprintf("bytes=%d-%d", start, min(start+partSize, size)-1),
+				),
+			},
 		)
+		if err != nil {
+			abort()
+			return controller.InternalServerError(
+				fmt.Errorf("problem copying part %d: %w", number, err),
+			)
+		}
+
+		completed = append(completed, types.CompletedPart{ //nolint:exhaustruct
+			ETag:       out.CopyPartResult.ETag,
+			PartNumber: aws.Int32(number),
+		})
 	}
 
-	res := make([]string, len(objects.Contents))
-	for i, c := range objects.Contents {
-		res[i] = strings.TrimPrefix(*c.Key, s.rootFolder+"/")
+	if _, err := s.client.CompleteMultipartUpload(ctx,
+		&s3.CompleteMultipartUploadInput{ //nolint:exhaustruct
+			Bucket:          s.bucket,
+			Key:             aws.String(newKey),
+			UploadId:        upload.UploadId,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+		},
+	); err != nil {
+		abort()
+		return controller.InternalServerError(
+			fmt.Errorf("problem completing multipart upload: %w", err),
+		)
 	}
 
-	return res, nil
+	return nil
+}
+
+// MoveFile copies the file to its new path and deletes the original, S3 can't rename
+// objects.
+func (s *S3) MoveFile(
+	ctx context.Context, filepath, newFilepath string,
+) (apiErr *controller.APIError) {
+	ctx, span := telemetry.Start(
+		ctx, "storage.S3.MoveFile",
+		attribute.String("storage.path", filepath),
+		attribute.String("storage.new_path", newFilepath),
+	)
+	defer func() { endSpan(span, apiErr) }()
+
+	key, err := url.JoinPath(s.rootFolder, filepath)
+	if err != nil {
+		return controller.InternalServerError(fmt.Errorf("problem joining path: %w", err))
+	}
+
+	newKey, err := url.JoinPath(s.rootFolder, newFilepath)
+	if err != nil {
+		return controller.InternalServerError(fmt.Errorf("problem joining path: %w", err))
+	}
+
+	object, err := s.client.HeadObject(ctx,
+		&s3.HeadObjectInput{ //nolint:exhaustruct
+			Bucket: s.bucket,
+			Key:    aws.String(key),
+		},
+	)
+	if err != nil {
+		if notFound := new(types.NotFound); errors.As(err, &notFound) {
+			return controller.ErrFileNotFound
+		}
+
+		return controller.InternalServerError(fmt.Errorf("problem getting object: %w", err))
+	}
+
+	if size := deptr(object.ContentLength); size > s3MaxCopySize {
+		if apiErr := s.copyParts(ctx, key, newKey, size, object.ContentType); apiErr != nil {
+			return apiErr
+		}
+	} else if _, err := s.client.CopyObject(ctx,
+		&s3.CopyObjectInput{ //nolint:exhaustruct
+			Bucket:     s.bucket,
+			CopySource: s.copySource(key),
+			Key:        aws.String(newKey),
+		}); err != nil {
+		return controller.InternalServerError(fmt.Errorf("problem copying file in s3: %w", err))
+	}
+
+	if _, err := s.client.DeleteObject(ctx,
+		&s3.DeleteObjectInput{ //nolint:exhaustruct
+			Bucket: s.bucket,
+			Key:    aws.String(key),
+		}); err != nil {
+		return controller.InternalServerError(fmt.Errorf("problem deleting file in s3: %w", err))
+	}
+
+	return nil
+}
+
+func (s *S3) ListFiles(ctx context.Context) iter.Seq2[string, *controller.APIError] {
+	return func(yield func(string, *controller.APIError) bool) {
+		ctx, span := telemetry.Start(ctx, "storage.S3.ListFiles")
+
+		var apiErr *controller.APIError
+		defer func() { endSpan(span, apiErr) }()
+
+		paginator := s3.NewListObjectsV2Paginator(s.client,
+			&s3.ListObjectsV2Input{ //nolint:exhaustruct
+				Bucket: s.bucket,
+				Prefix: aws.String(s.rootFolder + "/"),
+			})
+
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				apiErr = controller.InternalServerError(
+					fmt.Errorf("problem listing objects in s3: %w", err),
+				)
+				yield("", apiErr)
+
+				return
+			}
+
+			for _, c := range page.Contents {
+				if !yield(strings.TrimPrefix(*c.Key, s.rootFolder+"/"), nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Ping checks the bucket exists and is accessible with the configured credentials.
+func (s *S3) Ping(ctx context.Context) error {
+	if _, err := s.client.HeadBucket(ctx,
+		&s3.HeadBucketInput{ //nolint:exhaustruct
+			Bucket: s.bucket,
+		},
+	); err != nil {
+		return fmt.Errorf("problem accessing bucket: %w", err)
+	}
+
+	return nil
 }
@@ -47,18 +47,16 @@ func getS3() *storage.S3 {
 			o.UsePathStyle = true
 			o.EndpointOptions.DisableHTTPS = true
 		})
-	st := storage.NewS3(client, "default", "f215cf48-7458-4596-9aa5-2159fc6a3caf", url, logger)
+	st := storage.NewS3(
+		client, "default", "f215cf48-7458-4596-9aa5-2159fc6a3caf", url, 5<<20, 2, logger,
+	)
 	return st
 }
 
 func findFile(t *testing.T, s3 *storage.S3, filename string) bool {
 	t.Helper()
 
-	ff, err := s3.ListFiles(context.TODO())
-	if err != nil {
-		t.Fatal(err)
-	}
-	found := slices.Contains(ff, filename)
+	found := slices.Contains(listFiles(t, s3.ListFiles(context.TODO())), filename)
 
 	return found
 }
@@ -111,6 +109,43 @@ func TestDeleteFile(t *testing.T) {
 	}
 }
 
+func TestMoveFile(t *testing.T) {
+	t.Parallel()
+
+	s3 := getS3()
+
+	f, err := os.Open("s3_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// keys need to be escaped in the copy source
+	_, apiErr := s3.PutFile(context.TODO(), f, "to move/s3 test+1.go", "text")
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+
+	if apiErr := s3.MoveFile(
+		context.TODO(), "to move/s3 test+1.go", "moved/s3 test+1.go",
+	); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+
+	if findFile(t, s3, "to move/s3 test+1.go") {
+		t.Error("file wasn't moved")
+	}
+
+	if !findFile(t, s3, "moved/s3 test+1.go") {
+		t.Error("couldn't find moved file")
+	}
+
+	if apiErr := s3.MoveFile(
+		context.TODO(), "to move/s3 test+1.go", "moved/s3 test+1.go",
+	); apiErr == nil || apiErr.StatusCode() != http.StatusNotFound {
+		t.Errorf("expected moving a missing file to fail, got %v", apiErr)
+	}
+}
+
 func TestListFiles(t *testing.T) {
 	cases := []struct {
 		name string
@@ -123,11 +158,7 @@ func TestListFiles(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			got, err := s3.ListFiles(context.TODO())
-			if err != nil {
-				t.Error(err)
-			}
-
+			got := listFiles(t, s3.ListFiles(context.TODO()))
 			if len(got) == 0 {
 				t.Error("found no files")
 			}
@@ -242,7 +273,7 @@ func TestGetFilePresignedURL(t *testing.T) {
 				tc.requestHeaders,
 			)
 			opts := cmp.Options{
-				cmpopts.IgnoreFields(controller.File{}, "Body"),
+				cmpopts.IgnoreFields(controller.File{}, "Body", "LastModified"),
 			}
 			if !cmp.Equal(got, tc.expected, opts) {
 				t.Error(cmp.Diff(got, tc.expected, opts))
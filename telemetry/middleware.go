@@ -0,0 +1,83 @@
+package telemetry
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	strictgin "github.com/oapi-codegen/runtime/strictmiddleware/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware creates a server span for each request, continuing the trace sent by the
+// client if any.
+func Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		parent := otel.GetTextMapPropagator().Extract(
+			ctx.Request.Context(), propagation.HeaderCarrier(ctx.Request.Header),
+		)
+
+		route := ctx.FullPath()
+		name := ctx.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+
+		spanCtx, span := otel.Tracer(tracerName).Start(
+			parent,
+			name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(ctx.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(ctx.Request.URL.Path),
+				semconv.ClientAddress(ctx.ClientIP()),
+			),
+		)
+		defer span.End()
+
+		ctx.Request = ctx.Request.WithContext(spanCtx)
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// statusError is implemented by the errors the controller returns as responses.
+type statusError interface {
+	error
+	StatusCode() int
+}
+
+// StrictMiddleware creates a span for each controller operation.
+func StrictMiddleware(
+	f strictgin.StrictGinHandlerFunc, operationID string,
+) strictgin.StrictGinHandlerFunc {
+	return func(ctx *gin.Context, request any) (any, error) {
+		spanCtx, span := otel.Tracer(tracerName).Start(
+			ctx.Request.Context(), "controller."+operationID,
+		)
+		ctx.Request = ctx.Request.WithContext(spanCtx)
+
+		response, err := f(ctx, request)
+
+		spanErr := err
+		if serr, ok := response.(statusError); ok && spanErr == nil &&
+			serr.StatusCode() >= http.StatusInternalServerError {
+			spanErr = serr
+		}
+
+		End(span, spanErr)
+
+		return response, err //nolint:wrapcheck
+	}
+}
@@ -0,0 +1,127 @@
+// Package telemetry configures OpenTelemetry tracing and contains the helpers used to
+// instrument the service.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/nhost/hasura-storage"
+
+// Propagator extracts the trace context from W3C `traceparent` headers as well as B3,
+// single or multiple headers, and injects both formats.
+func Propagator() propagation.TextMapPropagator { //nolint:ireturn
+	return propagation.NewCompositeTextMapPropagator(
+		// later propagators take precedence when extracting, so traceparent wins
+		b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)),
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	)
+}
+
+func exporterConfigured(endpoint string) bool {
+	return endpoint != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup configures the global tracer provider to export spans via OTLP over HTTP to
+// endpoint, i.e. http://otel-collector:4318. If endpoint is empty the standard OTEL_*
+// environment variables are used and, if they aren't set either, spans aren't exported
+// but the trace context is still propagated. The returned function flushes the pending
+// spans and needs to be called before exiting.
+func Setup(
+	ctx context.Context, endpoint, serviceName, version string,
+) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(Propagator())
+
+	if !exporterConfigured(endpoint) {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{}
+	if endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("problem creating OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion(version),
+		),
+	)
+	if err != nil && !errors.Is(err, resource.ErrSchemaURLConflict) {
+		return nil, fmt.Errorf("problem creating resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start creates a span as a child of the one in ctx. Handlers receive a *gin.Context, which
+// doesn't expose the values of the request's context, so the parent span is looked up
+// there instead. The returned context wraps ctx, keeping its cancellation.
+func Start( //nolint:ireturn
+	ctx context.Context, name string, attrs ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	parent := ctx
+	if ginCtx, ok := ctx.(*gin.Context); ok && ginCtx.Request != nil {
+		parent = ginCtx.Request.Context()
+	}
+
+	_, span := otel.Tracer(tracerName).Start(parent, name, trace.WithAttributes(attrs...))
+
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+// SpanFromContext returns the current span, looking it up in the request's context for
+// *gin.Context.
+func SpanFromContext(ctx context.Context) trace.Span { //nolint:ireturn
+	if ginCtx, ok := ctx.(*gin.Context); ok && ginCtx.Request != nil {
+		ctx = ginCtx.Request.Context()
+	}
+
+	return trace.SpanFromContext(ctx)
+}
+
+// RecordError marks the span as failed.
+func RecordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// End marks the span as failed if err isn't nil and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		RecordError(span, err)
+	}
+
+	span.End()
+}
@@ -0,0 +1,196 @@
+package telemetry_test
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-cmp/cmp"
+	"github.com/nhost/hasura-storage/telemetry"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+type span struct {
+	Name    string
+	TraceID string
+	Parent  string
+	ID      string
+}
+
+// collector is a stand-in for an OTLP collector that keeps the spans it receives.
+type collector struct {
+	mu    sync.Mutex
+	spans []span
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/traces" {
+		http.NotFound(w, r)
+		return
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := &coltracepb.ExportTraceServiceRequest{}
+	if err := proto.Unmarshal(b, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, rs := range req.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			for _, s := range ss.GetSpans() {
+				c.spans = append(c.spans, span{
+					Name:    s.GetName(),
+					TraceID: hex.EncodeToString(s.GetTraceId()),
+					Parent:  hex.EncodeToString(s.GetParentSpanId()),
+					ID:      hex.EncodeToString(s.GetSpanId()),
+				})
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (c *collector) reset() []span {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	spans := c.spans
+	c.spans = nil
+
+	return spans
+}
+
+func TestTracing(t *testing.T) { //nolint:paralleltest
+	col := &collector{} //nolint:exhaustruct
+	srv := httptest.NewServer(col)
+	t.Cleanup(srv.Close)
+
+	shutdown, err := telemetry.Setup(context.Background(), srv.URL, "hasura-storage", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(telemetry.Middleware())
+	router.GET("/v1/files/:id", func(ctx *gin.Context) {
+		handler := telemetry.StrictMiddleware(
+			func(ctx *gin.Context, _ any) (any, error) {
+				_, span := telemetry.Start(ctx, "storage.S3.GetFile")
+				span.End()
+
+				return nil, nil //nolint:nilnil
+			},
+			"getFile",
+		)
+
+		_, _ = handler(ctx, nil)
+		ctx.Status(http.StatusOK)
+	})
+
+	cases := []struct {
+		name    string
+		headers http.Header
+		traceID string
+		parent  string
+	}{
+		{
+			name: "traceparent",
+			headers: http.Header{
+				"Traceparent": []string{
+					"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				},
+			},
+			traceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			parent:  "00f067aa0ba902b7",
+		},
+		{
+			name: "b3 single header",
+			headers: http.Header{
+				"B3": []string{"80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1"},
+			},
+			traceID: "80f198ee56343ba864fe8b2a57d3eff7",
+			parent:  "e457b5a2e4d86bd1",
+		},
+		{
+			name: "b3 multiple headers",
+			headers: http.Header{
+				"X-B3-Traceid": []string{"463ac35c9f6413ad48485a3953bb6124"},
+				"X-B3-Spanid":  []string{"a2fb4a1d1a96d312"},
+				"X-B3-Sampled": []string{"1"},
+			},
+			traceID: "463ac35c9f6413ad48485a3953bb6124",
+			parent:  "a2fb4a1d1a96d312",
+		},
+	}
+
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/v1/files/some-id", nil)
+		req.Header = tc.headers
+
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := col.reset()
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			trace := slices.DeleteFunc(slices.Clone(spans), func(s span) bool {
+				return s.TraceID != tc.traceID
+			})
+
+			// spans are exported as they end so children come first
+			if len(trace) != 3 { //nolint:mnd
+				t.Fatalf("expected 3 spans, got %v", trace)
+			}
+
+			expected := []span{
+				{
+					Name:    "storage.S3.GetFile",
+					TraceID: tc.traceID,
+					Parent:  trace[1].ID,
+					ID:      trace[0].ID,
+				},
+				{
+					Name:    "controller.getFile",
+					TraceID: tc.traceID,
+					Parent:  trace[2].ID,
+					ID:      trace[1].ID,
+				},
+				{
+					Name:    "GET /v1/files/:id",
+					TraceID: tc.traceID,
+					Parent:  tc.parent,
+					ID:      trace[2].ID,
+				},
+			}
+
+			if diff := cmp.Diff(expected, trace); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
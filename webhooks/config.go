@@ -0,0 +1,86 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/nhost/hasura-storage/controller"
+	"gopkg.in/yaml.v2"
+)
+
+// Webhook is an endpoint subscribed to file events. For instance:
+//
+//   - name: indexer
+//     url: https://indexer.example.com/hooks/storage
+//     secret: some-secret
+//     buckets: [default]
+//     events: [file.uploaded, file.replaced]
+type Webhook struct {
+	// Name identifies the webhook in the outbox, renaming it drops its pending events
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+	// Secret is the key used to sign the payloads
+	Secret string `yaml:"secret"`
+	// Buckets limits the subscription to files in these buckets, any bucket if empty
+	Buckets []string `yaml:"buckets"`
+	// Events limits the subscription to these event types, all of them if empty
+	Events []string `yaml:"events"`
+}
+
+func (w Webhook) subscribed(event controller.FileEvent) bool {
+	if len(w.Buckets) > 0 && !slices.Contains(w.Buckets, event.File.BucketId) {
+		return false
+	}
+
+	return len(w.Events) == 0 || slices.Contains(w.Events, event.Type)
+}
+
+func (w Webhook) validate() error {
+	switch {
+	case w.Name == "":
+		return errors.New("webhooks need a name") //nolint:err113
+	case w.URL == "":
+		return fmt.Errorf("webhook %s needs a url", w.Name) //nolint:err113
+	case w.Secret == "":
+		return fmt.Errorf("webhook %s needs a secret", w.Name) //nolint:err113
+	}
+
+	for _, event := range w.Events {
+		switch event {
+		case controller.EventFileUploaded, controller.EventFileReplaced, controller.EventFileDeleted:
+		default:
+			return fmt.Errorf("webhook %s has an unknown event: %s", w.Name, event) //nolint:err113
+		}
+	}
+
+	return nil
+}
+
+func LoadConfig(path string) ([]Webhook, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("problem reading webhooks config: %w", err)
+	}
+
+	var webhooks []Webhook
+	if err := yaml.UnmarshalStrict(b, &webhooks); err != nil {
+		return nil, fmt.Errorf("problem parsing webhooks config: %w", err)
+	}
+
+	names := make(map[string]struct{}, len(webhooks))
+	for _, w := range webhooks {
+		if err := w.validate(); err != nil {
+			return nil, err
+		}
+
+		if _, ok := names[w.Name]; ok {
+			return nil, fmt.Errorf("duplicated webhook: %s", w.Name) //nolint:err113
+		}
+
+		names[w.Name] = struct{}{}
+	}
+
+	return webhooks, nil
+}
@@ -0,0 +1,268 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SignatureHeader = "X-Hasura-Storage-Signature"
+	EventHeader     = "X-Hasura-Storage-Event"
+	DeliveryHeader  = "X-Hasura-Storage-Delivery"
+
+	pollInterval    = 5 * time.Second
+	claimBatchSize  = 50
+	deliveryTimeout = 10 * time.Second
+	// deliveries are claimed for this long, if the replica goes away before finishing
+	// them another one picks them up afterwards
+	claimLease = 3 * deliveryTimeout
+
+	maxAttempts = 10
+	minBackoff  = 10 * time.Second
+	maxBackoff  = time.Hour
+)
+
+// Delivery is an event pending to be sent to a webhook.
+type Delivery struct {
+	ID        string
+	Webhook   string
+	EventType string
+	Payload   []byte
+	Attempts  int
+}
+
+// Store is the outbox where deliveries are kept until the webhook acknowledges them.
+type Store interface {
+	Insert(ctx context.Context, deliveries []Delivery) error
+	// Claim returns up to limit deliveries due, they aren't returned again by other
+	// calls until the lease expires. Claiming a delivery counts as an attempt.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error)
+	MarkDelivered(ctx context.Context, id string) error
+	MarkRetry(ctx context.Context, id string, after time.Duration, lastErr string) error
+	MarkFailed(ctx context.Context, id string, lastErr string) error
+}
+
+// Sign returns the value of the signature header for a payload, receivers can verify
+// it by computing the HMAC-SHA256 of the body with the webhook's secret.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff returns how long to wait before the next attempt, doubling after each one.
+func backoff(attempts int) time.Duration {
+	d := minBackoff
+	for range attempts - 1 {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	return d
+}
+
+// Dispatcher implements controller.EventPublisher. Events are stored in the outbox
+// when published and sent in the background, retrying with an exponential backoff
+// until the webhook responds with a 2xx status code.
+type Dispatcher struct {
+	store    Store
+	webhooks map[string]Webhook
+	client   *http.Client
+	logger   logrus.FieldLogger
+}
+
+func NewDispatcher(store Store, webhooks []Webhook, logger logrus.FieldLogger) *Dispatcher {
+	byName := make(map[string]Webhook, len(webhooks))
+	for _, w := range webhooks {
+		byName[w.Name] = w
+	}
+
+	return &Dispatcher{
+		store:    store,
+		webhooks: byName,
+		client:   &http.Client{Timeout: deliveryTimeout}, //nolint:exhaustruct
+		logger:   logger,
+	}
+}
+
+func (d *Dispatcher) Publish(ctx context.Context, event controller.FileEvent) *controller.APIError {
+	deliveries := make([]Delivery, 0, len(d.webhooks))
+
+	var payload []byte
+
+	for _, w := range d.webhooks {
+		if !w.subscribed(event) {
+			continue
+		}
+
+		if payload == nil {
+			var err error
+
+			payload, err = json.Marshal(event)
+			if err != nil {
+				return controller.InternalServerError(
+					fmt.Errorf("problem marshalling event: %w", err),
+				)
+			}
+		}
+
+		deliveries = append(deliveries, Delivery{ //nolint:exhaustruct
+			Webhook:   w.Name,
+			EventType: event.Type,
+			Payload:   payload,
+		})
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := d.store.Insert(ctx, deliveries); err != nil {
+		return controller.InternalServerError(err)
+	}
+
+	return nil
+}
+
+// Start sends the pending deliveries in the background until the context is
+// cancelled, the returned function waits until the deliveries in progress finish.
+func (d *Dispatcher) Start(ctx context.Context) func() {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			d.DispatchPending(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() { <-done }
+}
+
+// DispatchPending sends the deliveries that are due until there are none left.
+func (d *Dispatcher) DispatchPending(ctx context.Context) {
+	for ctx.Err() == nil {
+		deliveries, err := d.store.Claim(ctx, claimBatchSize, claimLease)
+		if err != nil {
+			d.logger.WithError(err).Error("problem claiming webhook deliveries")
+			return
+		}
+
+		wg := sync.WaitGroup{}
+		for _, delivery := range deliveries {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+				d.dispatch(ctx, delivery)
+			}()
+		}
+
+		wg.Wait()
+
+		if len(deliveries) < claimBatchSize {
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, delivery Delivery) {
+	logger := d.logger.WithFields(logrus.Fields{
+		"webhook":  delivery.Webhook,
+		"delivery": delivery.ID,
+		"event":    delivery.EventType,
+		"attempt":  delivery.Attempts,
+	})
+
+	// the outcome is recorded even if we are shutting down
+	storeCtx := context.WithoutCancel(ctx)
+
+	webhook, ok := d.webhooks[delivery.Webhook]
+	if !ok {
+		logger.Warn("webhook is not configured anymore, dropping delivery")
+
+		if err := d.store.MarkFailed(storeCtx, delivery.ID, "webhook not configured"); err != nil {
+			logger.WithError(err).Error("problem recording webhook delivery")
+		}
+
+		return
+	}
+
+	sendErr := d.send(ctx, webhook, delivery)
+
+	var err error
+
+	switch {
+	case sendErr == nil:
+		logger.Debug("webhook delivered")
+
+		err = d.store.MarkDelivered(storeCtx, delivery.ID)
+	case delivery.Attempts >= maxAttempts:
+		logger.WithError(sendErr).Error("webhook delivery failed, giving up")
+
+		err = d.store.MarkFailed(storeCtx, delivery.ID, sendErr.Error())
+	default:
+		logger.WithError(sendErr).Warn("webhook delivery failed, retrying later")
+
+		err = d.store.MarkRetry(
+			storeCtx, delivery.ID, backoff(delivery.Attempts), sendErr.Error(),
+		)
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("problem recording webhook delivery")
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, webhook Webhook, delivery Delivery) error {
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload),
+	)
+	if err != nil {
+		return fmt.Errorf("problem creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, delivery.Payload))
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("problem sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16)) //nolint:mnd
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode) //nolint:err113
+	}
+
+	return nil
+}
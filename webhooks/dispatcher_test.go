@@ -0,0 +1,252 @@
+package webhooks_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nhost/hasura-storage/api"
+	"github.com/nhost/hasura-storage/controller"
+	"github.com/nhost/hasura-storage/webhooks"
+	"github.com/sirupsen/logrus"
+)
+
+type fakeStore struct {
+	mu        sync.Mutex
+	pending   []webhooks.Delivery
+	delivered []string
+	retried   []string
+	failed    []string
+}
+
+func (s *fakeStore) Insert(_ context.Context, deliveries []webhooks.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = append(s.pending, deliveries...)
+
+	return nil
+}
+
+func (s *fakeStore) Claim(_ context.Context, limit int, _ time.Duration) ([]webhooks.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := min(limit, len(s.pending))
+	claimed := s.pending[:n]
+	s.pending = s.pending[n:]
+
+	for i := range claimed {
+		claimed[i].Attempts++
+	}
+
+	return claimed, nil
+}
+
+func (s *fakeStore) MarkDelivered(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.delivered = append(s.delivered, id)
+
+	return nil
+}
+
+func (s *fakeStore) MarkRetry(_ context.Context, id string, _ time.Duration, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.retried = append(s.retried, id)
+
+	return nil
+}
+
+func (s *fakeStore) MarkFailed(_ context.Context, id string, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failed = append(s.failed, id)
+
+	return nil
+}
+
+func event(eventType, bucketID string) controller.FileEvent {
+	return controller.FileEvent{
+		ID:        "some-event",
+		Type:      eventType,
+		CreatedAt: time.Now(),
+		File: api.FileMetadata{ //nolint:exhaustruct
+			Id:       "55af1e60-0f28-454e-885e-ea6aab2bb288",
+			BucketId: bucketID,
+		},
+		Session: map[string]any{},
+	}
+}
+
+func TestPublish(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	store := &fakeStore{} //nolint:exhaustruct
+	dispatcher := webhooks.NewDispatcher(
+		store,
+		[]webhooks.Webhook{
+			{
+				Name:    "all",
+				URL:     "http://all",
+				Secret:  "secret",
+				Buckets: nil,
+				Events:  nil,
+			},
+			{
+				Name:    "default-uploads",
+				URL:     "http://default-uploads",
+				Secret:  "secret",
+				Buckets: []string{"default"},
+				Events:  []string{controller.EventFileUploaded},
+			},
+		},
+		logger,
+	)
+
+	for _, e := range []controller.FileEvent{
+		event(controller.EventFileUploaded, "default"),
+		event(controller.EventFileDeleted, "default"),
+		event(controller.EventFileUploaded, "other"),
+	} {
+		if apiErr := dispatcher.Publish(t.Context(), e); apiErr != nil {
+			t.Fatalf("unexpected error: %v", apiErr)
+		}
+	}
+
+	got := make([]string, 0, len(store.pending))
+	for _, d := range store.pending {
+		got = append(got, d.Webhook+" "+d.EventType)
+	}
+
+	slices.Sort(got)
+
+	if diff := cmp.Diff([]string{
+		"all file.deleted",
+		"all file.uploaded",
+		"all file.uploaded",
+		"default-uploads file.uploaded",
+	}, got); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestDispatchPending(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	cases := []struct {
+		name              string
+		status            int
+		attempts          int
+		expectedDelivered []string
+		expectedRetried   []string
+		expectedFailed    []string
+	}{
+		{
+			name:              "delivered",
+			status:            http.StatusNoContent,
+			attempts:          0,
+			expectedDelivered: []string{"some-delivery"},
+			expectedRetried:   nil,
+			expectedFailed:    nil,
+		},
+		{
+			name:              "retried",
+			status:            http.StatusInternalServerError,
+			attempts:          0,
+			expectedDelivered: nil,
+			expectedRetried:   []string{"some-delivery"},
+			expectedFailed:    nil,
+		},
+		{
+			name:              "too many attempts",
+			status:            http.StatusInternalServerError,
+			attempts:          9,
+			expectedDelivered: nil,
+			expectedRetried:   nil,
+			expectedFailed:    []string{"some-delivery"},
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	payload := []byte(`{"type":"file.uploaded"}`)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					body, _ := io.ReadAll(r.Body)
+					if got := r.Header.Get(webhooks.SignatureHeader); got != webhooks.Sign(
+						"secret", body,
+					) {
+						t.Errorf("wrong signature: %s", got)
+					}
+
+					if got := r.Header.Get(webhooks.DeliveryHeader); got != "some-delivery" {
+						t.Errorf("wrong delivery: %s", got)
+					}
+
+					w.WriteHeader(tc.status)
+				}),
+			)
+			defer server.Close()
+
+			store := &fakeStore{ //nolint:exhaustruct
+				pending: []webhooks.Delivery{
+					{
+						ID:        "some-delivery",
+						Webhook:   "some-webhook",
+						EventType: controller.EventFileUploaded,
+						Payload:   payload,
+						Attempts:  tc.attempts,
+					},
+				},
+			}
+
+			dispatcher := webhooks.NewDispatcher(
+				store,
+				[]webhooks.Webhook{
+					{
+						Name:    "some-webhook",
+						URL:     server.URL,
+						Secret:  "secret",
+						Buckets: nil,
+						Events:  nil,
+					},
+				},
+				logger,
+			)
+
+			dispatcher.DispatchPending(t.Context())
+
+			if diff := cmp.Diff(tc.expectedDelivered, store.delivered); diff != "" {
+				t.Error(diff)
+			}
+
+			if diff := cmp.Diff(tc.expectedRetried, store.retried); diff != "" {
+				t.Error(diff)
+			}
+
+			if diff := cmp.Diff(tc.expectedFailed, store.failed); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres implements Store with the storage.webhook_outbox table. Deliveries are
+// claimed with SKIP LOCKED so any number of replicas can dispatch them.
+type Postgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgres(ctx context.Context, connString string) (*Postgres, error) {
+	pool, err := pgxpool.New(ctx, connString)
+	if err != nil {
+		return nil, fmt.Errorf("problem creating postgres pool: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("problem connecting to postgres: %w", err)
+	}
+
+	return &Postgres{pool: pool}, nil
+}
+
+func (p *Postgres) Close() {
+	p.pool.Close()
+}
+
+func (p *Postgres) Insert(ctx context.Context, deliveries []Delivery) error {
+	batch := &pgx.Batch{} //nolint:exhaustruct
+	for _, d := range deliveries {
+		batch.Queue(
+			`INSERT INTO storage.webhook_outbox (webhook, event_type, payload)
+			VALUES ($1, $2, $3)`,
+			d.Webhook, d.EventType, d.Payload,
+		)
+	}
+
+	// a batch runs in an implicit transaction, either all the deliveries are stored or none
+	if err := p.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("problem inserting deliveries: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) Claim(
+	ctx context.Context, limit int, lease time.Duration,
+) ([]Delivery, error) {
+	rows, err := p.pool.Query(
+		ctx,
+		`UPDATE storage.webhook_outbox
+		SET attempts = attempts + 1, next_attempt_at = now() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM storage.webhook_outbox
+			WHERE delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, webhook, event_type, payload, attempts`,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("problem claiming deliveries: %w", err)
+	}
+
+	deliveries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Delivery, error) {
+		var d Delivery
+		err := row.Scan(&d.ID, &d.Webhook, &d.EventType, &d.Payload, &d.Attempts)
+
+		return d, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, fmt.Errorf("problem reading deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (p *Postgres) MarkDelivered(ctx context.Context, id string) error {
+	if _, err := p.pool.Exec(
+		ctx,
+		`UPDATE storage.webhook_outbox SET delivered_at = now(), last_error = NULL
+		WHERE id = $1`,
+		id,
+	); err != nil {
+		return fmt.Errorf("problem marking delivery as delivered: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) MarkRetry(ctx context.Context, id string, after time.Duration, lastErr string) error {
+	if _, err := p.pool.Exec(
+		ctx,
+		`UPDATE storage.webhook_outbox
+		SET next_attempt_at = now() + make_interval(secs => $2), last_error = $3
+		WHERE id = $1`,
+		id, after.Seconds(), lastErr,
+	); err != nil {
+		return fmt.Errorf("problem scheduling delivery retry: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) MarkFailed(ctx context.Context, id string, lastErr string) error {
+	if _, err := p.pool.Exec(
+		ctx,
+		`UPDATE storage.webhook_outbox SET failed_at = now(), last_error = $2 WHERE id = $1`,
+		id, lastErr,
+	); err != nil {
+		return fmt.Errorf("problem marking delivery as failed: %w", err)
+	}
+
+	return nil
+}